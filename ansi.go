@@ -0,0 +1,82 @@
+package csv2md
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	esc = '\x1b'
+	csi = '\u009b' // the C1 control equivalent of ESC [
+	bel = '\a'
+)
+
+// stripANSI removes the ANSI escape sequences and the other control
+// characters, see isControl, from the field.  The sequences are those of ECMA-48: control
+// sequences, e.g. ESC [ 31 m, control strings, e.g. the OSC 8 hyperlinks of
+// ESC ] 8 ; ; url BEL, which are terminated by a BEL or an ESC \, and the
+// other escape sequences, e.g. ESC ( B.  An unterminated sequence is
+// removed up to the end of the field.
+func stripANSI(field string) string {
+	var b strings.Builder
+	b.Grow(len(field))
+	for i := 0; i < len(field); {
+		r, n := utf8.DecodeRuneInString(field[i:])
+		switch {
+		case r == esc && i+1 < len(field) && field[i+1] == '[':
+			i = skipControlSequence(field, i+2)
+		case r == csi:
+			i = skipControlSequence(field, i+n)
+		case r == esc && i+1 < len(field) && strings.IndexByte("]PX^_", field[i+1]) >= 0:
+			i = skipControlString(field, i+2)
+		case r == esc:
+			i = skipEscapeSequence(field, i+1)
+		case isControl(r):
+			i += n
+		default:
+			b.WriteString(field[i : i+n])
+			i += n
+		}
+	}
+	return b.String()
+}
+
+// skipControlSequence returns the index of the byte that follows the
+// control sequence whose parameters start at i: parameter bytes, then
+// intermediate bytes, then the final byte.
+func skipControlSequence(field string, i int) int {
+	for i < len(field) && field[i] >= 0x20 && field[i] <= 0x3f {
+		i++
+	}
+	if i < len(field) && field[i] >= 0x40 && field[i] <= 0x7e {
+		i++
+	}
+	return i
+}
+
+// skipControlString returns the index of the byte that follows the BEL or
+// ESC \ that terminates the control string that starts at i.
+func skipControlString(field string, i int) int {
+	for ; i < len(field); i++ {
+		switch {
+		case field[i] == bel:
+			return i + 1
+		case field[i] == esc && i+1 < len(field) && field[i+1] == '\\':
+			return i + 2
+		}
+	}
+	return i
+}
+
+// skipEscapeSequence returns the index of the byte that follows the escape
+// sequence whose bytes, after the ESC, start at i: intermediate bytes and a
+// final byte.
+func skipEscapeSequence(field string, i int) int {
+	for i < len(field) && field[i] >= 0x20 && field[i] <= 0x2f {
+		i++
+	}
+	if i < len(field) && field[i] >= 0x30 && field[i] <= 0x7e {
+		i++
+	}
+	return i
+}
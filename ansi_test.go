@@ -0,0 +1,52 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"\x1b[31mred\x1b[0m", "red"},
+		{"\x1b[1;38;5;208mbold orange\x1b[m", "bold orange"},
+		{"\x1b[?25lhidden cursor\x1b[?25h", "hidden cursor"},
+		{"\u009b32mgreen\u009b0m", "green"},
+		{"\x1b]8;;https://example.com\x07link\x1b]8;;\x07", "link"},
+		{"\x1b]0;title\x1b\\text", "text"},
+		{"\x1b(Bascii", "ascii"},
+		{"\x1b7saved\x1b8", "saved"},
+		{"a\x00b\rc\td", "ab\rc\td"},
+		{"unterminated\x1b[31", "unterminated"},
+		{"unterminated\x1b]8;;url", "unterminated"},
+		{"trailing\x1b", "trailing"},
+		{"Zoë \x1b[4mNg\x1b[24m", "Zoë Ng"},
+		{"\x1b[31mMain St\x1b[0m\nApt 2", "Main St\nApt 2"},
+		{"Tree\x1b[K\r\nHouse", "Tree\r\nHouse"},
+	}
+	for i, test := range tests {
+		v := stripANSI(test.value)
+		if v != test.expected {
+			t.Errorf("%d: got %q want %q", i, v, test.expected)
+		}
+	}
+}
+
+// TestStripANSIQuotedLineBreak checks that StripANSI keeps the line break
+// in a quoted field so that it's written as the LineBreak.
+func TestStripANSIQuotedLineBreak(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader([]byte("Name,Address\nCalvin,\"\x1b[1mMain St\x1b[0m\nApt 2\"\n")), &w)
+	calvin.SanitizeControl = StripANSI
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Name|Address  \n---|---  \nCalvin|Main St<br>Apt 2  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
@@ -0,0 +1,71 @@
+package csv2md
+
+import (
+	"io"
+	"strings"
+)
+
+// the default text of SetColumnBool's true and false values
+const (
+	DefaultBoolTrue  = "✅"
+	DefaultBoolFalse = "❌"
+)
+
+// BoolOption configures the values of SetColumnBool.
+type BoolOption func(*boolean)
+
+// BoolText sets the text that true and false values are written as; e.g.
+// "yes" and "no", or "✔" and "".
+func BoolText(trueText, falseText string) BoolOption {
+	return func(b *boolean) {
+		b.text = [2]string{falseText, trueText}
+	}
+}
+
+type boolean struct {
+	t    *Transmogrifier
+	text [2]string // the false and true text
+}
+
+// SetColumnBool makes the named column a boolean column: values that are
+// booleans, true, false, yes, no, 1, or 0, in any case, are written as
+// DefaultBoolTrue or DefaultBoolFalse, a check mark or a cross, unless
+// BoolText is used.  Other values are written as is and empty values are
+// written as empty cells.
+//
+// A boolean column replaces any cell template the column has; see
+// SetColumnTemplate.  The values are normalized after the value maps and
+// before the field's styling.
+func (t *Transmogrifier) SetColumnBool(column string, opts ...BoolOption) {
+	b := &boolean{t: t, text: [2]string{DefaultBoolFalse, DefaultBoolTrue}}
+	for _, opt := range opts {
+		opt(b)
+	}
+	t.setColumnTemplate(&cellTemplate{column: column, exec: b.write, kind: "bool"})
+}
+
+func (b *boolean) write(w io.Writer, data CellData) error {
+	v, ok := parseBool(string(data.Value))
+	if !ok {
+		_, err := io.WriteString(w, b.t.escape(string(data.Value)))
+		return err
+	}
+	text := b.text[0]
+	if v {
+		text = b.text[1]
+	}
+	_, err := io.WriteString(w, b.t.escape(text))
+	return err
+}
+
+// parseBool returns the value's boolean, if it is one: true, false, yes,
+// no, 1, or 0, in any case.
+func parseBool(v string) (value, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "true", "yes", "1":
+		return true, true
+	case "false", "no", "0":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,40 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetColumnBool(t *testing.T) {
+	csvData := []byte("Feature,Supported\nExport,true\nImport,NO\nSync, 1 \nShare,0\nPrint,\nScan,maybe_later\n")
+	tests := []struct {
+		column   string
+		opts     []BoolOption
+		expected string
+		err      string
+	}{
+		{"Supported", nil, "Feature|Supported  \n---|---  \nExport|✅  \nImport|❌  \nSync|✅  \nShare|❌  \nPrint|   \nScan|maybe\\_later  \n", ""},
+		{"Supported", []BoolOption{BoolText("yes", "*no*")}, "Feature|Supported  \n---|---  \nExport|yes  \nImport|\\*no\\*  \nSync|yes  \nShare|\\*no\\*  \nPrint|   \nScan|maybe\\_later  \n", ""},
+		{"Supported", []BoolOption{BoolText("✔", "")}, "Feature|Supported  \n---|---  \nExport|✔  \nImport|   \nSync|✔  \nShare|   \nPrint|   \nScan|maybe\\_later  \n", ""},
+		{"Enabled", nil, "", `bool: unknown column "Enabled"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.SetColumnBool(test.column, test.opts...)
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
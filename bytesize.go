@@ -0,0 +1,80 @@
+package csv2md
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteUnits are the units of a byte size column; see SetColumnByteSize.
+type ByteUnits int
+
+const (
+	// BinaryUnits are powers of 1024: KiB, MiB, GiB, TiB, PiB, and EiB.
+	BinaryUnits ByteUnits = iota
+	// DecimalUnits are powers of 1000: kB, MB, GB, TB, PB, and EB.
+	DecimalUnits
+)
+
+var byteUnitNames = [...][]string{
+	BinaryUnits:  {"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"},
+	DecimalUnits: {"B", "kB", "MB", "GB", "TB", "PB", "EB"},
+}
+
+// ParseByteUnits parses the name of the byte units, binary or decimal; an
+// empty name is binary.
+func ParseByteUnits(s string) (ByteUnits, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "binary", "":
+		return BinaryUnits, nil
+	case "decimal":
+		return DecimalUnits, nil
+	}
+	return 0, fmt.Errorf("byte units %q: expected binary or decimal", s)
+}
+
+// SetColumnByteSize makes the named column a byte size column: its
+// values, numbers of bytes, are written in the largest unit they have at
+// least one of, with one digit after the decimal point, if it isn't 0,
+// e.g. 1288490189 is written as 1.2 GiB, and 364544 as 356 KiB.  Values
+// that aren't numbers are written as is.  The sizes are formatted after
+// the value maps and before the cell templates and the field's styling.
+// Setting the units of a column that already has a value format replaces
+// it.
+func (t *Transmogrifier) SetColumnByteSize(column string, units ByteUnits) {
+	t.setValueFormat(&valueFormat{column: column, kind: "byte size", format: units.format})
+}
+
+// format returns the number of bytes using the units if the value is a
+// number; otherwise the value is returned as is.
+func (u ByteUnits) format(v string) string {
+	n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+		return v
+	}
+	base := 1024.0
+	if u == DecimalUnits {
+		base = 1000
+	}
+	names := byteUnitNames[u]
+	var i int
+	size := math.Abs(n)
+	for i < len(names)-1 && size >= base {
+		size /= base
+		i++
+	}
+	// a size that rounds up to the base is written in the next unit
+	if i < len(names)-1 && math.Round(size*10)/10 >= base {
+		size /= base
+		i++
+	}
+	if n < 0 {
+		size = -size
+	}
+	if i == 0 {
+		return strconv.FormatFloat(n, 'f', -1, 64) + " " + names[i]
+	}
+	s := strconv.FormatFloat(size, 'f', 1, 64)
+	return strings.TrimSuffix(s, ".0") + " " + names[i]
+}
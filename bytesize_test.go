@@ -0,0 +1,123 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseByteUnits(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected ByteUnits
+		err      string
+	}{
+		{"", BinaryUnits, ""},
+		{"binary", BinaryUnits, ""},
+		{" Decimal", DecimalUnits, ""},
+		{"si", 0, `byte units "si": expected binary or decimal`},
+	}
+	for i, test := range tests {
+		u, err := ParseByteUnits(test.value)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if u != test.expected {
+			t.Errorf("%d: got %d want %d", i, u, test.expected)
+		}
+	}
+}
+
+func TestByteUnitsFormat(t *testing.T) {
+	tests := []struct {
+		units    ByteUnits
+		value    string
+		expected string
+	}{
+		{BinaryUnits, "0", "0 B"},
+		{BinaryUnits, "512", "512 B"},
+		{BinaryUnits, "1023", "1023 B"},
+		{BinaryUnits, "1024", "1 KiB"},
+		{BinaryUnits, "364544", "356 KiB"},
+		{BinaryUnits, " 1288490189 ", "1.2 GiB"},
+		{BinaryUnits, "1048575", "1 MiB"},
+		{BinaryUnits, "-2048", "-2 KiB"},
+		{BinaryUnits, "1.5e12", "1.4 TiB"},
+		{DecimalUnits, "364544", "364.5 kB"},
+		{DecimalUnits, "1288490189", "1.3 GB"},
+		{DecimalUnits, "999", "999 B"},
+		{DecimalUnits, "999999", "1 MB"},
+		{BinaryUnits, "n/a", "n/a"},
+		{BinaryUnits, "", ""},
+	}
+	for i, test := range tests {
+		v := test.units.format(test.value)
+		if v != test.expected {
+			t.Errorf("%d: %q: got %q want %q", i, test.value, v, test.expected)
+		}
+	}
+}
+
+func TestSetColumnByteSize(t *testing.T) {
+	csvData := "File,Size\ncalvin.log,1288490189\nhobbes.txt,364544\ntiger,unknown\n"
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+	calvin.SetColumnByteSize("Size", BinaryUnits)
+	calvin.SetFieldAlignment([]string{"", "r"})
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "File|Size  \n---|--:  \ncalvin.log|1.2 GiB  \nhobbes.txt|356 KiB  \ntiger|unknown  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+
+	calvin = NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+	calvin.SetColumnByteSize("Bytes", DecimalUnits)
+	err = calvin.MDTable()
+	if err == nil || err.Error() != `byte size: unknown column "Bytes"` {
+		t.Errorf("got error %v want an unknown column error", err)
+	}
+}
+
+func TestFieldFormatBytes(t *testing.T) {
+	csvData := "File,Size\ncalvin.log,1288490189\n"
+	tests := []struct {
+		formats  []FieldFormat
+		expected string
+		err      string
+	}{
+		{[]FieldFormat{{}, {Bytes: "binary"}}, "File|Size  \n---|---  \ncalvin.log|1.2 GiB  \n", ""},
+		{[]FieldFormat{{Column: "Size", Bytes: "decimal"}}, "File|Size  \n---|---  \ncalvin.log|1.3 GB  \n", ""},
+		{[]FieldFormat{{Column: "Size", Bytes: "si"}}, "", `field 1: byte units "si": expected binary or decimal`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		err := calvin.SetFieldFormats(test.formats)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package csv2md
+
+import "strings"
+
+// CaptionRenderer is a Renderer that writes the table's caption.  If the
+// Renderer does not implement CaptionRenderer, the caption is not written.
+type CaptionRenderer interface {
+	Renderer
+	// WriteCaption writes the table's caption; it is called before the
+	// header.
+	WriteCaption(caption string) error
+}
+
+// SetCaption sets the table's caption, its title, which is written before
+// the table.  In Markdown, the caption is a bold paragraph or, if the
+// CaptionHeading is set, a heading; in HTML, it is the table's <caption>
+// element.  When the table is split into multiple tables, the caption is
+// only written before the first one.  An empty caption means that no
+// caption is written.
+func (t *Transmogrifier) SetCaption(caption string) {
+	t.caption = caption
+}
+
+// writeCaption writes the caption, followed by a blank line, before the
+// table.  The HTML caption is written by the table itself.
+func (t *Transmogrifier) writeCaption() error {
+	t.captioned = false
+	if t.caption == "" {
+		return nil
+	}
+	if t.customRenderer != nil {
+		t.captioned = true
+		if r, ok := t.customRenderer.(CaptionRenderer); ok {
+			return r.WriteCaption(t.caption)
+		}
+		return nil
+	}
+	nl := t.lineEnd
+	var caption string
+	switch t.OutputFormat {
+	case HTML:
+		return nil
+	case Box, ASCIIBox:
+		caption = t.caption
+	default:
+		caption = "**" + t.escape(t.caption) + "**"
+		if t.CaptionHeading > 0 {
+			caption = strings.Repeat("#", t.CaptionHeading) + " " + t.escape(t.caption)
+		}
+	}
+	t.captioned = true
+	return t.write(caption+nl+nl, "caption")
+}
+
+// caption returns the HTML table's caption element if the caption has
+// not been written.
+func (h *htmlTable) caption() string {
+	if h.t.caption == "" || h.t.captioned {
+		return ""
+	}
+	h.t.captioned = true
+	return "<caption>" + htmlText(h.t.caption) + "</caption>" + h.nl()
+}
@@ -0,0 +1,70 @@
+package csv2md
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type captionRecorder struct {
+	recorder
+}
+
+func (r *captionRecorder) WriteCaption(caption string) error {
+	r.calls = append(r.calls, "caption "+caption)
+	return nil
+}
+
+func TestSetCaption(t *testing.T) {
+	csvData := []byte("Make,Model\nFord,Focus\nKia,Rio\n")
+	tests := []struct {
+		caption  string
+		heading  int
+		format   Format
+		split    bool
+		expected string
+	}{
+		{"", 0, GFM, false, "Make|Model  \n---|---  \nFord|Focus  \nKia|Rio  \n"},
+		{"Q3 *Sales*", 0, GFM, false, "**Q3 \\*Sales\\***\n\nMake|Model  \n---|---  \nFord|Focus  \nKia|Rio  \n"},
+		{"Q3 Sales", 3, GFM, false, "### Q3 Sales\n\nMake|Model  \n---|---  \nFord|Focus  \nKia|Rio  \n"},
+		{"Q3 Sales", 0, GFM, true, "**Q3 Sales**\n\n## Ford\n\nMake|Model  \n---|---  \nFord|Focus  \n\n## Kia\n\nMake|Model  \n---|---  \nKia|Rio  \n"},
+		{"Q3 & Q4", 2, HTML, false, "<table>\n<caption>Q3 &amp; Q4</caption>\n<thead>\n<tr>\n<th>Make</th>\n<th>Model</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>Ford</td>\n<td>Focus</td>\n</tr>\n<tr>\n<td>Kia</td>\n<td>Rio</td>\n</tr>\n</tbody>\n</table>\n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.SetCaption(test.caption)
+		calvin.CaptionHeading = test.heading
+		calvin.OutputFormat = test.format
+		if test.split {
+			calvin.SplitBy("Make")
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestSetCaptionRenderer(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader([]byte("Make\nFord\n")), &w)
+	r := &captionRecorder{}
+	calvin.SetRenderer(r)
+	calvin.SetCaption("Cars")
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{"caption Cars", `header ["Make"]`, "separator [0]", "row [{Ford 0 [] false []}]", "close"}
+	if !reflect.DeepEqual(r.calls, expected) {
+		t.Errorf("got %q want %q", r.calls, expected)
+	}
+	if w.Len() != 0 {
+		t.Errorf("got %q want nothing written", w.String())
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	"github.com/mohae/csv2md"
+)
+
+// command is one of csv2md's commands, e.g. fmt.  The flags are shared by
+// all of the commands; each command uses the ones that apply to it.
+type command struct {
+	name string
+	// words is the number of words, after the command's name, that are
+	// part of the command, e.g. fmt's init or check
+	words   int
+	usage   string
+	summary string
+	// run runs the command with its words and inputs; false is returned
+	// if the command failed without an error, e.g. a check found problems
+	run func(sub []string, inputs []string) (bool, error)
+}
+
+// commands are the commands; convert is the default command.
+var commands = []command{
+	{name: "convert", usage: "[OPTS] [FILE...]", summary: "write the inputs' Markdown tables; the default command", run: convertCommand},
+	{name: "check", usage: "[OPTS] [FILE...]", summary: "check that the output is up to date; the same as convert -check", run: checkCommand},
+	{name: "fmt", words: 1, usage: "init|check [OPTS] FILE...", summary: "write a starter format spec for each input, or check each input's format file", run: fmtCommand},
+	{name: "md2csv", usage: "[OPTS] [FILE]", summary: "write the input's Markdown table as CSV-encoded data, and its format to the -formatfile", run: md2csvCommand},
+}
+
+// the completion command is added when the package is initialized, as its
+// scripts complete the other commands
+func init() {
+	commands = append(commands, command{name: "completion", words: 1, usage: "bash|zsh|fish", summary: "write the shell's completion script, which completes the commands, flags, flag values, and files", run: completionCommand})
+}
+
+// findCommand returns the command named by the first of the args and its
+// words; if the args don't start with a command's name, the command is
+// convert and there are no words.
+func findCommand(args []string) (command, []string) {
+	if len(args) > 0 {
+		for _, cmd := range commands {
+			if args[0] != cmd.name {
+				continue
+			}
+			n := 1 + cmd.words
+			if n > len(args) {
+				n = len(args)
+			}
+			return cmd, args[:n]
+		}
+	}
+	return commands[0], nil
+}
+
+// convertCommand writes the inputs' tables, checks them, see -check, or
+// watches the inputs, see -watch.
+func convertCommand(sub []string, inputs []string) (bool, error) {
+	switch {
+	case check:
+		return checkOutputs(inputs)
+	case watch:
+		return true, watchInputs(inputs)
+	}
+	return true, run(inputs)
+}
+
+// checkCommand checks that the inputs' tables are up to date.
+func checkCommand(sub []string, inputs []string) (bool, error) {
+	check = true
+	return convertCommand(sub, inputs)
+}
+
+// md2csvCommand reads the input's Markdown table and writes it to the
+// output as CSV-encoded data.  If a format file is specified, the table's
+// field names, alignment, and styling are written to it.
+func md2csvCommand(sub []string, inputs []string) (bool, error) {
+	if len(inputs) > 1 {
+		return false, usageError(fmt.Errorf("md2csv: expected a single input, got %d", len(inputs)))
+	}
+	input := inputs[0]
+	in := os.Stdin
+	if input != "stdin" {
+		f, err := os.Open(input)
+		if err != nil {
+			return false, inputError(fmt.Errorf("input file error: %w", err))
+		}
+		defer f.Close()
+		in = f
+	}
+	tbl, err := csv2md.ReadMDTable(in)
+	if err != nil {
+		return false, withStatus(exitParse, fmt.Errorf("%s: %w", input, err))
+	}
+	out := os.Stdout
+	if output != "stdout" {
+		out, err = os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return false, outputError(fmt.Errorf("output file error: %w", err))
+		}
+		defer out.Close()
+	}
+	err = tbl.WriteCSV(csvWriter(out))
+	if err != nil {
+		return false, outputError(err)
+	}
+	if formatFile == "" {
+		return true, nil
+	}
+	f, err := os.OpenFile(formatFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return false, outputError(fmt.Errorf("format file error: %w", err))
+	}
+	defer f.Close()
+	return true, outputError(tbl.WriteFmt(csvWriter(f)))
+}
+
+// csvWriter returns a CSV writer of f that uses the -separator.
+func csvWriter(f *os.File) *csv.Writer {
+	w := csv.NewWriter(f)
+	if len(separator) > 0 {
+		w.Comma = []rune(separator)[0]
+	}
+	return w
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// how a flag's value is completed
+const (
+	anyArg  = iota // the value isn't completed
+	noArg          // the flag doesn't have a value, e.g. -pretty
+	enumArg        // the value is one of the flag's values
+	fileArg        // the value is a file
+	dirArg         // the value is a directory
+)
+
+// flagValues are the values of the flags that have a fixed set of values,
+// e.g. -output-format; they must be kept in step with what the flags
+// accept.
+var flagValues = map[string][]string{
+	"byte-units":    {"binary", "decimal"},
+	"cell-html":     {"default", "escape", "strip", "pass"},
+	"decompress":    {"auto", "gzip", "bzip2", "zstd", "none"},
+	"duration-unit": {"ns", "us", "ms", "s", "m", "h"},
+	"encoding":      {"utf-8", "utf-16le", "utf-16be", "latin1", "windows-1252"},
+	"header-align":  {"left", "center", "right", "auto"},
+	"header-style":  {"bold", "italic", "strike"},
+	"locale":        {"en", "de", "fr", "ch"},
+	"metadata":      {"yaml", "json", "none"},
+	"newline":       {"lf", "cr", "crlf"},
+	"output-format": {"gfm", "html", "box", "ascii"},
+	"ragged":        {"error", "pad", "truncate"},
+	"sanitize":      {"strip", "escape", "error", "ansi"},
+}
+
+// fileFlags are the flags whose value is a file.
+var fileFlags = map[string]bool{
+	"config":       true,
+	"error-report": true,
+	"formatfile":   true,
+	"inject":       true,
+	"input":        true,
+	"output":       true,
+}
+
+// completionShells are the shells that completion scripts are written for.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// completionFlag is a flag and how its value is completed.
+type completionFlag struct {
+	name    string
+	summary string
+	arg     int
+	values  []string
+}
+
+// completionCommand writes the shell's completion script to stdout.
+func completionCommand(sub []string, inputs []string) (bool, error) {
+	if len(sub) < 2 {
+		return false, usageError(fmt.Errorf("usage: %s completion bash|zsh|fish", prog))
+	}
+	w := bufio.NewWriter(os.Stdout)
+	switch sub[1] {
+	case "bash":
+		writeBashCompletion(w, completionFlags())
+	case "zsh":
+		writeZshCompletion(w, completionFlags())
+	case "fish":
+		writeFishCompletion(w, completionFlags())
+	default:
+		return false, usageError(fmt.Errorf("unknown completion shell %q: expected bash, zsh, or fish", sub[1]))
+	}
+	return true, outputError(w.Flush())
+}
+
+// completionFlags returns the flags, in lexicographical order, and how
+// their values are completed.  The short flags and aliases are completed
+// like the flags they are for.
+func completionFlags() []completionFlag {
+	var flags []completionFlag
+	flag.VisitAll(func(f *flag.Flag) {
+		c := completionFlag{name: f.Name, summary: flagSummary(f.Usage)}
+		name := aliasedFlag(f)
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			c.arg = noArg
+		} else if c.values = flagValues[name]; c.values != nil {
+			c.arg = enumArg
+		} else if fileFlags[name] {
+			c.arg = fileArg
+		} else if name == "outdir" {
+			c.arg = dirArg
+		}
+		flags = append(flags, c)
+	})
+	return flags
+}
+
+// aliasedFlag returns the name of the flag that the flag is a short flag
+// for, or an alias of; otherwise it is the flag's name.
+func aliasedFlag(f *flag.Flag) string {
+	for _, prefix := range []string{"short flag for -", "alias for -"} {
+		if strings.HasPrefix(f.Usage, prefix) {
+			return f.Usage[len(prefix):]
+		}
+	}
+	return f.Name
+}
+
+// flagSummary returns the first clause of a flag's usage, which is short
+// enough to be shown next to it.
+func flagSummary(usage string) string {
+	if i := strings.Index(usage, "; "); i > 0 {
+		return usage[:i]
+	}
+	return usage
+}
+
+// commandWords returns the words that may follow the command, e.g. fmt's
+// init and check.
+func commandWords(name string) []string {
+	switch name {
+	case "completion":
+		return completionShells
+	case "fmt":
+		return []string{"init", "check"}
+	}
+	return nil
+}
+
+// writeBashCompletion writes the bash completion script.
+func writeBashCompletion(w io.Writer, flags []completionFlag) {
+	var names []string
+	for _, f := range flags {
+		names = append(names, "-"+f.name)
+	}
+	fmt.Fprintf(w, "# bash completion for %s; source it, e.g. from ~/.bashrc:\n", prog)
+	fmt.Fprintf(w, "#   source <(%s completion bash)\n", prog)
+	fmt.Fprintf(w, "_%s() {\n", funcName(prog))
+	fmt.Fprintf(w, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(w, "\tCOMPREPLY=()\n")
+	fmt.Fprintf(w, "\tcase \"$prev\" in\n")
+	for _, f := range flags {
+		switch f.arg {
+		case noArg:
+			continue
+		case enumArg:
+			fmt.Fprintf(w, "\t-%s|--%s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return;;\n", f.name, f.name, strings.Join(f.values, " "))
+		case fileArg:
+			fmt.Fprintf(w, "\t-%s|--%s) COMPREPLY=($(compgen -f -- \"$cur\")); return;;\n", f.name, f.name)
+		case dirArg:
+			fmt.Fprintf(w, "\t-%s|--%s) COMPREPLY=($(compgen -d -- \"$cur\")); return;;\n", f.name, f.name)
+		default:
+			fmt.Fprintf(w, "\t-%s|--%s) return;;\n", f.name, f.name)
+		}
+	}
+	fmt.Fprintf(w, "\tesac\n")
+	fmt.Fprintf(w, "\tif [[ $cur == -* ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Fprintf(w, "\t\treturn\n")
+	fmt.Fprintf(w, "\tfi\n")
+	fmt.Fprintf(w, "\tif [[ $COMP_CWORD -eq 2 ]]; then\n")
+	fmt.Fprintf(w, "\t\tcase \"$prev\" in\n")
+	for _, cmd := range commands {
+		if words := commandWords(cmd.name); words != nil {
+			fmt.Fprintf(w, "\t\t%s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return;;\n", cmd.name, strings.Join(words, " "))
+		}
+	}
+	fmt.Fprintf(w, "\t\tesac\n")
+	fmt.Fprintf(w, "\tfi\n")
+	fmt.Fprintf(w, "\tif [[ $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(commandNames(), " "))
+	fmt.Fprintf(w, "\tfi\n")
+	fmt.Fprintf(w, "\tCOMPREPLY+=($(compgen -f -- \"$cur\"))\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "complete -o filenames -F _%s %s\n", funcName(prog), prog)
+}
+
+// writeZshCompletion writes the zsh completion script.
+func writeZshCompletion(w io.Writer, flags []completionFlag) {
+	fmt.Fprintf(w, "#compdef %s\n", prog)
+	fmt.Fprintf(w, "# zsh completion for %s; save it as _%s in a directory of the $fpath,\n", prog, prog)
+	fmt.Fprintf(w, "# e.g. %s completion zsh > ~/.zsh/completions/_%s\n", prog, prog)
+	fmt.Fprintf(w, "_%s() {\n", funcName(prog))
+	fmt.Fprintf(w, "\tlocal -a commands\n")
+	fmt.Fprintf(w, "\tcommands=(\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t\t%s\n", shellQuote(cmd.name+":"+strings.Replace(cmd.summary, ":", `\:`, -1)))
+	}
+	fmt.Fprintf(w, "\t)\n")
+	fmt.Fprintf(w, "\t_arguments \\\n")
+	for _, f := range flags {
+		spec := "-" + f.name + "[" + zshEscape(f.summary) + "]"
+		switch f.arg {
+		case enumArg:
+			spec += ":" + f.name + ":(" + strings.Join(f.values, " ") + ")"
+		case fileArg:
+			spec += ":" + f.name + ":_files"
+		case dirArg:
+			spec += ":" + f.name + ":_files -/"
+		case anyArg:
+			spec += ":" + f.name + ": "
+		}
+		fmt.Fprintf(w, "\t\t%s \\\n", shellQuote(spec))
+	}
+	fmt.Fprintf(w, "\t\t'1: :->first' \\\n")
+	fmt.Fprintf(w, "\t\t'2: :->second' \\\n")
+	fmt.Fprintf(w, "\t\t'*:file:_files'\n")
+	fmt.Fprintf(w, "\tcase $state in\n")
+	fmt.Fprintf(w, "\tfirst)\n")
+	fmt.Fprintf(w, "\t\t_describe -t commands command commands\n")
+	fmt.Fprintf(w, "\t\t_files\n")
+	fmt.Fprintf(w, "\t\t;;\n")
+	fmt.Fprintf(w, "\tsecond)\n")
+	fmt.Fprintf(w, "\t\tcase $line[1] in\n")
+	for _, cmd := range commands {
+		if words := commandWords(cmd.name); words != nil {
+			fmt.Fprintf(w, "\t\t%s) compadd %s;;\n", cmd.name, strings.Join(words, " "))
+		}
+	}
+	fmt.Fprintf(w, "\t\t*) _files;;\n")
+	fmt.Fprintf(w, "\t\tesac\n")
+	fmt.Fprintf(w, "\t\t;;\n")
+	fmt.Fprintf(w, "\tesac\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "_%s \"$@\"\n", funcName(prog))
+}
+
+// writeFishCompletion writes the fish completion script.
+func writeFishCompletion(w io.Writer, flags []completionFlag) {
+	fmt.Fprintf(w, "# fish completion for %s; save it in a completions directory, e.g.\n", prog)
+	fmt.Fprintf(w, "#   %s completion fish > ~/.config/fish/completions/%s.fish\n", prog, prog)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a %s -d %s\n", prog, cmd.name, shellQuote(cmd.summary))
+		if words := commandWords(cmd.name); words != nil {
+			fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from %s' -f -a %s\n", prog, cmd.name, shellQuote(strings.Join(words, " ")))
+		}
+	}
+	for _, f := range flags {
+		var arg string
+		switch f.arg {
+		case enumArg:
+			arg = " -x -a " + shellQuote(strings.Join(f.values, " "))
+		case fileArg:
+			arg = " -r -F"
+		case dirArg:
+			arg = " -x -a '(__fish_complete_directories)'"
+		case anyArg:
+			arg = " -x"
+		}
+		fmt.Fprintf(w, "complete -c %s -o %s%s -d %s\n", prog, f.name, arg, shellQuote(f.summary))
+	}
+}
+
+// commandNames returns the names of the commands.
+func commandNames() []string {
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.name
+	}
+	return names
+}
+
+// funcName returns the name as part of a shell function's name; the
+// characters that can't be part of one are replaced with underscores.
+func funcName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// zshEscape escapes the characters that are special in the _arguments
+// specs' descriptions.
+func zshEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, ":", `\:`).Replace(s)
+}
+
+// shellQuote returns the value as a single quoted shell word.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
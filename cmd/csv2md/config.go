@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// the names of the config files, in the order they are searched for
+var configNames = []string{".csv2md.toml", "csv2md.toml", ".csv2md.yaml", "csv2md.yaml", ".csv2md.yml", "csv2md.yml"}
+
+// setting is a config file setting: a flag and its values; a list has a
+// value per element.
+type setting struct {
+	line   int
+	name   string
+	values []string
+}
+
+// loadConfig sets the flags that were not set on the command line to the
+// config file's settings.  The config file is the -config file or, if that
+// isn't set, the first config file found in the working directory or the
+// home directory.  A -config of none disables the config file.
+func loadConfig() error {
+	name := configFile
+	if name == "none" {
+		return nil
+	}
+	if name == "" {
+		name = findConfig()
+		if name == "" {
+			return nil
+		}
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("config file error: %w", err)
+	}
+	defer f.Close()
+	ext := strings.ToLower(filepath.Ext(name))
+	settings, err := parseConfig(f, ext == ".yaml" || ext == ".yml")
+	if err != nil {
+		return fmt.Errorf("%s:%w", name, err)
+	}
+	// the flags set on the command line, by their long names, take
+	// precedence
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[longName(f)] = true
+	})
+	for _, s := range settings {
+		f := flag.Lookup(s.name)
+		if f == nil {
+			return fmt.Errorf("%s:%d: unknown flag %q", name, s.line, s.name)
+		}
+		if set[longName(f)] {
+			continue
+		}
+		for _, v := range s.values {
+			err = flag.Set(s.name, v)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %s: %w", name, s.line, s.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// longName returns the name of the flag that a short flag or an alias is
+// for; the name of any other flag is returned as is.
+func longName(f *flag.Flag) string {
+	for _, prefix := range []string{"short flag for -", "alias for -"} {
+		if strings.HasPrefix(f.Usage, prefix) {
+			return strings.TrimPrefix(f.Usage, prefix)
+		}
+	}
+	return f.Name
+}
+
+// findConfig returns the first config file that exists in the working
+// directory or the home directory; an empty string is returned if there
+// isn't one.
+func findConfig() string {
+	dirs := []string{"."}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, home)
+	}
+	for _, dir := range dirs {
+		for _, name := range configNames {
+			path := filepath.Join(dir, name)
+			if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// parseConfig parses the config file's settings.  The config file is
+// either TOML, with key = value settings, or YAML, with key: value
+// settings.  Only the subset of TOML and YAML needed for flags is
+// supported: the values are strings, numbers, booleans, or lists of them.
+// A list is either on one line, [a, b], or, in YAML, a block of "- value"
+// lines.  The keys are the flag names; an underscore may be used for a
+// hyphen, e.g. split_by.
+func parseConfig(r io.Reader, yaml bool) ([]setting, error) {
+	sep := "="
+	if yaml {
+		sep = ":"
+	}
+	var settings []setting
+	var block *setting // the YAML setting whose list is being read
+	s := bufio.NewScanner(r)
+	var n int
+	for s.Scan() {
+		n++
+		line := strings.TrimSpace(stripComment(s.Text()))
+		if line == "" || (yaml && (line == "---" || line == "...")) {
+			continue
+		}
+		if yaml && (strings.HasPrefix(line, "- ") || line == "-") {
+			if block == nil {
+				return nil, fmt.Errorf("%d: list item without a key", n)
+			}
+			v, err := parseConfigValue(strings.TrimSpace(strings.TrimPrefix(line, "-")), yaml)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", n, err)
+			}
+			block.values = append(block.values, v)
+			continue
+		}
+		block = nil
+		if !yaml && strings.HasPrefix(line, "[") {
+			return nil, fmt.Errorf("%d: tables are not supported", n)
+		}
+		i := strings.Index(line, sep)
+		if i < 1 {
+			return nil, fmt.Errorf("%d: expected key %s value", n, sep)
+		}
+		key := strings.Trim(strings.TrimSpace(line[:i]), `"'`)
+		set := setting{line: n, name: strings.Replace(key, "_", "-", -1)}
+		v := strings.TrimSpace(line[i+1:])
+		switch {
+		case v == "" && yaml:
+			// the values are a block list
+			settings = append(settings, set)
+			block = &settings[len(settings)-1]
+			continue
+		case strings.HasPrefix(v, "["):
+			if !strings.HasSuffix(v, "]") {
+				return nil, fmt.Errorf("%d: a list must be on one line", n)
+			}
+			for _, e := range splitConfigList(v[1 : len(v)-1]) {
+				e, err := parseConfigValue(e, yaml)
+				if err != nil {
+					return nil, fmt.Errorf("%d: %w", n, err)
+				}
+				set.values = append(set.values, e)
+			}
+		default:
+			e, err := parseConfigValue(v, yaml)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", n, err)
+			}
+			set.values = []string{e}
+		}
+		settings = append(settings, set)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// parseConfigValue returns the value of a scalar: a quoted string is
+// unquoted, anything else is used as is.  In TOML, anything else must be a
+// number or a boolean.
+func parseConfigValue(v string, yaml bool) (string, error) {
+	if v == "" {
+		return "", fmt.Errorf("missing value")
+	}
+	switch v[0] {
+	case '"':
+		s, err := strconv.Unquote(v)
+		if err != nil {
+			return "", fmt.Errorf("%s: invalid string", v)
+		}
+		return s, nil
+	case '\'':
+		if len(v) < 2 || v[len(v)-1] != '\'' {
+			return "", fmt.Errorf("%s: invalid string", v)
+		}
+		s := v[1 : len(v)-1]
+		if yaml {
+			s = strings.Replace(s, "''", "'", -1)
+		}
+		return s, nil
+	}
+	if !yaml && v != "true" && v != "false" {
+		if _, err := strconv.ParseFloat(strings.Replace(v, "_", "", -1), 64); err != nil {
+			return "", fmt.Errorf("%s: strings must be quoted", v)
+		}
+	}
+	return v, nil
+}
+
+// stripComment removes a # comment from the line; a # in a quoted string
+// is not a comment.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitConfigList splits the elements of a one line list on the commas
+// that aren't in quoted strings.
+func splitConfigList(s string) []string {
+	var elems []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			elems = append(elems, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		elems = append(elems, last)
+	}
+	return elems
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffOp is an operation of an edit script: a line that is kept, deleted
+// from a, or inserted from b.
+type diffOp struct {
+	kind byte // ' ', '-', or '+'
+	a, b int  // the line's index in a and b
+}
+
+// writeDiff writes the unified diff of a, the named file's contents, and
+// b, the contents that were generated for it.
+func writeDiff(w io.Writer, name string, a, b []byte) error {
+	al, bl := splitLines(a), splitLines(b)
+	ops := diffLines(al, bl)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s (generated)\n", name, name)
+	for i := 0; i < len(ops); {
+		// find the next change
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		// the hunk ends when more than twice the context of unchanged
+		// lines follows a change
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+				continue
+			}
+			if j-end >= 2*diffContext {
+				break
+			}
+		}
+		stop := end + diffContext
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+		writeHunk(&buf, al, bl, ops[start:stop])
+		i = stop
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeHunk writes the hunk of the edit script.
+func writeHunk(buf *bytes.Buffer, a, b []string, ops []diffOp) {
+	var na, nb int
+	for _, op := range ops {
+		if op.kind != '+' {
+			na++
+		}
+		if op.kind != '-' {
+			nb++
+		}
+	}
+	fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(ops[0].a, na), hunkRange(ops[0].b, nb))
+	for _, op := range ops {
+		// an inserted line's index in a can be past a's last line
+		var line string
+		if op.kind == '+' {
+			line = b[op.b]
+		} else {
+			line = a[op.a]
+		}
+		buf.WriteByte(op.kind)
+		buf.WriteString(line)
+		if len(line) == 0 || line[len(line)-1] != '\n' {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange returns the hunk's range of lines; the lines are numbered from
+// one and an empty range is numbered after the line it follows.
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if n == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+// splitLines splits b into lines; each line keeps its line ending.
+func splitLines(b []byte) []string {
+	var lines []string
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n') + 1
+		if i == 0 {
+			i = len(b)
+		}
+		lines = append(lines, string(b[:i]))
+		b = b[i:]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script that turns a into b, using
+// Myers' algorithm.  The index of a deleted line in b, and of an inserted
+// line in a, is the index of the line that follows it.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	max := n + m
+	v := make([]int, 2*max+2)
+	var trace [][]int
+	var d int
+loop:
+	for d = 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+				x = v[max+k+1]
+			} else {
+				x = v[max+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[max+k] = x
+			if x >= n && y >= m {
+				break loop
+			}
+		}
+	}
+	// walk the trace back from the end to build the script
+	var ops []diffOp
+	x, y := n, m
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[max+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{' ', x, y})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{'+', x, y})
+		} else {
+			x--
+			ops = append(ops, diffOp{'-', x, y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, diffOp{' ', x, y})
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteDiff(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected string
+	}{
+		{"a\nb\nc\n", "a\nx\nc\n", "--- t.md\n+++ t.md (generated)\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n"},
+		// lines appended to the end of the file
+		{"a\nb\n", "a\nb\nc\nd\n", "--- t.md\n+++ t.md (generated)\n@@ -1,2 +1,4 @@\n a\n b\n+c\n+d\n"},
+		{"", "a\n", "--- t.md\n+++ t.md (generated)\n@@ -0,0 +1 @@\n+a\n"},
+		{"a\nb\n", "a\n", "--- t.md\n+++ t.md (generated)\n@@ -1,2 +1 @@\n a\n-b\n"},
+		{"a\n", "a", "--- t.md\n+++ t.md (generated)\n@@ -1 +1 @@\n-a\n+a\n\\ No newline at end of file\n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		err := writeDiff(&w, "t.md", []byte(test.a), []byte(test.b))
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
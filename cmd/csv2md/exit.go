@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+
+	"github.com/mohae/csv2md"
+)
+
+// the exit statuses, so that scripts can tell why csv2md failed; they are
+// listed in the README
+const (
+	exitOK      = 0
+	exitFailure = 1 // any other error
+	exitUsage   = 2 // the flags or arguments are invalid, like the flag package's
+	exitInput   = 3 // an input couldn't be read
+	exitParse   = 4 // an input's CSV-encoded data, or Markdown table, is malformed
+	exitFormat  = 5 // the format file couldn't be read or is invalid
+	exitOutput  = 6 // the output couldn't be written
+	exitCheck   = 7 // the output isn't up to date, or a format file has problems
+)
+
+// exitError is an error and the exit status that it causes.
+type exitError struct {
+	status int
+	err    error
+}
+
+func (e exitError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error.
+func (e exitError) Unwrap() error {
+	return e.err
+}
+
+// withStatus returns the error with the exit status; an error that already
+// has one keeps it.
+func withStatus(status int, err error) error {
+	var e exitError
+	if err == nil || errors.As(err, &e) {
+		return err
+	}
+	return exitError{status: status, err: err}
+}
+
+func usageError(err error) error {
+	return withStatus(exitUsage, err)
+}
+
+func inputError(err error) error {
+	return withStatus(exitInput, err)
+}
+
+func formatError(err error) error {
+	return withStatus(exitFormat, err)
+}
+
+func outputError(err error) error {
+	return withStatus(exitOutput, err)
+}
+
+// exitStatus returns the exit status of the error.
+func exitStatus(err error) int {
+	var e exitError
+	if errors.As(err, &e) {
+		return e.status
+	}
+	return exitFailure
+}
+
+// conversionStatus returns the exit status of an error that occurred while
+// a table was being converted: the errors of malformed records are parse
+// errors, reading from and writing to the files are input and output
+// errors, a value that isn't in its -map file is a format error, like a
+// record that doesn't match the format, and a column that isn't in an
+// input's header is a usage error, as it was named by a flag or the format
+// file.
+func conversionStatus(err error) int {
+	var perr *os.PathError
+	if errors.As(err, &perr) {
+		if perr.Op == "write" {
+			return exitOutput
+		}
+		return exitInput
+	}
+	var (
+		parseErr  *csv.ParseError
+		countErr  csv2md.FieldCountError
+		raggedErr csv2md.RaggedRecordError
+		sizeErr   csv2md.FieldTooLargeError
+		ctrlErr   csv2md.ControlCharError
+		writeErr  csv2md.ShortWriteError
+		columnErr csv2md.UnknownColumnError
+		mapErr    csv2md.UnmappedValueError
+	)
+	switch {
+	case errors.As(err, &countErr), errors.As(err, &mapErr):
+		// the format doesn't match the data, see -strict and -map-strict
+		return exitFormat
+	case errors.As(err, &parseErr), errors.As(err, &raggedErr), errors.As(err, &sizeErr), errors.As(err, &ctrlErr):
+		return exitParse
+	case errors.As(err, &writeErr):
+		return exitOutput
+	case errors.As(err, &columnErr):
+		return exitUsage
+	}
+	return exitFailure
+}
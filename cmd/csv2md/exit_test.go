@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestUnmappedValueStatus checks that, with -map-strict, a value that isn't
+// in its -map file is a format error.
+func TestUnmappedValueStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "csv2md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "issues.csv")
+	err = ioutil.WriteFile(input, []byte("ID,Status\n1,1\n2,3\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statusMap := filepath.Join(dir, "status.csv")
+	err = ioutil.WriteFile(statusMap, []byte("1,Open\n2,Closed\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(q bool, m string, s bool) { quiet, mapFiles, mapStrict = q, m, s }(quiet, mapFiles, mapStrict)
+	quiet = true
+	logger, err = newLogger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapFiles = "Status=" + statusMap
+	tests := []struct {
+		mapStrict bool
+		status    int
+	}{
+		{false, exitOK},
+		{true, exitFormat},
+	}
+	for _, test := range tests {
+		mapStrict = test.mapStrict
+		var w bytes.Buffer
+		err = transmogrify([]string{input}, &w, "")
+		status := exitOK
+		if err != nil {
+			status = exitStatus(err)
+		}
+		if status != test.status {
+			t.Errorf("-map-strict=%t: got exit status %d (%v) want %d", test.mapStrict, status, err, test.status)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/mohae/csv2md"
+)
+
+// fmtCommand runs the fmt subcommand: fmt init writes a starter format spec
+// for each input and fmt check checks each input's format file.  False is
+// returned if a format file has problems.
+func fmtCommand(sub []string, inputs []string) (bool, error) {
+	if len(sub) < 2 {
+		return false, usageError(fmt.Errorf("usage: %s fmt init|check [OPTS] FILE...", prog))
+	}
+	switch sub[1] {
+	case "init":
+		if output != "stdout" && len(inputs) > 1 {
+			return false, usageError(fmt.Errorf("fmt init: the -output flag can only be used with a single input"))
+		}
+		for _, input := range inputs {
+			err := initFormatSpec(input)
+			if err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	case "check":
+		if formatFile != "" && len(inputs) > 1 {
+			return false, usageError(fmt.Errorf("fmt check: the -formatfile flag can only be used with a single input"))
+		}
+		ok := true
+		for _, input := range inputs {
+			checked, err := checkFormatFile(input)
+			if err != nil {
+				return false, err
+			}
+			ok = ok && checked
+		}
+		return ok, nil
+	}
+	return false, usageError(fmt.Errorf("unknown fmt command %q: expected init or check", sub[1]))
+}
+
+// initFormatSpec writes a format spec for the input, with the field types
+// and alignments inferred from its header record and a sample of its
+// records.  The spec is written to the output or, by default, next to the
+// input, e.g. data.fmt.yaml for data.csv.  An existing spec is only
+// overwritten with -force.
+func initFormatSpec(input string) error {
+	name := output
+	if name == "stdout" {
+		if input == "stdin" {
+			return usageError(fmt.Errorf("fmt init: cannot infer the format spec location when using stdin for the input; the location must be specified using the '-output' flag"))
+		}
+		name = trimExt(input) + ".fmt.yaml"
+	}
+	syntax := specSyntax(name)
+	if syntax == "" {
+		return usageError(fmt.Errorf("fmt init: %s: the format spec must be a .yaml, .yml, .toml, or .json file", name))
+	}
+	if !force {
+		if _, err := os.Stat(name); err == nil {
+			return usageError(fmt.Errorf("fmt init: %s already exists; use -force to overwrite it", name))
+		}
+	}
+	in := os.Stdin
+	if input != "stdin" {
+		var err error
+		in, err = os.Open(input)
+		if err != nil {
+			return inputError(fmt.Errorf("input file error: %w", err))
+		}
+		defer in.Close()
+	}
+	t, err := fmtTransmogrifier(in, input)
+	if err != nil {
+		return err
+	}
+	formats, err := t.InferFieldFormats()
+	if err != nil {
+		return withStatus(conversionStatus(err), fmt.Errorf("%s: %w", input, err))
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return outputError(fmt.Errorf("format file error: %w", err))
+	}
+	w := bufio.NewWriter(f)
+	err = csv2md.WriteFieldFormats(w, formats, syntax)
+	if err == nil {
+		err = w.Flush()
+	}
+	cerr := f.Close()
+	if err != nil {
+		return outputError(fmt.Errorf("format file error: %w", err))
+	}
+	if cerr != nil {
+		return outputError(fmt.Errorf("format file error: %w", cerr))
+	}
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "%s: format spec written to %s\n", input, name)
+	}
+	return nil
+}
+
+// formatFileExts are the extensions of the format files that are looked for
+// next to an input, in order.
+var formatFileExts = []string{".fmt", ".fmt.yaml", ".fmt.yml", ".fmt.toml", ".fmt.json"}
+
+// inferFormatFile returns the name of the input's format file: the input's
+// name with its extension replaced by the first of the formatFileExts that
+// exists, e.g. data.fmt.yaml, as written by fmt init, for data.csv.  If none
+// of them exists, the .fmt name is returned with false.
+func inferFormatFile(input string) (string, bool) {
+	for _, ext := range formatFileExts {
+		if _, err := os.Stat(trimExt(input) + ext); err == nil {
+			return trimExt(input) + ext, true
+		}
+	}
+	return trimExt(input) + ".fmt", false
+}
+
+// checkFormatFile checks the input's format file, the -formatfile or the
+// format file next to the input, against the input's data; each problem
+// is written to stdout.  False is returned if there are problems.
+func checkFormatFile(input string) (bool, error) {
+	name := formatFile
+	if name == "" {
+		if input == "stdin" {
+			return false, usageError(fmt.Errorf("fmt check: cannot infer the format file location when using stdin for the input; the location must be specified using either the '-formatfile' or '-m' flag"))
+		}
+		var ok bool
+		name, ok = inferFormatFile(input)
+		if !ok {
+			return false, formatError(fmt.Errorf("fmt check: %s: no format file; expected %s.fmt or a format spec, e.g. %[2]s.fmt.yaml", input, trimExt(input)))
+		}
+	}
+	in := os.Stdin
+	if input != "stdin" {
+		var err error
+		in, err = os.Open(input)
+		if err != nil {
+			return false, inputError(fmt.Errorf("input file error: %w", err))
+		}
+		defer in.Close()
+	}
+	t, err := fmtTransmogrifier(in, input)
+	if err != nil {
+		return false, err
+	}
+	var problems []csv2md.FormatProblem
+	if syntax := specSyntax(name); syntax != "" {
+		formats, err := readFormatSpec(name, syntax)
+		if err != nil {
+			return false, err
+		}
+		problems, err = t.CheckFieldFormats(formats)
+		if err != nil {
+			return false, withStatus(conversionStatus(err), fmt.Errorf("%s: %w", input, err))
+		}
+	} else {
+		f, err := os.Open(name)
+		if err != nil {
+			return false, formatError(fmt.Errorf("format file error: %w", err))
+		}
+		defer f.Close()
+		problems, err = t.CheckFmt(f)
+		if err != nil {
+			return false, formatError(fmt.Errorf("format file error: %s: %w", name, err))
+		}
+	}
+	for _, p := range problems {
+		fmt.Printf("%s: %s\n", name, p)
+	}
+	return len(problems) == 0, nil
+}
+
+// fmtTransmogrifier returns a Transmogrifier of the input's data, read from
+// in, that is configured by the input flags; nothing is written.
+func fmtTransmogrifier(in io.Reader, input string) (*csv2md.Transmogrifier, error) {
+	src, err := decompressor(in, input)
+	if err != nil {
+		return nil, err
+	}
+	t := csv2md.NewTransmogrifier(src, ioutil.Discard)
+	t.SetLogger(logger.With("input", input))
+	if len(separator) > 0 {
+		tmp := []rune(separator)
+		t.CSV.Comma = tmp[0]
+	}
+	if comment != "" {
+		tmp := []rune(comment)
+		if len(tmp) != 1 {
+			return nil, usageError(fmt.Errorf("the -comment flag must be a single character: %q", comment))
+		}
+		t.CSV.Comment = tmp[0]
+	}
+	t.HasHeaderRecord = !noHeaderRecord
+	t.CSV.LazyQuotes = lazyQuotes
+	t.CSV.TrimLeadingSpace = trimLeadingSpace
+	t.SkipBlankRecords = skipBlank
+	t.TrimSpace = trimSpace
+	t.CollapseSpace = collapseSpace
+	t.AutoAlignSample = autoSample
+	t.Encoding, err = csv2md.ParseEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	t.Ragged, err = csv2md.ParseRaggedMode(ragged)
+	if err != nil {
+		return nil, err
+	}
+	err = t.SetLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFormatSpecRoundTrip checks that the format spec written by fmt init is
+// the one that -format infers and fmt check checks.
+func TestFormatSpecRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "csv2md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "cars.csv")
+	err = ioutil.WriteFile(input, []byte("Make,Model,Year\nFord,Focus,2015\nChevy,Malibu,2016\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(q bool) { quiet, format = q, false }(quiet)
+	quiet = true
+	logger, err = newLogger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, ok := inferFormatFile(input); ok {
+		t.Fatalf("got %s before fmt init wrote it", name)
+	}
+	err = initFormatSpec(input)
+	if err != nil {
+		t.Fatalf("fmt init: unexpected error: %s", err)
+	}
+	name, ok := inferFormatFile(input)
+	if !ok || name != filepath.Join(dir, "cars.fmt.yaml") {
+		t.Fatalf("got %s, %t want %s, true", name, ok, filepath.Join(dir, "cars.fmt.yaml"))
+	}
+	checked, err := checkFormatFile(input)
+	if err != nil || !checked {
+		t.Errorf("fmt check: got %t, %v want true, nil", checked, err)
+	}
+	format = true
+	var w bytes.Buffer
+	err = transmogrify([]string{input}, &w, "")
+	if err != nil {
+		t.Fatalf("-format: unexpected error: %s", err)
+	}
+	expected := "Make|Model|Year  \n:--|:--|--:  \nFord|Focus|2015  \nChevy|Malibu|2016  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
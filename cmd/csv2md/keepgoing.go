@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mohae/csv2md"
+)
+
+// errorReport is the report of the malformed rows that are skipped with
+// -keep-going: a JSON object, with the input, row, raw line, and error,
+// for each row, one per line.
+type errorReport struct {
+	enc  *json.Encoder
+	f    *os.File
+	rows int
+	err  error
+}
+
+// report is the -keep-going error report; it is nil without -keep-going.
+var report *errorReport
+
+// newErrorReport returns the error report, which is written to the named
+// file or, if there is no name, to stderr.
+func newErrorReport(name string) (*errorReport, error) {
+	var w io.Writer = os.Stderr
+	r := &errorReport{}
+	if name != "" {
+		f, err := os.Create(name)
+		if err != nil {
+			return nil, outputError(fmt.Errorf("error report error: %w", err))
+		}
+		r.f = f
+		w = f
+	}
+	r.enc = json.NewEncoder(w)
+	return r, nil
+}
+
+// rowReporter returns the function that reports the input's malformed
+// rows; see csv2md.SkipMalformedRows.
+func (r *errorReport) rowReporter(input string) func(csv2md.MalformedRow) {
+	return func(m csv2md.MalformedRow) {
+		r.rows++
+		if r.err != nil {
+			return
+		}
+		r.err = r.enc.Encode(struct {
+			Input string `json:"input"`
+			Row   int    `json:"row"`
+			Raw   string `json:"raw"`
+			Error string `json:"error"`
+		}{input, m.Row, m.Raw, m.Err.Error()})
+	}
+}
+
+// close closes the report.  The error of writing the report, if there was
+// one, is returned; otherwise if any rows were skipped, they are a parse
+// error, so that the exit status shows that the output is incomplete.
+func (r *errorReport) close() error {
+	if r.f != nil {
+		cerr := r.f.Close()
+		if r.err == nil {
+			r.err = cerr
+		}
+	}
+	if r.err != nil {
+		return outputError(fmt.Errorf("error report error: %w", r.err))
+	}
+	if r.rows > 0 {
+		return withStatus(exitParse, fmt.Errorf("%d malformed rows were skipped", r.rows))
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// logger logs the conversions' diagnostics to stderr; see newLogger.
+var logger *slog.Logger
+
+// newLogger returns the logger of the diagnostics: by default the warnings
+// are logged, with -verbose the tables' sizes and timings and the skipped
+// rows are also logged, and with -quiet only the errors are.  The times
+// of the messages are omitted, as they are written as they happen.
+func newLogger() (*slog.Logger, error) {
+	if verbose && quiet {
+		return nil, usageError(fmt.Errorf("the -verbose and -quiet flags are mutually exclusive"))
+	}
+	level := slog.LevelWarn
+	switch {
+	case verbose:
+		level = slog.LevelDebug
+	case quiet:
+		level = slog.LevelError
+	}
+	h := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	return slog.New(h), nil
+}
@@ -1,60 +1,314 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mohae/csv2md"
+	"github.com/mohae/csv2md/internal/zstd"
 )
 
 // flags
 var (
+	agg              string
+	ascii            bool
+	autoAlign        bool
+	autoSample       int
+	autolink         bool
+	bools            string
+	boolText         string
+	byteSizes        string
+	byteUnits        string
+	caption          string
+	captionHeading   int
+	cellHTML         string
+	check            bool
+	chunk            int
+	chunkCaption     string
+	collapseRepeats  string
+	collapseSpace    bool
+	comment          string
+	compute          listFlag
+	currencies       listFlag
+	configFile       string
+	constants        listFlag
+	decompress       string
+	ellipsis         bool
+	dedup            bool
+	dedupBy          string
+	durations        string
+	durationUnit     string
+	encoding         string
+	errorReportFile  string
+	exclude          string
+	fieldNamePattern string
+	footer           string
+	footerLabel      string
+	force            bool
 	format           bool
 	formatFile       string
+	images           string
+	inject           string
+	injectName       string
 	input            string
+	keepGoing        bool
 	help             bool
+	groupBy          string
+	hash             string
+	hashSalt         string
+	head             int
+	headerAlign      string
+	headerStyle      string
+	heading          string
+	hideGroupCol     bool
+	htmlClasses      string
 	lazyQuotes       bool
+	limit            int
+	lineBreak        string
+	links            string
+	locale           string
+	mapFiles         string
+	maxCellWidth     int
+	maxColWidths     string
+	maxField         string
+	metadata         string
+	mapStrict        bool
+	merge            bool
+	mergePlaceholder string
 	newLine          string
+	negatives        listFlag
+	noEscape         bool
 	noHeaderRecord   bool
+	noHeadings       bool
+	noProgress       bool
+	noSpaces         bool
+	numbers          listFlag
+	offset           int
+	outDir           string
 	output           string
+	outerPipes       bool
+	outputFormat     string
+	pivot            string
+	pretty           bool
+	preview          bool
+	printVersion     bool
+	previewWidth     int
+	quiet            bool
+	ragged           string
+	redactions       listFlag
+	rename           string
+	replacements     listFlag
+	rowHeader        bool
+	rowNumbers       bool
+	rowStyleCol      string
+	rules            listFlag
+	sampleN          int
+	samplePct        float64
+	sanitize         string
+	seed             int64
 	separator        string
+	skipBlank        bool
+	sortGroups       bool
+	sortIgnoreCase   bool
+	sortNatural      bool
+	splitBy          string
+	splitHeading     string
+	stats            bool
+	strict           bool
+	styleIf          listFlag
+	tail             int
+	templates        listFlag
+	times            listFlag
+	transpose        bool
+	truncFootnotes   bool
 	trimLeadingSpace bool
+	trimSpace        bool
+	verbose          bool
+	watch            bool
+	where            string
+	widths           string
 )
 
+// listFlag is a flag that can be specified multiple times; each value is
+// appended to the list.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
 var prog = filepath.Base(os.Args[0])
 
 func init() {
+	flag.BoolVar(&ascii, "ascii", false, "draw the -preview table using ASCII characters")
+	flag.BoolVar(&autoAlign, "auto-align", false, "infer the alignment of every field from the data when the format file does not define the alignment")
+	flag.IntVar(&autoSample, "auto-sample", csv2md.DefaultAutoAlignSample, "number of records sampled to infer auto alignment; 0 uses all records")
+	flag.BoolVar(&check, "check", false, "check that the output is up to date instead of writing it; the differences are written as a unified diff and the exit status is 7 if there are any")
+	flag.BoolVar(&autolink, "autolink", false, "write the -link URLs as <url> autolinks")
+	flag.StringVar(&caption, "caption", "", "title written before the table, e.g. \"Q3 Sales\"; a bold paragraph, a heading, see -caption-heading, or an HTML <caption>")
+	flag.IntVar(&captionHeading, "caption-heading", 0, "heading level, 1 to 6, of the -caption; 0 writes the caption as a bold paragraph")
+	flag.IntVar(&chunk, "chunk", 0, "maximum number of rows per table; longer inputs are written as multiple tables, each with the header")
+	flag.StringVar(&chunkCaption, "chunk-caption", "", "caption template written after each -chunk table, e.g. \"Rows {first}-{last}\"")
+	flag.StringVar(&collapseRepeats, "collapse-repeats", "", "comma separated list of columns whose consecutive repeated values are blanked out")
+	flag.StringVar(&comment, "comment", "", "comment character; lines that start with it are ignored, e.g. '#'")
+	flag.BoolVar(&dedup, "dedup", false, "remove the duplicate rows; the number of removed rows is reported on stderr")
+	flag.StringVar(&dedupBy, "dedup-by", "", "comma separated list of the columns whose values identify duplicate rows; implies -dedup")
+	flag.StringVar(&decompress, "decompress", "auto", "decompression of the input: auto, gzip, bzip2, zstd, or none; auto detects gzip, bzip2, and zstd compressed input")
+	flag.StringVar(&exclude, "exclude", "", "comma separated list of the columns to omit, by name, position, or range of positions, e.g. \"internal_id,raw_json\" or \"5-7\"")
+	flag.StringVar(&encoding, "encoding", "utf-8", "character encoding of the input: utf-8, utf-16le, utf-16be, latin1, or windows-1252; byte order marks are removed")
+	flag.StringVar(&errorReportFile, "error-report", "", "file that the -keep-going error report, a JSON object for each skipped row, is written to; defaults to stderr")
+	flag.StringVar(&fieldNamePattern, "field-name-pattern", csv2md.DefaultFieldNamePattern, "pattern of the field names generated when the CSV data has no header record and there is no format file; {n} is the field's number")
+	flag.StringVar(&footer, "footer", "", "comma separated list of column=aggregate footer cells, e.g. \"Qty=sum,Price=avg\"; the aggregates are sum, avg, count, min, and max")
+	flag.StringVar(&footerLabel, "footer-label", "Total", "label written in the first cell of the -footer row")
+	flag.IntVar(&head, "head", 0, "only write the first N rows")
+	flag.IntVar(&tail, "tail", 0, "only write the last N rows")
+	flag.IntVar(&offset, "offset", 0, "skip the first N rows")
+	flag.IntVar(&limit, "limit", 0, "write at most N rows, after the -offset")
+	flag.StringVar(&locale, "locale", "", "locale of the input's numbers, for -auto-align, formatting, and aggregates: en, 1,234.56; de, 1.234,56; fr, 1 234,56; or ch, 1'234.56")
+	flag.IntVar(&sampleN, "sample", 0, "only write a random sample of N rows, in their original order")
+	flag.Float64Var(&samplePct, "sample-pct", 0, "only write a random sample of about this percent of the rows")
+	flag.Int64Var(&seed, "seed", 0, "seed of the -sample or -sample-pct random sample; the same seed selects the same rows")
+	flag.BoolVar(&ellipsis, "ellipsis", false, "write a row of ellipses in place of the rows omitted by -head, -tail, -offset, or -limit")
+	flag.StringVar(&pivot, "pivot", "", "write a pivot table of the row,column,value=aggregate columns, e.g. \"Region,Quarter,Sales=sum\"; the aggregate defaults to sum")
+	flag.StringVar(&bools, "bool", "", "comma separated list of columns whose boolean values, true, false, yes, no, 1, or 0, are written as check marks and crosses")
+	flag.StringVar(&boolText, "bool-text", "", "comma separated true and false text of the -bool columns, e.g. \"yes,no\"; defaults to ✅ and ❌")
+	flag.StringVar(&byteSizes, "bytes", "", "comma separated list of columns whose numbers of bytes are written in human readable units, e.g. 1.2 GiB")
+	flag.StringVar(&byteUnits, "byte-units", "binary", "units of the -bytes columns: binary, KiB, MiB, ..., or decimal, kB, MB, ...")
+	flag.StringVar(&durations, "duration", "", "comma separated list of columns whose durations, numbers of the -duration-unit or Go durations, are written in their two largest units, e.g. 2h14m")
+	flag.StringVar(&durationUnit, "duration-unit", "s", "unit of the -duration columns' numbers: ns, us, ms, s, m, or h")
+	flag.StringVar(&images, "image", "", "comma separated list of columns whose values are written as images; column=altcolumn takes the alt text from another column")
+	flag.StringVar(&links, "link", "", "comma separated list of columns whose URLs are written as links; column=textcolumn takes the link text from another column")
 	flag.BoolVar(&format, "format", false, "use format file; location inferred from input")
 	flag.BoolVar(&format, "f", false, "short flag for -format")
+	flag.BoolVar(&force, "force", false, "overwrite an existing format spec with fmt init")
 	flag.StringVar(&formatFile, "formatfile", "", "path to the format file; mutually exclusive with -format")
 	flag.StringVar(&formatFile, "m", "", "short flag for -formatfile")
+	flag.StringVar(&inject, "inject", "", "Markdown file to update in place; each input's table replaces the content between its csv2md:begin and csv2md:end markers")
+	flag.StringVar(&injectName, "inject-name", "", "name of the -inject markers; defaults to each input's file name without the extension")
 	flag.StringVar(&input, "input", "stdin", "input source")
 	flag.StringVar(&input, "i", "stdin", "short flag for -input")
+	flag.BoolVar(&keepGoing, "keep-going", false, "skip the malformed rows, e.g. a row with the wrong number of fields or a bare quote, instead of stopping; the skipped rows are reported, see -error-report, and the exit status is 4")
+	flag.StringVar(&groupBy, "groupby", "", "group rows by the named column, writing a subheader row for each group")
+	flag.StringVar(&groupBy, "group-by", "", "alias for -groupby")
+	flag.StringVar(&agg, "agg", "", "comma separated list of aggregate(column) columns, e.g. \"sum(Sales),count(*)\"; writes a row of aggregates for each -groupby group instead of the rows")
+	flag.BoolVar(&hideGroupCol, "hide-group-col", false, "omit the -groupby or -split-by column from the table")
+	flag.BoolVar(&sortGroups, "sort-groups", false, "sort the records by the -groupby or -split-by column; otherwise the -groupby input must already be sorted and the -split-by tables are in the order their values first occur in")
+	flag.BoolVar(&sortNatural, "sort-natural", false, "sort the -groupby or -split-by values naturally, e.g. 9 before 10 and v1.2 before v1.10; implies -sort-groups")
+	flag.BoolVar(&sortIgnoreCase, "sort-ignore-case", false, "sort the -groupby or -split-by values without regard to case; implies -sort-groups")
+	flag.StringVar(&splitBy, "split-by", "", "write a table, preceded by a heading, for each value of the named column; mutually exclusive with -groupby")
+	flag.StringVar(&splitHeading, "split-heading", csv2md.DefaultSplitHeading, "heading template used for each -split-by table; {column} and {value} are replaced by the column's name and value")
+	flag.StringVar(&headerStyle, "header-style", "", "comma separated list of the header's field styles, e.g. \"bold\"; a single style styles every field")
+	flag.StringVar(&headerAlign, "header-align", "", "comma separated list of the header's field alignments, e.g. \"c\"; a single alignment aligns every field")
+	flag.StringVar(&heading, "heading", csv2md.DefaultSourceHeading, "heading template used for each input when concatenating multiple inputs")
+	flag.BoolVar(&merge, "merge", false, "merge multiple inputs into one table; the columns are matched by their header names")
+	flag.StringVar(&mergePlaceholder, "merge-placeholder", "", "value of the fields that a -merge input does not have")
 	flag.BoolVar(&lazyQuotes, "lazyquotes", false, "allow lazy quotes")
 	flag.BoolVar(&lazyQuotes, "l", false, "short flag for -lazyquotes")
-	flag.StringVar(&newLine, "newline", "\n", "newline sequence")
+	flag.StringVar(&lineBreak, "line-break", csv2md.DefaultLineBreak, "what the line breaks in the values are replaced with, as a row can't span multiple lines, e.g. \" \"; they aren't replaced with -no-escape")
+	flag.StringVar(&mapFiles, "map", "", "comma separated list of column=file value maps; each file is CSV with from and to columns or, if its extension is .json, a JSON object of from: to members")
+	flag.BoolVar(&mapStrict, "map-strict", false, "values that are not in a column's -map file are an error")
+	flag.StringVar(&maxField, "maxfield", "", "maximum field size, e.g. 512KB or 1MB; by default field sizes are not limited")
+	flag.IntVar(&maxCellWidth, "max-cell-width", 0, "truncate values that are longer than this many characters, ending them with an ellipsis; 0 doesn't truncate")
+	flag.StringVar(&maxColWidths, "max-col-width", "", "comma separated list of column=width maximum widths that override -max-cell-width, e.g. \"Message=40\"")
+	flag.StringVar(&metadata, "metadata", "none", "metadata block written before the table: yaml, json, or none")
+	flag.StringVar(&newLine, "newline", "\n", "newline sequence: lf, cr, crlf, or the sequence, e.g. \\r\\n")
 	flag.StringVar(&newLine, "n", "\n", "short flag for -newline")
 	flag.BoolVar(&noHeaderRecord, "noheaderrecord", false, "CSV data does not include a header record")
 	flag.BoolVar(&noHeaderRecord, "r", false, "short flag for -noheaderrecord")
+	flag.BoolVar(&noEscape, "no-escape", false, "do not escape the Markdown characters, e.g. | and *, in the values")
+	flag.BoolVar(&noHeadings, "no-headings", false, "do not write a heading for each input when concatenating multiple inputs")
+	flag.BoolVar(&noProgress, "no-progress", false, "do not show the progress of the conversion on stderr")
+	flag.BoolVar(&noSpaces, "no-trailing-spaces", false, "do not end the lines of the table with two spaces before the newline sequence")
 	flag.StringVar(&output, "output", "stdout", "output destination")
 	flag.StringVar(&output, "o", "stdout", "short flag for -output")
+	flag.StringVar(&outDir, "outdir", "", "directory to write each input's table to, as a separate file named after the input; mutually exclusive with -output")
+	flag.StringVar(&ragged, "ragged", "error", "how records with fewer or more fields than the header are handled: error, pad, or truncate")
+	flag.Var(&redactions, "redact", "redact a column's values: mask, last:n to keep the last n characters, token:text, or hash[:n] for the first n digits of the value's SHA-256 hash, e.g. 'Card=last:4' or 'Email=token:[REDACTED]'; may be repeated")
+	flag.StringVar(&hash, "hash", "", "comma separated list of the columns whose values are replaced by a prefix of their SHA-256 hash, e.g. \"Email,UserID\"; the same values have the same hash")
+	flag.StringVar(&hashSalt, "hash-salt", "", "salt that is prepended to the values of the -hash columns before they are hashed")
+	flag.StringVar(&rename, "rename", "", "comma separated list of old=new field renames, e.g. \"Manufacturer=Make,Year=Yr\"")
+	flag.Var(&replacements, "replace", "replace the matches of a regular expression in a column's values, column=/pattern/replacement/, e.g. 'Host=/\\.example\\.com$//'; a column of * is every column; may be repeated")
+	flag.StringVar(&rowStyleCol, "row-style-col", "", "column whose values style their rows, e.g. bold, strike, or an HTML class; the column is omitted from the table")
+	flag.BoolVar(&rowHeader, "row-header", false, "make the first column the row header: bold in Markdown and <th scope=\"row\"> cells in HTML tables")
+	flag.BoolVar(&rowNumbers, "rownum", false, "start the table with a # column of the row numbers, in the order the rows are written")
+	flag.Var(&rules, "rule", "style a cell, or with a target of *, the row, when an expression is true, e.g. \"Price>100:bold\" or \"Price>100:bold:*\"; may be repeated")
+	flag.StringVar(&cellHTML, "cell-html", "", "how HTML in fields, e.g. <script>, is handled: escape, strip, or pass; by default it is passed through in Markdown and escaped in HTML tables")
+	flag.StringVar(&sanitize, "sanitize", "", "how control characters in fields are handled: strip, escape, error, or ansi, which also removes ANSI escape sequences, e.g. color codes; by default they are left as is")
+	flag.StringVar(&outputFormat, "output-format", "gfm", "format of the generated table: gfm, html, box, or ascii, the plain text tables of -preview; markdown and md are aliases of gfm")
+	flag.StringVar(&htmlClasses, "html-class", "", "comma separated list of the classes of the HTML tables' elements, element=class, where the element is id, table, thead, tbody, tr, odd, even, or td:column, e.g. \"table=data,odd=odd,even=even,td:Price=number\"")
+	flag.BoolVar(&outerPipes, "outer-pipes", false, "start and end each row with a pipe and surround the cells with spaces, e.g. | a | b |")
+	flag.BoolVar(&pretty, "pretty", false, "pad the cells so that the columns line up in the Markdown")
+	flag.BoolVar(&preview, "preview", false, "preview the table in the terminal; the table is written to stdout")
 	flag.StringVar(&separator, "separator", ",", "field separator")
-	flag.StringVar(&separator, "s", ",", "short flag for -s")
+	flag.StringVar(&separator, "s", ",", "short flag for -separator")
+	flag.BoolVar(&skipBlank, "skip-blank", false, "skip records whose fields are all empty, e.g. \",,\", instead of writing them as empty rows")
+	flag.BoolVar(&stats, "stats", false, "write a summary of each conversion on stderr: the rows read, written, skipped, and filtered, the bytes read and written, and the elapsed time and throughput")
+	flag.BoolVar(&strict, "strict", false, "inconsistencies between the data and the format, e.g. a record with more fields than the format file, are errors")
+	flag.StringVar(&configFile, "config", "", "config file of flag defaults; defaults to the first .csv2md.toml, csv2md.toml, .csv2md.yaml, or csv2md.yaml in the working or home directory; none disables it")
+	flag.Var(&compute, "compute", "append a column computed from an expression, e.g. \"Total=Qty*Price\"; may be repeated")
+	flag.Var(&compute, "derive", "alias for -compute")
+	flag.Var(&constants, "const", "append a column with the same value in every row, e.g. \"Source=Q3 export\"; may be repeated")
+	flag.Var(&styleIf, "style-if", "style a cell when an expression is true, e.g. \"Amount<0=bold\"; may be repeated")
+	flag.Var(&numbers, "number", "set a column's number format, [thousands][.precision][f|e], e.g. 'Price=,.2f' writes 1234567.891 as 1,234,567.89; may be repeated")
+	flag.Var(&currencies, "currency", "set a column's currency format, symbol[,.precision][,locale], e.g. 'Price=$' or 'Price=EUR,de'; a symbol of @column takes the currency codes from another column; may be repeated")
+	flag.Var(&negatives, "negative", "set how a column's negative numbers are written: parens, a style, or class:name for HTML, e.g. 'Amount=parens,bold'; a column of * is every column; may be repeated")
+	flag.Var(&times, "time", "set a column's time format, [layout>]output, using Go layouts or iso, date, datetime, rfc1123, unix, unixms, or relative, e.g. 'Created=Jan 2, 2006'; may be repeated")
+	flag.Var(&templates, "template", "set a column's cell template, e.g. 'Price={{printf \"%.2f\" .Value}}'; may be repeated")
+	flag.BoolVar(&transpose, "transpose", false, "swap the rows and columns; the field names become the first column")
+	flag.BoolVar(&truncFootnotes, "truncate-footnotes", false, "write the full values of truncated cells as footnotes after the table")
 	flag.BoolVar(&trimLeadingSpace, "trimleadingspace", false, "trim leading space")
 	flag.BoolVar(&trimLeadingSpace, "t", false, "short flag for -trimleadingspace")
+	flag.BoolVar(&trimSpace, "trim-space", false, "trim the leading and trailing white space of every field, including quoted fields")
+	flag.BoolVar(&collapseSpace, "collapse-space", false, "replace each run of white space in a field, including new lines, with a single space")
+	flag.IntVar(&previewWidth, "width", 0, "maximum width of the -preview table; defaults to the terminal width")
+	flag.BoolVar(&watch, "watch", false, "regenerate the output whenever an input, the format file, or a map file changes")
+	flag.StringVar(&where, "where", "", "only include the rows for which the expression is true, e.g. 'Year >= 2015 && Type == \"Sedan\"'")
+	flag.StringVar(&widths, "widths", "", "comma separated list of minimum field widths, e.g. \"8,0,0,12\"; overrides the format file's widths")
+	flag.BoolVar(&verbose, "verbose", false, "log the tables' sizes and timings, and the skipped rows, as well as the warnings, on stderr")
+	flag.BoolVar(&verbose, "v", false, "short flag for -verbose")
+	flag.BoolVar(&quiet, "quiet", false, "only write the errors on stderr: no warnings, progress, or other messages")
+	flag.BoolVar(&quiet, "q", false, "short flag for -quiet")
+	flag.BoolVar(&printVersion, "version", false, "print csv2md's version, the commit it was built from, and its build date")
 	flag.BoolVar(&help, "help", false, "csv2md help")
 	flag.BoolVar(&help, "h", false, "short flag for -help")
 }
 
 func usage() {
 	fmt.Fprintf(os.Stderr, "Usage:\n")
-	fmt.Fprintf(os.Stderr, "  %s [OPTS]\n", prog)
+	fmt.Fprintf(os.Stderr, "  %s [OPTS] [FILE...]\n", prog)
+	fmt.Fprintf(os.Stderr, "  %s COMMAND [OPTS] [FILE...]\n", prog)
 	fmt.Fprintf(os.Stderr, "\n")
 	fmt.Fprintf(os.Stderr, "Creates Github Style Markdown tables from CSV-encoded data\n")
 	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "If multiple input files are specified, the tables are concatenated into\n")
+	fmt.Fprintf(os.Stderr, "the output, each preceded by a heading identifying its source.\n")
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", cmd.name, cmd.usage)
+		fmt.Fprintf(os.Stderr, "    \t%s\n", cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "The options are shared by the commands; each command uses the ones that\n")
+	fmt.Fprintf(os.Stderr, "apply to it.\n")
+	fmt.Fprintf(os.Stderr, "\n")
 	fmt.Fprintf(os.Stderr, "Options:\n")
 	flag.PrintDefaults()
 }
@@ -66,11 +320,26 @@ func main() {
 func realMain() int {
 	flag.Usage = usage
 	flag.Parse()
-	if flag.NArg() > 0 {
-		flag.Usage()
-		return 2
+	// the command's flags may follow it
+	cmd, sub := findCommand(flag.Args())
+	if sub != nil {
+		flag.CommandLine.Parse(flag.Args()[len(sub):])
+	}
+	err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
 	}
-	// check args; there shouldn't be any, but this is in case help was
+	logger, err = newLogger()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitStatus(err)
+	}
+	if errorReportFile != "" && !keepGoing {
+		fmt.Fprintln(os.Stderr, "the -error-report flag requires the -keep-going flag")
+		return exitUsage
+	}
+	// check args; any args are input files, but this is in case help was
 	// used without the flag prefix
 	args := flag.Args()
 	for _, arg := range args {
@@ -81,67 +350,1133 @@ func realMain() int {
 	}
 	if help {
 		flag.Usage()
-		return 0
+		return exitOK
 	}
-	var in, out, formatR *os.File
-	var err error
-	// set input
-	in = os.Stdin
-	if input != "stdin" {
-		in, err = os.Open(input)
+	if printVersion {
+		fmt.Println(versionInfo())
+		return exitOK
+	}
+	var inputs []string
+	if input != "stdin" || len(args) == 0 {
+		inputs = append(inputs, input)
+	}
+	inputs = append(inputs, args...)
+	inputs, err = expandInputs(inputs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitStatus(err)
+	}
+	if keepGoing {
+		report, err = newErrorReport(errorReportFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "input file error: %s\n", err)
-			return 1
+			fmt.Fprintln(os.Stderr, err)
+			return exitStatus(err)
 		}
 	}
-	defer in.Close()
-	// set output
+	ok, err := cmd.run(sub, inputs)
+	if report != nil {
+		rerr := report.close()
+		if err == nil {
+			err = rerr
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitStatus(err)
+	}
+	if !ok {
+		return exitCheck
+	}
+	return exitOK
+}
+
+// run writes the inputs' tables to the output, the output directory, or
+// the document to inject them into.
+func run(inputs []string) error {
+	if inject != "" {
+		return injectInto(inject, inputs)
+	}
+	if outDir != "" {
+		return transmogrifyToDir(inputs)
+	}
+	var out *os.File
+	var err error
+	// set output; a preview is always written to stdout
 	out = os.Stdout
-	if output != "stdout" {
+	if output != "stdout" && !preview {
 		out, err = os.OpenFile(output, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "output file error: %s\n", err)
-			return 1
+			return outputError(fmt.Errorf("output file error: %w", err))
 		}
 		defer out.Close()
 	}
+	// the tables are written a row at a time
+	w := bufio.NewWriter(out)
+	err = writeTables(w, inputs)
+	ferr := w.Flush()
+	if err != nil {
+		return err
+	}
+	return outputError(ferr)
+}
+
+// writeTables writes the inputs' tables to w.
+func writeTables(w io.Writer, inputs []string) error {
+	// when multiple inputs are concatenated, each table gets a heading
+	// identifying its source
+	var sourceHeading string
+	if len(inputs) > 1 && !noHeadings && !merge {
+		sourceHeading = heading
+	}
+	for _, in := range tableInputs(inputs) {
+		err := transmogrify(in, w, sourceHeading)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableInputs returns the inputs of each table: when merging, all of the
+// inputs are one table, otherwise each input is its own table.
+func tableInputs(inputs []string) [][]string {
+	if merge {
+		return [][]string{inputs}
+	}
+	tables := make([][]string, len(inputs))
+	for i, in := range inputs {
+		tables[i] = []string{in}
+	}
+	return tables
+}
+
+// checkOutputs checks that the output, the outdir files, or the inject
+// file, are the same as what would be written.  The differences are
+// written to stdout as a unified diff.
+func checkOutputs(inputs []string) (bool, error) {
+	if watch {
+		return false, usageError(fmt.Errorf("the -check and -watch flags are mutually exclusive"))
+	}
+	if preview {
+		return false, usageError(fmt.Errorf("the -check and -preview flags are mutually exclusive"))
+	}
+	if inject != "" {
+		b, err := injectTables(inject, inputs)
+		if err != nil {
+			return false, err
+		}
+		return checkFile(inject, b)
+	}
+	if outDir != "" {
+		err := checkOutDir(inputs)
+		if err != nil {
+			return false, err
+		}
+		ok := true
+		for _, in := range inputs {
+			var buf bytes.Buffer
+			err = transmogrify([]string{in}, &buf, "")
+			if err != nil {
+				return false, err
+			}
+			same, err := checkFile(outDirFile(in), buf.Bytes())
+			if err != nil {
+				return false, err
+			}
+			ok = ok && same
+		}
+		return ok, nil
+	}
+	if output == "stdout" {
+		return false, usageError(fmt.Errorf("the -check flag requires an output file, an -outdir, or an -inject file"))
+	}
+	var buf bytes.Buffer
+	err := writeTables(&buf, inputs)
+	if err != nil {
+		return false, err
+	}
+	return checkFile(output, buf.Bytes())
+}
+
+// generatedTime matches the generated timestamp of a -metadata block, in
+// either YAML or JSON.
+var generatedTime = regexp.MustCompile(`(?m)^(\s*"?generated"?: )"[^"\r\n]*"`)
+
+// checkFile returns whether the named file's contents are b; if they are
+// not, the differences are written to stdout.  A file that doesn't exist
+// is checked as if it were empty.  The -metadata blocks' generated
+// timestamps are not compared, since they are when the tables were written.
+func checkFile(name string, b []byte) (bool, error) {
+	cur, err := ioutil.ReadFile(name)
+	if err != nil && !os.IsNotExist(err) {
+		return false, outputError(fmt.Errorf("check error: %w", err))
+	}
+	if sameOutput(cur, b) {
+		return true, nil
+	}
+	fmt.Fprintf(os.Stderr, "%s is out of date\n", name)
+	err = writeDiff(os.Stdout, name, cur, b)
+	if err != nil {
+		return false, outputError(fmt.Errorf("check error: %w", err))
+	}
+	return false, nil
+}
+
+// sameOutput returns whether the output is the same as the current output,
+// ignoring the generated timestamps if the tables have a -metadata block.
+func sameOutput(cur, b []byte) bool {
+	if bytes.Equal(cur, b) {
+		return true
+	}
+	m, err := csv2md.ParseMetadataFormat(metadata)
+	if err != nil || m == csv2md.NoMetadata {
+		return false
+	}
+	return bytes.Equal(generatedTime.ReplaceAll(cur, []byte(`$1""`)), generatedTime.ReplaceAll(b, []byte(`$1""`)))
+}
+
+// watchInterval is how often the watched files are checked for changes.
+const watchInterval = 500 * time.Millisecond
+
+// watchInputs writes the output and then rewrites it whenever one of the
+// files it is generated from changes, until the program is interrupted.
+// The files are polled for changes to their size or modification time.
+// An error while regenerating the output is written to stderr and the
+// files continue to be watched.
+func watchInputs(inputs []string) error {
+	if output == "stdout" && outDir == "" && inject == "" && !preview {
+		return usageError(fmt.Errorf("the -watch flag requires an output file, an -outdir, or an -inject file"))
+	}
+	var files []string
+	for _, in := range inputs {
+		if in == "stdin" {
+			return usageError(fmt.Errorf("stdin cannot be used as an input with the -watch flag"))
+		}
+		files = append(files, in)
+		if format && formatFile == "" {
+			name, _ := inferFormatFile(in)
+			files = append(files, name)
+		}
+	}
+	if formatFile != "" {
+		files = append(files, formatFile)
+	}
+	if mapFiles != "" {
+		for _, v := range splitList(mapFiles) {
+			if i := strings.Index(v, "="); i > 0 {
+				files = append(files, v[i+1:])
+			}
+		}
+	}
+	for {
+		// the files are checked after the output is written so that the
+		// changes made while writing it, e.g. to the format file, aren't
+		// seen as changes
+		err := run(inputs)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		} else if !preview && !quiet {
+			fmt.Fprintf(os.Stderr, "%s: output written\n", time.Now().Format("15:04:05"))
+		}
+		// wait until the files have changed and then stopped changing, so
+		// that a file that is being written is only read once it is done
+		prev := statFiles(files)
+		var dirty bool
+		for {
+			time.Sleep(watchInterval)
+			cur := statFiles(files)
+			if changed(prev, cur) {
+				prev, dirty = cur, true
+				continue
+			}
+			if dirty {
+				break
+			}
+		}
+	}
+}
+
+// statFiles returns the FileInfo of each of the files that exists.
+func statFiles(files []string) map[string]os.FileInfo {
+	infos := make(map[string]os.FileInfo, len(files))
+	for _, name := range files {
+		fi, err := os.Stat(name)
+		if err == nil {
+			infos[name] = fi
+		}
+	}
+	return infos
+}
+
+// changed returns whether a file was created, removed, or modified.
+func changed(prev, cur map[string]os.FileInfo) bool {
+	if len(prev) != len(cur) {
+		return true
+	}
+	for name, fi := range cur {
+		p, ok := prev[name]
+		if !ok || p.Size() != fi.Size() || !p.ModTime().Equal(fi.ModTime()) {
+			return true
+		}
+	}
+	return false
+}
+
+// injectTables returns the document with each input's table injected
+// between the input's markers.
+func injectTables(doc string, inputs []string) ([]byte, error) {
+	if output != "stdout" || outDir != "" {
+		return nil, usageError(fmt.Errorf("the -inject flag is mutually exclusive with the -output and -outdir flags"))
+	}
+	if preview {
+		return nil, usageError(fmt.Errorf("the -inject and -preview flags are mutually exclusive"))
+	}
+	if injectName != "" && len(tableInputs(inputs)) > 1 {
+		return nil, usageError(fmt.Errorf("the -inject-name flag cannot be used with multiple inputs"))
+	}
+	b, err := ioutil.ReadFile(doc)
+	if err != nil {
+		return nil, outputError(fmt.Errorf("inject file error: %w", err))
+	}
+	for _, in := range tableInputs(inputs) {
+		name := injectName
+		if name == "" {
+			if in[0] == "stdin" {
+				return nil, usageError(fmt.Errorf("the -inject-name flag must be specified when stdin is the input"))
+			}
+			name = trimExt(filepath.Base(in[0]))
+		}
+		var table, buf bytes.Buffer
+		err = transmogrify(in, &table, "")
+		if err != nil {
+			return nil, err
+		}
+		err = csv2md.Inject(bytes.NewReader(b), &buf, name, table.Bytes())
+		if err != nil {
+			return nil, outputError(fmt.Errorf("%s: %w", doc, err))
+		}
+		b = buf.Bytes()
+	}
+	return b, nil
+}
+
+// expandInputs expands any inputs that are glob patterns into the files
+// that match them.  A pattern that doesn't match any files is an error.
+func expandInputs(inputs []string) ([]string, error) {
+	var expanded []string
+	for _, in := range inputs {
+		if in == "stdin" || !strings.ContainsAny(in, "*?[") {
+			expanded = append(expanded, in)
+			continue
+		}
+		matches, err := filepath.Glob(in)
+		if err != nil {
+			return nil, usageError(fmt.Errorf("input %q: %w", in, err))
+		}
+		if len(matches) == 0 {
+			return nil, usageError(fmt.Errorf("input %q: no matching files", in))
+		}
+		expanded = append(expanded, matches...)
+	}
+	return expanded, nil
+}
+
+// transmogrifyToDir writes each input's table to its own file in the
+// outDir.  The file is named after the input, with its extension replaced
+// by the output format's extension.
+func transmogrifyToDir(inputs []string) error {
+	err := checkOutDir(inputs)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(outDir, 0755)
+	if err != nil {
+		return outputError(fmt.Errorf("output directory error: %w", err))
+	}
+	for _, in := range inputs {
+		out, err := os.OpenFile(outDirFile(in), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return outputError(fmt.Errorf("output file error: %w", err))
+		}
+		w := bufio.NewWriter(out)
+		err = transmogrify([]string{in}, w, "")
+		if err == nil {
+			err = outputError(w.Flush())
+		}
+		cerr := out.Close()
+		if err != nil {
+			return err
+		}
+		if cerr != nil {
+			return outputError(fmt.Errorf("output file error: %w", cerr))
+		}
+	}
+	return nil
+}
+
+// checkOutDir checks that the flags and inputs can be used with -outdir.
+func checkOutDir(inputs []string) error {
+	if output != "stdout" {
+		return usageError(fmt.Errorf("the -outdir and -output flags are mutually exclusive"))
+	}
+	if preview {
+		return usageError(fmt.Errorf("the -outdir and -preview flags are mutually exclusive"))
+	}
+	if merge {
+		return usageError(fmt.Errorf("the -outdir and -merge flags are mutually exclusive"))
+	}
+	for _, in := range inputs {
+		if in == "stdin" {
+			return usageError(fmt.Errorf("stdin cannot be used as an input with the -outdir flag"))
+		}
+	}
+	return nil
+}
+
+// outDirFile returns the name of the input's file in the outDir.
+func outDirFile(input string) string {
+	ext := ".md"
+	if strings.EqualFold(strings.TrimSpace(outputFormat), "html") {
+		ext = ".html"
+	}
+	return filepath.Join(outDir, trimExt(filepath.Base(input))+ext)
+}
+
+// injectInto updates the Markdown document in place, replacing the content
+// between each input's markers with the input's table.  The document is
+// only replaced if all of the inputs were injected.
+func injectInto(doc string, inputs []string) error {
+	b, err := injectTables(doc, inputs)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first so that an error doesn't leave the
+	// document partially written
+	fi, err := os.Stat(doc)
+	if err != nil {
+		return outputError(fmt.Errorf("inject file error: %w", err))
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(doc), "."+filepath.Base(doc))
+	if err != nil {
+		return outputError(fmt.Errorf("inject file error: %w", err))
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	if err == nil {
+		err = tmp.Chmod(fi.Mode())
+	}
+	cerr := tmp.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), doc)
+	}
+	if err != nil {
+		return outputError(fmt.Errorf("inject file error: %w", err))
+	}
+	return nil
+}
+
+// transmogrify writes the inputs' CSV-encoded data to out as a Markdown
+// table; multiple inputs are merged into one table.  The first input is
+// the table's input, e.g. for its format file and source.  If the
+// sourceHeading is not empty, it is used as the template for the heading
+// written before the table.
+func transmogrify(inputs []string, out io.Writer, sourceHeading string) (err error) {
+	// the errors that aren't classified, e.g. of the flags' values, are
+	// usage errors; the conversion's errors are classified by what failed
+	defer func() {
+		err = usageError(err)
+	}()
+	var in, formatR *os.File
+	input := inputs[0]
+	// set input
+	in = os.Stdin
+	if input != "stdin" {
+		in, err = os.Open(input)
+		if err != nil {
+			return inputError(fmt.Errorf("input file error: %w", err))
+		}
+	}
+	defer in.Close()
 
 	// if formatting was specified but no format file was given, set the
 	// format file to be the same as the input, replacing the input file's
-	// extension with '.fnmt'
-	if format && len(formatFile) == 0 {
+	// extension, and the compression extension if there is one, with
+	// '.fmt' or that of a format spec, e.g. '.fmt.yaml'
+	fmtFile := formatFile
+	if format && len(fmtFile) == 0 {
 		// if input is stdin error
 		if input == "stdin" {
-			fmt.Fprintln(os.Stderr, "cannot infer the format file location when using stdin for the input; when stdin is the input, the location must be specified using either the '-formatfile' or '-m' flag")
-			return 1
+			return fmt.Errorf("cannot infer the format file location when using stdin for the input; when stdin is the input, the location must be specified using either the '-formatfile' or '-m' flag")
 		}
 		// build the filepath from the input, if input is stdin error
-		formatFile = fmt.Sprintf("%s.fmt", strings.TrimSuffix(input, filepath.Ext(input)))
+		var ok bool
+		fmtFile, ok = inferFormatFile(input)
+		if !ok {
+			return formatError(fmt.Errorf("format file error: %s: no format file; expected %s or a format spec, e.g. %s.fmt.yaml", input, fmtFile, trimExt(input)))
+		}
 	}
-	// format stuff
-	if len(formatFile) > 0 {
+	// format stuff; a format spec, e.g. data.fmt.yaml, is a structured
+	// format file
+	var formats []csv2md.FieldFormat
+	syntax := specSyntax(fmtFile)
+	if syntax != "" {
+		formats, err = readFormatSpec(fmtFile, syntax)
+		if err != nil {
+			return err
+		}
+	} else if len(fmtFile) > 0 {
 		// if the format file is specified use that
-		formatR, err = os.OpenFile(formatFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+		formatR, err = os.Open(fmtFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "format file error: %s\n", err)
-			return 1
+			return formatError(fmt.Errorf("format file error: %w", err))
 		}
+		defer formatR.Close()
 	}
 
-	t := csv2md.NewTransmogrifier(in, out)
+	var r io.Reader = in
+	var bar *progressBar
+	if showProgress() && len(inputs) == 1 {
+		bar = newProgressBar(input, in)
+		r = bar.in
+	}
+	src, err := decompressor(r, input)
+	if err != nil {
+		return err
+	}
+
+	var t *csv2md.Transmogrifier
+	if len(inputs) > 1 {
+		merged := []csv2md.MergeInput{{Name: input, Reader: src}}
+		for _, name := range inputs[1:] {
+			f := os.Stdin
+			if name != "stdin" {
+				f, err = os.Open(name)
+				if err != nil {
+					return inputError(fmt.Errorf("input file error: %w", err))
+				}
+				defer f.Close()
+			}
+			src, err := decompressor(f, name)
+			if err != nil {
+				return err
+			}
+			merged = append(merged, csv2md.MergeInput{Name: name, Reader: src})
+		}
+		t = csv2md.NewTransmogrifierMerge(out, merged...)
+		t.MergePlaceholder = mergePlaceholder
+	} else {
+		t = csv2md.NewTransmogrifier(src, out)
+	}
+	if bar != nil {
+		t.SetProgress(1000, bar.update)
+	}
 	if len(separator) > 0 {
 		tmp := []rune(separator)
 		t.CSV.Comma = tmp[0]
 	}
+	if comment != "" {
+		tmp := []rune(comment)
+		if len(tmp) != 1 {
+			return fmt.Errorf("the -comment flag must be a single character: %q", comment)
+		}
+		t.CSV.Comment = tmp[0]
+	}
+	t.SkipBlankRecords = skipBlank
+	t.TrimSpace = trimSpace
+	t.CollapseSpace = collapseSpace
+	t.Encoding, err = csv2md.ParseEncoding(encoding)
+	if err != nil {
+		return err
+	}
+	t.AutoAlign = autoAlign
+	t.AutoAlignSample = autoSample
+	err = t.SetLocale(locale)
+	if err != nil {
+		return err
+	}
+	t.HasHeaderRecord = !noHeaderRecord
+	// nothing retains the records
+	t.ReuseRecord = true
+	t.FieldNamePattern = fieldNamePattern
+	t.Pretty = pretty
+	t.OuterPipes = outerPipes
+	t.Transpose = transpose
+	t.RowNumbers = rowNumbers
+	t.RowHeader = rowHeader
+	t.SetRowStyleColumn(rowStyleCol)
+	t.ChunkSize = chunk
+	t.ChunkCaption = chunkCaption
+	t.SetCaption(caption)
+	t.CaptionHeading = captionHeading
+	t.OutputFormat, err = csv2md.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if preview {
+		t.OutputFormat = csv2md.Box
+		if ascii {
+			t.OutputFormat = csv2md.ASCIIBox
+		}
+		t.PreviewWidth = terminalWidth()
+	}
+	t.Strict = strict
+	t.SanitizeControl, err = csv2md.ParseSanitize(sanitize)
+	if err != nil {
+		return err
+	}
+	t.CellHTML, err = csv2md.ParseHTMLHandling(cellHTML)
+	if err != nil {
+		return err
+	}
+	if htmlClasses != "" {
+		c, err := csv2md.ParseHTMLClasses(htmlClasses)
+		if err != nil {
+			return err
+		}
+		t.SetHTMLClasses(c)
+	}
+	t.Ragged, err = csv2md.ParseRaggedMode(ragged)
+	if err != nil {
+		return err
+	}
+	if maxField != "" {
+		t.MaxFieldBytes, err = parseSize(maxField)
+		if err != nil {
+			return err
+		}
+	}
+	t.EscapeMarkdown = !noEscape
+	t.LineBreak = lineBreak
 	t.CSV.LazyQuotes = lazyQuotes
 	t.CSV.TrimLeadingSpace = trimLeadingSpace
 	t.SetNewLine(newLine)
-	fmt.Printf("%q", t.NewLine())
-	t.SetFmt(formatR)
+	t.SetTrailingSpaces(!noSpaces)
+	if syntax != "" {
+		err = t.SetFieldFormats(formats)
+		if err != nil {
+			return formatError(fmt.Errorf("format file error: %s: %w", fmtFile, err))
+		}
+	} else if formatR != nil {
+		err = t.SetFmt(formatR)
+		if err != nil {
+			return formatError(fmt.Errorf("format file error: %s: %w", fmtFile, err))
+		}
+	}
+	if widths != "" {
+		w, err := csv2md.ParseFieldWidths(splitList(widths))
+		if err != nil {
+			return err
+		}
+		t.SetFieldWidths(w)
+	}
+	if headerStyle != "" {
+		err = t.SetHeaderStyle(splitList(headerStyle))
+		if err != nil {
+			return err
+		}
+	}
+	if headerAlign != "" {
+		err = t.SetHeaderAlignment(splitList(headerAlign))
+		if err != nil {
+			return err
+		}
+	}
+	if groupBy != "" && splitBy != "" {
+		return fmt.Errorf("the -groupby and -split-by flags are mutually exclusive")
+	}
+	if agg != "" {
+		if groupBy == "" {
+			return fmt.Errorf("the -agg flag requires the -groupby flag")
+		}
+		err = t.AggregateBy(groupBy, splitList(agg)...)
+		if err != nil {
+			return err
+		}
+	} else if groupBy != "" || splitBy != "" {
+		var opts []csv2md.GroupOption
+		if sortGroups {
+			opts = append(opts, csv2md.SortGroups())
+		}
+		if sortNatural {
+			opts = append(opts, csv2md.SortNatural())
+		}
+		if sortIgnoreCase {
+			opts = append(opts, csv2md.SortIgnoreCase())
+		}
+		if hideGroupCol {
+			opts = append(opts, csv2md.HideGroupColumn())
+		}
+		if groupBy != "" {
+			t.GroupBy(groupBy, opts...)
+		} else {
+			t.SplitBy(splitBy, append(opts, csv2md.SplitHeading(splitHeading))...)
+		}
+	}
+	if rename != "" {
+		names := make(map[string]string)
+		for _, v := range splitList(rename) {
+			i := strings.Index(v, "=")
+			if i < 1 {
+				return fmt.Errorf("rename error: %q: expected old=new", v)
+			}
+			names[v[:i]] = v[i+1:]
+		}
+		t.SetFieldNameMap(names)
+	}
+	if exclude != "" {
+		t.ExcludeColumns(splitList(exclude)...)
+	}
+	if mapFiles != "" {
+		for _, v := range splitList(mapFiles) {
+			err = setValueMap(t, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, def := range constants {
+		err = t.ParseConstantColumn(def)
+		if err != nil {
+			return err
+		}
+	}
+	for _, def := range compute {
+		err = t.ParseComputedColumn(def)
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range numbers {
+		i := strings.Index(v, "=")
+		if i < 1 {
+			return fmt.Errorf("number format error: %q: expected column=format", v)
+		}
+		f, err := csv2md.ParseNumberFormat(v[i+1:])
+		if err != nil {
+			return err
+		}
+		t.SetColumnNumberFormat(v[:i], f)
+	}
+	for _, v := range currencies {
+		i := strings.Index(v, "=")
+		if i < 1 {
+			return fmt.Errorf("currency format error: %q: expected column=format", v)
+		}
+		f, err := csv2md.ParseCurrencyFormat(v[i+1:])
+		if err != nil {
+			return err
+		}
+		err = t.SetColumnCurrency(v[:i], f)
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range negatives {
+		i := strings.Index(v, "=")
+		if i < 1 {
+			return fmt.Errorf("negative style error: %q: expected column=style", v)
+		}
+		ns, err := csv2md.ParseNegativeStyle(v[i+1:])
+		if err != nil {
+			return err
+		}
+		t.SetColumnNegative(v[:i], ns)
+	}
+	for _, v := range redactions {
+		i := strings.Index(v, "=")
+		if i < 1 {
+			return fmt.Errorf("redaction error: %q: expected column=redaction", v)
+		}
+		r, err := csv2md.ParseRedaction(v[i+1:])
+		if err != nil {
+			return err
+		}
+		r.Salt = hashSalt
+		t.SetColumnRedaction(v[:i], r)
+	}
+	if hash != "" {
+		for _, v := range splitList(hash) {
+			t.SetColumnRedaction(v, csv2md.Redaction{Hash: csv2md.DefaultHashLength, Salt: hashSalt})
+		}
+	}
+	for _, v := range replacements {
+		i := strings.Index(v, "=")
+		if i < 1 {
+			return fmt.Errorf("replacement error: %q: expected column=/pattern/replacement/", v)
+		}
+		r, err := csv2md.ParseReplacement(v[i+1:])
+		if err != nil {
+			return err
+		}
+		t.AddColumnReplacement(v[:i], r)
+	}
+	for _, v := range times {
+		i := strings.Index(v, "=")
+		if i < 1 {
+			return fmt.Errorf("time format error: %q: expected column=format", v)
+		}
+		f, err := csv2md.ParseTimeFormat(v[i+1:])
+		if err != nil {
+			return err
+		}
+		t.SetColumnTimeFormat(v[:i], f)
+	}
+	for _, v := range templates {
+		i := strings.Index(v, "=")
+		if i < 1 {
+			return fmt.Errorf("template error: %q: expected column=template", v)
+		}
+		err = t.SetColumnTemplate(v[:i], v[i+1:])
+		if err != nil {
+			return fmt.Errorf("template error: %w", err)
+		}
+	}
+	t.MaxCellWidth = maxCellWidth
+	if maxColWidths != "" {
+		for _, v := range splitList(maxColWidths) {
+			i := strings.LastIndex(v, "=")
+			if i < 1 {
+				return fmt.Errorf("max column width error: %q: expected column=width", v)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(v[i+1:]))
+			if err != nil || n < 0 {
+				return fmt.Errorf("max column width error: %q: not a valid width", v)
+			}
+			t.SetColumnMaxWidth(v[:i], n)
+		}
+	}
+	t.TruncateFootnotes = truncFootnotes
+	if input != "stdin" {
+		// the footnote labels of each input's table are unique
+		t.FootnotePrefix = trimExt(filepath.Base(input)) + "-"
+	}
+	if links != "" {
+		for _, v := range splitList(links) {
+			var opts []csv2md.LinkOption
+			if i := strings.Index(v, "="); i >= 0 {
+				opts = append(opts, csv2md.LinkText(v[i+1:]))
+				v = v[:i]
+			}
+			if autolink {
+				opts = append(opts, csv2md.Autolink())
+			}
+			t.SetColumnLink(v, opts...)
+		}
+	}
+	if images != "" {
+		for _, v := range splitList(images) {
+			var opts []csv2md.ImageOption
+			if i := strings.Index(v, "="); i >= 0 {
+				opts = append(opts, csv2md.ImageAlt(v[i+1:]))
+				v = v[:i]
+			}
+			t.SetColumnImage(v, opts...)
+		}
+	}
+	if bools != "" {
+		var opts []csv2md.BoolOption
+		if boolText != "" {
+			text := splitList(boolText)
+			if len(text) != 2 {
+				return fmt.Errorf("the -bool-text flag must be the true and false text, separated by a comma: %q", boolText)
+			}
+			opts = append(opts, csv2md.BoolText(text[0], text[1]))
+		}
+		for _, v := range splitList(bools) {
+			t.SetColumnBool(v, opts...)
+		}
+	}
+	if byteSizes != "" {
+		units, err := csv2md.ParseByteUnits(byteUnits)
+		if err != nil {
+			return err
+		}
+		for _, v := range splitList(byteSizes) {
+			t.SetColumnByteSize(v, units)
+		}
+	}
+	if durations != "" {
+		unit, err := csv2md.ParseDurationUnit(durationUnit)
+		if err != nil {
+			return err
+		}
+		for _, v := range splitList(durations) {
+			t.SetColumnDuration(v, unit)
+		}
+	}
+	for _, rule := range styleIf {
+		err = t.ParseStyleIf(rule)
+		if err != nil {
+			return err
+		}
+	}
+	for _, rule := range rules {
+		err = t.ParseRule(rule)
+		if err != nil {
+			return err
+		}
+	}
+	if footer != "" {
+		for _, v := range splitList(footer) {
+			err = t.ParseAggregate(v)
+			if err != nil {
+				return err
+			}
+		}
+		t.FooterLabel = footerLabel
+	}
+	if pivot != "" {
+		err = t.ParsePivot(pivot)
+		if err != nil {
+			return err
+		}
+	}
+	if where != "" {
+		err = t.SetFilter(where)
+		if err != nil {
+			return err
+		}
+	}
+	if head > 0 && (offset > 0 || limit > 0) {
+		return fmt.Errorf("the -head flag is mutually exclusive with the -offset and -limit flags")
+	}
+	n := limit
+	if head > 0 {
+		n = head
+	}
+	if tail > 0 && (offset > 0 || n > 0) {
+		return fmt.Errorf("the -tail flag is mutually exclusive with the -head, -offset, and -limit flags")
+	}
+	if offset > 0 || n > 0 {
+		t.SetRowRange(offset, n)
+	}
+	if tail > 0 {
+		t.SetTail(tail)
+	}
+	t.EllipsisRows = ellipsis
+	if sampleN > 0 && samplePct > 0 {
+		return fmt.Errorf("the -sample and -sample-pct flags are mutually exclusive")
+	}
+	if sampleN > 0 {
+		err = t.SetSample(sampleN, seed)
+		if err != nil {
+			return err
+		}
+	}
+	if samplePct > 0 {
+		err = t.SetSamplePercent(samplePct, seed)
+		if err != nil {
+			return err
+		}
+	}
+	if dedupBy != "" {
+		t.Dedup(splitList(dedupBy)...)
+	} else if dedup {
+		t.Dedup()
+	}
+	if collapseRepeats != "" {
+		t.CollapseRepeats(splitList(collapseRepeats))
+	}
+	t.Metadata, err = csv2md.ParseMetadataFormat(metadata)
+	if err != nil {
+		return err
+	}
+	t.SourceHeading = sourceHeading
+	if input != "stdin" {
+		t.Source.Path = input
+		fi, err := in.Stat()
+		if err == nil {
+			t.Source.ModTime = fi.ModTime()
+		}
+	}
+	t.SetLogger(logger.With("input", input))
+	if report != nil {
+		t.SkipMalformedRows(report.rowReporter(input))
+	}
 	err = t.MDTable()
+	if bar != nil {
+		bar.done(err)
+	}
+	if err == nil && (dedup || dedupBy != "") && !quiet {
+		fmt.Fprintf(os.Stderr, "%s: removed %d duplicate rows\n", input, t.Duplicates())
+	}
+	if err == nil && stats {
+		writeStats(input, t.Stats())
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "transmogrifierication error: %s\n", err)
-		return 1
+		return withStatus(conversionStatus(err), fmt.Errorf("transmogrifierication error: %w", err))
+	}
+	return nil
+}
+
+// writeStats writes the input's conversion statistics to stderr.
+func writeStats(input string, s csv2md.Stats) {
+	fmt.Fprintf(os.Stderr, "%s: %d rows read, %d written, %d skipped, %d filtered, %d malformed; %d bytes read, %d written; %s, %.0f rows/s, %.2f MB/s\n",
+		input, s.RowsRead, s.RowsWritten, s.RowsSkipped, s.RowsFiltered, s.RowsMalformed, s.BytesRead, s.BytesWritten,
+		s.Elapsed.Round(time.Microsecond), s.RowsPerSecond(), s.BytesPerSecond()/1e6)
+}
+
+// specSyntax returns the syntax of the format file if it is a format spec:
+// json, yaml, or toml, by its extension.  An empty string is returned for
+// a CSV-encoded format file.
+func specSyntax(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	}
+	return ""
+}
+
+// readFormatSpec reads the field formats from the format spec file.
+func readFormatSpec(name, syntax string) ([]csv2md.FieldFormat, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, formatError(fmt.Errorf("format file error: %w", err))
+	}
+	defer f.Close()
+	formats, err := csv2md.ReadFieldFormats(f, syntax)
+	if err != nil {
+		return nil, formatError(fmt.Errorf("format file error: %s: %w", name, err))
+	}
+	return formats, nil
+}
+
+// setValueMap sets the column's value map from a column=file mapping.  The
+// file is CSV-encoded, encoded the same way as the data, with each record
+// consisting of the value to replace and the value to replace it with.
+func setValueMap(t *csv2md.Transmogrifier, mapping string) error {
+	i := strings.Index(mapping, "=")
+	if i < 1 {
+		return fmt.Errorf("map error: %q: expected column=file", mapping)
+	}
+	f, err := os.Open(mapping[i+1:])
+	if err != nil {
+		return inputError(fmt.Errorf("map file error: %w", err))
+	}
+	defer f.Close()
+	// a .json map file is a JSON object; any other map file is CSV
+	syntax := "csv"
+	if strings.EqualFold(filepath.Ext(mapping[i+1:]), ".json") {
+		syntax = "json"
+	}
+	m, err := t.ReadValueMap(f, syntax)
+	if err != nil {
+		return inputError(fmt.Errorf("map file error: %s: %w", mapping[i+1:], err))
+	}
+	t.SetColumnValueMap(mapping[:i], m, !mapStrict)
+	return nil
+}
+
+// parseSize parses a human readable size, e.g. 512KB or 1MB, into bytes.
+// The units are powers of 1024; a number without a unit is bytes.
+func parseSize(s string) (int, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	mult := 1
+	for _, u := range []struct {
+		suffix string
+		mult   int
+	}{
+		{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+		{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+		{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1},
+	} {
+		if strings.HasSuffix(v, u.suffix) {
+			v = strings.TrimSpace(strings.TrimSuffix(v, u.suffix))
+			mult = u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int(n * float64(mult)), nil
+}
+
+// terminalWidth returns the width to use for the preview.  If the width
+// wasn't specified, the COLUMNS environment variable is used, if it is set;
+// otherwise, if stdout is a terminal, 80 is used.  A width of 0 means that
+// the width is not limited.
+func terminalWidth() int {
+	if previewWidth > 0 {
+		return previewWidth
+	}
+	n, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err == nil && n > 0 {
+		return n
+	}
+	fi, err := os.Stdout.Stat()
+	if err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		return 80
 	}
 	return 0
 }
+
+// the extensions of compressed files
+var compressedExts = []string{".gz", ".gzip", ".bz2", ".zst"}
+
+// trimExt returns the name without its extension; if the name has the
+// extension of a compressed file, that extension is removed too, e.g.
+// data.csv.gz is data.
+func trimExt(name string) string {
+	for _, ext := range compressedExts {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			name = name[:len(name)-len(ext)]
+			break
+		}
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// decompressor wraps the input in the decompressor selected by the
+// -decompress flag.  With auto, the compression is detected from the
+// input's first bytes.
+func decompressor(r io.Reader, name string) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	method := strings.ToLower(strings.TrimSpace(decompress))
+	if method == "auto" {
+		b, _ := br.Peek(4)
+		switch {
+		case bytes.HasPrefix(b, []byte{0x1f, 0x8b}):
+			method = "gzip"
+		case len(b) == 4 && bytes.HasPrefix(b, []byte("BZh")) && b[3] >= '1' && b[3] <= '9':
+			method = "bzip2"
+		case bytes.HasPrefix(b, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+			method = "zstd"
+		default:
+			method = "none"
+		}
+	}
+	switch method {
+	case "none":
+		return br, nil
+	case "gzip":
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, inputError(fmt.Errorf("%s: gzip error: %w", name, err))
+		}
+		return zr, nil
+	case "bzip2":
+		return bzip2.NewReader(br), nil
+	case "zstd":
+		return zstd.NewReader(br), nil
+	}
+	return nil, fmt.Errorf("unknown -decompress value %q: must be auto, gzip, bzip2, zstd, or none", decompress)
+}
+
+// splitList splits a comma separated list of values; leading and trailing
+// white space is removed from each value.
+func splitList(s string) []string {
+	vals := strings.Split(s, ",")
+	for i, v := range vals {
+		vals[i] = strings.TrimSpace(v)
+	}
+	return vals
+}
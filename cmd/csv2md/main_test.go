@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestDecompressor(t *testing.T) {
+	var cars bytes.Buffer
+	cars.WriteString("Make,Model,Year\n")
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&cars, "Ford,Focus,%d\n", 2000+i)
+	}
+	zstdCars := "\x28\xb5\x2f\xfd\x60\x50\x00\x4d\x02\x00\x44\x03\x4d\x61\x6b\x65\x2c\x4d\x6f\x64\x65\x6c\x2c\x59\x65\x61\x72\x0a\x46\x6f\x72\x64\x2c\x46\x6f\x63\x75\x73\x2c\x32\x30\x30\x30\x31\x32\x33\x34\x35\x36\x37\x38\x39\x31\x30\x31\x32\x33\x34\x35\x36\x37\x38\x39\x0a\x13\xa8\x10\xe8\xeb\x7f\x07\xf0\xe5\x10\xfe\xc6\x07\x56\xef\xfd\xd0\x24\x03"
+	data := "Make,Year\nFord,2015\n"
+	gzipData := "\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xf3\x4d\xcc\x4e\xd5\x89\x4c\x4d\x2c\xe2\x72\xcb\x2f\x4a\xd1\x31\x32\x30\x34\xe5\x02\x00\xfa\x6c\x6d\xa9\x14\x00\x00\x00"
+	bzip2Data := "\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\x6d\x21\x60\x46\x00\x00\x04\xdf\x80\x00\x10\x00\x04\x72\x00\x01\x02\x00\x20\x26\x08\x90\x00\x20\x00\x22\x10\x63\x53\x1a\x42\x98\x00\x0c\x7a\xb1\x10\x02\x6e\x5f\xc6\xc9\xd6\x7c\x5d\xc9\x14\xe1\x42\x41\xb4\x85\x81\x18"
+	tests := []struct {
+		method   string
+		input    string
+		expected string
+	}{
+		{"auto", zstdCars, cars.String()},
+		{"zstd", zstdCars, cars.String()},
+		{"auto", gzipData, data},
+		{"auto", bzip2Data, data},
+		{"auto", data, data},
+		{"none", gzipData, gzipData},
+	}
+	defer func(d string) { decompress = d }(decompress)
+	for i, test := range tests {
+		decompress = test.method
+		r, err := decompressor(bytes.NewReader([]byte(test.input)), "cars.csv")
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if string(b) != test.expected {
+			t.Errorf("%d: got %q want %q", i, b, test.expected)
+		}
+	}
+}
+
+func TestSameOutput(t *testing.T) {
+	yamlOut := "---\nsource: \"cars.csv\"\ngenerated: \"2026-01-02T15:04:05Z\"\nrows: 1\n---\n\nMake|Year  \n---|---  \nFord|2015  \n"
+	jsonOut := "<!--\n{\n  \"source\": \"cars.csv\",\n  \"generated\": \"2026-01-02T15:04:05Z\",\n  \"rows\": 1\n}\n-->\n\nMake|Year  \n---|---  \nFord|2015  \n"
+	regenerated := func(s string) string {
+		return strings.Replace(s, "2026-01-02T15:04:05Z", "2026-10-14T09:30:00Z", 1)
+	}
+	tests := []struct {
+		metadata string
+		cur      string
+		out      string
+		expected bool
+	}{
+		{"none", yamlOut, yamlOut, true},
+		{"none", yamlOut, regenerated(yamlOut), false},
+		{"yaml", yamlOut, regenerated(yamlOut), true},
+		{"yaml", yamlOut, strings.Replace(regenerated(yamlOut), "2015", "2016", 1), false},
+		{"yaml", yamlOut, strings.Replace(yamlOut, "rows: 1", "rows: 2", 1), false},
+		{"json", jsonOut, regenerated(jsonOut), true},
+		{"json", jsonOut, strings.Replace(regenerated(jsonOut), "Ford", "Kia", 1), false},
+		{"json", "", jsonOut, false},
+	}
+	defer func(m string) { metadata = m }(metadata)
+	for i, test := range tests {
+		metadata = test.metadata
+		same := sameOutput([]byte(test.cur), []byte(test.out))
+		if same != test.expected {
+			t.Errorf("%d: got %t want %t", i, same, test.expected)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// how long a conversion runs before its progress is shown
+	progressDelay = time.Second
+	// how often the progress is redrawn
+	progressRate = 100 * time.Millisecond
+	// the progress bar's width, in characters
+	progressWidth = 30
+)
+
+// counter counts the bytes read from the input file; with compressed
+// input, these are the compressed bytes.
+type counter struct {
+	r io.Reader
+	n int64
+}
+
+func (c *counter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// progressBar shows the progress of converting an input on stderr: the
+// percentage of the input that has been read, if its size is known, and
+// the number of rows that have been converted.  It is only shown once the
+// conversion has run for a while, so converting small inputs doesn't
+// flicker.
+type progressBar struct {
+	name  string
+	size  int64 // 0 if the input's size isn't known, e.g. a pipe
+	in    *counter
+	rows  int64
+	start time.Time
+	drawn time.Time
+}
+
+// newProgressBar returns a progress bar for the input file; the input is
+// read using the progress bar's counter.
+func newProgressBar(name string, f *os.File) *progressBar {
+	p := &progressBar{name: name, in: &counter{r: f}, start: time.Now()}
+	fi, err := f.Stat()
+	if err == nil && fi.Mode().IsRegular() {
+		p.size = fi.Size()
+	}
+	return p
+}
+
+// showProgress returns whether a progress bar is shown: it is shown when
+// the tables are written to files and stderr is a terminal.
+func showProgress() bool {
+	if noProgress || quiet || check || preview {
+		return false
+	}
+	if output == "stdout" && outDir == "" && inject == "" {
+		return false
+	}
+	fi, err := os.Stderr.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// update is the Transmogrifier's progress function.
+func (p *progressBar) update(rows, _, _ int64) {
+	p.rows = rows
+	now := time.Now()
+	if now.Sub(p.start) < progressDelay || now.Sub(p.drawn) < progressRate {
+		return
+	}
+	p.drawn = now
+	p.draw()
+}
+
+func (p *progressBar) draw() {
+	n := p.in.n
+	if p.size <= 0 {
+		fmt.Fprintf(os.Stderr, "\r%s: %d bytes read, %d rows", p.name, n, p.rows)
+		return
+	}
+	if n > p.size {
+		n = p.size
+	}
+	filled := int(n * progressWidth / p.size)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressWidth-filled)
+	fmt.Fprintf(os.Stderr, "\r%s: [%s] %3d%% %d rows", p.name, bar, n*100/p.size, p.rows)
+}
+
+// done ends the progress bar, if it was shown; a completed conversion's
+// progress is redrawn first so that it ends at 100%.
+func (p *progressBar) done(err error) {
+	if p.drawn.IsZero() {
+		return
+	}
+	if err == nil {
+		p.draw()
+	}
+	fmt.Fprintln(os.Stderr)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// the version, commit, and build date can be set when building, e.g.
+// -ldflags "-X main.version=v1.2.0 -X main.commit=0123abc -X main.date=2026-10-14";
+// otherwise they are those of the build info, if there is any
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// versionInfo returns the binary's version, the commit it was built from,
+// and its build date; the values that aren't known are unknown.
+func versionInfo() string {
+	v, c, d := version, commit, date
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v == "" && info.Main.Version != "" {
+			v = info.Main.Version
+		}
+		var modified bool
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if c == "" {
+					c = s.Value
+				}
+			case "vcs.time":
+				if d == "" {
+					d = s.Value
+				}
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+		if modified && commit == "" {
+			c += "-dirty"
+		}
+	}
+	if v == "" {
+		v = "unknown"
+	}
+	if c == "" {
+		c = "unknown"
+	}
+	if d == "" {
+		d = "unknown"
+	}
+	return fmt.Sprintf("%s %s (commit %s, built %s, %s)", prog, v, c, d, runtime.Version())
+}
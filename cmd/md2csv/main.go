@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mohae/csv2md"
+)
+
+// flags
+var (
+	formatFile string
+	help       bool
+	input      string
+	output     string
+	separator  string
+)
+
+var prog = filepath.Base(os.Args[0])
+
+func init() {
+	flag.StringVar(&formatFile, "formatfile", "", "path to write the table's format file to; by default no format file is written")
+	flag.StringVar(&formatFile, "m", "", "short flag for -formatfile")
+	flag.StringVar(&input, "input", "stdin", "input source")
+	flag.StringVar(&input, "i", "stdin", "short flag for -input")
+	flag.StringVar(&output, "output", "stdout", "output destination")
+	flag.StringVar(&output, "o", "stdout", "short flag for -output")
+	flag.StringVar(&separator, "separator", ",", "field separator")
+	flag.StringVar(&separator, "s", ",", "short flag for -separator")
+	flag.BoolVar(&help, "help", false, "md2csv help")
+	flag.BoolVar(&help, "h", false, "short flag for -help")
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n")
+	fmt.Fprintf(os.Stderr, "  %s [OPTS]\n", prog)
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "Creates CSV-encoded data from a Github Style Markdown table\n")
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "Options:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	os.Exit(realMain())
+}
+
+func realMain() int {
+	flag.Usage = usage
+	flag.Parse()
+	// check args; in case help was used without the flag prefix
+	for _, arg := range flag.Args() {
+		if arg == "help" {
+			help = true
+			break
+		}
+	}
+	if help {
+		flag.Usage()
+		return 0
+	}
+	err := convert()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// convert reads the input's Markdown table and writes it to the output as
+// CSV-encoded data.  If a format file is specified, the table's field names,
+// alignment, and styling are written to it.
+func convert() error {
+	in := os.Stdin
+	if input != "stdin" {
+		f, err := os.Open(input)
+		if err != nil {
+			return fmt.Errorf("input file error: %s", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	tbl, err := csv2md.ReadMDTable(in)
+	if err != nil {
+		return fmt.Errorf("%s: %s", input, err)
+	}
+	out := os.Stdout
+	if output != "stdout" {
+		out, err = os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("output file error: %s", err)
+		}
+		defer out.Close()
+	}
+	err = tbl.WriteCSV(newWriter(out))
+	if err != nil {
+		return err
+	}
+	if formatFile == "" {
+		return nil
+	}
+	f, err := os.OpenFile(formatFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("format file error: %s", err)
+	}
+	defer f.Close()
+	return tbl.WriteFmt(newWriter(f))
+}
+
+func newWriter(f *os.File) *csv.Writer {
+	w := csv.NewWriter(f)
+	if len(separator) > 0 {
+		w.Comma = []rune(separator)[0]
+	}
+	return w
+}
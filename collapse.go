@@ -0,0 +1,65 @@
+package csv2md
+
+// collapse blanks out consecutive repeated values in a set of columns.
+type collapse struct {
+	columns []string
+	indices []int
+	prev    []string
+	seen    bool
+}
+
+// CollapseRepeats blanks out a listed column's value when it is the same
+// as the previous row's value for that column, so that only the first
+// occurrence of a run of repeated values is shown.  The columns are
+// compared in the order that they are listed; whenever an earlier column's
+// value changes, the later columns are shown again, even if their values
+// are repeated, so that nested values, e.g. Region and Zone, collapse
+// correctly.  A new group, see GroupBy, also resets the comparison.
+//
+// Values are blanked after the records have been sorted and grouped and
+// before any styling is applied.
+func (t *Transmogrifier) CollapseRepeats(columns []string) {
+	t.collapse = &collapse{columns: append([]string(nil), columns...)}
+}
+
+// prepareCollapse resolves the collapsed columns against the header.
+func (t *Transmogrifier) prepareCollapse(header []string) error {
+	c := t.collapse
+	c.indices = c.indices[:0]
+	for _, name := range c.columns {
+		i := columnIndex(header, name)
+		if i < 0 {
+			return UnknownColumnError{Name: name, operation: "collapse repeats"}
+		}
+		c.indices = append(c.indices, i)
+	}
+	c.prev = make([]string, len(c.indices))
+	c.seen = false
+	return nil
+}
+
+// reset makes the next record's values be shown regardless of the
+// previous record's values.
+func (c *collapse) reset() {
+	c.seen = false
+}
+
+// apply blanks out the repeated values in the record.  The previous values
+// are the record's original values.
+func (c *collapse) apply(record []string) []string {
+	changed := !c.seen
+	c.seen = true
+	for j, i := range c.indices {
+		var v string
+		if i < len(record) {
+			v = record[i]
+		}
+		if !changed && v == c.prev[j] {
+			record[i] = ""
+			continue
+		}
+		changed = true
+		c.prev[j] = v
+	}
+	return record
+}
@@ -0,0 +1,54 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCollapseRepeats(t *testing.T) {
+	csvData := []byte("Region,Zone,Host\nus-east-1,a,h1\nus-east-1,a,h2\nus-east-1,b,h3\nus-west-2,b,h4\nus-west-2,b,h5\n")
+	tests := []struct {
+		columns  []string
+		expected string
+		err      string
+	}{
+		{nil, "Region|Zone|Host  \n---|---|---  \nus-east-1|a|h1  \nus-east-1|a|h2  \nus-east-1|b|h3  \nus-west-2|b|h4  \nus-west-2|b|h5  \n", ""},
+		{[]string{"Region"}, "Region|Zone|Host  \n---|---|---  \nus-east-1|a|h1  \n |a|h2  \n |b|h3  \nus-west-2|b|h4  \n |b|h5  \n", ""},
+		{[]string{"Region", "Zone"}, "Region|Zone|Host  \n---|---|---  \nus-east-1|a|h1  \n | |h2  \n |b|h3  \nus-west-2|b|h4  \n | |h5  \n", ""},
+		{[]string{"Zone"}, "Region|Zone|Host  \n---|---|---  \nus-east-1|a|h1  \nus-east-1| |h2  \nus-east-1|b|h3  \nus-west-2| |h4  \nus-west-2| |h5  \n", ""},
+		{[]string{"Rack"}, "", `collapse repeats: unknown column "Rack"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		if test.columns != nil {
+			calvin.CollapseRepeats(test.columns)
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestCollapseRepeatsGroupBy(t *testing.T) {
+	csvData := []byte("Team,Name\nWeb,Ann\nWeb,Bob\nPlatform,Cat\n")
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+	calvin.GroupBy("Team", SortGroups())
+	calvin.CollapseRepeats([]string{"Team"})
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Team|Name  \n---|---  \n**Team: Platform**|   \nPlatform|Cat  \n**Team: Web**|   \nWeb|Ann  \n |Bob  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
@@ -0,0 +1,206 @@
+package csv2md
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ComputedColumnError occurs when a computed column's value cannot be
+// computed.
+type ComputedColumnError struct {
+	Row    int
+	Column string
+	Err    error
+}
+
+func (e ComputedColumnError) Error() string {
+	return fmt.Sprintf("row %d: computed column %q: %s", e.Row, e.Column, e.Err)
+}
+
+func (e ComputedColumnError) Unwrap() error {
+	return e.Err
+}
+
+// ComputedColumnOption configures a computed column.
+type ComputedColumnOption func(*computedColumn)
+
+// ComputedColumnAt inserts the computed column at the i'th position,
+// counting from 0, instead of appending it after the existing columns.  The
+// position is resolved against the columns as they are when the column is
+// added to the table, including any computed columns that were added
+// before it.  If i is greater than the number of columns, the column is
+// appended.
+func ComputedColumnAt(i int) ComputedColumnOption {
+	return func(c *computedColumn) {
+		c.pos = i
+	}
+}
+
+type computedColumn struct {
+	name  string
+	f     func(record map[string]string) (string, error)
+	pos   int
+	index int
+	// the columns referenced by the column's expression, if it has one
+	columns []string
+}
+
+// AddComputedColumn adds a column whose value is computed, for each
+// record, by calling f with the record's values keyed by column name.  The
+// record includes the values of any computed columns that were added
+// before this one.  By default, computed columns are appended after the
+// existing columns, in the order they were added; see ComputedColumnAt.
+//
+// Once added, a computed column is like any other column: field alignment
+// and styles are positional and include the computed columns, and the
+// column can be referred to by name, e.g. by GroupBy or a style rule.
+// Computed columns require the table to have a header; either a header
+// record or field names.  If f returns an error, MDTable stops and returns
+// a ComputedColumnError.
+func (t *Transmogrifier) AddComputedColumn(name string, f func(record map[string]string) (string, error), opts ...ComputedColumnOption) {
+	c := &computedColumn{name: name, f: f, pos: -1}
+	for _, opt := range opts {
+		opt(c)
+	}
+	t.computed = append(t.computed, c)
+}
+
+// AddComputedExpr adds a computed column whose value is the result of the
+// expression; e.g. `Qty * Price` or `First + " " + Last`.  See
+// AddComputedColumn for how the column is added.  Column names in the
+// expression refer to the record's values for those columns; a column that
+// isn't in the header, or computed before this one, is an
+// UnknownColumnError.
+func (t *Transmogrifier) AddComputedExpr(name, expr string, opts ...ComputedColumnOption) error {
+	n, err := parseExpr(expr)
+	if err != nil {
+		return err
+	}
+	t.AddComputedColumn(name, func(record map[string]string) (string, error) {
+		v, err := n.eval(func(name string) (string, bool) {
+			s, ok := record[name]
+			return s, ok
+		})
+		if err != nil {
+			return "", err
+		}
+		return v.s, nil
+	}, opts...)
+	t.computed[len(t.computed)-1].columns = exprColumns(n)
+	return nil
+}
+
+// AddConstantColumn adds a computed column whose value is the same for
+// every record; e.g. the source of the data when multiple inputs are
+// concatenated.  See AddComputedColumn for how the column is added.
+func (t *Transmogrifier) AddConstantColumn(name, value string, opts ...ComputedColumnOption) {
+	t.AddComputedColumn(name, func(map[string]string) (string, error) {
+		return value, nil
+	}, opts...)
+}
+
+// ParseConstantColumn parses a constant column definition of the form
+// name=value; e.g. "Source=Q3 export" and adds it as a constant column
+// that is appended after the existing columns.
+func (t *Transmogrifier) ParseConstantColumn(def string) error {
+	i := strings.Index(def, "=")
+	if i < 0 {
+		return fmt.Errorf("constant column %q: expected name=value", def)
+	}
+	name := strings.TrimSpace(def[:i])
+	if name == "" {
+		return fmt.Errorf("constant column %q: no name", def)
+	}
+	t.AddConstantColumn(name, def[i+1:])
+	return nil
+}
+
+// ParseComputedColumn parses a computed column definition of the form
+// name=expression; e.g. "Total=Qty*Price" and adds it as a computed column
+// that is appended after the existing columns.
+func (t *Transmogrifier) ParseComputedColumn(def string) error {
+	i := strings.Index(def, "=")
+	if i < 0 {
+		return fmt.Errorf("computed column %q: expected name=expression", def)
+	}
+	name := strings.TrimSpace(def[:i])
+	if name == "" {
+		return fmt.Errorf("computed column %q: no name", def)
+	}
+	return t.AddComputedExpr(name, def[i+1:])
+}
+
+// prepareComputed adds the computed columns to the header and computes the
+// values of any records that have already been buffered.  The returned
+// header includes the computed columns.
+func (t *Transmogrifier) prepareComputed(header []string) ([]string, error) {
+	if header == nil {
+		return nil, fmt.Errorf("computed column %q: the table has no header", t.computed[0].name)
+	}
+	known := append([]string(nil), header...)
+	for _, c := range t.computed {
+		for _, name := range c.columns {
+			if columnIndex(known, name) < 0 {
+				return nil, UnknownColumnError{Name: name, operation: fmt.Sprintf("computed column %q", c.name)}
+			}
+		}
+		known = append(known, c.name)
+	}
+	t.computedFrom = append([]string(nil), header...)
+	for _, c := range t.computed {
+		c.index = c.pos
+		if c.index < 0 || c.index > len(header) {
+			c.index = len(header)
+		}
+		header = append(header, "")
+		copy(header[c.index+1:], header[c.index:])
+		header[c.index] = c.name
+	}
+	t.header = header
+	t.nComputed = 0
+	return header, t.computeBuffered()
+}
+
+// computeBuffered adds the computed values to the buffered records that
+// don't have them yet.  A buffered record's row is the row it will have
+// once it is read.
+func (t *Transmogrifier) computeBuffered() error {
+	if t.computedFrom == nil {
+		return nil
+	}
+	for i := t.nComputed; i < len(t.records); i++ {
+		record, err := t.compute(t.records[i], t.row+i+1)
+		if err != nil {
+			return err
+		}
+		t.records[i] = record
+	}
+	t.nComputed = len(t.records)
+	return nil
+}
+
+// compute returns the record with the computed columns' values inserted.
+func (t *Transmogrifier) compute(record []string, row int) ([]string, error) {
+	m := make(map[string]string, len(t.computedFrom)+len(t.computed))
+	for i, name := range t.computedFrom {
+		if i < len(record) {
+			m[name] = record[i]
+		} else {
+			m[name] = ""
+		}
+	}
+	for _, c := range t.computed {
+		v, err := c.f(m)
+		if err != nil {
+			return nil, ComputedColumnError{Row: row, Column: c.name, Err: err}
+		}
+		m[c.name] = v
+		for len(record) < c.index {
+			record = append(record, "")
+		}
+		record = append(record, "")
+		copy(record[c.index+1:], record[c.index:])
+		record[c.index] = v
+	}
+	return record, nil
+}
@@ -0,0 +1,161 @@
+package csv2md
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestAddComputedColumn(t *testing.T) {
+	csvData := []byte("First,Last,Qty,Price\nAnn,Lee,3,2.5\nBob,Ray,2,10\n")
+	tests := []struct {
+		name     string
+		expr     string
+		opts     []ComputedColumnOption
+		expected string
+		err      string
+	}{
+		{"Total", "Qty*Price", nil, "First|Last|Qty|Price|Total  \n---|---|---|---|---  \nAnn|Lee|3|2.5|7.5  \nBob|Ray|2|10|20  \n", ""},
+		{"Name", `First + " " + Last`, []ComputedColumnOption{ComputedColumnAt(0)}, "Name|First|Last|Qty|Price  \n---|---|---|---|---  \nAnn Lee|Ann|Lee|3|2.5  \nBob Ray|Bob|Ray|2|10  \n", ""},
+		{"Total", "Qty*Price", []ComputedColumnOption{ComputedColumnAt(99)}, "First|Last|Qty|Price|Total  \n---|---|---|---|---  \nAnn|Lee|3|2.5|7.5  \nBob|Ray|2|10|20  \n", ""},
+		{"Bad", "First*Qty", nil, "", `row 2: computed column "Bad": "Ann" * "3": "Ann" is not a number`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		err := calvin.AddComputedExpr(test.name, test.expr, test.opts...)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		err = calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+// computed columns take part in the other settings, both positionally and
+// by name, and their values are available to later computed columns.
+func TestComputedColumnSettings(t *testing.T) {
+	csvData := []byte("Team,Qty,Price\nWeb,1,4\nPlatform,3,2\nWeb,2,1\n")
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+	calvin.AddComputedColumn("Total", func(record map[string]string) (string, error) {
+		if record["Team"] == "Platform" {
+			return "n/a", nil
+		}
+		return record["Qty"] + "x" + record["Price"], nil
+	})
+	err := calvin.ParseComputedColumn("Label = Team + ':' + Total")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	calvin.SetFieldAlignment([]string{"l", "r", "r", "c", "auto"})
+	calvin.SetCellStyleRule("Total", func(v string, record []string) bool { return v == "n/a" }, "i")
+	calvin.GroupBy("Team", SortGroups(), HideGroupColumn())
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Qty|Price|Total|Label  \n--:|--:|:--:|:--  \n**Team: Platform**| | |   \n3|2|_n/a_|Platform:n/a  \n**Team: Web**| | |   \n1|4|1x4|Web:1x4  \n2|1|2x1|Web:2x1  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
+
+func TestComputedColumnError(t *testing.T) {
+	errBad := errors.New("bad value")
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader([]byte("a,b\n1,2\n3,x\n")), &w)
+	calvin.AddComputedColumn("c", func(record map[string]string) (string, error) {
+		if record["b"] == "x" {
+			return "", errBad
+		}
+		return record["b"], nil
+	})
+	err := calvin.MDTable()
+	var cerr ComputedColumnError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("got %v, want a ComputedColumnError", err)
+	}
+	if cerr.Row != 3 || cerr.Column != "c" || !errors.Is(err, errBad) {
+		t.Errorf("got %#v, want row 3 column c", cerr)
+	}
+}
+
+func TestConstantColumn(t *testing.T) {
+	csvData := []byte("Qty,Price\n2,3\n4,5\n")
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+	err := calvin.ParseConstantColumn("Source=Q3 export, final")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	calvin.AddConstantColumn("Region", "EU", ComputedColumnAt(0))
+	err = calvin.ParseComputedColumn(`Label=Region + ":" + Qty`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Region|Qty|Price|Source|Label  \n---|---|---|---|---  \nEU|2|3|Q3 export, final|EU:2  \nEU|4|5|Q3 export, final|EU:4  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+
+	tests := []struct {
+		def string
+		err string
+	}{
+		{"Source=", ""},
+		{"Source", `constant column "Source": expected name=value`},
+		{" =x", `constant column " =x": no name`},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(bytes.NewReader(nil), &bytes.Buffer{})
+		err := calvin.ParseConstantColumn(test.def)
+		var s string
+		if err != nil {
+			s = err.Error()
+		}
+		if s != test.err {
+			t.Errorf("%d: got error %q want %q", i, s, test.err)
+		}
+	}
+}
+
+func TestParseComputedColumn(t *testing.T) {
+	tests := []struct {
+		def string
+		err string
+	}{
+		{"Total=Qty*Price", ""},
+		{"Total", `computed column "Total": expected name=expression`},
+		{"=Qty", `computed column "=Qty": no name`},
+		{"Total=Qty*", `expression "Qty*": position 4: unexpected end of expression`},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(bytes.NewReader(nil), &bytes.Buffer{})
+		err := calvin.ParseComputedColumn(test.def)
+		var s string
+		if err != nil {
+			s = err.Error()
+		}
+		if s != test.err {
+			t.Errorf("%d: got error %q want %q", i, s, test.err)
+		}
+	}
+}
@@ -12,11 +12,15 @@
 package csv2md
 
 import (
+	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -24,11 +28,48 @@ const (
 	centered      = ":--:"
 	right         = "--:"
 	none          = "---"
+	auto          = "auto"
 	italic        = "_"
 	bold          = "__"
 	strikethrough = "~~"
 )
 
+// Format is an output format.
+type Format int
+
+const (
+	// GFM is a GitHub Flavored Markdown table.
+	GFM Format = iota
+	// Box is a plain text table drawn with box-drawing characters, for
+	// previewing the table in a terminal.
+	Box
+	// ASCIIBox is a plain text table drawn with ASCII characters, for
+	// previewing the table in a terminal.
+	ASCIIBox
+	// HTML is an HTML table.
+	HTML
+)
+
+// ParseFormat returns the Format for the value: gfm, html, box, or ascii.
+// An empty value is gfm.
+func ParseFormat(s string) (Format, error) {
+	switch strings.TrimSpace(strings.ToLower(s)) {
+	case "", "gfm", "markdown", "md":
+		return GFM, nil
+	case "html":
+		return HTML, nil
+	case "box":
+		return Box, nil
+	case "ascii":
+		return ASCIIBox, nil
+	}
+	return GFM, fmt.Errorf("unknown output format %q", s)
+}
+
+// DefaultAutoAlignSample is the default number of records sampled to infer
+// field alignment.
+const DefaultAutoAlignSample = 100
+
 // ShortWriteError occurs when the number of bytes written is less than
 // the number of bytes to be written.
 type ShortWriteError struct {
@@ -41,34 +82,394 @@ func (e ShortWriteError) Error() string {
 	return fmt.Sprintf("%s: short write of, wrote %d of %d bytes", e.operation, e.n, e.written)
 }
 
+// UnknownColumnError occurs when a column is referenced by a name that is
+// not one of the table's field names.
+type UnknownColumnError struct {
+	Name      string
+	operation string
+}
+
+func (e UnknownColumnError) Error() string {
+	return fmt.Sprintf("%s: unknown column %q", e.operation, e.Name)
+}
+
+// FieldCountError occurs, in strict mode, when the number of fields in a
+// record does not match the number of entries in a field setting, e.g.
+// the field styles.  The Row is the record's position in the CSV-encoded
+// data, including the header record.
+type FieldCountError struct {
+	Row     int
+	Fields  int
+	Entries int
+	Setting string
+}
+
+func (e FieldCountError) Error() string {
+	return fmt.Sprintf("row %d: record has %d fields, field %s has %d entries", e.Row, e.Fields, e.Setting, e.Entries)
+}
+
+// RowError occurs when a record cannot be read or written and the cause
+// does not identify the record; e.g. an error returned by the underlying
+// reader, a Renderer, or the writer.  The Row is the record's position in
+// the CSV-encoded data, including the header record.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+// Unwrap returns the error that occurred.
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// rowError returns err as a RowError of the row, unless err already
+// identifies the record it occurred in.
+func rowError(row int, err error) error {
+	switch err.(type) {
+	case RowError, FieldCountError, FieldTooLargeError, ControlCharError, ComputedColumnError, UnmappedValueError, TemplateError, RaggedRecordError, InputError, *csv.ParseError:
+		return err
+	}
+	return RowError{Row: row, Err: err}
+}
+
+// DefaultFieldNamePattern is the FieldNamePattern used when none is set.
+const DefaultFieldNamePattern = "Column {n}"
+
 // ErrNoFormatData occurs when no data is found in the provided reader.
 var ErrNoFormatData = errors.New("no format data")
 
 // Transmogrifier turns CSV data into a markdown table
 type Transmogrifier struct {
 	// HasHeaderRecord specifies whether or not the CSV-encoded data's
-	// first record has field names.  If false, either the field names
-	// must be set; either by calling SetFieldNames or SetFmt.  In either
-	// case, the number of fields must match the number of fields per
-	// record in the CSV data.
+	// first record has field names.  If false, the field names should be
+	// set; either by calling SetFieldNames or SetFmt.  In either case, the
+	// number of fields must match the number of fields per record in the
+	// CSV data.  If they aren't set, the field names are generated; see
+	// FieldNamePattern.
 	HasHeaderRecord bool
+	// FieldNamePattern is the pattern of the field names that are
+	// generated when the CSV-encoded data has no header record and no field
+	// names have been set; {n} is replaced by the field's number, from 1.
+	// The number of fields is the number of fields in the first record.  If
+	// it is empty, the DefaultFieldNamePattern is used.  Names are only
+	// generated for GFM tables, which must have a header; the other
+	// formats are written without one.
+	FieldNamePattern string
 	// CSV is a csv.Reader.  This is exported so that the caller can
-	// can configure the CSV reader.
-	CSV            *csv.Reader
+	// can configure the CSV reader.  If the Transmogrifier was created
+	// using NewTransmogrifierCSV, this is the provided csv.Reader.
+	CSV *csv.Reader
+	// AutoAlignSample is the number of records that are sampled to infer
+	// the alignment of the fields whose alignment is auto.  If it is 0,
+	// all of the records are used; this requires all of the CSV-encoded
+	// data to be read into memory.
+	AutoAlignSample int
+	// AutoAlign specifies whether the alignment of every field is inferred
+	// from the data when no field alignment has been set; this is the same
+	// as setting each field's alignment to auto.
+	AutoAlign bool
+	// Strict specifies whether inconsistencies between the CSV-encoded data
+	// and the Transmogrifier's configuration are errors.  When false, they
+	// are handled as gracefully as possible; e.g. fields without a style
+	// entry are not styled.
+	Strict bool
+	// SanitizeControl specifies how control characters in the fields,
+	// including the header fields, are handled.  The default is
+	// PassThrough.
+	SanitizeControl Sanitize
+	// CellHTML specifies how the HTML in the fields, e.g. <script>, is
+	// handled in both Markdown and HTML tables.  The default is
+	// DefaultHTML.  The results of the cell templates, see
+	// SetColumnTemplate, are Markdown and are not handled.
+	CellHTML HTMLHandling
+	// Ragged specifies how records that have fewer or more fields than the
+	// header are handled.  The default is RaggedError.
+	Ragged RaggedMode
+	// MaxFieldBytes is the maximum size, in bytes, of a field in the
+	// CSV-encoded data.  If a field exceeds this size, reading stops and
+	// a FieldTooLargeError is returned.  If it is 0, field sizes are not
+	// limited.
+	MaxFieldBytes int
+	// Encoding is the character encoding of the CSV-encoded data; it is
+	// decoded to UTF-8 before it is parsed.  A byte order mark at the start
+	// of the data is removed.  The default is UTF8, which also detects
+	// UTF-16 data that starts with a byte order mark.
+	Encoding Encoding
+	// NormalizeLineEndings specifies whether lone carriage returns, \r,
+	// outside of quoted fields are treated as line endings.  This allows
+	// data with old Mac style line endings, or a mix of line endings, to
+	// be read.  This is true by default.
+	NormalizeLineEndings bool
+	// ReuseRecord sets the CSV reader's ReuseRecord, so that each record
+	// is read into the same slice instead of a new slice being allocated
+	// for each record.  This minimizes the allocations made when a table is
+	// streamed; records that have to be kept, e.g. when the records are
+	// sorted, are copied.  The record passed to a style rule's predicate,
+	// see SetCellStyleRule, is only valid until the predicate returns:
+	// a predicate must not retain it.
+	ReuseRecord bool
+	// SkipBlankRecords specifies whether records whose fields are all
+	// empty, or only white space, e.g. ",,", are skipped instead of being
+	// written as empty rows.  A skipped record does not have to have the
+	// same number of fields as the other records.  The CSV reader already
+	// skips empty lines.
+	SkipBlankRecords bool
+	// TrimSpace specifies whether the leading and trailing white space of
+	// the fields, including the header record's, is removed as they are
+	// read, before anything else is done with them; e.g. the padding of a
+	// spreadsheet export that would otherwise shift the alignment or break
+	// the fields' styling.  Unlike the CSV reader's TrimLeadingSpace, the
+	// trailing white space, and the white space inside quotes, is removed.
+	TrimSpace bool
+	// CollapseSpace specifies whether each run of white space in the
+	// fields, including new lines, is replaced by a single space as they
+	// are read.  It can be combined with TrimSpace.
+	CollapseSpace bool
+	// EllipsisRows specifies whether a row of ellipses is written in place
+	// of the records that are omitted before and after the row range; see
+	// SetRowRange and SetTail.  They are not written when the table is
+	// transposed, pivoted, aggregated, split, or chunked.
+	EllipsisRows bool
+	// MergePlaceholder is the value of the fields that an input does not
+	// have when inputs are merged; see NewTransmogrifierMerge.
+	MergePlaceholder string
+	// Transpose specifies whether the table's rows and columns are swapped:
+	// the field names become the first column and each record becomes a
+	// column.  This is useful for tables with a single record or with many
+	// fields.  Computed columns, filtering, pivoting, and group aggregation
+	// are applied before the table is transposed; all other settings apply
+	// to the transposed table, e.g. the first field's alignment is the
+	// alignment of the column of field names.  Transposing requires all of
+	// the CSV-encoded data to be read into memory.
+	Transpose bool
+	// RowNumbers specifies whether the table starts with a RowNumberColumn
+	// of the rows' numbers, starting from 1, in the order that the rows
+	// are written: after filtering, sorting, pivoting, aggregation, and
+	// transposition.  Like a computed column, the column is included in
+	// the positional settings, e.g. the first field's alignment is the
+	// row numbers' alignment.  Numbering the rows requires all of the
+	// CSV-encoded data to be read into memory.
+	RowNumbers bool
+	// RowHeader specifies whether the table's first column is the row
+	// header, e.g. the features of a table of features by product: in
+	// Markdown, its cells are bold and in HTML tables, they are th
+	// elements with a row scope.  Like the other positional settings, the
+	// first column is the RowNumberColumn when the rows are numbered; the
+	// hidden columns are skipped.
+	RowHeader bool
+	// Pretty specifies whether the cells are padded so that the table's
+	// pipes line up in the generated Markdown, making it easier to read
+	// and edit by hand.  This requires all of the records to be held in
+	// memory until the table is written.
+	Pretty bool
+	// OuterPipes specifies whether the rows are delimited by leading and
+	// trailing pipes and the cells are surrounded by a space, | a | b |,
+	// instead of a|b.  Some Markdown linters and renderers require this
+	// style.
+	OuterPipes bool
+	// EscapeMarkdown specifies whether the characters in the header and
+	// record values that would break the table, |, or be interpreted as
+	// inline markup, * _ ` ~, are backslash escaped so that the values are
+	// shown as is; backslashes that would escape the following character
+	// are also escaped.  The field styles' markup is not escaped.  This is
+	// true by default.
+	EscapeMarkdown bool
+	// LineBreak is what the line breaks in the values are replaced with
+	// when EscapeMarkdown is true, as a GFM table's row can't span
+	// multiple lines, e.g. a space.  If it is empty, DefaultLineBreak is
+	// used.
+	LineBreak string
+	// OutputFormat is the format that the table is written in.  The
+	// default is GFM.
+	OutputFormat Format
+	// PreviewWidth is the maximum width, in columns, of a Box or
+	// ASCIIBox table.  If the table is wider, the widest columns are
+	// shrunk and their values truncated.  If it is 0, the width is not
+	// limited.
+	PreviewWidth int
+	// Metadata is the format of the metadata block, describing the table,
+	// that is written before the table.  The default is NoMetadata.
+	// Writing the metadata requires all of the CSV-encoded data to be read
+	// into memory.
+	Metadata MetadataFormat
+	// SourceHeading is the template for a heading that is written before
+	// the table; if it is empty, no heading is written.  See
+	// ExpandSourceHeading for the supported substitutions.
+	SourceHeading string
+	// Source describes where the CSV-encoded data came from.  It is used
+	// when expanding the SourceHeading.
+	Source Source
+	// FooterLabel is written in the footer row's first cell, unless the
+	// first column has an aggregate.  See SetColumnAggregate.
+	FooterLabel string
+	// MaxCellWidth is the maximum width of a cell's value, in runes; longer
+	// values are truncated and end with an ellipsis.  If it is 0, values
+	// are not truncated.  See SetColumnMaxWidth.
+	MaxCellWidth int
+	// TruncateFootnotes writes the full values of the truncated cells as
+	// footnotes after the table; each truncated value refers to its
+	// footnote.  Footnotes are only written for GFM output; HTML output
+	// always has the full values in the cells' title attributes.
+	TruncateFootnotes bool
+	// FootnotePrefix prefixes the footnote labels, which are numbered from
+	// 1, so that the labels of multiple tables in the same document are
+	// unique.
+	FootnotePrefix string
+	// ChunkSize is the maximum number of records in a table.  If there are
+	// more records, they are written as multiple tables, separated by a
+	// blank line, each with the header record.  If it is 0, the records
+	// are not chunked.  When the table is split, see SplitBy, each of the
+	// tables is chunked.  A footer row is only written after the last
+	// chunk.
+	ChunkSize int
+	// ChunkCaption is the template for the caption that is written after
+	// each chunk of records, separated from the chunk by a blank line; if
+	// it is empty, no caption is written.  The {first} and {last}
+	// substitutions are replaced by the numbers of the chunk's first and
+	// last records, e.g. "Rows {first}-{last}".
+	ChunkCaption string
+	// CaptionHeading is the level, 1 to 6, of the heading that the caption
+	// is written as in Markdown; if it is 0, the caption is written as a
+	// bold paragraph.  See SetCaption.
+	CaptionHeading int
 	w              io.Writer
 	fieldNames     []string
+	fieldNameMap   map[string]string
 	fieldAlignment []string
 	fieldStyle     []string
+	headerStyle    []string
+	headerAlign    []string
+	fieldWidths    []int
+	fieldTypes     map[int]columnType
+	fieldHidden    []bool
+	columnWidths   map[string]int
+	columnFormats  []columnFormat
+	widths         []int
 	newLine        string
+	lineEnd        string
+	noSpaces       bool
 	rBytes         int64
 	wBytes         int64
+	nRows          int64
+	nSkipped       int64 // the blank records that were skipped
+	nExcluded      int64 // the records that weren't included in the table
+	elapsed        time.Duration
+	progress       func(rows, readBytes, writtenBytes int64)
+	progressN      int64
+	hidden         map[int]bool
+	excluded       []string
+	group          *group
+	collapse       *collapse
+	valueMaps      []*valueMap
+	replacements   []*replacement
+	redactions     []*redaction
+	styleRules     []*styleRule
+	negatives      []*negative
+	rowStyle       *rowStyle
+	rowNumber      int // the number of the last numbered row; see RowNumbers
+	rowHeaderCol   int // the index of the row header's column; see RowHeader
+	templates      []*cellTemplate
+	tmplHeader     []string // the header the templates' fields are named by
+	columnMaxWidth map[string]int
+	maxWidths      map[int]int
+	fieldMaxWidths map[int]int // the max widths of the format spec's fields, by position
+	columnTruncate map[string]Truncation
+	fieldTruncate  map[int]Truncation
+	truncations    map[int]Truncation
+	truncated      map[int]string // the full values of the current record's truncated fields
+	raw            []string       // the raw fields of the record being rendered
+	notes          []string       // the footnotes of the truncated values
+	caption        string
+	captioned      bool // whether the caption has been written
+	htmlClasses    HTMLClasses
+	columnClasses  map[int]string
+	htmlTables     int // the number of HTML tables that have been started
+	customRenderer Renderer
+	null           string // the placeholder for SQL NULLs
+	computed       []*computedColumn
+	computedFrom   []string // the header the computed columns are computed from
+	nComputed      int      // the number of buffered records that have been computed
+	filter         *recordFilter
+	dedup          *dedup
+	rows           *rowRange
+	sampling       *sample
+	aggregates     map[string]string
+	footer         []*aggregate
+	pivot          *pivot
+	groupAggs      *groupAggregation
+	valueFormats   []*valueFormat
+	now            func() time.Time // the relative times' now; nil is time.Now
+	locale         locale
+	tables         *tables
+	nFiltered      int // the number of buffered records that have been filtered
+	nFields        int // the number of fields in the header; see Ragged
+	checkRagged    bool
+	merge          *merger
+	in             *input // the reader of the CSV reader, if it was created by NewTransmogrifier
+	malformed      func(MalformedRow)
+	nMalformed     int64
+	header         []string
+	warnings       []string
+	logger         *slog.Logger
+	row            int
+	nRead          int // the number of records read from the CSV reader
+	ctx            context.Context
+	rowBuf         rowBuffer
+	buffered       bool
+	records        [][]string
 }
 
 // NewTransmogrifier returns an initialized Transmogrifier for
 // transmogrifierication of CSV-encoded data to GitHub Flavored Markdown
 // tables.
 func NewTransmogrifier(r io.Reader, w io.Writer) *Transmogrifier {
-	return &Transmogrifier{HasHeaderRecord: true, CSV: csv.NewReader(r), w: w, newLine: "  \n"}
+	t := &Transmogrifier{HasHeaderRecord: true, NormalizeLineEndings: true, EscapeMarkdown: true, AutoAlignSample: DefaultAutoAlignSample, w: w, newLine: "  \n", lineEnd: "\n"}
+	t.in = newInput(r, t)
+	t.CSV = csv.NewReader(t.in)
+	return t
+}
+
+// NewTransmogrifierCSV returns an initialized Transmogrifier that uses the
+// provided csv.Reader, with its existing configuration, as its CSV reader.
+// This is for when the io.Reader that the csv.Reader reads from is not
+// available.
+//
+// Since the Transmogrifier does not have access to the underlying
+// io.Reader, MaxFieldBytes is not enforced, line endings are not
+// normalized, and the data is not decoded; the csv.Reader is responsible
+// for these.
+func NewTransmogrifierCSV(c *csv.Reader, w io.Writer) *Transmogrifier {
+	return &Transmogrifier{HasHeaderRecord: true, EscapeMarkdown: true, CSV: c, AutoAlignSample: DefaultAutoAlignSample, w: w, newLine: "  \n", lineEnd: "\n"}
+}
+
+// Warnings returns the warnings about inconsistencies that were handled
+// while the table was being created, e.g. a field alignment with fewer
+// entries than there are fields.
+func (t *Transmogrifier) Warnings() []string {
+	return t.warnings
+}
+
+// warnf adds a warning and logs it.
+func (t *Transmogrifier) warnf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	t.warnings = append(t.warnings, msg)
+	t.log(slog.LevelWarn, msg)
+}
+
+// BytesRead returns the number of bytes read from the reader.  The reader
+// is read ahead of the records being converted, so the count can include
+// data that has not been converted yet.  If the data is not read using the
+// Transmogrifier's reader, e.g. it was created using NewTransmogrifierCSV,
+// it is the CSV reader's input offset.
+func (t *Transmogrifier) BytesRead() int64 {
+	if t.rBytes == 0 && t.CSV != nil {
+		return t.CSV.InputOffset()
+	}
+	return t.rBytes
 }
 
 // BytesWritten returns the number of bytes written to the writer.
@@ -76,34 +477,67 @@ func (t *Transmogrifier) BytesWritten() int64 {
 	return t.wBytes
 }
 
-// SetNewLine sets the newLine value based on the received value.  If the
-// received value is not recognized, nothing is done.
+// SetNewLine sets the new line sequence that ends each line to the
+// received value.  If the received value is empty, nothing is done.
 //
-// Valid new line values:
-//    * Carriage Return (new line)
+// Named new line values:
+//    * Carriage Return
 //      * cr
 //      * CR
-//      * \n
 //    * Line Feed
 //      * lf
 //      * LF
-//      * \r
 //    * Carriage Return/Line Feed
 //      * crlf
 //      * CRLF
-//      * \r\n
+//
+// Any other value is used as the new line sequence as is, except that its
+// escape sequences, e.g. a \r\n typed on the command line, are
+// interpreted.
 //
 // For a new line to occur, Markdown requires the line to terminate with
-// either two spaces, "  ", or have a double line feed.  The newLine is
-// prefixed with two spaces.
+// either two spaces, "  ", or have a double line feed.  The lines of the
+// table are terminated with two spaces followed by the new line sequence,
+// unless the trailing spaces are turned off using SetTrailingSpaces.
 func (t *Transmogrifier) SetNewLine(s string) {
 	switch s {
-	case "cr", "CR", "\n":
-		t.newLine = "  \n"
-	case "lf", "LF", "\r":
-		t.newLine = "  \r"
-	case "crlf", "CRLF", "\r\n":
-		t.newLine = "   \r"
+	case "":
+		return
+	case "cr", "CR":
+		s = "\r"
+	case "lf", "LF":
+		s = "\n"
+	case "crlf", "CRLF":
+		s = "\r\n"
+	default:
+		if strings.Contains(s, `\`) {
+			if v, err := strconv.Unquote(`"` + s + `"`); err == nil && v != "" {
+				s = v
+			}
+		}
+	}
+	t.lineEnd = s
+	t.setNewLine()
+}
+
+// SetTrailingSpaces sets whether the lines of the table end with two
+// spaces, a Markdown hard line break, before the new line sequence; they
+// do by default.  A table's rows end at the new line whether or not they
+// have the trailing spaces, so they can be turned off for tables that are
+// only rendered as tables.
+func (t *Transmogrifier) SetTrailingSpaces(b bool) {
+	t.noSpaces = !b
+	t.setNewLine()
+}
+
+// setNewLine sets the sequence that ends the lines of the table.
+func (t *Transmogrifier) setNewLine() {
+	if t.lineEnd == "" {
+		t.lineEnd = "\n"
+	}
+	t.newLine = t.lineEnd
+	if !t.noSpaces {
+		t.newLine = "  " + t.lineEnd
 	}
 }
 
@@ -136,25 +570,40 @@ func (t *Transmogrifier) SetFieldNames(vals []string) {
 //     * r
 //     * right
 //     * --:
+//   * Inferred from the data: numeric columns are right justified,
+//     boolean columns are centered, and other columns are left justified.
+//     If the column has no values, it has no justification.  See
+//     AutoAlignSample.
+//     * auto
 //   * No justification
 //     * empty string
 func (t *Transmogrifier) SetFieldAlignment(vals []string) {
 	for _, v := range vals {
-		v = strings.TrimSpace(strings.ToLower(v))
-		switch v {
-		case "l", "left", left:
-			t.fieldAlignment = append(t.fieldAlignment, left)
-		case "c", "center", "centered", centered:
-			t.fieldAlignment = append(t.fieldAlignment, centered)
-		case "r", "right", right:
-			t.fieldAlignment = append(t.fieldAlignment, right)
-		default:
-			t.fieldAlignment = append(t.fieldAlignment, none)
-		}
+		a, _ := parseAlignment(v)
+		t.fieldAlignment = append(t.fieldAlignment, a)
 	}
 	return
 }
 
+// parseAlignment returns the alignment for the alignment value; see
+// SetFieldAlignment for the accepted values.  An unrecognized value
+// results in no justification and false.
+func parseAlignment(v string) (string, bool) {
+	switch strings.TrimSpace(strings.ToLower(v)) {
+	case "l", "left", left:
+		return left, true
+	case "c", "center", "centered", centered:
+		return centered, true
+	case "r", "right", right:
+		return right, true
+	case auto:
+		return auto, true
+	case "":
+		return none, true
+	}
+	return none, false
+}
+
 // SetFieldStyle sets the text styling for a record's field.
 // Accepted values:
 //    * Bold
@@ -168,26 +617,32 @@ func (t *Transmogrifier) SetFieldAlignment(vals []string) {
 //      * _
 //    * Strikethrough
 //      * s
+//      * strike
 //      * strikethrough
 //      * ~~
 //    * No text styling
 //      * empty string
 func (t *Transmogrifier) SetFieldStyle(vals []string) {
 	for _, v := range vals {
-		v = strings.TrimSpace(strings.ToLower(v))
-		switch v {
-		case "b", "bold", bold:
-			t.fieldStyle = append(t.fieldStyle, bold)
-		case "i", "italic", "italics", italic:
-			t.fieldStyle = append(t.fieldStyle, italic)
-		case "s", "strikethrough", strikethrough:
-			t.fieldStyle = append(t.fieldStyle, strikethrough)
-		default:
-			t.fieldStyle = append(t.fieldStyle, "")
-		}
+		t.fieldStyle = append(t.fieldStyle, parseStyle(v))
 	}
 }
 
+// parseStyle returns the text styling markup for the style value; see
+// SetFieldStyle for the accepted values.  An unrecognized value results in
+// no styling.
+func parseStyle(v string) string {
+	switch strings.TrimSpace(strings.ToLower(v)) {
+	case "b", "bold", bold:
+		return bold
+	case "i", "italic", "italics", italic:
+		return italic
+	case "s", "strike", "strikethrough", strikethrough:
+		return strikethrough
+	}
+	return ""
+}
+
 // SetFmt takes a reader and reads the format information from it as CSV
 // encoded data.  The CSV reader used to read the format information is
 // configured to be consistent with CSV's configuration under the assumption
@@ -221,159 +676,858 @@ func (t *Transmogrifier) SetFmt(r io.Reader) error {
 	if len(records) > 2 {
 		t.SetFieldStyle(records[2])
 	}
+	// fourth row is the minimum width of each field, if it exists
+	if len(records) > 3 {
+		widths, err := ParseFieldWidths(records[3])
+		if err != nil {
+			return err
+		}
+		t.SetFieldWidths(widths)
+	}
+	// fifth row is the cell template of each field, if it exists
+	if len(records) > 4 {
+		return t.SetFieldTemplates(records[4])
+	}
 	return nil
 }
 
+// MDTableContext is MDTable with a context.  The context is checked before
+// each record is read, including when the records are read into memory;
+// if it is done, the table is not finished and the context's error is
+// returned.  Whatever has already been written is left as is.
+func (t *Transmogrifier) MDTableContext(ctx context.Context) error {
+	t.ctx = ctx
+	defer func() { t.ctx = nil }()
+	return t.MDTable()
+}
+
 // MDTable reads from the configured reader, CSV, transforms the data into
 // a GitHub Flavored Markdown table, applying justification and text
 // styling, and writes the resulting bytes to the Transmogrifier's writer.
+// If a SourceHeading is set, it is written before the table.
 func (t *Transmogrifier) MDTable() error {
-	// if the field names are set, write those first
-	if len(t.fieldNames) > 0 {
-		err := t.writeHeaderRecord(t.fieldNames)
+	start := time.Now()
+	defer func() { t.elapsed = time.Since(start) }()
+	if t.ReuseRecord {
+		t.CSV.ReuseRecord = true
+	}
+	t.prepareRagged()
+	// the row count is only known after the data has been read; sorting
+	// the groups, and ordering the split tables, also requires all of the
+	// data
+	if strings.Contains(t.SourceHeading, "{rows}") || (t.group != nil && (t.group.sort || t.group.split)) || t.Metadata != NoMetadata {
+		err := t.buffer()
 		if err != nil {
 			return err
 		}
 	}
-	// read until EOF
-	var row int
-	for {
-		row++
-		record, err := t.CSV.Read()
-		if err == io.EOF {
-			break
+	header, err := t.readHeader()
+	if err != nil {
+		return err
+	}
+	t.header = header
+	if len(t.computed) > 0 {
+		header, err = t.prepareComputed(header)
+		if err != nil {
+			return err
+		}
+	}
+	err = t.prepareFilter()
+	if err != nil {
+		return err
+	}
+	if t.sampling != nil && t.sampling.n > 0 {
+		err = t.readSample()
+		if err != nil {
+			return err
 		}
+	}
+	if t.rows != nil && t.rows.tail > 0 {
+		err = t.readTail()
 		if err != nil {
 			return err
 		}
-		if row == 1 && t.HasHeaderRecord {
-			if len(t.fieldNames) > 0 {
-				continue
-			}
-			err = t.writeHeaderRecord(record)
+	}
+	if t.pivot != nil && t.groupAggs != nil {
+		return errors.New("pivoting and group aggregation are mutually exclusive")
+	}
+	if t.pivot != nil {
+		header, err = t.pivotTable(header)
+		if err != nil {
+			return err
+		}
+	}
+	if t.groupAggs != nil {
+		header, err = t.aggregateGroups(header)
+		if err != nil {
+			return err
+		}
+	}
+	if t.Transpose {
+		// the renamed field names become the first column
+		header, err = t.renameFields(header)
+		if err != nil {
+			return err
+		}
+		header, err = t.transpose(header)
+		if err != nil {
+			return err
+		}
+		if header == nil && !t.HasHeaderRecord && t.isGFM() {
+			header, err = t.generateHeader()
 			if err != nil {
 				return err
 			}
-			continue
+			t.header = header
 		}
-		err = t.writeRecord(record)
+	}
+	if t.RowNumbers {
+		header, err = t.numberRows(header)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
-}
-
-func (t *Transmogrifier) writeHeaderRecord(fields []string) error {
-	var err error
-	var n int
-	end := len(fields) - 1
-	for i, field := range fields {
-		if i < end {
-			field = fmt.Sprintf("%s|", field)
-		}
-		n, err = t.w.Write([]byte(field))
+	if t.Metadata != NoMetadata {
+		err = t.writeMetadata(header)
 		if err != nil {
 			return err
 		}
-		if n != len(field) {
-			return ShortWriteError{n: len(field), written: n, operation: "header field"}
+	}
+	if t.SourceHeading != "" {
+		err = t.writeSourceHeading()
+		if err != nil {
+			return err
 		}
-		t.wBytes += int64(n)
 	}
-	n, err = t.w.Write([]byte(t.newLine))
+	err = t.prepare(header)
 	if err != nil {
 		return err
 	}
-	if n != len(t.newLine) {
-		return ShortWriteError{n: len(t.newLine), written: n, operation: "new line"}
+	err = t.writeCaption()
+	if err != nil {
+		return err
 	}
-	t.wBytes += int64(n)
-	// write the header record separator
-	if len(t.fieldAlignment) == 0 {
-		// no field alignment was set, just write out the separator row
-		for i := 0; i < len(fields); i++ {
-			val := none
-			if i < end {
-				val = fmt.Sprintf("%s|", val)
-			}
-			n, err = t.w.Write([]byte(val))
+	r := t.renderer()
+	if header != nil {
+		names := header
+		if !t.Transpose {
+			names, err = t.renameFields(header)
 			if err != nil {
 				return err
 			}
-			if n != len(val) {
-				return ShortWriteError{n: len(val), written: n, operation: "header row separator"}
-			}
-			t.wBytes += int64(n)
 		}
-		n, err = t.w.Write([]byte(t.newLine))
+		err = t.writeHeader(r, names)
 		if err != nil {
 			return err
 		}
-		if n != len(t.newLine) {
-			return ShortWriteError{n: len(t.newLine), written: n, operation: "new line"}
-		}
-		t.wBytes += int64(n)
-		return nil
 	}
-	end = len(t.fieldAlignment) - 1
-	for i, field := range t.fieldAlignment {
-		if i < end {
-			field = fmt.Sprintf("%s|", field)
+	// read until EOF
+	for {
+		record, err := t.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
 		}
-		n, err = t.w.Write([]byte(field))
+		err = t.writeEllipsis(r, false)
 		if err != nil {
 			return err
 		}
-		if n != len(field) {
-			return ShortWriteError{n: len(field), written: n, operation: "header row separator"}
+		err = t.writeRow(r, record)
+		if err != nil {
+			return err
 		}
-		t.wBytes += int64(n)
 	}
-	n, err = t.w.Write([]byte(t.newLine))
+	err = t.writeEllipsis(r, true)
+	if err != nil {
+		return err
+	}
+	err = t.writeFooter(r)
 	if err != nil {
 		return err
 	}
-	if n != len(t.newLine) {
-		return ShortWriteError{n: len(t.newLine), written: n, operation: "new line"}
+	err = r.Close()
+	if err != nil {
+		return err
 	}
-	t.wBytes += int64(n)
-	return err
+	err = t.writeFootnotes()
+	if err != nil {
+		return err
+	}
+	if t.SourceHeading != "" {
+		// separate the table from whatever follows it
+		err = t.write(t.lineEnd, "new line")
+		if err != nil {
+			return err
+		}
+	}
+	t.reportProgress()
+	t.log(slog.LevelInfo, "table written", "rows", t.nRows, "bytes_read", t.BytesRead(), "bytes_written", t.wBytes, "elapsed", time.Since(start))
+	return nil
 }
 
-func (t *Transmogrifier) writeRecord(fields []string) error {
-	var err error
-	var n int
-	format := len(t.fieldStyle) > 0
-	end := len(fields) - 1
-	for i, field := range fields {
-		// if the field is empty, add a space to indicate to MD that there is a value
-		// otherwise columns may not end up in the correct spot.
-		if field == "" {
-			field = " "
+// prepare resolves the settings that refer to columns against the header
+// and resolves the auto alignment.
+func (t *Transmogrifier) prepare(header []string) error {
+	err := t.prepareColumnFormats(header)
+	if err != nil {
+		return err
+	}
+	t.hidden = nil
+	for i, hide := range t.fieldHidden {
+		if !hide {
+			continue
 		}
-		if format {
-			field = fmt.Sprintf("%s%s%s", t.fieldStyle[i], field, t.fieldStyle[i])
+		if t.hidden == nil {
+			t.hidden = make(map[int]bool)
 		}
-		if i < end {
-			field = fmt.Sprintf("%s|", field)
+		t.hidden[i] = true
+	}
+	err = t.prepareExcluded(header)
+	if err != nil {
+		return err
+	}
+	err = t.prepareRedactions(header)
+	if err != nil {
+		return err
+	}
+	if t.group != nil {
+		err := t.prepareGroup(header)
+		if err != nil {
+			return err
 		}
-		n, err = t.w.Write([]byte(field))
+	}
+	err = t.prepareValueMaps(header)
+	if err != nil {
+		return err
+	}
+	err = t.prepareReplacements(header)
+	if err != nil {
+		return err
+	}
+	err = t.prepareValueFormats(header)
+	if err != nil {
+		return err
+	}
+	err = t.prepareStyleRules(header)
+	if err != nil {
+		return err
+	}
+	err = t.prepareNegatives(header)
+	if err != nil {
+		return err
+	}
+	err = t.prepareRowStyle(header)
+	if err != nil {
+		return err
+	}
+	err = t.prepareHTMLClasses(header)
+	if err != nil {
+		return err
+	}
+	t.prepareRowHeader()
+	err = t.prepareTemplates(header)
+	if err != nil {
+		return err
+	}
+	err = t.prepareMaxWidths(header)
+	if err != nil {
+		return err
+	}
+	err = t.prepareWidths(header)
+	if err != nil {
+		return err
+	}
+	err = t.prepareFooter(header)
+	if err != nil {
+		return err
+	}
+	err = t.resolveAutoAlignment()
+	if err != nil {
+		return err
+	}
+	if t.collapse != nil {
+		return t.prepareCollapse(header)
+	}
+	return nil
+}
+
+// writeRow writes the record, preceded by a group subheader if the record
+// starts a new group, using the Renderer.  If the record starts a new split
+// table or chunk, the current table is ended first.
+func (t *Transmogrifier) writeRow(r Renderer, record []string) error {
+	err := t.renderRow(r, record)
+	if err != nil {
+		return rowError(t.row, err)
+	}
+	t.progressed()
+	return nil
+}
+
+// renderRow does the work of writeRow.
+func (t *Transmogrifier) renderRow(r Renderer, record []string) error {
+	if t.RowNumbers {
+		t.numberRow(record)
+	}
+	if len(t.redactions) > 0 {
+		t.redact(record)
+	}
+	if t.tables != nil {
+		err := t.tables.startRow(record)
 		if err != nil {
 			return err
 		}
-		if n != len(field) {
-			return ShortWriteError{n: len(field), written: n, operation: "record field"}
+	}
+	if t.group != nil && !t.group.split {
+		err := t.writeGroupRow(r, record)
+		if err != nil {
+			return err
+		}
+	}
+	if t.footer != nil {
+		t.accumulate(record)
+	}
+	// style rules are evaluated against the raw record
+	raw := record
+	if t.styled() {
+		raw = append([]string(nil), record...)
+	}
+	err := t.mapValues(record)
+	if err != nil {
+		return err
+	}
+	if len(t.replacements) > 0 {
+		t.replaceValues(record)
+	}
+	if len(t.valueFormats) > 0 {
+		t.formatValues(record)
+	}
+	if len(t.negatives) > 0 {
+		t.parenthesize(record, raw)
+	}
+	if len(t.templates) > 0 {
+		err = t.applyTemplates(record)
+		if err != nil {
+			return err
+		}
+	}
+	if t.MaxCellWidth > 0 || t.maxWidths != nil {
+		t.truncate(record)
+	}
+	if t.collapse != nil {
+		record = t.collapse.apply(record)
+	}
+	return t.writeRecord(r, record, raw)
+}
+
+// renderer returns the Renderer for the table.  If the table is split or
+// chunked, each of the tables is rendered by its own formatRenderer.
+func (t *Transmogrifier) renderer() Renderer {
+	t.tables = nil
+	if (t.group != nil && t.group.split) || t.ChunkSize > 0 {
+		t.tables = &tables{t: t}
+		return t.tables
+	}
+	return t.formatRenderer()
+}
+
+// isGFM returns whether the table is written as a GFM table.
+func (t *Transmogrifier) isGFM() bool {
+	return t.customRenderer == nil && t.OutputFormat == GFM
+}
+
+// formatRenderer returns the Renderer that has been set or, if one hasn't,
+// the Renderer for the OutputFormat.
+func (t *Transmogrifier) formatRenderer() Renderer {
+	if t.customRenderer != nil {
+		return t.customRenderer
+	}
+	switch t.OutputFormat {
+	case Box, ASCIIBox:
+		return &box{t: t, ascii: t.OutputFormat == ASCIIBox}
+	case HTML:
+		return &htmlTable{t: t}
+	}
+	if t.Pretty {
+		return &pretty{g: gfm{t: t}}
+	}
+	return gfm{t: t}
+}
+
+// gfm renders GitHub Flavored Markdown tables.
+type gfm struct {
+	t *Transmogrifier
+}
+
+func (g gfm) WriteHeader(names []string) error {
+	return g.WriteHeaderCells(nameCells(names))
+}
+
+func (g gfm) WriteHeaderCells(cells []Cell) error {
+	return g.writeCells(cells, g.headerCell, "header record")
+}
+
+// WriteSeparator writes the header record separator; it must have the
+// same number of cells as the header record.
+func (g gfm) WriteSeparator(alignment []Alignment) error {
+	t := g.t
+	r := &t.rowBuf
+	r.reset(t.OuterPipes)
+	i := -1
+	for _, a := range alignment {
+		i = t.nextField(i)
+		r.cell(stretchSeparator(markdownAlignments[a], t.width(i)), 0, none)
+	}
+	return t.writeBytes(r.end(t.newLine), "header row separator")
+}
+
+func (g gfm) WriteGroup(column, value string) error {
+	return g.t.writeGroupRecord(column, value)
+}
+
+func (g gfm) WriteRow(cells []Cell) error {
+	return g.writeCells(cells, g.cell, "record")
+}
+
+func (g gfm) WriteFooter(cells []Cell) error {
+	return g.writeCells(cells, g.footerCell, "record")
+}
+
+func (g gfm) Close() error {
+	return nil
+}
+
+// readHeader returns the field names to use for the table's header
+// record.  If the field names have been set, they are used and the CSV
+// data's header record, if it has one, is skipped.  A nil header means
+// that there are no field names.
+func (t *Transmogrifier) readHeader() ([]string, error) {
+	if !t.HasHeaderRecord {
+		header, err := t.fieldNamesHeader()
+		if err != nil {
+			return nil, err
+		}
+		if header != nil {
+			record, err := t.peek()
+			if err != nil || record == nil {
+				return header, err
+			}
+			return header, t.checkFieldNames(t.row+1, len(record))
 		}
-		t.wBytes += int64(n)
+		if t.Transpose || !t.isGFM() {
+			// the transposed table's names are generated once it has
+			// been transposed
+			return nil, nil
+		}
+		return t.generateHeader()
+	}
+	record, err := t.read()
+	if err == io.EOF {
+		return t.fieldNamesHeader()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(t.fieldNames) > 0 {
+		err = t.checkFieldNames(t.row, len(record))
+		if err != nil {
+			return nil, err
+		}
+		return t.fieldNamesHeader()
+	}
+	return t.keep(record), nil
+}
+
+// checkFieldNames checks that the number of field names matches the
+// number of fields, n, in the row.  A mismatch is an error in strict mode;
+// otherwise it is a warning.
+func (t *Transmogrifier) checkFieldNames(row, n int) error {
+	if len(t.fieldNames) == n {
+		return nil
+	}
+	if t.Strict {
+		return FieldCountError{Row: row, Fields: n, Entries: len(t.fieldNames), Setting: "name"}
+	}
+	t.warnf("field name has %d entries, row %d has %d fields", len(t.fieldNames), row, n)
+	return nil
+}
+
+// fieldNamesHeader returns a copy of the field names, with their control
+// characters handled according to SanitizeControl.  If the field names
+// have not been set, nil is returned.
+func (t *Transmogrifier) fieldNamesHeader() ([]string, error) {
+	if len(t.fieldNames) == 0 {
+		return nil, nil
+	}
+	names := append([]string(nil), t.fieldNames...)
+	err := t.sanitize(names, 0)
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// generateHeader returns field names, generated using the
+// FieldNamePattern, for each of the fields of the next record.  If there
+// are no more records, nil is returned.
+func (t *Transmogrifier) generateHeader() ([]string, error) {
+	record, err := t.peek()
+	if err != nil || record == nil {
+		return nil, err
+	}
+	return t.fieldNamesFor(len(record)), nil
+}
+
+// peek returns the next record without reading it; it is read into the
+// buffer if necessary.  If there are no more records, nil is returned.
+func (t *Transmogrifier) peek() ([]string, error) {
+	if len(t.records) == 0 && !t.buffered {
+		record, err := t.readCSV()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// the record is read again from the buffer
+		t.records = append(t.records, t.keep(record))
 	}
-	n, err = t.w.Write([]byte(t.newLine))
+	if len(t.records) == 0 {
+		return nil, nil
+	}
+	return t.records[0], nil
+}
+
+// fieldNamesFor returns n field names generated using the
+// FieldNamePattern.
+func (t *Transmogrifier) fieldNamesFor(n int) []string {
+	pattern := t.FieldNamePattern
+	if pattern == "" {
+		pattern = DefaultFieldNamePattern
+	}
+	names := make([]string, n)
+	for i := range names {
+		names[i] = strings.Replace(pattern, "{n}", strconv.Itoa(i+1), -1)
+	}
+	return names
+}
+
+// buffer reads all of the CSV-encoded data into memory.  Once the data has
+// been buffered, read returns records from the buffer.
+func (t *Transmogrifier) buffer() error {
+	for {
+		record, err := t.readCSV()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		t.records = append(t.records, t.keep(record))
+	}
+	t.buffered = true
+	err := t.computeBuffered()
 	if err != nil {
 		return err
 	}
-	if n != len(t.newLine) {
-		return ShortWriteError{n: len(t.newLine), written: n, operation: "new line"}
+	t.filterBuffered()
+	return nil
+}
+
+// sample returns up to n of the records that have not been read yet,
+// reading them into the buffer if necessary; read will still return them.
+// If n is 0, all of the remaining records are returned.
+func (t *Transmogrifier) sample(n int) ([][]string, error) {
+	if n <= 0 {
+		err := t.buffer()
+		if err != nil {
+			return nil, err
+		}
+		return t.records, nil
 	}
+	for !t.buffered && len(t.records) < n {
+		record, err := t.readCSV()
+		if err == io.EOF {
+			t.buffered = true
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		t.records = append(t.records, t.keep(record))
+		// filtered records don't count towards the sample
+		err = t.computeBuffered()
+		if err != nil {
+			return nil, err
+		}
+		t.filterBuffered()
+	}
+	err := t.computeBuffered()
+	if err != nil {
+		return nil, err
+	}
+	t.filterBuffered()
+	if len(t.records) < n {
+		return t.records, nil
+	}
+	return t.records[:n], nil
+}
+
+// read returns the next record; either from the buffer, if there are any
+// buffered records, or from CSV.  The row is updated to the number of
+// records that have been read, including the header record.  Once the
+// header has been read, the record includes the computed columns and
+// records that don't match the filter are skipped.  The record's control
+// characters are handled according to SanitizeControl.
+func (t *Transmogrifier) read() ([]string, error) {
+	for {
+		var record []string
+		computed, filtered := false, false
+		if len(t.records) == 0 {
+			// once the row range's limit has been reached, the rest of
+			// the records are not read
+			if t.buffered || (t.rows != nil && t.rows.more) {
+				return nil, io.EOF
+			}
+			var err error
+			record, err = t.readCSV()
+			if err != nil {
+				return record, err
+			}
+		} else {
+			err := t.done()
+			if err != nil {
+				return nil, err
+			}
+			record = t.records[0]
+			t.records = t.records[1:]
+			if t.nComputed > 0 {
+				t.nComputed--
+				computed = true
+			}
+			if t.nFiltered > 0 {
+				t.nFiltered--
+				filtered = true
+			}
+		}
+		t.row++
+		if t.SkipBlankRecords && blank(record) {
+			t.nSkipped++
+			t.log(slog.LevelDebug, "blank row skipped", "row", t.row)
+			continue
+		}
+		var err error
+		if t.computedFrom != nil && !computed {
+			record, err = t.compute(record, t.row)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if t.filtering() && !filtered && !t.include(record, t.row) {
+			continue
+		}
+		err = t.sanitize(record, t.row)
+		if err != nil {
+			return nil, err
+		}
+		return record, nil
+	}
+}
+
+// keep returns a record that can be kept after the next record is read:
+// if the CSV reader reuses its record, a copy of the record is returned.
+func (t *Transmogrifier) keep(record []string) []string {
+	if !t.CSV.ReuseRecord {
+		return record
+	}
+	return append([]string(nil), record...)
+}
+
+// readCSV reads the next record from the CSV reader.  An error that does
+// not identify the record is returned as a RowError.  If the malformed
+// records are skipped, see SkipMalformedRows, they are reported and the
+// next record is read instead.
+func (t *Transmogrifier) readCSV() ([]string, error) {
+	for {
+		var start int64
+		if t.malformed != nil {
+			start = t.CSV.InputOffset()
+		}
+		record, err := t.readCSVRecord()
+		if t.malformed == nil {
+			return record, err
+		}
+		raw := t.rawRecord(start)
+		if err == nil || !t.skippable(err) {
+			return record, err
+		}
+		t.skipMalformed(raw, err)
+	}
+}
+
+// readCSVRecord reads the next record from the CSV reader; see readCSV.
+func (t *Transmogrifier) readCSVRecord() ([]string, error) {
+	err := t.done()
+	if err != nil {
+		return nil, err
+	}
+	var record []string
+	if t.merge != nil {
+		record, err = t.merge.read()
+	} else {
+		record, err = t.CSV.Read()
+	}
+	if err == io.EOF {
+		return nil, err
+	}
+	t.nRead++
+	if err != nil {
+		var perr *csv.ParseError
+		if t.SkipBlankRecords && errors.As(err, &perr) && perr.Err == csv.ErrFieldCount && blank(record) {
+			// read skips the record
+			return record, nil
+		}
+		return record, rowError(t.nRead, err)
+	}
+	if t.TrimSpace || t.CollapseSpace {
+		t.normalizeSpace(record)
+	}
+	if t.checkRagged {
+		return t.fit(record)
+	}
+	return record, nil
+}
+
+// blank returns whether the record's fields are all empty or white space.
+func blank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// done returns the error of the MDTableContext context, if it is done.
+func (t *Transmogrifier) done() error {
+	if t.ctx == nil {
+		return nil
+	}
+	return t.ctx.Err()
+}
+
+// write writes s to the writer and updates the bytes written.  The
+// operation is used to identify what was being written when a short write
+// occurs.
+func (t *Transmogrifier) write(s, operation string) error {
+	return t.writeBytes([]byte(s), operation)
+}
+
+// writeBytes is write for a byte slice.
+func (t *Transmogrifier) writeBytes(b []byte, operation string) error {
+	n, err := t.w.Write(b)
 	t.wBytes += int64(n)
-	return err
+	if err != nil {
+		return err
+	}
+	if n != len(b) {
+		return ShortWriteError{n: len(b), written: n, operation: operation}
+	}
+	return nil
+}
+
+// checkHeader checks the field settings against the header's fields; in
+// strict mode, a setting with a different number of entries is an error.
+func (t *Transmogrifier) checkHeader(fields []string) error {
+	if !t.Strict {
+		return nil
+	}
+	if len(t.fieldAlignment) > 0 && len(t.fieldAlignment) != len(fields) {
+		return FieldCountError{Row: t.row, Fields: len(fields), Entries: len(t.fieldAlignment), Setting: "alignment"}
+	}
+	if len(t.fieldStyle) > 0 && len(t.fieldStyle) != len(fields) {
+		return FieldCountError{Row: t.row, Fields: len(fields), Entries: len(t.fieldStyle), Setting: "style"}
+	}
+	if len(t.fieldWidths) > 0 && len(t.fieldWidths) != len(fields) {
+		return FieldCountError{Row: t.row, Fields: len(fields), Entries: len(t.fieldWidths), Setting: "width"}
+	}
+	return nil
+}
+
+// checkStyles checks, in strict mode, that the record has a field style
+// entry for each of its fields.
+func (t *Transmogrifier) checkStyles(fields []string) error {
+	if t.Strict && len(t.fieldStyle) > 0 && len(fields) != len(t.fieldStyle) {
+		return FieldCountError{Row: t.row, Fields: len(fields), Entries: len(t.fieldStyle), Setting: "style"}
+	}
+	return nil
+}
+
+// writeCells writes a row of the cells, padding them to their fields'
+// widths; value returns the Markdown of the i'th field's cell.  The row
+// is assembled in the row buffer, since this is done for every record.
+func (g gfm) writeCells(cells []Cell, value func(i int, c Cell) string, operation string) error {
+	t := g.t
+	r := &t.rowBuf
+	r.reset(t.OuterPipes)
+	i := -1
+	for _, c := range cells {
+		i = t.nextField(i)
+		r.cell(value(i, c), t.width(i), markdownAlignments[c.Align])
+	}
+	return t.writeBytes(r.end(t.newLine), operation)
+}
+
+// headerCell returns the Markdown of the header's i'th field: the cell's
+// value, escaped and styled.
+func (g gfm) headerCell(i int, c Cell) string {
+	return markup(g.t.escape(c.Value), c.Styles)
+}
+
+// cell returns the Markdown of the record's i'th field: the cell's value,
+// escaped and styled.
+func (g gfm) cell(i int, c Cell) string {
+	t := g.t
+	// if the field is empty, add a space to indicate to MD that there is a value
+	// otherwise columns may not end up in the correct spot.
+	field := c.Value
+	if field == "" {
+		field = " "
+	}
+	// a template's result is Markdown
+	if t.templates == nil || !t.templated(i) {
+		field = t.escape(field)
+	}
+	// the field's style, if it has one, is the first of the cell's styles
+	styles := c.Styles
+	if i < len(t.fieldStyle) && t.fieldStyle[i] != "" && len(styles) > 0 {
+		field = markup(field, styles[:1])
+		styles = styles[1:]
+	}
+	// the row header is bold, unless its field style already is
+	if c.Header && field != " " && (i >= len(t.fieldStyle) || t.fieldStyle[i] != bold) {
+		field = bold + field + bold
+	}
+	return markup(field, styles)
+}
+
+// footerCell returns the Markdown of the footer row's i'th field: the
+// cell's value, escaped and styled.
+func (g gfm) footerCell(i int, c Cell) string {
+	if c.Value == "" {
+		return " "
+	}
+	return markup(g.t.escape(c.Value), c.Styles)
+}
+
+// columnIndex returns the index of the named column in the header; -1 is
+// returned if the header has no such column.
+func columnIndex(header []string, name string) int {
+	for i, v := range header {
+		if v == name {
+			return i
+		}
+	}
+	return -1
 }
@@ -2,7 +2,15 @@ package csv2md
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestSetFieldNames(t *testing.T) {
@@ -75,6 +83,47 @@ func TestSetFieldStyle(t *testing.T) {
 	}
 }
 
+func TestSetNewLine(t *testing.T) {
+	tests := []struct {
+		newLine  string
+		spaces   bool
+		expected string
+	}{
+		{"", true, "  \n"},
+		{"lf", true, "  \n"},
+		{"CR", true, "  \r"},
+		{"crlf", true, "  \r\n"},
+		{"\r\n", true, "  \r\n"},
+		{`\r\n`, true, "  \r\n"},
+		{"<br>\n", true, "  <br>\n"},
+		{`\`, true, `  \`},
+		{"crlf", false, "\r\n"},
+		{"", false, "\n"},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(nil, nil)
+		calvin.SetNewLine(test.newLine)
+		calvin.SetTrailingSpaces(test.spaces)
+		if calvin.NewLine() != test.expected {
+			t.Errorf("%d: got %q want %q", i, calvin.NewLine(), test.expected)
+		}
+	}
+	// every line, including the blank lines, ends with the new line
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader("Make,Model\nFord,Focus\n"), &w)
+	calvin.SetNewLine("crlf")
+	calvin.SetTrailingSpaces(false)
+	calvin.SetCaption("Cars")
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "**Cars**\r\n\r\nMake|Model\r\n---|---\r\nFord|Focus\r\n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
+
 func TestSetFmt(t *testing.T) {
 	var b []byte
 	var w bytes.Buffer
@@ -131,7 +180,7 @@ func TestMDTable(t *testing.T) {
 		{true, false, csvData, "Make|Model|Type|Yr  \n:--:|:--|:--|--:  \n__Manufacturer__|_Model_|Type|~~Year~~  \n__Ford__|_Focus_|Sedan|~~2015~~  \n__Chevy__|_Malibu_|Sedan|~~2015~~  \n"},
 		{true, true, csvData, "Make|Model|Type|Yr  \n:--:|:--|:--|--:  \n__Ford__|_Focus_|Sedan|~~2015~~  \n__Chevy__|_Malibu_|Sedan|~~2015~~  \n"},
 		{false, false, []byte("Manufacturer,Model,Type,Year\n,Focus,Sedan,2015\n,Malibu,Sedan,2015\n"),
-			"Manufacturer|Model|Type|Year  \n |Focus|Sedan|2015  \n |Malibu|Sedan|2015  \n"},
+			"Column 1|Column 2|Column 3|Column 4  \n---|---|---|---  \nManufacturer|Model|Type|Year  \n |Focus|Sedan|2015  \n |Malibu|Sedan|2015  \n"},
 	}
 	for i, test := range tests {
 		var w bytes.Buffer
@@ -156,3 +205,455 @@ func TestMDTable(t *testing.T) {
 		}
 	}
 }
+
+func TestMDTableMultiLineField(t *testing.T) {
+	csvData := []byte("Name,Address\nCalvin,\"1 Main St\nApt 2\"\nHobbes,\"The\r\nTree\"\n")
+	tests := []struct {
+		lineBreak string
+		pretty    bool
+		expected  string
+	}{
+		{"", false, "Name|Address  \n---|---  \nCalvin|1 Main St<br>Apt 2  \nHobbes|The<br>Tree  \n"},
+		{" ", false, "Name|Address  \n---|---  \nCalvin|1 Main St Apt 2  \nHobbes|The Tree  \n"},
+		{"", true, "Name  |Address             \n------|------------------  \nCalvin|1 Main St<br>Apt 2  \nHobbes|The<br>Tree         \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.LineBreak = test.lineBreak
+		calvin.Pretty = test.pretty
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestNewTransmogrifierCSV(t *testing.T) {
+	csvData := []byte("# cars\nManufacturer;Model;Year\n# ford\nFord;Focus;2015\nChevy;Malibu;2015\n")
+	c := csv.NewReader(bytes.NewReader(csvData))
+	c.Comma = ';'
+	c.Comment = '#'
+	c.FieldsPerRecord = 3
+	var w bytes.Buffer
+	calvin := NewTransmogrifierCSV(c, &w)
+	if calvin.CSV != c {
+		t.Fatal("expected the provided csv.Reader to be used")
+	}
+	if !calvin.EscapeMarkdown {
+		t.Error("expected EscapeMarkdown to be true")
+	}
+	// the format is read using the csv.Reader's settings
+	err := calvin.SetFmt(bytes.NewReader([]byte("# format\nMake;Model;Yr\nl;c;r\n")))
+	if err != nil {
+		t.Fatalf("unexpected error setting format: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error creating mdtable: %s", err)
+	}
+	expected := "Make|Model|Yr  \n:--|:--:|--:  \nFord|Focus|2015  \nChevy|Malibu|2015  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+	// the csv.Reader's FieldsPerRecord is enforced
+	c = csv.NewReader(bytes.NewReader([]byte("a;b;c\n1;2\n")))
+	c.Comma = ';'
+	c.FieldsPerRecord = 3
+	calvin = NewTransmogrifierCSV(c, &w)
+	err = calvin.MDTable()
+	if err == nil {
+		t.Error("expected a wrong number of fields error, got none")
+	}
+}
+
+// records that are wider than the configured field settings used to panic.
+func TestMDTableFieldCountMismatch(t *testing.T) {
+	tests := []struct {
+		data      string
+		format    string
+		lazy      bool
+		ragged    bool
+		expected  string
+		strictErr string
+	}{
+		{"a,b,c,d,e\n1,2,3,4,5\n", "A,B,C,D\nl,c,r,\ni,b,s,\n", false, false,
+			"A|B|C|D  \n:--|:--:|--:|---  \n_1_|__2__|~~3~~|4|5  \n", "row 1: record has 5 fields, field name has 4 entries"},
+		{"a,b,c,d\n1,2,3,4\n5,6,7,8,9\n", "A,B,C,D\n,,,\nb,,,b\n", false, true,
+			"A|B|C|D  \n---|---|---|---  \n__1__|2|3|__4__  \n__5__|6|7|__8__|9  \n", "row 3: record has 5 fields, field style has 4 entries"},
+		{"a,b\n1,2\nx \"y\",z,w\n", "A,B\nl,r\nb,i\n", true, true,
+			"A|B  \n:--|--:  \n__1__|_2_  \n__x \"y\"__|_z_|w  \n", "row 3: record has 3 fields, field style has 2 entries"},
+		{"a,b,c\n1,2,3\n", "A,B,C\nl,r\nb\n", false, true,
+			"A|B|C  \n:--|--:|---  \n__1__|2|3  \n", "row 1: record has 3 fields, field alignment has 2 entries"},
+		{"a\n1\n", "A\nl\nb,i,s\n", false, true,
+			"A  \n:--  \n__1__  \n", "row 1: record has 1 fields, field style has 3 entries"},
+	}
+	for i, test := range tests {
+		for _, strict := range []bool{false, true} {
+			var w bytes.Buffer
+			calvin := NewTransmogrifier(bytes.NewReader([]byte(test.data)), &w)
+			calvin.Strict = strict
+			calvin.CSV.LazyQuotes = test.lazy
+			if test.ragged {
+				calvin.CSV.FieldsPerRecord = -1
+			}
+			err := calvin.SetFmt(bytes.NewReader([]byte(test.format)))
+			if err != nil {
+				t.Errorf("%d: unexpected error setting format: %s", i, err)
+				continue
+			}
+			err = calvin.MDTable()
+			if strict {
+				if _, ok := err.(FieldCountError); !ok || err.Error() != test.strictErr {
+					t.Errorf("%d: strict: got error %v want %q", i, err, test.strictErr)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+				continue
+			}
+			if w.String() != test.expected {
+				t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+			}
+		}
+	}
+}
+
+func TestMDTableSeparatorWidth(t *testing.T) {
+	tests := []struct {
+		names     []string
+		alignment []string
+		expected  string
+		warnings  int
+	}{
+		{nil, []string{"l", "r"}, "a|b|c  \n:--|--:|---  \n1|2|3  \n", 1},
+		{nil, []string{"l", "r", "c", "c"}, "a|b|c  \n:--|--:|:--:  \n1|2|3  \n", 1},
+		{nil, []string{"l", "r", "c"}, "a|b|c  \n:--|--:|:--:  \n1|2|3  \n", 0},
+		{[]string{"A", "B"}, []string{"l", "r", "c"}, "A|B  \n:--|--:  \n1|2|3  \n", 2},
+		{[]string{"A", "B", "C", "D"}, []string{"l"}, "A|B|C|D  \n:--|---|---|---  \n1|2|3  \n", 2},
+	}
+	for i, test := range tests {
+		for _, strict := range []bool{false, true} {
+			var w bytes.Buffer
+			calvin := NewTransmogrifier(bytes.NewReader([]byte("a,b,c\n1,2,3\n")), &w)
+			calvin.Strict = strict
+			calvin.SetFieldNames(test.names)
+			calvin.SetFieldAlignment(test.alignment)
+			err := calvin.MDTable()
+			if strict {
+				if test.warnings == 0 {
+					if err != nil {
+						t.Errorf("%d: strict: unexpected error: %s", i, err)
+					}
+					continue
+				}
+				if _, ok := err.(FieldCountError); !ok {
+					t.Errorf("%d: strict: got %v, want a FieldCountError", i, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+				continue
+			}
+			if w.String() != test.expected {
+				t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+			}
+			if len(calvin.Warnings()) != test.warnings {
+				t.Errorf("%d: got %d warnings want %d", i, len(calvin.Warnings()), test.warnings)
+			}
+		}
+	}
+}
+
+func TestOuterPipes(t *testing.T) {
+	csvData := []byte("Team,Name\nPlatform,Ann\nWeb,Bartholomew\n")
+	tests := []struct {
+		pretty   bool
+		group    []GroupOption
+		expected string
+	}{
+		{false, nil, "| Team | Name |  \n| --- | --- |  \n| Platform | Ann |  \n| Web | Bartholomew |  \n"},
+		{true, nil, "| Team     | Name        |  \n| -------- | ----------- |  \n| Platform | Ann         |  \n| Web      | Bartholomew |  \n"},
+		{false, []GroupOption{HideGroupColumn()}, "| Name |  \n| --- |  \n| **Team: Platform** |  \n| Ann |  \n| **Team: Web** |  \n| Bartholomew |  \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.OuterPipes = true
+		calvin.Pretty = test.pretty
+		if test.group != nil {
+			calvin.GroupBy("Team", test.group...)
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestGeneratedFieldNames(t *testing.T) {
+	tests := []struct {
+		csv      string
+		pattern  string
+		names    []string
+		format   Format
+		expected string
+	}{
+		{"Ford,Focus\nKia,Rio\n", "", nil, GFM, "Column 1|Column 2  \n---|---  \nFord|Focus  \nKia|Rio  \n"},
+		{"Ford,Focus\nKia,Rio\n", "Field {n}", nil, GFM, "Field 1|Field 2  \n---|---  \nFord|Focus  \nKia|Rio  \n"},
+		{"Ford,Focus\nKia,Rio\n", "", []string{"Make", "Model"}, GFM, "Make|Model  \n---|---  \nFord|Focus  \nKia|Rio  \n"},
+		{"Ford,Focus\n", "", nil, HTML, "<table>\n<tbody>\n<tr>\n<td>Ford</td>\n<td>Focus</td>\n</tr>\n</tbody>\n</table>\n"},
+		{"", "", nil, GFM, ""},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader([]byte(test.csv)), &w)
+		calvin.HasHeaderRecord = false
+		calvin.FieldNamePattern = test.pattern
+		calvin.OutputFormat = test.format
+		calvin.SetFieldNames(test.names)
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+// failingRenderer is a Renderer whose WriteRow fails once n rows have been
+// written.
+type failingRenderer struct {
+	recorder
+	n int
+}
+
+func (r *failingRenderer) WriteRow(cells []Cell) error {
+	if r.n == 0 {
+		return errors.New("renderer failed")
+	}
+	r.n--
+	return nil
+}
+
+func TestRowError(t *testing.T) {
+	errRead := errors.New("read failed")
+	tests := []struct {
+		r        io.Reader
+		renderer Renderer
+		expected string
+		row      int
+	}{
+		{strings.NewReader("a,b\n1,2\n3,4\n5,6\n"), &failingRenderer{n: 2}, "row 4: renderer failed", 4},
+		{io.MultiReader(strings.NewReader("a,b\n1,2\n"), iotest.ErrReader(errRead)), nil, "row 3: read failed", 3},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(test.r, ioutil.Discard)
+		if test.renderer != nil {
+			calvin.SetRenderer(test.renderer)
+		}
+		err := calvin.MDTable()
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%d: got error %v want %q", i, err, test.expected)
+			continue
+		}
+		var rowErr RowError
+		if !errors.As(err, &rowErr) || rowErr.Row != test.row {
+			t.Errorf("%d: got %#v, want a RowError of row %d", i, err, test.row)
+		}
+	}
+	// errors that identify the record aren't wrapped
+	calvin := NewTransmogrifier(strings.NewReader("a\n1\n"), ioutil.Discard)
+	calvin.SetColumnValueMap("a", map[string]string{}, false)
+	err := calvin.MDTable()
+	if _, ok := err.(UnmappedValueError); !ok {
+		t.Errorf("got %#v, want an UnmappedValueError", err)
+	}
+}
+
+// cancelingRenderer is a Renderer that cancels the context once n rows
+// have been written.
+type cancelingRenderer struct {
+	recorder
+	n      int
+	cancel context.CancelFunc
+}
+
+func (r *cancelingRenderer) WriteRow(cells []Cell) error {
+	r.n--
+	if r.n == 0 {
+		r.cancel()
+	}
+	return r.recorder.WriteRow(cells)
+}
+
+func TestMDTableContext(t *testing.T) {
+	csvData := "Team,Name\nWeb,Ann\nWeb,Bob\nWeb,Cy\n"
+	tests := []struct {
+		sorted bool
+		rows   int
+	}{
+		{false, 1},
+		{true, 2}, // the group subheader and the first record
+	}
+	for i, test := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		r := &cancelingRenderer{n: test.rows, cancel: cancel}
+		calvin := NewTransmogrifier(strings.NewReader(csvData), ioutil.Discard)
+		calvin.SetRenderer(r)
+		if test.sorted {
+			// the records are read into memory
+			calvin.GroupBy("Team", SortGroups(), HideGroupColumn())
+		}
+		err := calvin.MDTableContext(ctx)
+		if err != context.Canceled {
+			t.Errorf("%d: got error %v want %v", i, err, context.Canceled)
+		}
+		var rows int
+		for _, call := range r.calls {
+			if strings.HasPrefix(call, "row") {
+				rows++
+			}
+		}
+		if rows != test.rows {
+			t.Errorf("%d: got %d rows want %d: %q", i, rows, test.rows, r.calls)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var w bytes.Buffer
+	err := NewTransmogrifier(strings.NewReader(csvData), &w).MDTableContext(ctx)
+	if err != context.Canceled || w.Len() != 0 {
+		t.Errorf("got error %v and %q, want %v and nothing written", err, w.String(), context.Canceled)
+	}
+}
+
+// benchmarkCSV returns n records of CSV-encoded data, with a header record.
+func benchmarkCSV(n int) []byte {
+	var b bytes.Buffer
+	b.WriteString("ID,Make,Model,Year,Price,Notes\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "%d,Chevrolet,Volt,%d,%d.99,\"a note, with a comma and a | pipe\"\n", i, 2000+i%20, 20000+i)
+	}
+	return b.Bytes()
+}
+
+func BenchmarkMDTable(b *testing.B) {
+	csvData := benchmarkCSV(10000)
+	benchmarks := []struct {
+		name  string
+		setup func(*Transmogrifier)
+	}{
+		{"plain", func(t *Transmogrifier) {}},
+		{"formatted", func(t *Transmogrifier) {
+			t.SetFieldAlignment([]string{"r", "l", "c", "r", "r", "l"})
+			t.SetFieldStyle([]string{"b", "", "i", "", "", ""})
+		}},
+		{"pretty", func(t *Transmogrifier) { t.Pretty = true }},
+		{"reuse record", func(t *Transmogrifier) { t.ReuseRecord = true }},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(csvData)))
+			for i := 0; i < b.N; i++ {
+				calvin := NewTransmogrifier(bytes.NewReader(csvData), ioutil.Discard)
+				bm.setup(calvin)
+				err := calvin.MDTable()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestReuseRecord(t *testing.T) {
+	csvData := "Team,Name,Amount\nWeb,Ann,1\nOps,Bob,22\nWeb,Cy,333\nOps,Di,4\n"
+	tests := []struct {
+		name  string
+		setup func(*Transmogrifier)
+	}{
+		{"plain", func(t *Transmogrifier) {}},
+		{"pretty", func(t *Transmogrifier) { t.Pretty = true }},
+		{"box", func(t *Transmogrifier) { t.OutputFormat = Box }},
+		{"sorted groups", func(t *Transmogrifier) { t.GroupBy("Team", SortGroups()) }},
+		{"transpose", func(t *Transmogrifier) { t.Transpose = true }},
+		{"auto align", func(t *Transmogrifier) { t.AutoAlign = true }},
+		{"no header", func(t *Transmogrifier) { t.HasHeaderRecord = false }},
+		{"collapse", func(t *Transmogrifier) { t.CollapseRepeats([]string{"Team"}) }},
+		{"footer", func(t *Transmogrifier) { t.SetColumnAggregate("Amount", "max") }},
+	}
+	for _, test := range tests {
+		var expected, w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &expected)
+		test.setup(calvin)
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		calvin = NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.ReuseRecord = true
+		test.setup(calvin)
+		err = calvin.MDTable()
+		if err != nil {
+			t.Errorf("%s: reuse record: unexpected error: %s", test.name, err)
+			continue
+		}
+		if w.String() != expected.String() {
+			t.Errorf("%s: got %q want %q", test.name, w.String(), expected.String())
+		}
+	}
+}
+
+func TestSkipBlankRecords(t *testing.T) {
+	tests := []struct {
+		csv      string
+		skip     bool
+		pretty   bool
+		comment  rune
+		expected string
+		err      string
+	}{
+		{"Make,Model\nFord,Focus\n,\nKia,Rio\n", false, false, 0, "Make|Model  \n---|---  \nFord|Focus  \n |   \nKia|Rio  \n", ""},
+		{"Make,Model\nFord,Focus\n,\nKia,Rio\n", true, false, 0, "Make|Model  \n---|---  \nFord|Focus  \nKia|Rio  \n", ""},
+		{"Make,Model\n, \nFord,Focus\n\"\",\nKia,Rio\n", true, true, 0, "Make|Model  \n----|-----  \nFord|Focus  \nKia |Rio    \n", ""},
+		{"Make,Model\nFord,Focus\n,,\nKia,Rio\n", false, false, 0, "", "row 3: record has 3 fields, the header has 2"},
+		{"Make,Model\nFord,Focus\n,,\nKia,Rio\n", true, false, 0, "Make|Model  \n---|---  \nFord|Focus  \nKia|Rio  \n", ""},
+		{"# cars\nMake,Model\nFord,Focus\n# more cars\n,\nKia,Rio\n", true, false, '#', "Make|Model  \n---|---  \nFord|Focus  \nKia|Rio  \n", ""},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(test.csv), &w)
+		calvin.SkipBlankRecords = test.skip
+		calvin.Pretty = test.pretty
+		calvin.CSV.Comment = test.comment
+		err := calvin.MDTable()
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
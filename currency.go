@@ -0,0 +1,168 @@
+package csv2md
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// currency is an ISO 4217 currency's symbol and number of digits after
+// the decimal point.
+type currency struct {
+	symbol string
+	digits int
+}
+
+var currencies = map[string]currency{
+	"AUD": {"A$", 2},
+	"BRL": {"R$", 2},
+	"CAD": {"CA$", 2},
+	"CHF": {"CHF", 2},
+	"CNY": {"¥", 2},
+	"EUR": {"€", 2},
+	"GBP": {"£", 2},
+	"HKD": {"HK$", 2},
+	"INR": {"₹", 2},
+	"JPY": {"¥", 0},
+	"KRW": {"₩", 0},
+	"MXN": {"MX$", 2},
+	"NZD": {"NZ$", 2},
+	"RUB": {"₽", 2},
+	"USD": {"$", 2},
+}
+
+// CurrencyFormat is the format of a currency column's amounts; see
+// SetColumnCurrency.
+type CurrencyFormat struct {
+	// Symbol is the currency's symbol, e.g. $ or €, or its ISO 4217
+	// code, e.g. USD, whose symbol is used.  Symbols that are letters,
+	// e.g. CHF, are separated from the amount by a space.
+	Symbol string
+	// Precision is the number of digits after the decimal point; a
+	// negative precision uses the currency's, e.g. 2 for USD and 0 for
+	// JPY, or 2 if it isn't known.
+	Precision int
+	// Locale sets the thousands separator and the decimal mark, and
+	// whether the symbol follows the amount: en, $1,234.56; de,
+	// 1.234,56 €; fr, 1 234,56 €; or ch, CHF 1'234.56.  An empty locale
+	// is en.
+	Locale string
+	// CodeColumn is the column with each amount's ISO 4217 currency code,
+	// e.g. USD; the code's symbol is used instead of the Symbol, unless
+	// the code is empty.  Codes that aren't known are used as the symbol.
+	CodeColumn string
+}
+
+// ParseCurrencyFormat parses a currency format of the form
+// symbol[,.precision][,locale], e.g. "$", "EUR,de", or "JPY,.2".  A symbol
+// of the form @column is the CodeColumn, e.g. "@Currency".  The precision
+// defaults to the currency's.
+func ParseCurrencyFormat(s string) (CurrencyFormat, error) {
+	f := CurrencyFormat{Precision: -1}
+	parts := strings.Split(s, ",")
+	f.Symbol = strings.TrimSpace(parts[0])
+	if strings.HasPrefix(f.Symbol, "@") {
+		f.CodeColumn, f.Symbol = f.Symbol[1:], ""
+		if f.CodeColumn == "" {
+			return CurrencyFormat{}, fmt.Errorf("currency format %q: expected symbol[,.precision][,locale]", s)
+		}
+	}
+	for _, p := range parts[1:] {
+		p = strings.TrimSpace(p)
+		if strings.HasPrefix(p, ".") {
+			n, err := strconv.Atoi(p[1:])
+			if err != nil || n < 0 {
+				return CurrencyFormat{}, fmt.Errorf("currency format %q: expected symbol[,.precision][,locale]", s)
+			}
+			f.Precision = n
+			continue
+		}
+		if _, err := parseLocale(p); err != nil || p == "" {
+			return CurrencyFormat{}, fmt.Errorf("currency format %q: expected symbol[,.precision][,locale]", s)
+		}
+		f.Locale = p
+	}
+	return f, nil
+}
+
+// SetColumnCurrency makes the named column a currency column: its
+// amounts are written with the format's symbol, precision, and locale;
+// e.g. with a Symbol of "EUR" and the de Locale, 1234.5 is written as
+// 1.234,50 €.  Values that aren't numbers are written as is.  The amounts
+// are formatted after the value maps and before the cell templates and
+// the field's styling.  Setting the format of a column that already has
+// a value format replaces it.
+func (t *Transmogrifier) SetColumnCurrency(column string, f CurrencyFormat) error {
+	vf, err := t.currencyFormat(f)
+	if err != nil {
+		return err
+	}
+	vf.column = column
+	t.setValueFormat(vf)
+	return nil
+}
+
+// currencyFormat returns the value format of the currency format; the
+// amounts are the numbers of the Transmogrifier's locale.
+func (t *Transmogrifier) currencyFormat(f CurrencyFormat) (*valueFormat, error) {
+	l, err := parseLocale(f.Locale)
+	if err != nil {
+		return nil, fmt.Errorf("currency: %s", err)
+	}
+	vf := &valueFormat{kind: "currency"}
+	format := func(v, code string) string {
+		return f.format(v, code, t.locale, l)
+	}
+	if f.CodeColumn != "" {
+		vf.ref, vf.formatRef = f.CodeColumn, format
+		return vf, nil
+	}
+	vf.format = func(v string) string {
+		return format(v, "")
+	}
+	return vf, nil
+}
+
+// format returns the amount, written using the locale l, if the value is
+// one of the in locale's numbers; otherwise the value is returned as is.
+func (f CurrencyFormat) format(v, code string, in, l locale) string {
+	_, n, ok := in.number(v)
+	if !ok || math.IsInf(n, 0) || math.IsNaN(n) {
+		return v
+	}
+	symbol, digits := f.symbol(code)
+	if f.Precision >= 0 {
+		digits = f.Precision
+	}
+	s := strconv.FormatFloat(math.Abs(n), 'f', digits, 64)
+	s = l.separate(NumberFormat{Precision: -1, Thousands: ","}.format(s, locale{}))
+	// amounts that round to 0 aren't negative
+	var sign string
+	if n < 0 && strings.Trim(s, "0"+l.thousands+l.decimal) != "" {
+		sign = "-"
+	}
+	switch {
+	case symbol == "":
+		return sign + s
+	case l.suffix:
+		return sign + s + " " + symbol
+	case unicode.IsLetter([]rune(symbol)[len([]rune(symbol))-1]):
+		return sign + symbol + " " + s
+	}
+	return sign + symbol + s
+}
+
+// symbol returns the symbol of the amount, whose currency code is the
+// code, and the currency's number of digits after the decimal point.
+func (f CurrencyFormat) symbol(code string) (string, int) {
+	symbol := f.Symbol
+	if code = strings.TrimSpace(code); code != "" {
+		symbol = code
+	}
+	if c, ok := currencies[strings.ToUpper(symbol)]; ok && len(symbol) == 3 {
+		return c.symbol, c.digits
+	}
+	return symbol, 2
+}
@@ -0,0 +1,157 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseCurrencyFormat(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected CurrencyFormat
+		err      string
+	}{
+		{"$", CurrencyFormat{Symbol: "$", Precision: -1}, ""},
+		{"EUR,de", CurrencyFormat{Symbol: "EUR", Precision: -1, Locale: "de"}, ""},
+		{"JPY, .2", CurrencyFormat{Symbol: "JPY", Precision: 2}, ""},
+		{"@Currency,.0,fr", CurrencyFormat{Precision: 0, Locale: "fr", CodeColumn: "Currency"}, ""},
+		{"", CurrencyFormat{Precision: -1}, ""},
+		{"@", CurrencyFormat{}, `currency format "@": expected symbol[,.precision][,locale]`},
+		{"$,.x", CurrencyFormat{}, `currency format "$,.x": expected symbol[,.precision][,locale]`},
+		{"$,xx", CurrencyFormat{}, `currency format "$,xx": expected symbol[,.precision][,locale]`},
+		{"$,", CurrencyFormat{}, `currency format "$,": expected symbol[,.precision][,locale]`},
+	}
+	for i, test := range tests {
+		f, err := ParseCurrencyFormat(test.value)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if f != test.expected {
+			t.Errorf("%d: got %+v want %+v", i, f, test.expected)
+		}
+	}
+}
+
+func TestCurrencyFormat(t *testing.T) {
+	tests := []struct {
+		format   string
+		value    string
+		code     string
+		expected string
+	}{
+		{"$", "1234.5", "", "$1,234.50"},
+		{"$", "-1234.5", "", "-$1,234.50"},
+		{"$", "-0.001", "", "$0.00"},
+		{"$,.0", "999.5", "", "$1,000"},
+		{"USD", "12", "", "$12.00"},
+		{"GBP", "1234567.891", "", "£1,234,567.89"},
+		{"JPY", "1234567.891", "", "¥1,234,568"},
+		{"JPY,.2", "1234.5", "", "¥1,234.50"},
+		{"EUR,de", "1234.5", "", "1.234,50 €"},
+		{"EUR,fr", "-1234.5", "", "-1 234,50 €"},
+		{"CHF,ch", "1234.5", "", "CHF 1'234.50"},
+		{"SEK", "1234.5", "", "SEK 1,234.50"},
+		{"kr", "5", "", "kr 5.00"},
+		{"", "1234.5", "", "1,234.50"},
+		{"@Code", "1234.5", "EUR", "€1,234.50"},
+		{"@Code", "1234.6", "jpy", "¥1,235"},
+		{"@Code", "1234.5", "XYZ", "XYZ 1,234.50"},
+		{"@Code", "1234.5", "", "1,234.50"},
+		{"$", "n/a", "", "n/a"},
+		{"$", "", "", ""},
+	}
+	for i, test := range tests {
+		f, err := ParseCurrencyFormat(test.format)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		l, _ := parseLocale(f.Locale)
+		v := f.format(test.value, test.code, locale{}, l)
+		if v != test.expected {
+			t.Errorf("%d: %s %q: got %q want %q", i, test.format, test.value, v, test.expected)
+		}
+	}
+}
+
+func TestSetColumnCurrency(t *testing.T) {
+	csvData := "Item,Currency,Price\nCar,dollar,25999.5\nHouse,EUR,1234567.891\nIdea,JPY,n/a\n"
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+	err := calvin.SetColumnCurrency("Price", CurrencyFormat{Precision: -1, CodeColumn: "Currency", Locale: "de"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// the codes are the code column's mapped values
+	calvin.SetColumnValueMap("Currency", map[string]string{"dollar": "USD"}, true)
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Item|Currency|Price  \n---|---|---  \nCar|USD|25.999,50 $  \nHouse|EUR|1.234.567,89 €  \nIdea|JPY|n/a  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+
+	tests := []struct {
+		column string
+		f      CurrencyFormat
+		err    string
+	}{
+		{"Cost", CurrencyFormat{Symbol: "$"}, `currency: unknown column "Cost"`},
+		{"Price", CurrencyFormat{CodeColumn: "Code"}, `currency: unknown column "Code"`},
+		{"Price", CurrencyFormat{Locale: "xx"}, `currency: unknown locale "xx": expected en, de, fr, or ch`},
+	}
+	for i, test := range tests {
+		calvin = NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+		err = calvin.SetColumnCurrency(test.column, test.f)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%d: got error %v want %q", i, err, test.err)
+		}
+	}
+}
+
+func TestFieldFormatCurrency(t *testing.T) {
+	csvData := "Item,Currency,Price\nCar,GBP,25999.5\n"
+	tests := []struct {
+		formats  []FieldFormat
+		expected string
+		err      string
+	}{
+		{[]FieldFormat{{}, {}, {Currency: "@Currency"}}, "Item|Currency|Price  \n---|---|---  \nCar|GBP|£25,999.50  \n", ""},
+		{[]FieldFormat{{Column: "Price", Currency: "$,.0"}}, "Item|Currency|Price  \n---|---|---  \nCar|GBP|$26,000  \n", ""},
+		{[]FieldFormat{{Column: "Price", Currency: "$,xx"}}, "", `field 1: currency format "$,xx": expected symbol[,.precision][,locale]`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		err := calvin.SetFieldFormats(test.formats)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package csv2md
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The time format presets, which may be used instead of a layout; see
+// TimeFormat.  TimeRelative is only an output preset.
+const (
+	TimeISO      = "iso"      // RFC 3339, 2006-01-02T15:04:05Z07:00
+	TimeDate     = "date"     // 2006-01-02
+	TimeDateTime = "datetime" // 2006-01-02 15:04:05
+	TimeRFC1123  = "rfc1123"  // Mon, 02 Jan 2006 15:04:05 MST
+	TimeUnix     = "unix"     // seconds since January 1, 1970 UTC
+	TimeUnixMS   = "unixms"   // milliseconds since January 1, 1970 UTC
+	TimeRelative = "relative" // relative to now, e.g. 3 days ago or in 2 hours
+)
+
+var timePresets = map[string]string{
+	TimeISO:      time.RFC3339,
+	"rfc3339":    time.RFC3339,
+	TimeDate:     "2006-01-02",
+	TimeDateTime: "2006-01-02 15:04:05",
+	TimeRFC1123:  time.RFC1123,
+}
+
+// timeLayouts are the layouts that the values are parsed with when the
+// TimeFormat has no Layout.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.ANSIC,
+}
+
+// TimeFormat is the format of a column's dates and times; see
+// SetColumnTimeFormat.  The layouts are those of the time package, e.g.
+// "Jan 2, 2006", or one of the presets, e.g. TimeISO.
+type TimeFormat struct {
+	// Layout is the layout of the values.  If it is empty, the values are
+	// parsed using the common layouts: RFC 3339, with or without the T
+	// and the time zone, dates, RFC 1123, RFC 850, and ANSI C.  Values
+	// without a time zone are in UTC.
+	Layout string
+	// Output is the layout the values are written with, or TimeRelative.
+	Output string
+}
+
+// ParseTimeFormat parses a time format of the form [layout>]output,
+// e.g. "Jan 2, 2006" or "unix>iso".  Either layout may be a preset.
+func ParseTimeFormat(s string) (TimeFormat, error) {
+	var f TimeFormat
+	f.Output = s
+	if i := strings.Index(s, ">"); i >= 0 {
+		f.Layout, f.Output = s[:i], s[i+1:]
+		if f.Layout == "" {
+			return TimeFormat{}, fmt.Errorf("time format %q: expected [layout>]output", s)
+		}
+	}
+	if f.Output == "" {
+		return TimeFormat{}, fmt.Errorf("time format %q: expected [layout>]output", s)
+	}
+	if f.Layout == TimeRelative {
+		return TimeFormat{}, fmt.Errorf("time format %q: relative is not an input layout", s)
+	}
+	return f, nil
+}
+
+// SetColumnTimeFormat sets the format of the named column's dates and
+// times; e.g. with an Output of "Jan 2, 2006", 2015-03-01T14:05:00Z is
+// written as Mar 1, 2015.  Values that can't be parsed using the Layout
+// are written as is.  The times are formatted after the value maps and
+// before the cell templates and the field's styling.  Setting a format
+// for a column that already has a value format replaces it.
+func (t *Transmogrifier) SetColumnTimeFormat(column string, f TimeFormat) {
+	t.setValueFormat(&valueFormat{column: column, kind: "time format", format: t.timeFormatter(f)})
+}
+
+// timeFormatter returns the function that formats the values using f;
+// relative times are relative to the time it is called.
+func (t *Transmogrifier) timeFormatter(f TimeFormat) func(string) string {
+	return func(v string) string {
+		now := time.Now
+		if t.now != nil {
+			now = t.now
+		}
+		return f.format(v, now())
+	}
+}
+
+// format returns the formatted value if it is a time; otherwise the value
+// is returned as is.
+func (f TimeFormat) format(v string, now time.Time) string {
+	tm, ok := parseTime(strings.TrimSpace(v), f.Layout)
+	if !ok {
+		return v
+	}
+	switch f.Output {
+	case TimeRelative:
+		return relativeTime(tm, now)
+	case TimeUnix:
+		return strconv.FormatInt(tm.Unix(), 10)
+	case TimeUnixMS:
+		return strconv.FormatInt(tm.UnixNano()/int64(time.Millisecond), 10)
+	}
+	if layout, ok := timePresets[f.Output]; ok {
+		return tm.Format(layout)
+	}
+	return tm.Format(f.Output)
+}
+
+// parseTime parses the value using the layout, or, if the layout is empty,
+// the common layouts.
+func parseTime(v, layout string) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, false
+	}
+	switch layout {
+	case TimeUnix, TimeUnixMS:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return time.Time{}, false
+		}
+		if layout == TimeUnixMS {
+			n /= 1000
+		}
+		sec, frac := math.Modf(n)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+	case "":
+		for _, l := range timeLayouts {
+			if tm, err := time.Parse(l, v); err == nil {
+				return tm, true
+			}
+		}
+		return time.Time{}, false
+	}
+	if l, ok := timePresets[layout]; ok {
+		layout = l
+	}
+	tm, err := time.Parse(layout, v)
+	return tm, err == nil
+}
+
+// relativeTime returns the time relative to now in its largest whole unit,
+// e.g. 3 days ago or in 2 hours; times within a minute are just now.
+func relativeTime(tm, now time.Time) string {
+	d := now.Sub(tm)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	units := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, u := range units {
+		n := int64(d / u.d)
+		if n == 0 {
+			continue
+		}
+		s := strconv.FormatInt(n, 10) + " " + u.name
+		if n > 1 {
+			s += "s"
+		}
+		if future {
+			return "in " + s
+		}
+		return s + " ago"
+	}
+	return "just now"
+}
@@ -0,0 +1,136 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeFormat(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected TimeFormat
+		err      string
+	}{
+		{"Jan 2, 2006", TimeFormat{Output: "Jan 2, 2006"}, ""},
+		{"unix>iso", TimeFormat{Layout: "unix", Output: "iso"}, ""},
+		{"2006-01-02>relative", TimeFormat{Layout: "2006-01-02", Output: "relative"}, ""},
+		{"", TimeFormat{}, `time format "": expected [layout>]output`},
+		{">date", TimeFormat{}, `time format ">date": expected [layout>]output`},
+		{"date>", TimeFormat{}, `time format "date>": expected [layout>]output`},
+		{"relative>date", TimeFormat{}, `time format "relative>date": relative is not an input layout`},
+	}
+	for i, test := range tests {
+		f, err := ParseTimeFormat(test.value)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if f != test.expected {
+			t.Errorf("%d: got %+v want %+v", i, f, test.expected)
+		}
+	}
+}
+
+func TestTimeFormat(t *testing.T) {
+	now := time.Date(2015, time.March, 4, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		format   string
+		value    string
+		expected string
+	}{
+		{"Jan 2, 2006", "2015-03-01T14:05:00Z", "Mar 1, 2015"},
+		{"Jan 2, 2006 15:04", "2015-03-01 14:05:00", "Mar 1, 2015 14:05"},
+		{"date", " 2015-03-01T14:05:00+01:00 ", "2015-03-01"},
+		{"iso", "Sun, 01 Mar 2015 14:05:00 UTC", "2015-03-01T14:05:00Z"},
+		{"datetime", "2015-03-01", "2015-03-01 00:00:00"},
+		{"unix>iso", "1425218700", "2015-03-01T14:05:00Z"},
+		{"unixms>datetime", "1425218700500", "2015-03-01 14:05:00"},
+		{"iso>unix", "2015-03-01T14:05:00Z", "1425218700"},
+		{"02/01/2006>date", "01/03/2015", "2015-03-01"},
+		{"02/01/2006>date", "2015-03-01", "2015-03-01"},
+		{"relative", "2015-03-01T12:00:00Z", "3 days ago"},
+		{"relative", "2015-03-04T10:30:00Z", "1 hour ago"},
+		{"relative", "2015-03-04T12:00:30Z", "just now"},
+		{"relative", "2015-03-18", "in 1 week"},
+		{"relative", "2013-01-01", "2 years ago"},
+		{"Jan 2, 2006", "soon", "soon"},
+		{"Jan 2, 2006", "", ""},
+	}
+	for i, test := range tests {
+		f, err := ParseTimeFormat(test.format)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		v := f.format(test.value, now)
+		if v != test.expected {
+			t.Errorf("%d: %s %q: got %q want %q", i, test.format, test.value, v, test.expected)
+		}
+	}
+}
+
+func TestSetColumnTimeFormat(t *testing.T) {
+	csvData := "Event,Created\nLaunch,2015-03-01T14:05:00Z\nParty,2015-03-03 20:00\nNap,someday\n"
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+	calvin.now = func() time.Time { return time.Date(2015, time.March, 4, 12, 0, 0, 0, time.UTC) }
+	calvin.SetColumnTimeFormat("Created", TimeFormat{Output: TimeRelative})
+	calvin.SetFieldStyle([]string{"", "i"})
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Event|Created  \n---|---  \nLaunch|_2 days ago_  \nParty|_16 hours ago_  \nNap|_someday_  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+
+	calvin = NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+	calvin.SetColumnTimeFormat("Updated", TimeFormat{Output: TimeDate})
+	err = calvin.MDTable()
+	if err == nil || err.Error() != `time format: unknown column "Updated"` {
+		t.Errorf("got error %v want an unknown column error", err)
+	}
+}
+
+func TestFieldFormatTime(t *testing.T) {
+	csvData := "Event,Created\nLaunch,2015-03-01T14:05:00Z\n"
+	tests := []struct {
+		formats  []FieldFormat
+		expected string
+		err      string
+	}{
+		{[]FieldFormat{{}, {Time: "Jan 2, 2006"}}, "Event|Created  \n---|---  \nLaunch|Mar 1, 2015  \n", ""},
+		{[]FieldFormat{{Column: "Created", Time: "iso>unix"}}, "Event|Created  \n---|---  \nLaunch|1425218700  \n", ""},
+		{[]FieldFormat{{Column: "Created", Time: "date>"}}, "", `field 1: time format "date>": expected [layout>]output`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		err := calvin.SetFieldFormats(test.formats)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
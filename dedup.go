@@ -0,0 +1,66 @@
+package csv2md
+
+import "strconv"
+
+// Dedup removes the duplicate records from the table: a record is a
+// duplicate if the values of the named columns are the same as those of
+// a previous record.  If no columns are named, a record is a duplicate if
+// all of its values are the same.  Only the first of the duplicates is
+// kept.  The records are compared after the filter, see SetFilter, and
+// before any value maps or formatting are applied.  The header record is
+// never removed.  See Duplicates for the number of removed records.
+//
+// The values of every record that is kept are held in memory until the
+// table has been written.
+func (t *Transmogrifier) Dedup(columns ...string) {
+	t.dedup = &dedup{columns: columns}
+	t.nFiltered = 0
+}
+
+// Duplicates returns the number of duplicate records that were removed
+// from the table; see Dedup.
+func (t *Transmogrifier) Duplicates() int {
+	if t.dedup == nil {
+		return 0
+	}
+	return t.dedup.removed
+}
+
+// dedup is the set of the kept records' keys; see Dedup.
+type dedup struct {
+	columns  []string
+	index    []int
+	seen     map[string]struct{}
+	removed  int
+	prepared bool
+}
+
+// duplicate returns whether the record is a duplicate of a previous
+// record; if it isn't, its key is added to the seen keys.
+func (d *dedup) duplicate(record []string) bool {
+	var key []byte
+	add := func(v string) {
+		key = strconv.AppendInt(key, int64(len(v)), 10)
+		key = append(key, ':')
+		key = append(key, v...)
+	}
+	if len(d.index) == 0 {
+		for _, v := range record {
+			add(v)
+		}
+	} else {
+		for _, i := range d.index {
+			if i < len(record) {
+				add(record[i])
+			} else {
+				add("")
+			}
+		}
+	}
+	if _, ok := d.seen[string(key)]; ok {
+		d.removed++
+		return true
+	}
+	d.seen[string(key)] = struct{}{}
+	return false
+}
@@ -0,0 +1,72 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDedup(t *testing.T) {
+	csvData := "Make,Model,Year\nFord,Focus,2015\nKia,Rio,2016\nFord,Focus,2015\nFord,Focus,2017\nKia,Rio,2016\n"
+	tests := []struct {
+		columns    []string
+		filter     string
+		expected   string
+		duplicates int
+		err        string
+	}{
+		{nil, "", "Make|Model|Year  \n---|---|---  \nFord|Focus|2015  \nKia|Rio|2016  \nFord|Focus|2017  \n", 2, ""},
+		{[]string{"Make", "Model"}, "", "Make|Model|Year  \n---|---|---  \nFord|Focus|2015  \nKia|Rio|2016  \n", 3, ""},
+		{[]string{"Year"}, "Year > 2015", "Make|Model|Year  \n---|---|---  \nKia|Rio|2016  \nFord|Focus|2017  \n", 1, ""},
+		{[]string{"Color"}, "", "", 0, `dedup: unknown column "Color"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.Dedup(test.columns...)
+		if test.filter != "" {
+			err := calvin.SetFilter(test.filter)
+			if err != nil {
+				t.Fatalf("%d: unexpected error: %s", i, err)
+			}
+		}
+		err := calvin.MDTable()
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+		if calvin.Duplicates() != test.duplicates {
+			t.Errorf("%d: got %d duplicates want %d", i, calvin.Duplicates(), test.duplicates)
+		}
+	}
+}
+
+func TestDedupBuffered(t *testing.T) {
+	// buffered records, e.g. for pretty output and transposing, are only
+	// deduplicated once
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader("Make,Model\nFord,Focus\nFord,Focus\nKia,Rio\n"), &w)
+	calvin.Dedup()
+	calvin.Pretty = true
+	calvin.Transpose = true
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Make |Ford |Kia  \n-----|-----|---  \nModel|Focus|Rio  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+	if calvin.Duplicates() != 1 {
+		t.Errorf("got %d duplicates want 1", calvin.Duplicates())
+	}
+}
@@ -0,0 +1,29 @@
+package csv2md
+
+import "testing"
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		v        string
+		expected int
+	}{
+		{"", 0},
+		{"Calvin", 6},
+		{"Zoë", 3},
+		{"é", 1},
+		{"東京", 4},
+		{"서울", 4},
+		{"ｶﾀｶﾅ", 4},
+		{"ＡＢ", 4},
+		{"✅ done", 7},
+		{"🚀", 2},
+		{"a\u200db", 2},
+		{"e\u0301", 1},
+	}
+	for i, test := range tests {
+		n := displayWidth(test.v)
+		if n != test.expected {
+			t.Errorf("%d: %q: got %d want %d", i, test.v, n, test.expected)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package csv2md
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// ParseDurationUnit parses the unit of a duration column's numbers: ns,
+// us or µs, ms, s, m, or h.  An empty unit is seconds.
+func ParseDurationUnit(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Second, nil
+	}
+	if d, ok := durationUnits[s]; ok {
+		return d, nil
+	}
+	return 0, fmt.Errorf("duration unit %q: expected ns, us, ms, s, m, or h", s)
+}
+
+// SetColumnDuration makes the named column a duration column: its values,
+// numbers of the unit, e.g. time.Second, or Go durations, e.g. 8040s or
+// 1h30m0.5s, are written in their two largest units, e.g. 2h14m or 3d4h.
+// Durations of less than a minute are written using their most precise
+// unit, to the millisecond, or, if shorter than a second, the
+// microsecond, e.g. 1.234s or 350ms.  Values that aren't durations are
+// written as is.  The durations are formatted after the value maps and
+// before the cell templates and the field's styling.  Setting the unit of
+// a column that already has a value format replaces it.
+func (t *Transmogrifier) SetColumnDuration(column string, unit time.Duration) {
+	t.setValueFormat(&valueFormat{column: column, kind: "duration", format: durationFormatter(unit)})
+}
+
+// durationFormatter returns the function that formats the durations whose
+// numbers are of the unit.
+func durationFormatter(unit time.Duration) func(string) string {
+	return func(v string) string {
+		d, ok := parseDuration(strings.TrimSpace(v), unit)
+		if !ok {
+			return v
+		}
+		return formatDuration(d)
+	}
+}
+
+// parseDuration parses the value as a number of the unit or a Go duration.
+func parseDuration(v string, unit time.Duration) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if n, err := strconv.ParseFloat(v, 64); err == nil {
+		n *= float64(unit)
+		if math.IsNaN(n) || math.Abs(n) > math.MaxInt64 {
+			return 0, false
+		}
+		return time.Duration(n), true
+	}
+	d, err := time.ParseDuration(v)
+	return d, err == nil
+}
+
+// formatDuration returns the duration in its two largest units.
+func formatDuration(d time.Duration) string {
+	var sign string
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	switch {
+	case d < time.Second:
+		return sign + d.Round(time.Microsecond).String()
+	case d < time.Minute:
+		return sign + d.Round(time.Millisecond).String()
+	}
+	units := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+	i := 0
+	for d < units[i].d {
+		i++
+	}
+	// the duration is rounded to its second unit, which may carry it into
+	// the larger unit, e.g. 59m59.6s is 1h
+	d = d.Round(units[i+1].d)
+	if i > 0 && d >= units[i-1].d {
+		i--
+	}
+	s := sign + strconv.FormatInt(int64(d/units[i].d), 10) + units[i].name
+	if n := int64(d % units[i].d / units[i+1].d); n > 0 {
+		s += strconv.FormatInt(n, 10) + units[i+1].name
+	}
+	return s
+}
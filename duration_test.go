@@ -0,0 +1,127 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDurationUnit(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		err      string
+	}{
+		{"", time.Second, ""},
+		{"ms", time.Millisecond, ""},
+		{"µs", time.Microsecond, ""},
+		{" h ", time.Hour, ""},
+		{"days", 0, `duration unit "days": expected ns, us, ms, s, m, or h`},
+	}
+	for i, test := range tests {
+		d, err := ParseDurationUnit(test.value)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if d != test.expected {
+			t.Errorf("%d: got %s want %s", i, d, test.expected)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		unit     time.Duration
+		value    string
+		expected string
+	}{
+		{time.Second, "8040", "2h14m"},
+		{time.Second, "8070", "2h15m"},
+		{time.Second, "7200", "2h"},
+		{time.Second, "273600", "3d4h"},
+		{time.Second, "90", "1m30s"},
+		{time.Second, "3599.6", "1h"},
+		{time.Second, "86399", "1d"},
+		{time.Second, "42", "42s"},
+		{time.Second, "1.2345", "1.235s"},
+		{time.Second, "0", "0s"},
+		{time.Second, "-90", "-1m30s"},
+		{time.Millisecond, "350", "350ms"},
+		{time.Millisecond, "8040000", "2h14m"},
+		{time.Millisecond, "1.5", "1.5ms"},
+		{time.Second, "1h30m0.5s", "1h30m"},
+		{time.Second, "250ms", "250ms"},
+		{time.Second, "slow", "slow"},
+		{time.Second, "", ""},
+	}
+	for i, test := range tests {
+		v := durationFormatter(test.unit)(test.value)
+		if v != test.expected {
+			t.Errorf("%d: %s %q: got %q want %q", i, test.unit, test.value, v, test.expected)
+		}
+	}
+}
+
+func TestSetColumnDuration(t *testing.T) {
+	csvData := "Benchmark,Elapsed\nParse,8040\nWrite,0.35\nNap,forever\n"
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+	calvin.SetColumnDuration("Elapsed", time.Second)
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Benchmark|Elapsed  \n---|---  \nParse|2h14m  \nWrite|350ms  \nNap|forever  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+
+	calvin = NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+	calvin.SetColumnDuration("Latency", time.Millisecond)
+	err = calvin.MDTable()
+	if err == nil || err.Error() != `duration: unknown column "Latency"` {
+		t.Errorf("got error %v want an unknown column error", err)
+	}
+}
+
+func TestFieldFormatDuration(t *testing.T) {
+	csvData := "Benchmark,Elapsed\nParse,8040\n"
+	tests := []struct {
+		formats  []FieldFormat
+		expected string
+		err      string
+	}{
+		{[]FieldFormat{{}, {Duration: "s"}}, "Benchmark|Elapsed  \n---|---  \nParse|2h14m  \n", ""},
+		{[]FieldFormat{{Column: "Elapsed", Duration: "ms"}}, "Benchmark|Elapsed  \n---|---  \nParse|8.04s  \n", ""},
+		{[]FieldFormat{{Column: "Elapsed", Duration: "days"}}, "", `field 1: duration unit "days": expected ns, us, ms, s, m, or h`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		err := calvin.SetFieldFormats(test.formats)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
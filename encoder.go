@@ -0,0 +1,180 @@
+package csv2md
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrHeaderAfterRecords occurs when an Encoder's header is written after
+// records have been written.
+var ErrHeaderAfterRecords = errors.New("the header must be written before any records")
+
+// Encoder writes GitHub Flavored Markdown tables from records that are
+// generated programmatically; it is the table equivalent of csv.Writer.
+// The records are processed the same way as MDTable processes CSV-encoded
+// records: styling, computed columns, filtering, value maps, grouping, etc.
+// Since the records are not buffered, the settings that require all of the
+// records, e.g. SortGroups, the order of SplitBy's tables, Metadata,
+// Transpose, or auto alignment, have no effect and auto aligned fields are
+// unjustified.
+//
+// Writes are buffered; Flush must be called once all of the table's
+// records have been written.
+type Encoder struct {
+	t       *Transmogrifier
+	w       *bufio.Writer
+	r       Renderer
+	started bool
+	records bool
+	err     error
+}
+
+// NewEncoder returns an Encoder that writes to w.  The options are applied,
+// in order, to the Encoder's Transmogrifier.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	bw := bufio.NewWriter(w)
+	// there is no CSV-encoded data; anything that samples it gets EOF
+	t := NewTransmogrifier(strings.NewReader(""), bw)
+	for _, opt := range opts {
+		opt(t)
+	}
+	return &Encoder{t: t, w: bw}
+}
+
+// WriteHeader writes the header record; it must be written before any of
+// the records.  If the Encoder has field names, they are used instead of
+// the names, the same as with a header record.
+func (e *Encoder) WriteHeader(names []string) error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.records {
+		e.err = ErrHeaderAfterRecords
+		return e.err
+	}
+	header := append([]string(nil), names...)
+	if len(e.t.fieldNames) > 0 {
+		header = append([]string(nil), e.t.fieldNames...)
+	}
+	e.t.row++
+	e.err = e.t.sanitize(header, e.t.row)
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.start(header)
+	return e.err
+}
+
+// WriteRecord writes a record.  The fields are not modified.
+func (e *Encoder) WriteRecord(fields []string) error {
+	if e.err != nil {
+		return e.err
+	}
+	if !e.started {
+		// without a header record, any field names are the header;
+		// otherwise a GFM table's names are generated
+		header, err := e.t.fieldNamesHeader()
+		if err == nil {
+			if header == nil && e.t.isGFM() {
+				header = e.t.fieldNamesFor(len(fields))
+			}
+			err = e.start(header)
+		}
+		if err != nil {
+			e.err = err
+			return err
+		}
+	}
+	e.records = true
+	record := append([]string(nil), fields...)
+	e.t.row++
+	if e.t.computedFrom != nil {
+		record, e.err = e.t.compute(record, e.t.row)
+		if e.err != nil {
+			return e.err
+		}
+	}
+	if e.t.filter != nil && !e.t.match(record, e.t.row) {
+		return nil
+	}
+	e.err = e.t.sanitize(record, e.t.row)
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.t.writeRow(e.r, record)
+	return e.err
+}
+
+// Flush ends the table and writes any buffered data to the underlying
+// io.Writer.  If the output format requires all of the records, e.g.
+// Pretty, the table is written by Flush.  Anything written after Flush is
+// a new table.
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.started {
+		e.err = e.t.writeFooter(e.r)
+		if e.err != nil {
+			return e.err
+		}
+		e.err = e.r.Close()
+		if e.err != nil {
+			return e.err
+		}
+		e.err = e.t.writeFootnotes()
+		if e.err != nil {
+			return e.err
+		}
+		e.started = false
+		e.records = false
+		e.t.row = 0
+	}
+	e.err = e.w.Flush()
+	if e.err == nil {
+		e.t.reportProgress()
+	}
+	return e.err
+}
+
+// Error returns the first error that occurred while writing, if any.
+func (e *Encoder) Error() error {
+	return e.err
+}
+
+// start prepares the Transmogrifier and writes the header, if the header
+// is not nil.
+func (e *Encoder) start(header []string) error {
+	e.started = true
+	e.t.header = header
+	var err error
+	if len(e.t.computed) > 0 {
+		header, err = e.t.prepareComputed(header)
+		if err != nil {
+			return err
+		}
+	}
+	err = e.t.prepareFilter()
+	if err != nil {
+		return err
+	}
+	err = e.t.prepare(header)
+	if err != nil {
+		return err
+	}
+	err = e.t.writeCaption()
+	if err != nil {
+		return err
+	}
+	e.r = e.t.renderer()
+	if header == nil {
+		return nil
+	}
+	names, err := e.t.renameFields(header)
+	if err != nil {
+		return err
+	}
+	return e.t.writeHeader(e.r, names)
+}
@@ -0,0 +1,89 @@
+package csv2md
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	tests := []struct {
+		opts     []Option
+		header   []string
+		records  [][]string
+		expected string
+	}{
+		{nil, []string{"Make", "Model"}, [][]string{{"Ford", "Focus"}, {"Kia", ""}}, "Make|Model  \n---|---  \nFord|Focus  \nKia|   \n"},
+		{[]Option{WithFieldAlignment([]string{"l", "r"}), WithFieldStyle([]string{"b", ""})}, []string{"Make", "Year"}, [][]string{{"Ford", "2012"}}, "Make|Year  \n:--|--:  \n__Ford__|2012  \n"},
+		{[]Option{WithFieldNames([]string{"A", "B"})}, nil, [][]string{{"1", "2"}}, "A|B  \n---|---  \n1|2  \n"},
+		{[]Option{WithFieldNames([]string{"A", "B"})}, []string{"a", "b"}, [][]string{{"1", "2"}}, "A|B  \n---|---  \n1|2  \n"},
+		{[]Option{func(t *Transmogrifier) { t.Pretty = true }}, []string{"Make", "Model"}, [][]string{{"Chevrolet", "Volt"}}, "Make     |Model  \n---------|-----  \nChevrolet|Volt   \n"},
+		{nil, []string{"a"}, nil, "a  \n---  \n"},
+		{nil, nil, nil, ""},
+		{nil, nil, [][]string{{"1", "2"}}, "Column 1|Column 2  \n---|---  \n1|2  \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		enc := NewEncoder(&w, test.opts...)
+		if test.header != nil {
+			err := enc.WriteHeader(test.header)
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+				continue
+			}
+		}
+		for _, record := range test.records {
+			err := enc.WriteRecord(record)
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+			}
+		}
+		err := enc.Flush()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestEncoderDoesNotModifyRecords(t *testing.T) {
+	var w bytes.Buffer
+	enc := NewEncoder(&w)
+	enc.t.SetColumnValueMap("Status", map[string]string{"0": "open"}, true)
+	err := enc.WriteHeader([]string{"ID", "Status"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	record := []string{"1", "0"}
+	err = enc.WriteRecord(record)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(record, []string{"1", "0"}) {
+		t.Errorf("record was modified: %q", record)
+	}
+	enc.Flush()
+	expected := "ID|Status  \n---|---  \n1|open  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
+
+func TestEncoderHeaderAfterRecords(t *testing.T) {
+	var w bytes.Buffer
+	enc := NewEncoder(&w)
+	err := enc.WriteRecord([]string{"1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = enc.WriteHeader([]string{"a"})
+	if err != ErrHeaderAfterRecords {
+		t.Errorf("got %v want %v", err, ErrHeaderAfterRecords)
+	}
+	if enc.Error() != ErrHeaderAfterRecords {
+		t.Errorf("got %v want %v", enc.Error(), ErrHeaderAfterRecords)
+	}
+}
@@ -0,0 +1,184 @@
+package csv2md
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is the character encoding of the CSV-encoded data.
+type Encoding int
+
+const (
+	// UTF8 is UTF-8 encoded data.  If the data starts with a UTF-16 byte
+	// order mark, it is decoded as UTF-16 instead.
+	UTF8 Encoding = iota
+	// UTF16LE is little endian UTF-16 encoded data.
+	UTF16LE
+	// UTF16BE is big endian UTF-16 encoded data.
+	UTF16BE
+	// Latin1 is ISO-8859-1 encoded data.
+	Latin1
+	// Windows1252 is Windows-1252, the Windows Western European code
+	// page, encoded data.
+	Windows1252
+)
+
+// ParseEncoding returns the Encoding for the value: utf-8, utf-16le,
+// utf-16be, latin1, or windows-1252.  The values are not case sensitive
+// and the common aliases, e.g. iso-8859-1 or cp1252, are accepted.  An
+// empty value is utf-8.
+func ParseEncoding(s string) (Encoding, error) {
+	switch strings.TrimSpace(strings.ToLower(s)) {
+	case "", "utf-8", "utf8":
+		return UTF8, nil
+	case "utf-16le", "utf16le":
+		return UTF16LE, nil
+	case "utf-16be", "utf16be":
+		return UTF16BE, nil
+	case "latin1", "latin-1", "iso-8859-1", "iso8859-1":
+		return Latin1, nil
+	case "windows-1252", "windows1252", "cp1252":
+		return Windows1252, nil
+	}
+	return UTF8, fmt.Errorf("unknown encoding %q", s)
+}
+
+// the byte order marks
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// decoder returns a reader that decodes r from the encoding to UTF-8.  A
+// byte order mark at the start of r is removed; if the encoding is UTF8,
+// a UTF-16 byte order mark selects the UTF-16 encoding it marks.
+func decoder(r io.Reader, enc Encoding) *bufio.Reader {
+	br := bufio.NewReader(r)
+	b, _ := br.Peek(len(utf8BOM))
+	switch {
+	case enc == UTF8 && bytes.HasPrefix(b, utf8BOM):
+		br.Discard(len(utf8BOM))
+	case (enc == UTF8 || enc == UTF16LE) && bytes.HasPrefix(b, utf16LEBOM):
+		br.Discard(len(utf16LEBOM))
+		enc = UTF16LE
+	case (enc == UTF8 || enc == UTF16BE) && bytes.HasPrefix(b, utf16BEBOM):
+		br.Discard(len(utf16BEBOM))
+		enc = UTF16BE
+	}
+	switch enc {
+	case UTF16LE, UTF16BE:
+		return bufio.NewReader(&utf16Reader{r: br, bigEndian: enc == UTF16BE})
+	case Latin1:
+		return bufio.NewReader(&charmapReader{r: br})
+	case Windows1252:
+		return bufio.NewReader(&charmapReader{r: br, high: &windows1252})
+	}
+	return br
+}
+
+// utf16Reader decodes UTF-16 encoded data to UTF-8.  Invalid surrogates
+// and a trailing odd byte are decoded as the replacement character.
+type utf16Reader struct {
+	r         *bufio.Reader
+	bigEndian bool
+	buf       []byte // decoded bytes that have not been read yet
+	err       error
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	for len(u.buf) < len(p) && u.err == nil {
+		r, err := u.unit()
+		if err != nil {
+			u.err = err
+			break
+		}
+		if utf16.IsSurrogate(r) {
+			r2, err := u.unit()
+			if err != nil {
+				u.err = err
+			}
+			r = utf16.DecodeRune(r, r2)
+			if r == utf8.RuneError && err == nil && !utf16.IsSurrogate(r2) {
+				// the second unit is a character of its own
+				u.buf = utf8.AppendRune(u.buf, r)
+				r = r2
+			}
+		}
+		u.buf = utf8.AppendRune(u.buf, r)
+		// return what has been decoded instead of waiting for more data
+		if u.r.Buffered() < 2 {
+			break
+		}
+	}
+	if len(u.buf) == 0 {
+		return 0, u.err
+	}
+	n := copy(p, u.buf)
+	u.buf = u.buf[n:]
+	return n, nil
+}
+
+// unit reads a UTF-16 code unit.
+func (u *utf16Reader) unit() (rune, error) {
+	var b [2]byte
+	n, err := io.ReadFull(u.r, b[:])
+	if n == 1 {
+		return utf8.RuneError, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if u.bigEndian {
+		return rune(b[0])<<8 | rune(b[1]), nil
+	}
+	return rune(b[1])<<8 | rune(b[0]), nil
+}
+
+// charmapReader decodes single byte encoded data to UTF-8.  Bytes below
+// 0x80 are ASCII and bytes from 0xA0 are their Latin-1 code points; if
+// high is not nil, it maps the bytes from 0x80 to 0x9F, otherwise they are
+// Latin-1's C1 control characters.
+type charmapReader struct {
+	r    *bufio.Reader
+	high *[32]rune
+	buf  []byte // decoded bytes that have not been read yet
+	err  error
+}
+
+func (c *charmapReader) Read(p []byte) (int, error) {
+	for len(c.buf) < len(p) && c.err == nil {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			c.err = err
+			break
+		}
+		r := rune(b)
+		if c.high != nil && b >= 0x80 && b < 0xA0 {
+			r = c.high[b-0x80]
+		}
+		c.buf = utf8.AppendRune(c.buf, r)
+		// return what has been decoded instead of waiting for more data
+		if c.r.Buffered() == 0 {
+			break
+		}
+	}
+	if len(c.buf) == 0 {
+		return 0, c.err
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+// windows1252 are the characters of the Windows-1252 bytes from 0x80 to
+// 0x9F; the undefined bytes are the replacement character.
+var windows1252 = [32]rune{
+	'€', utf8.RuneError, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', utf8.RuneError, 'Ž', utf8.RuneError,
+	utf8.RuneError, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', utf8.RuneError, 'ž', 'Ÿ',
+}
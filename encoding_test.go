@@ -0,0 +1,74 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+	"testing/iotest"
+)
+
+func TestEncoding(t *testing.T) {
+	expected := "Name|City  \n---|---  \nJosé|Zürich  \n"
+	tests := []struct {
+		enc      Encoding
+		data     []byte
+		expected string
+	}{
+		{UTF8, []byte("Name,City\nJosé,Zürich\n"), expected},
+		{UTF8, []byte("\xEF\xBB\xBFName,City\nJosé,Zürich\n"), expected},
+		{UTF8, []byte("\xFF\xFEN\x00a\x00m\x00e\x00,\x00C\x00i\x00t\x00y\x00\n\x00J\x00o\x00s\x00\xE9\x00,\x00Z\x00\xFC\x00r\x00i\x00c\x00h\x00\n\x00"), expected},
+		{UTF16LE, []byte("N\x00a\x00m\x00e\x00,\x00C\x00i\x00t\x00y\x00\n\x00J\x00o\x00s\x00\xE9\x00,\x00Z\x00\xFC\x00r\x00i\x00c\x00h\x00\n\x00"), expected},
+		{UTF16BE, []byte("\xFE\xFF\x00N\x00a\x00m\x00e\x00,\x00C\x00i\x00t\x00y\x00\n\x00J\x00o\x00s\x00\xE9\x00,\x00Z\x00\xFC\x00r\x00i\x00c\x00h\x00\n"), expected},
+		// a surrogate pair, an unpaired surrogate, and a trailing odd byte
+		{UTF16LE, []byte("a\x00\n\x00\x3D\xD8\x00\xDE\x00\xD8b\x00x"), "a  \n---  \n😀�b�  \n"},
+		{Latin1, []byte("Name,City\nJos\xE9,Z\xFCrich\n"), expected},
+		{Windows1252, []byte("Name,City\n\x93Jos\xE9\x94,\x80 Z\xFCrich\n"), "Name|City  \n---|---  \n“José”|€ Zürich  \n"},
+		{Latin1, []byte("\xEF\xBB\xBFa\n"), "ï»¿a  \n---  \n"},
+	}
+	for i, test := range tests {
+		for _, oneByte := range []bool{false, true} {
+			r := bytes.NewReader(test.data)
+			var w bytes.Buffer
+			calvin := NewTransmogrifier(r, &w)
+			if oneByte {
+				calvin = NewTransmogrifier(iotest.OneByteReader(r), &w)
+			}
+			calvin.Encoding = test.enc
+			err := calvin.MDTable()
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+				continue
+			}
+			if w.String() != test.expected {
+				t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+			}
+		}
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected Encoding
+		err      string
+	}{
+		{"", UTF8, ""},
+		{"UTF-16LE", UTF16LE, ""},
+		{"utf-16be", UTF16BE, ""},
+		{"ISO-8859-1", Latin1, ""},
+		{"cp1252", Windows1252, ""},
+		{"ebcdic", UTF8, `unknown encoding "ebcdic"`},
+	}
+	for i, test := range tests {
+		enc, err := ParseEncoding(test.s)
+		var s string
+		if err != nil {
+			s = err.Error()
+		}
+		if s != test.err {
+			t.Errorf("%d: got error %q want %q", i, s, test.err)
+		}
+		if enc != test.expected {
+			t.Errorf("%d: got %d want %d", i, enc, test.expected)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package csv2md
+
+import "strings"
+
+// DefaultLineBreak is what the line breaks in the values are replaced with,
+// unless LineBreak is set.
+const DefaultLineBreak = "<br>"
+
+// markdownMeta are the characters that are escaped: | breaks a GFM table's
+// structure and the rest would otherwise be interpreted as inline markup.
+const markdownMeta = "|*_`~"
+
+// escape returns the value with its line breaks replaced by the LineBreak
+// and its Markdown metacharacters backslash escaped, if EscapeMarkdown is
+// true.  A backslash is only escaped when it would otherwise escape the
+// character that follows it, which includes the cell's closing | and the
+// LineBreak's <.  The value's HTML is handled first; see CellHTML.
+func (t *Transmogrifier) escape(v string) string {
+	if t.CellHTML != DefaultHTML {
+		v = t.markdownHTML(v)
+	}
+	if !t.EscapeMarkdown {
+		return v
+	}
+	v = t.breakLines(v)
+	if !strings.ContainsAny(v, markdownMeta+`\`) {
+		return v
+	}
+	var b strings.Builder
+	b.Grow(len(v) + escapes(v))
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if escaped(v, i) {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// breakLines returns the value with its line breaks, \r\n, \n, or \r,
+// replaced by the LineBreak.
+func (t *Transmogrifier) breakLines(v string) string {
+	if !strings.ContainsAny(v, "\r\n") {
+		return v
+	}
+	br := t.LineBreak
+	if br == "" {
+		br = DefaultLineBreak
+	}
+	return strings.NewReplacer("\r\n", br, "\n", br, "\r", br).Replace(v)
+}
+
+// escaped returns whether the i'th byte of v is escaped.
+func escaped(v string, i int) bool {
+	c := v[i]
+	return strings.IndexByte(markdownMeta, c) >= 0 || (c == '\\' && (i == len(v)-1 || isASCIIPunct(v[i+1])))
+}
+
+// escapes returns the number of bytes of v that are escaped, so that the
+// escaped value is allocated once.
+func escapes(v string) int {
+	var n int
+	for i := 0; i < len(v); i++ {
+		if escaped(v, i) {
+			n++
+		}
+	}
+	return n
+}
+
+// isASCIIPunct returns whether the byte is ASCII punctuation; in Markdown,
+// any ASCII punctuation can be backslash escaped.
+func isASCIIPunct(c byte) bool {
+	return c >= '!' && c <= '/' || c >= ':' && c <= '@' || c >= '[' && c <= '`' || c >= '{' && c <= '~'
+}
@@ -0,0 +1,63 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"plain text", "plain text"},
+		{"a|b", `a\|b`},
+		{"2*3*4", `2\*3\*4`},
+		{"snake_case_name", `snake\_case\_name`},
+		{"`code`", "\\`code\\`"},
+		{"~~gone~~", `\~\~gone\~\~`},
+		{`C:\temp`, `C:\temp`},
+		{`a\|b`, `a\\\|b`},
+		{`trailing\`, `trailing\\`},
+		{`\\x00`, `\\\x00`},
+		{"one\ntwo", "one<br>two"},
+		{"one\r\ntwo\rthree", "one<br>two<br>three"},
+		{"a_b\n", `a\_b<br>`},
+		{"end\\\n", `end\\<br>`},
+	}
+	calvin := NewTransmogrifier(nil, nil)
+	for i, test := range tests {
+		v := calvin.escape(test.value)
+		if v != test.expected {
+			t.Errorf("%d: got %q want %q", i, v, test.expected)
+		}
+	}
+}
+
+func TestMDTableEscapeMarkdown(t *testing.T) {
+	csvData := []byte("Name|Alias,Expr\nsnake_case,2*3|4\n`tick`,~x~\n")
+	tests := []struct {
+		escape   bool
+		style    []string
+		expected string
+	}{
+		{true, nil, "Name\\|Alias|Expr  \n---|---  \nsnake\\_case|2\\*3\\|4  \n\\`tick\\`|\\~x\\~  \n"},
+		{true, []string{"b", "i"}, "Name\\|Alias|Expr  \n---|---  \n__snake\\_case__|_2\\*3\\|4_  \n__\\`tick\\`__|_\\~x\\~_  \n"},
+		{false, nil, "Name|Alias|Expr  \n---|---  \nsnake_case|2*3|4  \n`tick`|~x~  \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.EscapeMarkdown = test.escape
+		calvin.SetFieldStyle(test.style)
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package csv2md
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExcludeColumns omits the columns from the table.  A column is either a
+// field name, a field's position, starting at 1, or a range of positions,
+// e.g. "3-5"; a name is used before a position, so a field named "2" is
+// excluded by its name.  The columns are resolved against the header,
+// including the computed columns, so a computed column can be derived
+// from an excluded column; the settings that refer to columns, e.g.
+// SetFilter or GroupBy, can still use the excluded columns.  Every name
+// must be the name of a field; otherwise MDTable returns an
+// UnknownColumnError.  Each call adds to the excluded columns.
+func (t *Transmogrifier) ExcludeColumns(columns ...string) {
+	t.excluded = append(t.excluded, columns...)
+}
+
+// prepareExcluded hides the excluded columns.
+func (t *Transmogrifier) prepareExcluded(header []string) error {
+	for _, column := range t.excluded {
+		first, last, err := excludedRange(header, column)
+		if err != nil {
+			return err
+		}
+		if t.hidden == nil {
+			t.hidden = make(map[int]bool)
+		}
+		for i := first; i <= last; i++ {
+			t.hidden[i] = true
+		}
+	}
+	return nil
+}
+
+// excludedRange returns the indexes of the first and last fields of the
+// excluded column, which is a field name, a position, or a range of
+// positions.
+func excludedRange(header []string, column string) (first, last int, err error) {
+	if i := columnIndex(header, column); i >= 0 {
+		return i, i, nil
+	}
+	v := strings.TrimSpace(column)
+	lo, hi := v, v
+	if i := strings.Index(v, "-"); i > 0 {
+		lo, hi = strings.TrimSpace(v[:i]), strings.TrimSpace(v[i+1:])
+	}
+	first, err = strconv.Atoi(lo)
+	if err != nil {
+		return 0, 0, UnknownColumnError{Name: column, operation: "exclude"}
+	}
+	last, err = strconv.Atoi(hi)
+	if err != nil {
+		return 0, 0, UnknownColumnError{Name: column, operation: "exclude"}
+	}
+	if first < 1 || last < first || last > len(header) {
+		return 0, 0, fmt.Errorf("exclude %q: expected a range of 1 to %d", column, len(header))
+	}
+	return first - 1, last - 1, nil
+}
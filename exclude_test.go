@@ -0,0 +1,67 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExcludeColumns(t *testing.T) {
+	csvData := "id,Name,2,Qty,json\n1,Ann,x,3,{}\n2,Bob,y,4,{}\n"
+	tests := []struct {
+		columns  []string
+		expected string
+		err      string
+	}{
+		{[]string{"id", "json"}, "Name|2|Qty  \n---|---|---  \nAnn|x|3  \nBob|y|4  \n", ""},
+		{[]string{"2"}, "id|Name|Qty|json  \n---|---|---|---  \n1|Ann|3|{}  \n2|Bob|4|{}  \n", ""},
+		{[]string{"2-4"}, "id|json  \n---|---  \n1|{}  \n2|{}  \n", ""},
+		{[]string{"5", " 1 - 1 "}, "Name|2|Qty  \n---|---|---  \nAnn|x|3  \nBob|y|4  \n", ""},
+		{[]string{"Total"}, "", `exclude: unknown column "Total"`},
+		{[]string{"4-9"}, "", `exclude "4-9": expected a range of 1 to 5`},
+		{[]string{"0"}, "", `exclude "0": expected a range of 1 to 5`},
+		{[]string{"3-2"}, "", `exclude "3-2": expected a range of 1 to 5`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.ExcludeColumns(test.columns...)
+		err := calvin.MDTable()
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestExcludeComputedSource(t *testing.T) {
+	csvData := "Name,Qty,Price\nAnn,2,3\nBob,4,5\n"
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+	err := calvin.ParseComputedColumn("Total=Qty*Price")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.SetFilter("Price > 3")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	calvin.ExcludeColumns("Qty", "Price")
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Name|Total  \n---|---  \nBob|20  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
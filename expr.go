@@ -0,0 +1,500 @@
+package csv2md
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ExprError occurs when an expression cannot be parsed.
+type ExprError struct {
+	Expr string
+	Pos  int
+	Msg  string
+}
+
+func (e ExprError) Error() string {
+	return fmt.Sprintf("expression %q: position %d: %s", e.Expr, e.Pos, e.Msg)
+}
+
+// value is the result of evaluating an expression.
+type value struct {
+	s     string
+	n     float64
+	isNum bool
+	// decimals is the number's decimal places; it is -1 if they aren't
+	// known, e.g. 1e-3.
+	decimals int
+}
+
+func stringValue(s string) value {
+	v := value{s: s}
+	s = strings.TrimSpace(s)
+	n, err := strconv.ParseFloat(s, 64)
+	if err == nil {
+		v.n = n
+		v.isNum = true
+		v.decimals = decimalPlaces(s)
+	}
+	return v
+}
+
+// numberValue returns the number rounded to its decimal places, so that
+// the floating point error of a calculation, e.g. 3 * 1.1, isn't shown.
+func numberValue(n float64, decimals int) value {
+	return value{s: strconv.FormatFloat(n, 'f', decimals, 64), n: n, isNum: true, decimals: decimals}
+}
+
+// decimalPlaces returns the number's decimal places; -1 if the number has
+// an exponent or isn't decimal.
+func decimalPlaces(s string) int {
+	if strings.ContainsAny(s, "eExXnN") {
+		return -1
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+func boolValue(b bool) value {
+	if b {
+		return value{s: "true", n: 1}
+	}
+	return value{s: "false"}
+}
+
+// truth returns whether the value is true: non-zero numbers and strings
+// other than "" and "false" are true.
+func (v value) truth() bool {
+	if v.isNum {
+		return v.n != 0
+	}
+	return v.s != "" && v.s != "false"
+}
+
+// exprEnv returns the value of the named column; the bool is false if
+// there is no such column.
+type exprEnv func(name string) (string, bool)
+
+type node interface {
+	eval(env exprEnv) (value, error)
+}
+
+type literal struct {
+	v value
+}
+
+func (l literal) eval(env exprEnv) (value, error) {
+	return l.v, nil
+}
+
+type column struct {
+	name string
+}
+
+func (c column) eval(env exprEnv) (value, error) {
+	s, ok := env(c.name)
+	if !ok {
+		return value{}, fmt.Errorf("unknown column %q", c.name)
+	}
+	return stringValue(s), nil
+}
+
+type negation struct {
+	n node
+}
+
+func (n negation) eval(env exprEnv) (value, error) {
+	v, err := n.n.eval(env)
+	if err != nil {
+		return value{}, err
+	}
+	if !v.isNum {
+		return value{}, fmt.Errorf("cannot negate %q: not a number", v.s)
+	}
+	return numberValue(-v.n, v.decimals), nil
+}
+
+type not struct {
+	n node
+}
+
+func (n not) eval(env exprEnv) (value, error) {
+	v, err := n.n.eval(env)
+	if err != nil {
+		return value{}, err
+	}
+	return boolValue(!v.truth()), nil
+}
+
+type binary struct {
+	op   string
+	l, r node
+}
+
+func (b binary) eval(env exprEnv) (value, error) {
+	l, err := b.l.eval(env)
+	if err != nil {
+		return value{}, err
+	}
+	// the logical operators only evaluate the right operand if it's needed
+	switch b.op {
+	case "&&":
+		if !l.truth() {
+			return boolValue(false), nil
+		}
+	case "||":
+		if l.truth() {
+			return boolValue(true), nil
+		}
+	}
+	r, err := b.r.eval(env)
+	if err != nil {
+		return value{}, err
+	}
+	switch b.op {
+	case "+", "-", "*", "/":
+		return arithmetic(b.op, l, r)
+	case "&&", "||":
+		return boolValue(r.truth()), nil
+	}
+	return boolValue(compare(b.op, l, r)), nil
+}
+
+// arithmetic applies the arithmetic operator to the values.  If either
+// value isn't a number, + concatenates them; any other operator is an
+// error.  The result is rounded to the operands' decimal places, like the
+// aggregates of a footer: a sum or difference has the most decimal places
+// of the operands, a product has the decimal places of both, and a
+// quotient has two more than the most, like an average.
+func arithmetic(op string, l, r value) (value, error) {
+	if !l.isNum || !r.isNum {
+		if op == "+" {
+			return stringValue(l.s + r.s), nil
+		}
+		v := l
+		if l.isNum {
+			v = r
+		}
+		return value{}, fmt.Errorf("%q %s %q: %q is not a number", l.s, op, r.s, v.s)
+	}
+	d := l.decimals
+	if r.decimals > d {
+		d = r.decimals
+	}
+	if l.decimals < 0 || r.decimals < 0 {
+		d = -1
+	}
+	switch op {
+	case "+":
+		return numberValue(l.n+r.n, d), nil
+	case "-":
+		return numberValue(l.n-r.n, d), nil
+	case "*":
+		if d >= 0 {
+			d = l.decimals + r.decimals
+		}
+		return numberValue(l.n*r.n, d), nil
+	}
+	if r.n == 0 {
+		return value{}, fmt.Errorf("%q / %q: division by zero", l.s, r.s)
+	}
+	if d >= 0 {
+		d += 2
+	}
+	return numberValue(l.n/r.n, d), nil
+}
+
+// compare compares the values; numbers are compared numerically and
+// anything else is compared as strings.  A number is never ordered before
+// or after a string.
+func compare(op string, l, r value) bool {
+	if l.isNum && r.isNum {
+		switch op {
+		case "==", "=":
+			return l.n == r.n
+		case "!=":
+			return l.n != r.n
+		case "<":
+			return l.n < r.n
+		case "<=":
+			return l.n <= r.n
+		case ">":
+			return l.n > r.n
+		case ">=":
+			return l.n >= r.n
+		}
+		return false
+	}
+	switch op {
+	case "==", "=":
+		return l.s == r.s
+	case "!=":
+		return l.s != r.s
+	}
+	if l.isNum || r.isNum {
+		return false
+	}
+	switch op {
+	case "<":
+		return l.s < r.s
+	case "<=":
+		return l.s <= r.s
+	case ">":
+		return l.s > r.s
+	case ">=":
+		return l.s >= r.s
+	}
+	return false
+}
+
+// precedence of the binary operators; higher binds tighter.
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "=": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5,
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	s    string
+	pos  int
+}
+
+// operators, longest first so that "<=" is matched before "<".
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "=", "!", "+", "-", "*", "/"}
+
+func isOpChar(r rune) bool {
+	return strings.ContainsRune("=!<>&|+-*/()\"'`", r) || unicode.IsSpace(r)
+}
+
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(s) {
+		c := rune(s[i])
+		if unicode.IsSpace(c) {
+			i++
+			continue
+		}
+		switch c {
+		case '(':
+			toks = append(toks, token{kind: tokLParen, s: "(", pos: i})
+			i++
+			continue
+		case ')':
+			toks = append(toks, token{kind: tokRParen, s: ")", pos: i})
+			i++
+			continue
+		case '"', '\'', '`':
+			j := strings.IndexRune(s[i+1:], c)
+			if j < 0 {
+				return nil, ExprError{Expr: s, Pos: i, Msg: "unterminated quote"}
+			}
+			kind := tokString
+			if c == '`' {
+				kind = tokIdent
+			}
+			toks = append(toks, token{kind: kind, s: s[i+1 : i+1+j], pos: i})
+			i += j + 2
+			continue
+		}
+		var op string
+		for _, o := range operators {
+			if strings.HasPrefix(s[i:], o) {
+				op = o
+				break
+			}
+		}
+		if op != "" {
+			toks = append(toks, token{kind: tokOp, s: op, pos: i})
+			i += len(op)
+			continue
+		}
+		// anything else is a word: either a number or a column name
+		j := i
+		for j < len(s) && !isOpChar(rune(s[j])) {
+			j++
+		}
+		if j == i {
+			return nil, ExprError{Expr: s, Pos: i, Msg: fmt.Sprintf("unexpected %q", s[i])}
+		}
+		word := s[i:j]
+		kind := tokIdent
+		if _, err := strconv.ParseFloat(word, 64); err == nil {
+			kind = tokNumber
+		}
+		toks = append(toks, token{kind: kind, s: word, pos: i})
+		i = j
+	}
+	toks = append(toks, token{kind: tokEOF, pos: len(s)})
+	return toks, nil
+}
+
+type parser struct {
+	expr string
+	toks []token
+	pos  int
+}
+
+// parseExpr parses the expression.  Expressions are a small language that
+// is used to evaluate a record's values, e.g. for conditional styling,
+// computed columns, and filtering.  The operands are column names, numbers,
+// and strings, which are quoted, e.g. "cancelled" in Status == "cancelled";
+// an unquoted operand that isn't a column name is an error, see
+// checkExprColumns.  Column names that contain
+// spaces or operator characters are quoted using backticks, e.g.
+// `First Name` == "Ann".  Values are compared numerically when both are
+// numbers and as strings otherwise.  Adding values that aren't both
+// numbers concatenates them; the other arithmetic operators require
+// numbers.  The operators, from the loosest binding to the tightest, are
+// ||, &&, the comparisons == or =, !=, <, <=, >, and >=, + and -, * and /,
+// and the unary - and !.
+func parseExpr(s string) (node, error) {
+	toks, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{expr: s, toks: toks}
+	n, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.peek().s)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.toks[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return ExprError{Expr: p.expr, Pos: p.peek().pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseBinary parses binary operations whose operators have at least the
+// minimum precedence.
+func (p *parser) parseBinary(min int) (node, error) {
+	l, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		prec, ok := precedence[tok.s]
+		if tok.kind != tokOp || !ok || prec < min {
+			return l, nil
+		}
+		p.next()
+		r, err := p.parseBinary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op: tok.s, l: l, r: r}
+	}
+}
+
+func (p *parser) parseOperand() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokIdent:
+		return column{name: tok.s}, nil
+	case tokNumber, tokString:
+		return literal{v: stringValue(tok.s)}, nil
+	case tokOp:
+		if tok.s != "-" && tok.s != "!" {
+			break
+		}
+		n, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if tok.s == "!" {
+			return not{n: n}, nil
+		}
+		return negation{n: n}, nil
+	case tokLParen:
+		n, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.errorf("missing )")
+		}
+		p.next()
+		return n, nil
+	case tokEOF:
+		return nil, ExprError{Expr: p.expr, Pos: tok.pos, Msg: "unexpected end of expression"}
+	}
+	return nil, ExprError{Expr: p.expr, Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.s)}
+}
+
+// exprColumns returns the names of the columns referenced by the
+// expression, in the order they appear.
+func exprColumns(n node) []string {
+	switch n := n.(type) {
+	case column:
+		return []string{n.name}
+	case binary:
+		return append(exprColumns(n.l), exprColumns(n.r)...)
+	case negation:
+		return exprColumns(n.n)
+	case not:
+		return exprColumns(n.n)
+	}
+	return nil
+}
+
+// checkExprColumns returns an UnknownColumnError for the first column that
+// the expression references and that isn't in the header, so that e.g. a
+// misspelled column name, or an unquoted string, isn't silently evaluated
+// against every record.
+func checkExprColumns(n node, header []string, operation string) error {
+	for _, name := range exprColumns(n) {
+		if columnIndex(header, name) < 0 {
+			return UnknownColumnError{Name: name, operation: operation}
+		}
+	}
+	return nil
+}
+
+// recordEnv returns an exprEnv that looks up the named column's value in
+// the record.
+func recordEnv(header, record []string) exprEnv {
+	return func(name string) (string, bool) {
+		i := columnIndex(header, name)
+		if i < 0 {
+			return "", false
+		}
+		if i >= len(record) {
+			return "", true
+		}
+		return record[i], true
+	}
+}
@@ -0,0 +1,123 @@
+package csv2md
+
+import "testing"
+
+func TestParseExpr(t *testing.T) {
+	header := []string{"Amount", "Status", "First Name"}
+	record := []string{"-12.5", "cancelled", "Ann"}
+	tests := []struct {
+		expr     string
+		expected bool
+		err      string
+	}{
+		{"Amount<0", true, ""},
+		{"Amount < -20", false, ""},
+		{"Amount>=-12.5", true, ""},
+		{"Amount == -12.50", true, ""},
+		{"Status == \"cancelled\"", true, ""},
+		{"Status = \"cancelled\"", true, ""},
+		{"Status != 'cancelled'", false, ""},
+		{"`First Name` == \"Ann\"", true, ""},
+		{"Status < \"open\"", true, ""},
+		{"Status < 1", false, ""},
+		{"(Amount < 0)", true, ""},
+		{"Status", true, ""},
+		{"Amount <", false, `expression "Amount <": position 8: unexpected end of expression`},
+		{"Status == \"x", false, `expression "Status == \"x": position 10: unterminated quote`},
+		{"(Amount < 0", false, `expression "(Amount < 0": position 11: missing )`},
+		{"Amount 0", false, `expression "Amount 0": position 7: unexpected "0"`},
+		{"Amount * 2 == -25", true, ""},
+		{"Amount + 2 * 3 < -6", true, ""},
+		{"(Amount + 2) * 3 < -31", true, ""},
+		{"-Amount > 12", true, ""},
+		{"Status + '!' == 'cancelled!'", true, ""},
+		{"Amount *", false, `expression "Amount *": position 8: unexpected end of expression`},
+		{"Amount < 0 && Status == \"cancelled\"", true, ""},
+		{"Amount > 0 && Status == \"cancelled\"", false, ""},
+		{"Amount > 0 || Status == \"cancelled\"", true, ""},
+		{"Amount > 0 || Status == \"open\" && Amount < 0", false, ""},
+		{"!(Amount > 0)", true, ""},
+		{"!Status", false, ""},
+		{"Amount > 0 && Status * 2", false, ""},
+		{"Amount < 0 || Status * 2", true, ""},
+		{"Amount & 0", false, `expression "Amount & 0": position 7: unexpected '&'`},
+	}
+	for i, test := range tests {
+		n, err := parseExpr(test.expr)
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		r, err := n.eval(recordEnv(header, record))
+		if err != nil {
+			t.Errorf("%d: %s: unexpected error: %s", i, test.expr, err)
+			continue
+		}
+		v := r.truth()
+		if v != test.expected {
+			t.Errorf("%d: %s: got %t want %t", i, test.expr, v, test.expected)
+		}
+	}
+}
+
+func TestExprColumns(t *testing.T) {
+	n, err := parseExpr("(Amount < 0) == `Is Negative`")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cols := exprColumns(n)
+	if len(cols) != 2 || cols[0] != "Amount" || cols[1] != "Is Negative" {
+		t.Errorf("got %v want [Amount Is Negative]", cols)
+	}
+}
+
+func TestEvalExpr(t *testing.T) {
+	header := []string{"Qty", "Price", "First", "Last", "Rate"}
+	record := []string{"3", "2.5", "Ann", "Lee", "1.1"}
+	tests := []struct {
+		expr     string
+		expected string
+		err      string
+	}{
+		{"Qty*Price", "7.5", ""},
+		{"Qty - Price", "0.5", ""},
+		{"Qty / 2", "1.50", ""},
+		{"Qty + Price * 2", "8.0", ""},
+		{"Qty * Rate", "3.3", ""},
+		{"Rate * Rate", "1.21", ""},
+		{"0.1 + 0.2", "0.3", ""},
+		{"-Qty", "-3", ""},
+		{"First + \" \" + Last", "Ann Lee", ""},
+		{"First + Qty", "Ann3", ""},
+		{"First * Qty", "", `"Ann" * "3": "Ann" is not a number`},
+		{"Qty / (Price - 2.5)", "", `"3" / "0.0": division by zero`},
+		{"-First", "", `cannot negate "Ann": not a number`},
+	}
+	for i, test := range tests {
+		n, err := parseExpr(test.expr)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		v, err := n.eval(recordEnv(header, record))
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if v.s != test.expected {
+			t.Errorf("%d: %s: got %q want %q", i, test.expr, v.s, test.expected)
+		}
+	}
+}
@@ -0,0 +1,717 @@
+package csv2md
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldFormat is the format of a field, a column, of the table.  It is the
+// structured equivalent of a column of a format file, see SetFmt, with the
+// field's type and visibility.
+type FieldFormat struct {
+	// Column is the name of the column, in the header, that the format is
+	// for.  If it is empty, the format is for the field at the format's
+	// position; see SetFieldFormats.  The AllColumns format is the
+	// default format of the columns that don't have one.
+	Column string `json:"column,omitempty"`
+	// Name is the field's name, see SetFieldNames.  The name of a Column
+	// format renames the column, see SetFieldNameMap.
+	Name string `json:"name,omitempty"`
+	// Align is the field's alignment, see SetFieldAlignment.
+	Align string `json:"align,omitempty"`
+	// Style is the field's text styling, see SetFieldStyle.
+	Style string `json:"style,omitempty"`
+	// Width is the field's minimum width, see SetFieldWidths.
+	Width int `json:"width,omitempty"`
+	// MaxWidth is the field's maximum width; longer values are truncated,
+	// see SetColumnMaxWidth.
+	MaxWidth int `json:"max_width,omitempty"`
+	// Truncate is how the field's values are truncated: ellipsis, cut, or
+	// footnote, see ParseTruncation.
+	Truncate string `json:"truncate,omitempty"`
+	// Type is the type of the field's values: number, boolean, or text.
+	// It is used instead of the type inferred from the values, e.g. for
+	// auto alignment and the metadata; if it is empty, the type is
+	// inferred.
+	Type string `json:"type,omitempty"`
+	// Hidden fields are not written.  They are still read, so that they
+	// can be filtered on, sorted by, and used by the templates and the
+	// computed columns.
+	Hidden bool `json:"hidden,omitempty"`
+	// Visibility is the field's visibility, visible or hidden; a hidden
+	// field is the same as a Hidden one.  If it is empty, the field is
+	// visible unless it is Hidden.
+	Visibility string `json:"visibility,omitempty"`
+	// Template is the field's cell template, see SetFieldTemplates.
+	Template string `json:"template,omitempty"`
+	// Number is the format of the field's numbers, see ParseNumberFormat.
+	Number string `json:"number,omitempty"`
+	// Time is the format of the field's dates and times, see
+	// ParseTimeFormat.
+	Time string `json:"time,omitempty"`
+	// Bytes are the units of the field's byte sizes, binary or decimal,
+	// see SetColumnByteSize.
+	Bytes string `json:"bytes,omitempty"`
+	// Duration is the unit of the field's durations, see
+	// ParseDurationUnit and SetColumnDuration.
+	Duration string `json:"duration,omitempty"`
+	// Currency is the format of the field's amounts, see
+	// ParseCurrencyFormat.
+	Currency string `json:"currency,omitempty"`
+	// Replace is a regular expression substitution of the field's values,
+	// see ParseReplacement.
+	Replace string `json:"replace,omitempty"`
+}
+
+// fieldFormatSpec is a format spec file's contents.
+type fieldFormatSpec struct {
+	Fields []FieldFormat `json:"fields"`
+}
+
+// columnFormat is the format of a named column, with its parsed template.
+type columnFormat struct {
+	FieldFormat
+	tmpl *cellTemplate
+}
+
+// SetFieldFormats sets the format of each field.  Either every format has
+// a Column or none do.  Without a Column, the formats are by position: the
+// names, alignments, styles, widths, and templates replace those that have
+// been set, unless none of the fields have one; e.g. if no field has a
+// width, the field widths are left as they are.
+//
+// With a Column, the formats are by name, so only the columns that are
+// formatted need to be listed, in any order.  The other columns keep their
+// settings.  The formats are applied once the header has been read; a
+// format whose column is not in the header is a warning, or, in strict
+// mode, an UnknownColumnError.  A format whose Column is AllColumns is the
+// default format: it is applied to every column that doesn't have a
+// format of its own, e.g. to right align all of the columns but the
+// first; it can't have a Name.
+func (t *Transmogrifier) SetFieldFormats(formats []FieldFormat) error {
+	var keyed int
+	for i, f := range formats {
+		err := checkFieldFormat(f)
+		if err != nil {
+			return fmt.Errorf("field %d: %s", i+1, err)
+		}
+		if f.Column != "" {
+			keyed++
+		}
+		if f.Column == AllColumns && f.Name != "" {
+			return fmt.Errorf("field %d: the %s format can't rename the columns", i+1, AllColumns)
+		}
+	}
+	if keyed > 0 && keyed < len(formats) {
+		return fmt.Errorf("field formats: %d of %d formats have a column: either every format has a column or none do", keyed, len(formats))
+	}
+	t.columnFormats = nil
+	t.fieldTypes = nil
+	t.fieldHidden = nil
+	t.fieldMaxWidths = nil
+	t.fieldTruncate = nil
+	if keyed > 0 {
+		for _, f := range formats {
+			cf := columnFormat{FieldFormat: f}
+			if f.Template != "" {
+				var err error
+				cf.tmpl, err = newColumnTemplate(f.Column, f.Template)
+				if err != nil {
+					return err
+				}
+			}
+			t.columnFormats = append(t.columnFormats, cf)
+		}
+		return nil
+	}
+	names := make([]string, len(formats))
+	alignment := make([]string, len(formats))
+	styles := make([]string, len(formats))
+	widths := make([]int, len(formats))
+	templates := make([]string, len(formats))
+	var named, aligned, styled, sized, templated bool
+	for i, f := range formats {
+		names[i] = f.Name
+		named = named || f.Name != ""
+		alignment[i], _ = parseAlignment(f.Align)
+		aligned = aligned || alignment[i] != none
+		styles[i] = parseStyle(f.Style)
+		styled = styled || styles[i] != ""
+		widths[i] = f.Width
+		sized = sized || f.Width > 0
+		templates[i] = f.Template
+		templated = templated || f.Template != ""
+		if f.Type != "" {
+			if t.fieldTypes == nil {
+				t.fieldTypes = make(map[int]columnType)
+			}
+			t.fieldTypes[i], _ = parseColumnType(f.Type)
+		}
+		if f.hidden() {
+			if t.fieldHidden == nil {
+				t.fieldHidden = make([]bool, len(formats))
+			}
+			t.fieldHidden[i] = true
+		}
+		if f.Number != "" {
+			nf, _ := ParseNumberFormat(f.Number)
+			t.setValueFormat(&valueFormat{index: i, kind: "number format", format: t.numberFormatter(nf)})
+		}
+		if f.Time != "" {
+			tf, _ := ParseTimeFormat(f.Time)
+			t.setValueFormat(&valueFormat{index: i, kind: "time format", format: t.timeFormatter(tf)})
+		}
+		if f.Bytes != "" {
+			units, _ := ParseByteUnits(f.Bytes)
+			t.setValueFormat(&valueFormat{index: i, kind: "byte size", format: units.format})
+		}
+		if f.Duration != "" {
+			unit, _ := ParseDurationUnit(f.Duration)
+			t.setValueFormat(&valueFormat{index: i, kind: "duration", format: durationFormatter(unit)})
+		}
+		if f.Currency != "" {
+			cf, _ := ParseCurrencyFormat(f.Currency)
+			vf, _ := t.currencyFormat(cf)
+			vf.index = i
+			t.setValueFormat(vf)
+		}
+		if f.Replace != "" {
+			r, _ := ParseReplacement(f.Replace)
+			t.replacements = append(t.replacements, &replacement{index: i, r: r})
+		}
+		if f.MaxWidth > 0 {
+			if t.fieldMaxWidths == nil {
+				t.fieldMaxWidths = make(map[int]int)
+			}
+			t.fieldMaxWidths[i] = f.MaxWidth
+		}
+		if f.Truncate != "" {
+			if t.fieldTruncate == nil {
+				t.fieldTruncate = make(map[int]Truncation)
+			}
+			t.fieldTruncate[i], _ = ParseTruncation(f.Truncate)
+		}
+	}
+	if templated {
+		err := t.SetFieldTemplates(templates)
+		if err != nil {
+			return err
+		}
+	}
+	if named {
+		t.fieldNames = names
+	}
+	if aligned {
+		t.fieldAlignment = alignment
+	}
+	if styled {
+		t.fieldStyle = styles
+	}
+	if sized {
+		t.SetFieldWidths(widths)
+	}
+	return nil
+}
+
+// checkFieldFormat checks the format's values.
+func checkFieldFormat(f FieldFormat) error {
+	if _, ok := parseAlignment(f.Align); !ok {
+		return fmt.Errorf("unknown alignment %q", f.Align)
+	}
+	if parseStyle(f.Style) == "" && strings.TrimSpace(f.Style) != "" {
+		return fmt.Errorf("unknown style %q", f.Style)
+	}
+	if f.Width < 0 {
+		return fmt.Errorf("width %d: not a valid width", f.Width)
+	}
+	if f.MaxWidth < 0 {
+		return fmt.Errorf("max width %d: not a valid width", f.MaxWidth)
+	}
+	if _, err := ParseTruncation(f.Truncate); err != nil {
+		return err
+	}
+	if _, ok := parseColumnType(f.Type); !ok && f.Type != "" {
+		return fmt.Errorf("unknown type %q", f.Type)
+	}
+	switch strings.ToLower(strings.TrimSpace(f.Visibility)) {
+	case "", "hidden":
+	case "visible":
+		if f.Hidden {
+			return fmt.Errorf("visibility %q: the field is hidden", f.Visibility)
+		}
+	default:
+		return fmt.Errorf("unknown visibility %q", f.Visibility)
+	}
+	if f.Number != "" {
+		if _, err := ParseNumberFormat(f.Number); err != nil {
+			return err
+		}
+	}
+	if f.Time != "" {
+		if _, err := ParseTimeFormat(f.Time); err != nil {
+			return err
+		}
+	}
+	if f.Bytes != "" {
+		if _, err := ParseByteUnits(f.Bytes); err != nil {
+			return err
+		}
+	}
+	if f.Duration != "" {
+		if _, err := ParseDurationUnit(f.Duration); err != nil {
+			return err
+		}
+	}
+	if f.Currency != "" {
+		if _, err := ParseCurrencyFormat(f.Currency); err != nil {
+			return err
+		}
+	}
+	if f.Replace != "" {
+		if _, err := ParseReplacement(f.Replace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hidden returns whether the field is hidden, see Hidden and Visibility.
+func (f FieldFormat) hidden() bool {
+	return f.Hidden || strings.EqualFold(strings.TrimSpace(f.Visibility), "hidden")
+}
+
+// prepareColumnFormats applies the formats of the named columns to their
+// fields.  The AllColumns formats are applied to the fields of the columns
+// that don't have a format.
+func (t *Transmogrifier) prepareColumnFormats(header []string) error {
+	var defaults []columnFormat
+	listed := make(map[int]bool)
+	for _, cf := range t.columnFormats {
+		if cf.Column == AllColumns {
+			defaults = append(defaults, cf)
+			continue
+		}
+		i := columnIndex(header, cf.Column)
+		if i < 0 {
+			if t.Strict {
+				return UnknownColumnError{Name: cf.Column, operation: "format"}
+			}
+			t.warnf("format: unknown column %q", cf.Column)
+			continue
+		}
+		listed[i] = true
+		t.applyColumnFormat(cf, i, header)
+	}
+	for _, cf := range defaults {
+		for i, column := range header {
+			if !listed[i] {
+				t.applyColumnFormat(cf.forColumn(column), i, header)
+			}
+		}
+	}
+	return nil
+}
+
+// forColumn returns the AllColumns format as the named column's format.
+func (cf columnFormat) forColumn(column string) columnFormat {
+	cf.Column = column
+	if cf.tmpl != nil {
+		tmpl := *cf.tmpl
+		tmpl.column = column
+		cf.tmpl = &tmpl
+	}
+	return cf
+}
+
+// applyColumnFormat applies the column's format to its field, the i'th.
+func (t *Transmogrifier) applyColumnFormat(cf columnFormat, i int, header []string) {
+	if cf.Name != "" {
+		if t.fieldNameMap == nil {
+			t.fieldNameMap = make(map[string]string)
+		}
+		t.fieldNameMap[cf.Column] = cf.Name
+	}
+	if a, _ := parseAlignment(cf.Align); a != none {
+		// the other fields are auto aligned if every field would have been
+		fill := none
+		if t.AutoAlign && len(t.fieldAlignment) == 0 {
+			fill = auto
+		}
+		t.fieldAlignment = extend(t.fieldAlignment, len(header), fill)
+		t.fieldAlignment[i] = a
+	}
+	if style := parseStyle(cf.Style); style != "" {
+		t.fieldStyle = extend(t.fieldStyle, len(header), "")
+		t.fieldStyle[i] = style
+	}
+	if cf.Width > 0 {
+		t.SetColumnWidth(cf.Column, cf.Width)
+	}
+	if cf.MaxWidth > 0 {
+		t.SetColumnMaxWidth(cf.Column, cf.MaxWidth)
+	}
+	if cf.Truncate != "" {
+		tr, _ := ParseTruncation(cf.Truncate)
+		t.SetColumnTruncation(cf.Column, tr)
+	}
+	if cf.Type != "" {
+		if t.fieldTypes == nil {
+			t.fieldTypes = make(map[int]columnType)
+		}
+		t.fieldTypes[i], _ = parseColumnType(cf.Type)
+	}
+	if cf.hidden() {
+		for len(t.fieldHidden) <= i {
+			t.fieldHidden = append(t.fieldHidden, false)
+		}
+		t.fieldHidden[i] = true
+	}
+	if cf.tmpl != nil {
+		t.setColumnTemplate(cf.tmpl)
+	}
+	if cf.Number != "" {
+		nf, _ := ParseNumberFormat(cf.Number)
+		t.SetColumnNumberFormat(cf.Column, nf)
+	}
+	if cf.Time != "" {
+		tf, _ := ParseTimeFormat(cf.Time)
+		t.SetColumnTimeFormat(cf.Column, tf)
+	}
+	if cf.Bytes != "" {
+		units, _ := ParseByteUnits(cf.Bytes)
+		t.SetColumnByteSize(cf.Column, units)
+	}
+	if cf.Duration != "" {
+		unit, _ := ParseDurationUnit(cf.Duration)
+		t.SetColumnDuration(cf.Column, unit)
+	}
+	if cf.Currency != "" {
+		f, _ := ParseCurrencyFormat(cf.Currency)
+		t.SetColumnCurrency(cf.Column, f)
+	}
+	if cf.Replace != "" {
+		r, _ := ParseReplacement(cf.Replace)
+		t.AddColumnReplacement(cf.Column, r)
+	}
+}
+
+// extend extends the values to n values using v.
+func extend(vals []string, n int, v string) []string {
+	for len(vals) < n {
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+// parseColumnType returns the column type for the type value: number,
+// boolean or bool, or text, in any case.
+func parseColumnType(v string) (columnType, bool) {
+	switch strings.TrimSpace(strings.ToLower(v)) {
+	case "number":
+		return numberColumn, true
+	case "boolean", "bool":
+		return boolColumn, true
+	case "text":
+		return textColumn, true
+	}
+	return emptyColumn, false
+}
+
+// ReadFieldFormats reads the field formats from a format spec, a
+// structured alternative to the CSV-encoded format file that lists the
+// fields, in order, with their formats; see FieldFormat.  The syntax is
+// json, yaml, or toml.  e.g. in YAML:
+//
+//	fields:
+//	  - name: Make
+//	    style: bold
+//	  - name: Price
+//	    align: right
+//	    width: 10
+//	    type: number
+//	  - name: Notes
+//	    hidden: true
+//
+// in TOML, each field is a [[fields]] table:
+//
+//	[[fields]]
+//	name = "Make"
+//	style = "bold"
+//
+// and in JSON, the fields are an array: {"fields": [{"name": "Make"}]}.
+// Only the subset of YAML and TOML needed for the spec is supported: the
+// values are strings, numbers, or booleans.
+func ReadFieldFormats(r io.Reader, syntax string) ([]FieldFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(syntax)) {
+	case "json":
+		var spec fieldFormatSpec
+		d := json.NewDecoder(r)
+		d.DisallowUnknownFields()
+		err := d.Decode(&spec)
+		if err != nil {
+			return nil, fmt.Errorf("format spec: %s", err)
+		}
+		return spec.Fields, nil
+	case "yaml", "yml":
+		return readFieldFormats(r, true)
+	case "toml":
+		return readFieldFormats(r, false)
+	}
+	return nil, fmt.Errorf("format spec: unknown syntax %q: must be json, yaml, or toml", syntax)
+}
+
+// WriteFieldFormats writes the field formats as a format spec, in the json,
+// yaml, or toml syntax, that ReadFieldFormats reads; the formats' empty
+// attributes are omitted.
+func WriteFieldFormats(w io.Writer, formats []FieldFormat, syntax string) error {
+	var b bytes.Buffer
+	switch strings.ToLower(strings.TrimSpace(syntax)) {
+	case "json":
+		j, err := json.MarshalIndent(fieldFormatSpec{Fields: formats}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("format spec: %s", err)
+		}
+		b.Write(j)
+		b.WriteString("\n")
+	case "yaml", "yml":
+		if len(formats) == 0 {
+			b.WriteString("fields: []\n")
+			break
+		}
+		b.WriteString("fields:\n")
+		for _, f := range formats {
+			prefix := "  - "
+			for _, attr := range specAttrs(f) {
+				b.WriteString(prefix + attr[0] + ": " + attr[1] + "\n")
+				prefix = "    "
+			}
+			if prefix != "    " {
+				// a format without any attributes
+				b.WriteString("  -\n")
+			}
+		}
+	case "toml":
+		for i, f := range formats {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString("[[fields]]\n")
+			for _, attr := range specAttrs(f) {
+				b.WriteString(attr[0] + " = " + attr[1] + "\n")
+			}
+		}
+	default:
+		return fmt.Errorf("format spec: unknown syntax %q: must be json, yaml, or toml", syntax)
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// specAttrs returns the format's attributes that aren't empty, as their
+// keys, which are the same as the JSON keys, and their values; the string
+// values are quoted.
+func specAttrs(f FieldFormat) [][2]string {
+	v := reflect.ValueOf(f)
+	typ := v.Type()
+	var attrs [][2]string
+	for i := 0; i < typ.NumField(); i++ {
+		var s string
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			if field.String() != "" {
+				s = strconv.Quote(field.String())
+			}
+		case reflect.Int:
+			if field.Int() != 0 {
+				s = strconv.FormatInt(field.Int(), 10)
+			}
+		case reflect.Bool:
+			if field.Bool() {
+				s = "true"
+			}
+		}
+		if s == "" {
+			continue
+		}
+		key := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		attrs = append(attrs, [2]string{key, s})
+	}
+	return attrs
+}
+
+// readFieldFormats reads a YAML or TOML format spec.
+func readFieldFormats(r io.Reader, yaml bool) ([]FieldFormat, error) {
+	sep := "="
+	if yaml {
+		sep = ":"
+	}
+	var formats []FieldFormat
+	var list bool // whether the YAML fields list has started
+	s := bufio.NewScanner(r)
+	var n int
+	for s.Scan() {
+		n++
+		line := strings.TrimSpace(stripComment(s.Text()))
+		if line == "" || (yaml && (line == "---" || line == "...")) {
+			continue
+		}
+		if yaml {
+			if line == "fields:" || line == "fields: []" {
+				list = true
+				continue
+			}
+			if !list {
+				return nil, fmt.Errorf("format spec: %d: expected fields:", n)
+			}
+			if strings.HasPrefix(line, "- ") || line == "-" {
+				formats = append(formats, FieldFormat{})
+				line = strings.TrimSpace(line[1:])
+				if line == "" {
+					continue
+				}
+			}
+		} else if strings.HasPrefix(line, "[") {
+			if line != "[[fields]]" {
+				return nil, fmt.Errorf("format spec: %d: unknown table %s", n, line)
+			}
+			formats = append(formats, FieldFormat{})
+			continue
+		}
+		if len(formats) == 0 {
+			return nil, fmt.Errorf("format spec: %d: expected a field", n)
+		}
+		i := strings.Index(line, sep)
+		if i < 1 {
+			return nil, fmt.Errorf("format spec: %d: expected key %s value", n, sep)
+		}
+		key := strings.Trim(strings.TrimSpace(line[:i]), `"'`)
+		v, err := parseSpecValue(strings.TrimSpace(line[i+1:]), yaml)
+		if err != nil {
+			return nil, fmt.Errorf("format spec: %d: %s", n, err)
+		}
+		err = setFieldFormat(&formats[len(formats)-1], key, v)
+		if err != nil {
+			return nil, fmt.Errorf("format spec: %d: %s", n, err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return formats, nil
+}
+
+// setFieldFormat sets the FieldFormat's attribute for the key.
+func setFieldFormat(f *FieldFormat, key, v string) error {
+	switch key {
+	case "column":
+		f.Column = v
+	case "name":
+		f.Name = v
+	case "align":
+		f.Align = v
+	case "style":
+		f.Style = v
+	case "width":
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("width %q: not a valid width", v)
+		}
+		f.Width = n
+	case "max_width":
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("max width %q: not a valid width", v)
+		}
+		f.MaxWidth = n
+	case "truncate":
+		f.Truncate = v
+	case "type":
+		f.Type = v
+	case "hidden":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("hidden %q: not a boolean", v)
+		}
+		f.Hidden = b
+	case "visibility":
+		f.Visibility = v
+	case "template":
+		f.Template = v
+	case "number":
+		f.Number = v
+	case "time":
+		f.Time = v
+	case "bytes":
+		f.Bytes = v
+	case "duration":
+		f.Duration = v
+	case "currency":
+		f.Currency = v
+	case "replace":
+		f.Replace = v
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+	return nil
+}
+
+// parseSpecValue returns the value of a scalar: a quoted string is
+// unquoted, anything else is used as is.  In TOML, anything else must be a
+// number or a boolean.
+func parseSpecValue(v string, yaml bool) (string, error) {
+	if v == "" {
+		return "", fmt.Errorf("missing value")
+	}
+	switch v[0] {
+	case '"':
+		s, err := strconv.Unquote(v)
+		if err != nil {
+			return "", fmt.Errorf("%s: invalid string", v)
+		}
+		return s, nil
+	case '\'':
+		if len(v) < 2 || v[len(v)-1] != '\'' {
+			return "", fmt.Errorf("%s: invalid string", v)
+		}
+		s := v[1 : len(v)-1]
+		if yaml {
+			s = strings.Replace(s, "''", "'", -1)
+		}
+		return s, nil
+	}
+	if !yaml && v != "true" && v != "false" {
+		if _, err := strconv.Atoi(v); err != nil {
+			return "", fmt.Errorf("%s: strings must be quoted", v)
+		}
+	}
+	return v, nil
+}
+
+// stripComment removes a # comment from the line; a # in a quoted string
+// is not a comment.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,272 @@
+package csv2md
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadFieldFormats(t *testing.T) {
+	expected := []FieldFormat{
+		{Name: "Make", Style: "bold"},
+		{Name: "Price", Align: "right", Width: 10, Type: "number"},
+		{Name: "Notes # 1", Hidden: true, Template: `{{printf "%s" .Value}}`, MaxWidth: 12, Truncate: "cut"},
+	}
+	tests := []struct {
+		syntax string
+		spec   string
+		err    string
+	}{
+		{"yaml", `# the cars
+fields:
+  - name: Make
+    style: bold
+  - name: Price
+    align: right # numbers
+    width: 10
+    type: number
+  - name: "Notes # 1"
+    hidden: true
+    template: '{{printf "%s" .Value}}'
+    max_width: 12
+    truncate: cut
+`, ""},
+		{"toml", `[[fields]]
+name = "Make"
+style = 'bold'
+
+[[fields]]
+name = "Price"
+align = "right"
+width = 10
+type = "number"
+
+[[fields]]
+name = "Notes # 1"
+hidden = true
+template = '{{printf "%s" .Value}}'
+max_width = 12
+truncate = "cut"
+`, ""},
+		{"json", `{"fields": [{"name": "Make", "style": "bold"}, {"name": "Price", "align": "right", "width": 10, "type": "number"}, {"name": "Notes # 1", "hidden": true, "template": "{{printf \"%s\" .Value}}", "max_width": 12, "truncate": "cut"}]}`, ""},
+		{"yaml", "name: Make\n", "format spec: 1: expected fields:"},
+		{"yaml", "fields:\n  - name: Make\n    colour: red\n", `format spec: 3: unknown key "colour"`},
+		{"yaml", "fields:\n  - width: wide\n", `format spec: 2: width "wide": not a valid width`},
+		{"toml", "name = \"Make\"\n", "format spec: 1: expected a field"},
+		{"toml", "[[fields]]\nname = Make\n", "format spec: 2: Make: strings must be quoted"},
+		{"toml", "[columns]\n", "format spec: 1: unknown table [columns]"},
+		{"json", `{"fields": [{"colour": "red"}]}`, `format spec: json: unknown field "colour"`},
+		{"xml", "", `format spec: unknown syntax "xml": must be json, yaml, or toml`},
+	}
+	for i, test := range tests {
+		formats, err := ReadFieldFormats(strings.NewReader(test.spec), test.syntax)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(formats, expected) {
+			t.Errorf("%d: got %+v want %+v", i, formats, expected)
+		}
+	}
+}
+
+func TestSetFieldFormats(t *testing.T) {
+	csvData := []byte("Make,Model,Price,Notes\nFord,Focus,18000,new\nKia,Rio,16500,\n")
+	tests := []struct {
+		formats  []FieldFormat
+		expected string
+		err      string
+	}{
+		{nil, "Make|Model|Price|Notes  \n---|---|---|---  \nFord|Focus|18000|new  \nKia|Rio|16500|   \n", ""},
+		{
+			[]FieldFormat{{Name: "Maker", Style: "bold"}, {Name: "Model", Width: 6}, {Name: "Price", Align: "auto"}, {Name: "Notes", Hidden: true}},
+			"Maker|Model |Price  \n---|------|--:  \n__Ford__|Focus |18000  \n__Kia__|Rio   |16500  \n", "",
+		},
+		{
+			[]FieldFormat{{Align: "center"}, {}, {Align: "auto", Type: "text"}, {Template: "[{{.Value}}]"}},
+			"Make|Model|Price|Notes  \n:--:|---|:--|---  \nFord|Focus|18000|[new]  \nKia|Rio|16500|[]  \n", "",
+		},
+		{[]FieldFormat{{Align: "up"}}, "", `field 1: unknown alignment "up"`},
+		{[]FieldFormat{{}, {Style: "loud"}}, "", `field 2: unknown style "loud"`},
+		{[]FieldFormat{{}, {}, {Type: "money"}}, "", `field 3: unknown type "money"`},
+		{[]FieldFormat{{}, {}, {}, {Visibility: "gone"}}, "", `field 4: unknown visibility "gone"`},
+		{
+			[]FieldFormat{{MaxWidth: 3}, {MaxWidth: 4, Truncate: "cut"}, {}, {}},
+			"Make|Model|Price|Notes  \n---|---|---|---  \nFo…|Focu|18000|new  \nKia|Rio|16500|   \n", "",
+		},
+		{[]FieldFormat{{Truncate: "clip"}}, "", `field 1: unknown truncation "clip": expected ellipsis, cut, or footnote`},
+		{[]FieldFormat{{}, {}, {}, {Hidden: true, Visibility: "visible"}}, "", `field 4: visibility "visible": the field is hidden`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		err := calvin.SetFieldFormats(test.formats)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		err = calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestSetColumnFormats(t *testing.T) {
+	csvData := []byte("Make,Model,Price,Notes\nFord,Focus,18000,new\nKia,Rio,16500,\n")
+	tests := []struct {
+		formats  []FieldFormat
+		strict   bool
+		expected string
+		warnings []string
+		err      string
+	}{
+		{
+			[]FieldFormat{{Column: "Price", Style: "bold", Align: "right"}},
+			false,
+			"Make|Model|Price|Notes  \n---|---|--:|---  \nFord|Focus|__18000__|new  \nKia|Rio|__16500__|   \n", nil, "",
+		},
+		{
+			[]FieldFormat{{Column: "Notes", Hidden: true}, {Column: "Make", Name: "Maker", Width: 6, Template: "{{.Value}}!"}, {Column: "Price", Align: "auto", Type: "text"}},
+			false,
+			"Maker |Model|Price  \n------|---|:--  \nFord! |Focus|18000  \nKia!  |Rio|16500  \n", nil, "",
+		},
+		{
+			[]FieldFormat{{Column: "Colour", Style: "italic"}, {Column: "Model", Style: "italic"}},
+			false,
+			"Make|Model|Price|Notes  \n---|---|---|---  \nFord|_Focus_|18000|new  \nKia|_Rio_|16500|   \n", []string{`format: unknown column "Colour"`}, "",
+		},
+		{
+			[]FieldFormat{{Column: "Price", Visibility: "Hidden"}, {Column: "Notes", Visibility: "visible"}},
+			false,
+			"Make|Model|Notes  \n---|---|---  \nFord|Focus|new  \nKia|Rio|   \n", nil, "",
+		},
+		{
+			[]FieldFormat{{Column: "Model", MaxWidth: 4, Truncate: "footnote"}},
+			false,
+			"Make|Model|Price|Notes  \n---|---|---|---  \nFord|Foc…[^1]|18000|new  \nKia|Rio|16500|   \n\n[^1]: Focus\n", nil, "",
+		},
+		// the * format is the default of the columns without a format
+		{
+			[]FieldFormat{{Column: "*", Align: "right", Style: "italic", Width: 5}, {Column: "Make", Align: "left"}, {Column: "Colour", Style: "bold"}},
+			false,
+			"Make|Model|Price|Notes  \n:--|----:|----:|----:  \nFord|_Focus_|_18000_|_new_  \nKia|_Rio_|_16500_|  _ _  \n", []string{`format: unknown column "Colour"`}, "",
+		},
+		{[]FieldFormat{{Column: "*", Name: "Field"}}, false, "", nil, "field 1: the * format can't rename the columns"},
+		{[]FieldFormat{{Column: "Colour", Style: "italic"}}, true, "", nil, `format: unknown column "Colour"`},
+		{[]FieldFormat{{Column: "Make"}, {Style: "bold"}}, false, "", nil, "field formats: 1 of 2 formats have a column: either every format has a column or none do"},
+		{[]FieldFormat{{Column: "Make", Template: "{{.Value"}}, false, "", nil, "template: Make:1: unclosed action"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.Strict = test.strict
+		err := calvin.SetFieldFormats(test.formats)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+		if !reflect.DeepEqual(calvin.Warnings(), test.warnings) {
+			t.Errorf("%d: warnings: got %q want %q", i, calvin.Warnings(), test.warnings)
+		}
+	}
+}
+
+func TestHiddenFieldFilter(t *testing.T) {
+	spec := "fields:\n  - column: Price\n    visibility: hidden\n"
+	formats, err := ReadFieldFormats(strings.NewReader(spec), "yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader("Make,Price\nFord,18000\nKia,16500\n"), &w)
+	err = calvin.SetFieldFormats(formats)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// the hidden field is still read
+	err = calvin.SetFilter("Price < 17000")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Make  \n---  \nKia  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
+
+func TestWriteFieldFormats(t *testing.T) {
+	formats := []FieldFormat{
+		{Column: "Make", Style: "bold"},
+		{Column: "Price", Align: "right", Width: 10, Hidden: true},
+		{},
+		{Column: "Notes # 1", Template: `{{printf "%s" .Value}}`},
+	}
+	tests := []struct {
+		syntax   string
+		expected string
+	}{
+		{"yaml", "fields:\n  - column: \"Make\"\n    style: \"bold\"\n  - column: \"Price\"\n    align: \"right\"\n    width: 10\n    hidden: true\n  -\n" +
+			"  - column: \"Notes # 1\"\n    template: \"{{printf \\\"%s\\\" .Value}}\"\n"},
+		{"toml", "[[fields]]\ncolumn = \"Make\"\nstyle = \"bold\"\n\n[[fields]]\ncolumn = \"Price\"\nalign = \"right\"\nwidth = 10\nhidden = true\n\n[[fields]]\n\n" +
+			"[[fields]]\ncolumn = \"Notes # 1\"\ntemplate = \"{{printf \\\"%s\\\" .Value}}\"\n"},
+		{"json", ""},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		err := WriteFieldFormats(&w, formats, test.syntax)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if test.expected != "" && w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+		// the spec is read back as it was written
+		read, err := ReadFieldFormats(&w, test.syntax)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(read, formats) {
+			t.Errorf("%d: got %+v want %+v", i, read, formats)
+		}
+	}
+	err := WriteFieldFormats(&bytes.Buffer{}, formats, "xml")
+	if err == nil || err.Error() != `format spec: unknown syntax "xml": must be json, yaml, or toml` {
+		t.Errorf("got error %v want an unknown syntax error", err)
+	}
+}
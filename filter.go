@@ -0,0 +1,139 @@
+package csv2md
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// recordFilter is the expression that records must match to be included
+// in the table.
+type recordFilter struct {
+	expr     string
+	n        node
+	prepared bool
+}
+
+// SetFilter sets the expression that a record must match to be included
+// in the table; e.g. `Year >= 2015 && Type == "Sedan"`.  The expression is
+// evaluated against each record, including its computed columns, before
+// any value maps or formatting are applied; records for which it is false
+// are skipped.  The header record is never filtered.  A column that the
+// expression references and that isn't in the header is an
+// UnknownColumnError; strings must be quoted, e.g. "Sedan".  If the expression
+// cannot be evaluated for a record, e.g. a value in an arithmetic
+// operation isn't a number, the record is skipped and a warning is added.
+//
+// The records are filtered as they are read, so settings that use all of
+// the records, e.g. Metadata and auto alignment, only see the records
+// that are in the table.
+func (t *Transmogrifier) SetFilter(expr string) error {
+	n, err := parseExpr(expr)
+	if err != nil {
+		return err
+	}
+	t.filter = &recordFilter{expr: expr, n: n}
+	t.nFiltered = 0
+	return nil
+}
+
+// prepareFilter starts filtering the records; the header must have been
+// read.  Any records that have already been buffered are filtered.
+func (t *Transmogrifier) prepareFilter() error {
+	err := t.prepareRowRange()
+	if err != nil {
+		return err
+	}
+	err = t.prepareSample()
+	if err != nil {
+		return err
+	}
+	if t.dedup != nil {
+		t.dedup.index = t.dedup.index[:0]
+		for _, column := range t.dedup.columns {
+			i := columnIndex(t.header, column)
+			if i < 0 {
+				return UnknownColumnError{Name: column, operation: "dedup"}
+			}
+			t.dedup.index = append(t.dedup.index, i)
+		}
+		t.dedup.seen = make(map[string]struct{})
+		t.dedup.removed = 0
+		t.dedup.prepared = true
+	}
+	if t.filter != nil {
+		err = checkExprColumns(t.filter.n, t.header, fmt.Sprintf("filter %q", t.filter.expr))
+		if err != nil {
+			return err
+		}
+		t.filter.prepared = true
+	}
+	t.filterBuffered()
+	return nil
+}
+
+// filtering returns whether the records are being filtered, by the filter,
+// by removing the duplicates, by sampling, or by the row range.
+func (t *Transmogrifier) filtering() bool {
+	return (t.filter != nil && t.filter.prepared) || (t.dedup != nil && t.dedup.prepared) || (t.sampling != nil && t.sampling.prepared) || (t.rows != nil && t.rows.prepared)
+}
+
+// include returns whether the record is included in the table: it matches
+// the filter, it isn't a duplicate, it is sampled, and it is in the row
+// range.
+func (t *Transmogrifier) include(record []string, row int) bool {
+	if t.filter != nil && t.filter.prepared && !t.match(record, row) {
+		t.nExcluded++
+		t.log(slog.LevelDebug, "row filtered", "row", row)
+		return false
+	}
+	if t.dedup != nil && t.dedup.prepared && t.dedup.duplicate(record) {
+		t.nExcluded++
+		t.log(slog.LevelDebug, "duplicate row removed", "row", row)
+		return false
+	}
+	if t.sampling != nil && t.sampling.prepared && !t.sampling.sampled() {
+		t.nExcluded++
+		return false
+	}
+	if t.rows != nil && t.rows.prepared && !t.rows.selected(record) {
+		t.nExcluded++
+		return false
+	}
+	return true
+}
+
+// filterBuffered removes the buffered records that don't match the filter
+// and haven't been filtered yet.  Records are only filtered once their
+// computed columns have been added.  A buffered record's row is the row it
+// would have once it is read.
+func (t *Transmogrifier) filterBuffered() {
+	if !t.filtering() {
+		return
+	}
+	n := len(t.records)
+	if t.computedFrom != nil {
+		n = t.nComputed
+	}
+	kept := t.records[:t.nFiltered]
+	for i := t.nFiltered; i < n; i++ {
+		if t.include(t.records[i], t.row+i+1) {
+			kept = append(kept, t.records[i])
+		}
+	}
+	removed := n - len(kept)
+	t.records = append(kept, t.records[n:]...)
+	if t.computedFrom != nil {
+		t.nComputed -= removed
+	}
+	t.nFiltered = len(kept)
+}
+
+// match returns whether the record matches the filter.
+func (t *Transmogrifier) match(record []string, row int) bool {
+	v, err := t.filter.n.eval(recordEnv(t.header, record))
+	if err != nil {
+		t.warnf("row %d: filter %q: %s", row, t.filter.expr, err)
+		return false
+	}
+	return v.truth()
+}
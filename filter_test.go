@@ -0,0 +1,102 @@
+package csv2md
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSetFilter(t *testing.T) {
+	csvData := []byte("Make,Type,Year\nFord,Sedan,2012\nKia,Sedan,2016\nTesla,Sedan,2017\nJeep,SUV,2018\nAudi,Sedan,x\n")
+	tests := []struct {
+		expr     string
+		setup    func(*Transmogrifier)
+		expected string
+		warnings int
+	}{
+		{`Year >= 2015 && Type == "Sedan"`, nil, "Make|Type|Year  \n---|---|---  \nKia|Sedan|2016  \nTesla|Sedan|2017  \n", 0},
+		{`Make == "Ford" || Type == "SUV"`, nil, "Make|Type|Year  \n---|---|---  \nFord|Sedan|2012  \nJeep|SUV|2018  \n", 0},
+		{`!(Year > 2012)`, nil, "Make|Type|Year  \n---|---|---  \nFord|Sedan|2012  \nAudi|Sedan|x  \n", 0},
+		{`Year - 2000 > 17`, nil, "Make|Type|Year  \n---|---|---  \nJeep|SUV|2018  \n", 1},
+		{`Make == "Nope"`, nil, "Make|Type|Year  \n---|---|---  \n", 0},
+		// the buffered records are filtered
+		{`Type == "SUV" || Year == 2012`, func(t *Transmogrifier) { t.GroupBy("Type", SortGroups()) }, "Make|Type|Year  \n---|---|---  \n**Type: SUV**| |   \nJeep|SUV|2018  \n**Type: Sedan**| |   \nFord|Sedan|2012  \n", 0},
+		{`Year < 2017`, func(t *Transmogrifier) { t.SetFieldAlignment([]string{"", "", "auto"}) }, "Make|Type|Year  \n---|---|--:  \nFord|Sedan|2012  \nKia|Sedan|2016  \n", 0},
+		// computed columns can be filtered on
+		{`Label == "KiaSedan"`, func(t *Transmogrifier) { t.AddComputedExpr("Label", "Make + Type") }, "Make|Type|Year|Label  \n---|---|---|---  \nKia|Sedan|2016|KiaSedan  \n", 0},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		if test.setup != nil {
+			test.setup(calvin)
+		}
+		err := calvin.SetFilter(test.expr)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		err = calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+		if len(calvin.Warnings()) != test.warnings {
+			t.Errorf("%d: got %d warnings want %d: %q", i, len(calvin.Warnings()), test.warnings, calvin.Warnings())
+		}
+	}
+}
+
+func TestExprUnknownColumn(t *testing.T) {
+	csvData := []byte("Make,Type,Year\nFord,Sedan,2012\n")
+	tests := []struct {
+		setup    func(*Transmogrifier) error
+		expected string
+	}{
+		{func(t *Transmogrifier) error { return t.SetFilter("Yeer >= 2015") }, `filter "Yeer >= 2015": unknown column "Yeer"`},
+		{func(t *Transmogrifier) error { return t.SetFilter("Type == Sedan") }, `filter "Type == Sedan": unknown column "Sedan"`},
+		{func(t *Transmogrifier) error { return t.ParseRule("Year > 2010 && Maek == \"Ford\":bold") }, `style rule "Year > 2010 && Maek == \"Ford\"": unknown column "Maek"`},
+		{func(t *Transmogrifier) error { return t.ParseComputedColumn("Age=2020 - Yr") }, `computed column "Age": unknown column "Yr"`},
+		{func(t *Transmogrifier) error {
+			t.ParseComputedColumn("Next=Age + 1")
+			return t.ParseComputedColumn("Age=2020 - Year")
+		}, `computed column "Next": unknown column "Age"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		err := test.setup(calvin)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		err = calvin.MDTable()
+		if err == nil {
+			t.Errorf("%d: expected error %q, got none", i, test.expected)
+			continue
+		}
+		var uerr UnknownColumnError
+		if !errors.As(err, &uerr) || err.Error() != test.expected {
+			t.Errorf("%d: got error %q want UnknownColumnError %q", i, err, test.expected)
+		}
+	}
+}
+
+func TestEncoderFilter(t *testing.T) {
+	var w bytes.Buffer
+	enc := NewEncoder(&w, func(t *Transmogrifier) { t.SetFilter("Qty > 1") })
+	enc.WriteHeader([]string{"Item", "Qty"})
+	enc.WriteRecord([]string{"a", "1"})
+	enc.WriteRecord([]string{"b", "2"})
+	err := enc.Flush()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Item|Qty  \n---|---  \nb|2  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
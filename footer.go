@@ -0,0 +1,195 @@
+package csv2md
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the aggregates that can be used in a footer row
+const (
+	aggSum   = "sum"
+	aggAvg   = "avg"
+	aggCount = "count"
+	aggMin   = "min"
+	aggMax   = "max"
+)
+
+// aggregate accumulates a column's values for the footer row.
+type aggregate struct {
+	fn       string
+	index    int
+	count    int // the number of non-empty values
+	n        int // the number of numeric values
+	sum      float64
+	min, max float64
+	decimals int // the most decimal places of the numeric values
+	locale   locale
+}
+
+// SetColumnAggregate adds a footer row to the table in which the named
+// column's cell is the aggregate of the column's values.  The supported
+// aggregates are:
+//
+//	sum    the sum of the values
+//	avg    the average of the values
+//	count  the number of non-empty values
+//	min    the smallest value
+//	max    the largest value
+//
+// Values that are not numbers are ignored by every aggregate other than
+// count; if the column has no numbers, the cell is empty.  The sum, min,
+// and max have the same number of decimal places as the value with the
+// most decimal places; the avg has two more.  The aggregates are computed
+// from the values as they were read, after filtering and before value maps
+// are applied.
+//
+// The footer row is written after the records and its cells are bold.
+// Columns without an aggregate are empty, except for the first visible
+// column, which contains the FooterLabel.
+func (t *Transmogrifier) SetColumnAggregate(column, agg string) error {
+	agg, err := parseAggregate(agg)
+	if err != nil {
+		return err
+	}
+	if t.aggregates == nil {
+		t.aggregates = make(map[string]string)
+	}
+	t.aggregates[column] = agg
+	return nil
+}
+
+// parseAggregate returns the normalized aggregate; an unknown aggregate is
+// an error.
+func parseAggregate(agg string) (string, error) {
+	agg = strings.ToLower(strings.TrimSpace(agg))
+	switch agg {
+	case aggSum, aggAvg, aggCount, aggMin, aggMax:
+		return agg, nil
+	}
+	return "", fmt.Errorf("aggregate %q: unknown aggregate", agg)
+}
+
+// ParseAggregate parses a column aggregate of the form column=aggregate;
+// e.g. "Amount=sum", and adds it to the footer row.
+func (t *Transmogrifier) ParseAggregate(s string) error {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return fmt.Errorf("aggregate %q: expected column=aggregate", s)
+	}
+	return t.SetColumnAggregate(strings.TrimSpace(s[:i]), s[i+1:])
+}
+
+// prepareFooter resolves the column aggregates against the header.
+func (t *Transmogrifier) prepareFooter(header []string) error {
+	t.footer = nil
+	for column, fn := range t.aggregates {
+		i := columnIndex(header, column)
+		if i < 0 {
+			return UnknownColumnError{Name: column, operation: "aggregate"}
+		}
+		t.footer = append(t.footer, &aggregate{fn: fn, index: i, locale: t.locale})
+	}
+	return nil
+}
+
+// accumulate adds the record's values to the aggregates.
+func (t *Transmogrifier) accumulate(record []string) {
+	for _, a := range t.footer {
+		if a.index < len(record) {
+			a.add(record[a.index])
+		}
+	}
+}
+
+func (a *aggregate) add(v string) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return
+	}
+	a.count++
+	v, n, ok := a.locale.number(v)
+	if !ok {
+		return
+	}
+	if a.n == 0 || n < a.min {
+		a.min = n
+	}
+	if a.n == 0 || n > a.max {
+		a.max = n
+	}
+	a.n++
+	a.sum += n
+	if i := strings.IndexByte(v, '.'); i >= 0 && !strings.ContainsAny(v, "eE") {
+		if d := len(v) - i - 1; d > a.decimals {
+			a.decimals = d
+		}
+	}
+}
+
+// value returns the aggregate's value.
+func (a *aggregate) value() string {
+	if a.fn == aggCount {
+		return strconv.Itoa(a.count)
+	}
+	if a.n == 0 {
+		return ""
+	}
+	var s string
+	switch a.fn {
+	case aggSum:
+		s = strconv.FormatFloat(a.sum, 'f', a.decimals, 64)
+	case aggAvg:
+		s = strconv.FormatFloat(a.sum/float64(a.n), 'f', a.decimals+2, 64)
+	case aggMin:
+		s = strconv.FormatFloat(a.min, 'f', a.decimals, 64)
+	default:
+		s = strconv.FormatFloat(a.max, 'f', a.decimals, 64)
+	}
+	return a.locale.decimalMark(s)
+}
+
+// footerRecord returns the footer row's fields; n is the number of fields
+// in the table.
+func (t *Transmogrifier) footerRecord(n int) []string {
+	fields := make([]string, n)
+	aggregated := make(map[int]bool, len(t.footer))
+	for _, a := range t.footer {
+		for len(fields) <= a.index {
+			fields = append(fields, "")
+		}
+		fields[a.index] = a.value()
+		aggregated[a.index] = true
+	}
+	for i := range fields {
+		if t.hidden[i] {
+			continue
+		}
+		if !aggregated[i] {
+			fields[i] = t.FooterLabel
+		}
+		break
+	}
+	return fields
+}
+
+// writeFooter writes the footer row, if the table has one, using the
+// Renderer.  The footer row's cells are bold, except for the empty ones.
+func (t *Transmogrifier) writeFooter(r Renderer) error {
+	if len(t.footer) == 0 {
+		return nil
+	}
+	fields := t.footerRecord(len(t.header))
+	cells := make([]Cell, 0, len(fields))
+	for i, f := range fields {
+		if t.hidden[i] {
+			continue
+		}
+		cell := Cell{Value: f, Align: alignments[t.alignment(i)]}
+		if f != "" {
+			cell.Styles = []Style{StyleBold}
+		}
+		cells = append(cells, cell)
+	}
+	return writeFooterCells(r, cells)
+}
@@ -0,0 +1,96 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetColumnAggregate(t *testing.T) {
+	csvData := []byte("Item,Qty,Price\nPen,3,1.25\nInk,,10.5\nPad,2,n/a\n")
+	tests := []struct {
+		aggs     []string
+		label    string
+		setup    func(*Transmogrifier)
+		expected string
+		err      string
+	}{
+		{[]string{"Qty=sum", "Price=sum"}, "Total", nil, "Item|Qty|Price  \n---|---|---  \nPen|3|1.25  \nInk| |10.5  \nPad|2|n/a  \n__Total__|__5__|__11.75__  \n", ""},
+		{[]string{"Qty=avg", "Price=max"}, "", nil, "Item|Qty|Price  \n---|---|---  \nPen|3|1.25  \nInk| |10.5  \nPad|2|n/a  \n |__2.50__|__10.50__  \n", ""},
+		{[]string{"Item=count", "Price=min"}, "Total", nil, "Item|Qty|Price  \n---|---|---  \nPen|3|1.25  \nInk| |10.5  \nPad|2|n/a  \n__3__| |__1.25__  \n", ""},
+		{[]string{"Item=sum"}, "Total", nil, "Item|Qty|Price  \n---|---|---  \nPen|3|1.25  \nInk| |10.5  \nPad|2|n/a  \n | |   \n", ""},
+		// the aggregates are of the filtered, raw values
+		{[]string{"Qty=sum"}, "Total", func(t *Transmogrifier) {
+			t.SetFilter(`Item != "Ink"`)
+			t.SetColumnValueMap("Qty", map[string]string{"3": "three"}, true)
+			t.GroupBy("Item", HideGroupColumn())
+		}, "Qty|Price  \n---|---  \n**Item: Pen**|   \nthree|1.25  \n**Item: Pad**|   \n2|n/a  \n__5__|   \n", ""},
+		{[]string{"Cost=sum"}, "Total", nil, "", `aggregate: unknown column "Cost"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.FooterLabel = test.label
+		for _, agg := range test.aggs {
+			err := calvin.ParseAggregate(agg)
+			if err != nil {
+				t.Fatalf("%d: unexpected error: %s", i, err)
+			}
+		}
+		if test.setup != nil {
+			test.setup(calvin)
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestParseAggregate(t *testing.T) {
+	tests := []struct {
+		s   string
+		err string
+	}{
+		{"Qty=sum", ""},
+		{"Qty = AVG", ""},
+		{"Qty", `aggregate "Qty": expected column=aggregate`},
+		{"Qty=total", `aggregate "total": unknown aggregate`},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(bytes.NewReader(nil), &bytes.Buffer{})
+		err := calvin.ParseAggregate(test.s)
+		var s string
+		if err != nil {
+			s = err.Error()
+		}
+		if s != test.err {
+			t.Errorf("%d: got error %q want %q", i, s, test.err)
+		}
+	}
+}
+
+func TestFooterHTML(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader([]byte("Item,Qty\nPen,3\nInk,2\n")), &w)
+	calvin.OutputFormat = HTML
+	calvin.FooterLabel = "Total"
+	calvin.SetColumnAggregate("Qty", "sum")
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "<table>\n<thead>\n<tr>\n<th>Item</th>\n<th>Qty</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>Pen</td>\n<td>3</td>\n</tr>\n<tr>\n<td>Ink</td>\n<td>2</td>\n</tr>\n</tbody>\n<tfoot>\n<tr>\n<td><strong>Total</strong></td>\n<td><strong>5</strong></td>\n</tr>\n</tfoot>\n</table>\n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
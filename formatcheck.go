@@ -0,0 +1,164 @@
+package csv2md
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// FormatProblem is a problem with a format file or spec that CheckFmt or
+// CheckFieldFormats found.  The Row is the format file's row and the Field
+// is the field, or the format spec's field, both starting from 1; a Row or
+// Field of 0 is the problem of the whole format file or row.
+type FormatProblem struct {
+	Row   int
+	Field int
+	Msg   string
+}
+
+func (p FormatProblem) Error() string {
+	switch {
+	case p.Row > 0 && p.Field > 0:
+		return fmt.Sprintf("row %d, field %d: %s", p.Row, p.Field, p.Msg)
+	case p.Row > 0:
+		return fmt.Sprintf("row %d: %s", p.Row, p.Msg)
+	case p.Field > 0:
+		return fmt.Sprintf("field %d: %s", p.Field, p.Msg)
+	}
+	return p.Msg
+}
+
+// formatRows are the names of the format file's rows.
+var formatRows = []string{"field names", "alignments", "styles", "widths", "templates"}
+
+// CheckFmt checks the format file, see SetFmt, against the CSV-encoded
+// data's header record, or first record, without applying it: each of its
+// rows must have a value for each of the data's fields, and the values
+// must be valid, e.g. a known alignment.  Every problem that is found is
+// returned, in order; an error is only returned if the format file or the
+// data can't be read.  The data is not written.
+func (t *Transmogrifier) CheckFmt(r io.Reader) ([]FormatProblem, error) {
+	c := csv.NewReader(r)
+	// make sure this reader's settings are consistent with CSV's
+	c.Comma = t.CSV.Comma
+	c.Comment = t.CSV.Comment
+	c.LazyQuotes = t.CSV.LazyQuotes
+	c.TrimLeadingSpace = t.CSV.TrimLeadingSpace
+	// the rows' lengths are checked against the data
+	c.FieldsPerRecord = -1
+	records, err := c.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []FormatProblem{{Msg: ErrNoFormatData.Error()}}, nil
+	}
+	n, err := t.checkedFields()
+	if err != nil {
+		return nil, err
+	}
+	var problems []FormatProblem
+	for i, record := range records {
+		row := i + 1
+		if i >= len(formatRows) {
+			problems = append(problems, FormatProblem{Row: row, Msg: fmt.Sprintf("a format file has at most %d rows", len(formatRows))})
+			continue
+		}
+		if n > 0 && len(record) != n {
+			problems = append(problems, FormatProblem{Row: row, Msg: fmt.Sprintf("%s: has %d fields, the data has %d", formatRows[i], len(record), n)})
+		}
+		for j, v := range record {
+			var msg string
+			switch i {
+			case 1:
+				if _, ok := parseAlignment(v); !ok {
+					msg = fmt.Sprintf("unknown alignment %q", v)
+				}
+			case 2:
+				if parseStyle(v) == "" && strings.TrimSpace(v) != "" {
+					msg = fmt.Sprintf("unknown style %q", v)
+				}
+			case 3:
+				if w, err := strconv.Atoi(strings.TrimSpace(v)); (err != nil || w < 0) && strings.TrimSpace(v) != "" {
+					msg = fmt.Sprintf("width %q: not a valid width", v)
+				}
+			case 4:
+				if v != "" {
+					if _, err := template.New("").Funcs(templateFuncs).Parse(v); err != nil {
+						msg = fmt.Sprintf("template %q: %s", v, strings.TrimPrefix(err.Error(), "template: :"))
+					}
+				}
+			}
+			if msg != "" {
+				problems = append(problems, FormatProblem{Row: row, Field: j + 1, Msg: msg})
+			}
+		}
+	}
+	return problems, nil
+}
+
+// CheckFieldFormats checks the field formats, e.g. those of a format spec,
+// see ReadFieldFormats, against the CSV-encoded data's header record, or
+// first record, without applying them: the formats' values must be
+// valid, the columns of the formats with a Column must be in the header,
+// and otherwise there must be a format for each of the data's fields.
+// Every problem that is found is returned, in order; an error is only
+// returned if the data can't be read.  The data is not written.
+func (t *Transmogrifier) CheckFieldFormats(formats []FieldFormat) ([]FormatProblem, error) {
+	n, err := t.checkedFields()
+	if err != nil {
+		return nil, err
+	}
+	var problems []FormatProblem
+	var keyed int
+	columns := make(map[string]int)
+	for i, f := range formats {
+		field := i + 1
+		if err := checkFieldFormat(f); err != nil {
+			problems = append(problems, FormatProblem{Field: field, Msg: err.Error()})
+		}
+		if f.Column == "" {
+			continue
+		}
+		keyed++
+		if f.Column == AllColumns {
+			if f.Name != "" {
+				problems = append(problems, FormatProblem{Field: field, Msg: fmt.Sprintf("the %s format can't rename the columns", AllColumns)})
+			}
+			continue
+		}
+		if prev, ok := columns[f.Column]; ok {
+			problems = append(problems, FormatProblem{Field: field, Msg: fmt.Sprintf("column %q: also the column of field %d", f.Column, prev)})
+		}
+		columns[f.Column] = field
+		if t.HasHeaderRecord && columnIndex(t.header, f.Column) < 0 {
+			problems = append(problems, FormatProblem{Field: field, Msg: fmt.Sprintf("unknown column %q", f.Column)})
+		}
+	}
+	switch {
+	case keyed > 0 && keyed < len(formats):
+		problems = append(problems, FormatProblem{Msg: fmt.Sprintf("%d of %d formats have a column: either every format has a column or none do", keyed, len(formats))})
+	case keyed == 0 && n > 0 && len(formats) != n:
+		problems = append(problems, FormatProblem{Msg: fmt.Sprintf("the spec has %d fields, the data has %d", len(formats), n)})
+	}
+	return problems, nil
+}
+
+// checkedFields reads the data's header record, if it has one, and returns
+// the number of its fields, or of its first record's fields.
+func (t *Transmogrifier) checkedFields() (int, error) {
+	t.prepareRagged()
+	if t.HasHeaderRecord {
+		header, err := t.read()
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		t.header = header
+		return len(header), nil
+	}
+	record, err := t.peek()
+	return len(record), err
+}
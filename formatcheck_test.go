@@ -0,0 +1,82 @@
+package csv2md
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckFmt(t *testing.T) {
+	csvData := "Make,Model,Price\nFord,Focus,18000\n"
+	tests := []struct {
+		format   string
+		header   bool
+		expected []string
+	}{
+		{"Make,Model,Price\nl,c,r\nb,,i\n8,0,10\n{{.Value}},,\n", true, nil},
+		{"Make,Model\nl,up,r\nbold,loud,\n8,wide,-1\n{{.Value,,\n", true, []string{
+			"row 1: field names: has 2 fields, the data has 3",
+			"row 2, field 2: unknown alignment \"up\"",
+			"row 3, field 2: unknown style \"loud\"",
+			"row 4, field 2: width \"wide\": not a valid width",
+			"row 4, field 3: width \"-1\": not a valid width",
+			"row 5, field 1: template \"{{.Value\": 1: unclosed action",
+		}},
+		{"Make,Model,Price\n,,\n,,\n,,\n,,\n,,\n", false, []string{"row 6: a format file has at most 5 rows"}},
+		{"", true, []string{"no format data"}},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+		calvin.HasHeaderRecord = test.header
+		problems, err := calvin.CheckFmt(strings.NewReader(test.format))
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		var got []string
+		for _, p := range problems {
+			got = append(got, p.Error())
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%d: got %q want %q", i, got, test.expected)
+		}
+	}
+}
+
+func TestCheckFieldFormats(t *testing.T) {
+	csvData := "Make,Model,Price\nFord,Focus,18000\n"
+	tests := []struct {
+		formats  []FieldFormat
+		expected []string
+	}{
+		{[]FieldFormat{{Column: "*", Align: "right"}, {Column: "Make", Style: "bold"}}, nil},
+		{[]FieldFormat{{Name: "Maker"}, {}, {Align: "auto"}}, nil},
+		{[]FieldFormat{{Column: "Make", Style: "loud"}, {Column: "Colour"}, {Column: "Make"}, {Column: "*", Name: "Field"}}, []string{
+			"field 1: unknown style \"loud\"",
+			"field 2: unknown column \"Colour\"",
+			"field 3: column \"Make\": also the column of field 1",
+			"field 4: the * format can't rename the columns",
+		}},
+		{[]FieldFormat{{Column: "Make"}, {Align: "up"}}, []string{
+			"field 2: unknown alignment \"up\"",
+			"1 of 2 formats have a column: either every format has a column or none do",
+		}},
+		{[]FieldFormat{{}, {}}, []string{"the spec has 2 fields, the data has 3"}},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+		problems, err := calvin.CheckFieldFormats(test.formats)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		var got []string
+		for _, p := range problems {
+			got = append(got, p.Error())
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%d: got %q want %q", i, got, test.expected)
+		}
+	}
+}
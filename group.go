@@ -0,0 +1,137 @@
+package csv2md
+
+import "sort"
+
+// GroupOption configures the grouping of rows by GroupBy.
+type GroupOption func(*group)
+
+// SortGroups sorts the records by the group column's value before the
+// table is written so that all of a group's records are contiguous.  This
+// requires all of the CSV-encoded data to be read into memory.  The sort
+// is stable; within a group, records retain their original order.
+func SortGroups() GroupOption {
+	return func(g *group) {
+		g.sort = true
+	}
+}
+
+// HideGroupColumn omits the group column from the table; its value is
+// only shown in the group's subheader row.
+func HideGroupColumn() GroupOption {
+	return func(g *group) {
+		g.hide = true
+	}
+}
+
+type group struct {
+	column  string
+	sort    bool
+	natural bool
+	fold    bool
+	hide    bool
+	split   bool
+	heading string
+	index   int
+	fields  int
+	prev    string
+	seen    bool
+}
+
+// GroupBy groups the table's rows by the value of the named column.
+// Whenever the column's value changes, a subheader row of the form
+// "**column: value**" is written before the group's records.  Since GFM
+// tables have no colspan, the rest of the subheader row's cells are empty.
+//
+// Unless SortGroups is used, the CSV-encoded data is expected to be sorted
+// by the group column; the records are streamed and a new group starts
+// whenever the value changes.
+func (t *Transmogrifier) GroupBy(column string, opts ...GroupOption) {
+	g := &group{column: column}
+	for _, opt := range opts {
+		opt(g)
+	}
+	t.group = g
+}
+
+// prepareGroup resolves the group column against the header and, if the
+// groups are to be sorted, sorts the buffered records; the records of a
+// split table are ordered by their values' first rows, see SplitBy.
+func (t *Transmogrifier) prepareGroup(header []string) error {
+	t.group.index = columnIndex(header, t.group.column)
+	if t.group.index < 0 {
+		return UnknownColumnError{Name: t.group.column, operation: "group by"}
+	}
+	t.group.seen = false
+	if t.group.hide {
+		if t.hidden == nil {
+			t.hidden = make(map[int]bool)
+		}
+		t.hidden[t.group.index] = true
+	}
+	t.group.fields = len(header)
+	if t.group.sort {
+		values := make([]string, len(t.records))
+		for i, record := range t.records {
+			values[i] = t.group.value(record)
+		}
+		less := t.group.valueLess(values)
+		sort.SliceStable(t.records, func(i, j int) bool {
+			return less(t.group.value(t.records[i]), t.group.value(t.records[j]))
+		})
+	} else if t.group.split {
+		t.orderSplit()
+	}
+	return nil
+}
+
+func (g *group) value(record []string) string {
+	if g.index < len(record) {
+		return record[g.index]
+	}
+	return ""
+}
+
+// writeGroupRow writes a group subheader if the record starts a new group.
+func (t *Transmogrifier) writeGroupRow(r Renderer, record []string) error {
+	v := t.group.value(record)
+	if t.group.seen && v == t.group.prev {
+		return nil
+	}
+	t.group.seen = true
+	t.group.prev = v
+	if t.collapse != nil {
+		t.collapse.reset()
+	}
+	return t.writeGroup(r, t.fieldName(t.group.column), v)
+}
+
+// writeGroupRecord writes a group subheader row of the form
+// "**column: value**"; the rest of the row's cells are empty.  The cells
+// are padded to the widths of the prepared header's visible fields, which
+// are the widths the table's rows are padded to.
+func (t *Transmogrifier) writeGroupRecord(column, value string) error {
+	var widths []int
+	for i := 0; i < t.group.fields; i++ {
+		if !t.hidden[i] {
+			widths = append(widths, t.width(i))
+		}
+	}
+	n := len(widths)
+	if n == 0 {
+		n = 1
+	}
+	r := &t.rowBuf
+	r.reset(t.OuterPipes)
+	for j := 0; j < n; j++ {
+		v := " "
+		if j == 0 {
+			v = "**" + t.escape(column) + ": " + t.escape(value) + "**"
+		}
+		var width int
+		if j < len(widths) {
+			width = widths[j]
+		}
+		r.cell(v, width, left)
+	}
+	return t.writeBytes(r.end(t.newLine), "group row")
+}
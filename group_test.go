@@ -0,0 +1,64 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGroupBy(t *testing.T) {
+	csvData := []byte("Team,Name\nPlatform,Ann\nPlatform,Bob\nWeb,Cat\nPlatform,Dan\n")
+	tests := []struct {
+		column   string
+		opts     []GroupOption
+		expected string
+		err      string
+	}{
+		{"Team", nil, "Team|Name  \n---|---  \n**Team: Platform**|   \nPlatform|Ann  \nPlatform|Bob  \n**Team: Web**|   \nWeb|Cat  \n**Team: Platform**|   \nPlatform|Dan  \n", ""},
+		{"Team", []GroupOption{SortGroups()}, "Team|Name  \n---|---  \n**Team: Platform**|   \nPlatform|Ann  \nPlatform|Bob  \nPlatform|Dan  \n**Team: Web**|   \nWeb|Cat  \n", ""},
+		{"Team", []GroupOption{SortGroups(), HideGroupColumn()}, "Name  \n---  \n**Team: Platform**  \nAnn  \nBob  \nDan  \n**Team: Web**  \nCat  \n", ""},
+		{"Name", []GroupOption{HideGroupColumn()}, "Team  \n---  \n**Name: Ann**  \nPlatform  \n**Name: Bob**  \nPlatform  \n**Name: Cat**  \nWeb  \n**Name: Dan**  \nPlatform  \n", ""},
+		{"Region", nil, "", `group by: unknown column "Region"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.GroupBy(test.column, test.opts...)
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+// TestGroupByPrettyWidths checks that the group subheader rows are padded to
+// the prepared header's widths, which include the computed columns and
+// exclude the columns that are hidden after the group column is resolved.
+func TestGroupByPrettyWidths(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader([]byte("Team,Name,Style,Score\nWeb,Ann,bold,10\nWeb,Bob,,200\n")), &w)
+	calvin.Pretty = true
+	calvin.GroupBy("Team", HideGroupColumn())
+	calvin.SetRowStyleColumn("Style")
+	err := calvin.AddComputedExpr("Double", "Score * 2")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Name   |Score |Double  \n-------|------|------  \n**Team: Web**|      |        \n__Ann__|__10__|__20__  \nBob    |200   |400     \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
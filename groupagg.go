@@ -0,0 +1,149 @@
+package csv2md
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// groupAggregation is the aggregated table of AggregateBy.
+type groupAggregation struct {
+	column string
+	aggs   []groupAggregate
+}
+
+// groupAggregate is one of the aggregated columns of AggregateBy.
+type groupAggregate struct {
+	fn     string
+	column string // * is every record
+	index  int
+}
+
+// AggregateBy writes an aggregated table instead of the records: the
+// table has a row for each value of the named column, in the order that
+// the values first occur, followed by a column for each of the aggregates.
+// An aggregate is of the form aggregate(column), e.g. "sum(Sales)"; the
+// aggregates are those of SetColumnAggregate: sum, avg, count, min, and
+// max.  "count(*)" is the number of records in the group.  The aggregated
+// columns are named after their aggregate, e.g. "sum(Sales)"; they can be
+// renamed using the field names.
+//
+// The table must have a header record.  Computed columns and filtering
+// are applied before the records are aggregated; all other settings, e.g.
+// the field formats and the footer row, apply to the aggregated table.
+// Aggregating requires all of the CSV-encoded data to be read into memory.
+func (t *Transmogrifier) AggregateBy(column string, aggs ...string) error {
+	if len(aggs) == 0 {
+		return errors.New("aggregate by: no aggregates")
+	}
+	g := &groupAggregation{column: column}
+	for _, s := range aggs {
+		agg, err := parseGroupAggregate(s)
+		if err != nil {
+			return err
+		}
+		g.aggs = append(g.aggs, agg)
+	}
+	t.groupAggs = g
+	return nil
+}
+
+// parseGroupAggregate parses an aggregate of the form aggregate(column).
+func parseGroupAggregate(s string) (groupAggregate, error) {
+	v := strings.TrimSpace(s)
+	i := strings.IndexByte(v, '(')
+	if i < 0 || !strings.HasSuffix(v, ")") {
+		return groupAggregate{}, fmt.Errorf("aggregate %q: expected aggregate(column)", s)
+	}
+	fn, err := parseAggregate(v[:i])
+	if err != nil {
+		return groupAggregate{}, err
+	}
+	column := strings.TrimSpace(v[i+1 : len(v)-1])
+	if column == "" {
+		return groupAggregate{}, fmt.Errorf("aggregate %q: expected aggregate(column)", s)
+	}
+	if column == "*" && fn != aggCount {
+		return groupAggregate{}, fmt.Errorf("aggregate %q: only count can be of *", s)
+	}
+	return groupAggregate{fn: fn, column: column}, nil
+}
+
+// name returns the aggregated column's name.
+func (a groupAggregate) name() string {
+	return a.fn + "(" + a.column + ")"
+}
+
+// aggregateGroups reads all of the remaining records and buffers the
+// aggregated table.  The aggregated table's header is returned.  Like
+// transpose, the records have already been computed and filtered, so the
+// buffered records are not computed or filtered again.
+func (t *Transmogrifier) aggregateGroups(header []string) ([]string, error) {
+	if header == nil {
+		return nil, errors.New("aggregate by: the table must have a header record")
+	}
+	g := t.groupAggs
+	index := columnIndex(header, g.column)
+	if index < 0 {
+		return nil, UnknownColumnError{Name: g.column, operation: "aggregate by"}
+	}
+	for i := range g.aggs {
+		g.aggs[i].index = -1
+		if g.aggs[i].column == "*" {
+			continue
+		}
+		g.aggs[i].index = columnIndex(header, g.aggs[i].column)
+		if g.aggs[i].index < 0 {
+			return nil, UnknownColumnError{Name: g.aggs[i].column, operation: "aggregate by"}
+		}
+	}
+	var keys []string
+	groups := make(map[string][]*aggregate)
+	for {
+		record, err := t.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var key string
+		if index < len(record) {
+			key = record[index]
+		}
+		cells, ok := groups[key]
+		if !ok {
+			keys = append(keys, key)
+			cells = make([]*aggregate, len(g.aggs))
+			for i, agg := range g.aggs {
+				cells[i] = &aggregate{fn: agg.fn, index: agg.index, locale: t.locale}
+			}
+			groups[key] = cells
+		}
+		for _, a := range cells {
+			switch {
+			case a.index < 0:
+				a.add("*")
+			case a.index < len(record):
+				a.add(record[a.index])
+			}
+		}
+	}
+	t.records = make([][]string, len(keys))
+	for i, key := range keys {
+		record := []string{key}
+		for _, a := range groups[key] {
+			record = append(record, a.value())
+		}
+		t.records[i] = record
+	}
+	t.buffered = true
+	t.nComputed = len(t.records)
+	t.nFiltered = len(t.records)
+	t.header = []string{g.column}
+	for _, agg := range g.aggs {
+		t.header = append(t.header, agg.name())
+	}
+	return t.header, nil
+}
@@ -0,0 +1,88 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const groupAggCSV = "Region,Rep,Sales\nEast,Ann,10\nWest,Bob,5\nEast,Cy,\nEast,Ann,2.5\n"
+
+func TestAggregateBy(t *testing.T) {
+	tests := []struct {
+		column   string
+		aggs     []string
+		expected string
+		err      string
+	}{
+		{"Region", []string{"sum(Sales)", "count(*)"}, "Region|sum(Sales)|count(\\*)  \n---|---|---  \nEast|12.5|3  \nWest|5|1  \n", ""},
+		{"Region", []string{"COUNT(Sales)", " avg( Sales ) ", "min(Sales)", "max(Sales)"}, "Region|count(Sales)|avg(Sales)|min(Sales)|max(Sales)  \n---|---|---|---|---  \nEast|2|6.250|2.5|10.0  \nWest|1|5.00|5|5  \n", ""},
+		{"Rep", []string{"count(Region)"}, "Rep|count(Region)  \n---|---  \nAnn|2  \nBob|1  \nCy|1  \n", ""},
+		{"Region", nil, "", "aggregate by: no aggregates"},
+		{"Region", []string{"sum"}, "", `aggregate "sum": expected aggregate(column)`},
+		{"Region", []string{"sum()"}, "", `aggregate "sum()": expected aggregate(column)`},
+		{"Region", []string{"sum(*)"}, "", `aggregate "sum(*)": only count can be of *`},
+		{"Region", []string{"median(Sales)"}, "", `aggregate "median": unknown aggregate`},
+		{"Region", []string{"sum(Price)"}, "", `aggregate by: unknown column "Price"`},
+		{"Team", []string{"count(*)"}, "", `aggregate by: unknown column "Team"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(groupAggCSV), &w)
+		err := calvin.AggregateBy(test.column, test.aggs...)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestAggregateByFilter(t *testing.T) {
+	// the records are filtered before they are aggregated
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(groupAggCSV), &w)
+	err := calvin.SetFilter(`Rep != "Ann"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.AggregateBy("Region", "count(*)")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Region|count(\\*)  \n---|---  \nWest|1  \nEast|1  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
+
+func TestAggregateByPivot(t *testing.T) {
+	calvin := NewTransmogrifier(strings.NewReader(groupAggCSV), &bytes.Buffer{})
+	err := calvin.SetPivot("Region", "Rep", "Sales", "sum")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.AggregateBy("Region", "count(*)")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err == nil || err.Error() != "pivoting and group aggregation are mutually exclusive" {
+		t.Errorf("got error %v want the pivot and aggregation to be mutually exclusive", err)
+	}
+}
@@ -0,0 +1,68 @@
+package csv2md
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetHeaderStyle sets the text styling of the header record's fields; see
+// SetFieldStyle for the accepted values.  The field styles only style the
+// records, so this allows e.g. a bold header with plain records.  If there
+// is one value, it is the style of every field.  Setting the styles
+// replaces the previous ones; an unknown style is an error.  A Renderer,
+// see SetRenderer, is passed the unstyled field names, unless it is a
+// HeaderRenderer.
+func (t *Transmogrifier) SetHeaderStyle(vals []string) error {
+	styles := make([]string, len(vals))
+	for i, v := range vals {
+		styles[i] = parseStyle(v)
+		if styles[i] == "" && strings.TrimSpace(v) != "" {
+			return fmt.Errorf("header style: unknown style %q", v)
+		}
+	}
+	t.headerStyle = styles
+	return nil
+}
+
+// SetHeaderAlignment sets the alignment of the header record's fields; see
+// SetFieldAlignment for the accepted values.  A field without a header
+// alignment, or whose header alignment is auto, has its column's
+// alignment.  If there is one value, it is the alignment of every field.
+// In Markdown, the header record separator aligns the whole column, so
+// the header's alignment only changes how its cells are padded, see
+// SetFieldWidths and Pretty; in HTML tables, it is the alignment of the
+// header's cells.  Setting the alignments replaces the previous ones; an
+// unknown alignment is an error.
+func (t *Transmogrifier) SetHeaderAlignment(vals []string) error {
+	alignment := make([]string, len(vals))
+	for i, v := range vals {
+		a, ok := parseAlignment(v)
+		if !ok {
+			return fmt.Errorf("header alignment: unknown alignment %q", v)
+		}
+		alignment[i] = a
+	}
+	t.headerAlign = alignment
+	return nil
+}
+
+// headerAlignment returns the alignment of the header's i'th field.
+func (t *Transmogrifier) headerAlignment(i int) string {
+	a := headerSetting(t.headerAlign, i)
+	if a == "" || a == none || a == auto {
+		return t.alignment(i)
+	}
+	return a
+}
+
+// headerSetting returns the i'th field's value of the header setting; a
+// single value is every field's.
+func headerSetting(vals []string, i int) string {
+	if len(vals) == 1 {
+		return vals[0]
+	}
+	if i < len(vals) {
+		return vals[i]
+	}
+	return ""
+}
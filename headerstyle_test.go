@@ -0,0 +1,58 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHeaderStyle(t *testing.T) {
+	csvData := "Name,Qty\nAnn,3\nBob,12\n"
+	tests := []struct {
+		styles    []string
+		alignment []string
+		format    Format
+		pretty    bool
+		expected  string
+	}{
+		{[]string{"b"}, nil, GFM, false, "__Name__|__Qty__  \n---|--:  \nAnn|3  \nBob|12  \n"},
+		{[]string{"", "i"}, nil, GFM, false, "Name|_Qty_  \n---|--:  \nAnn|3  \nBob|12  \n"},
+		{[]string{"bold"}, []string{"", "l"}, GFM, true, "__Name__|__Qty__  \n--------|------:  \nAnn     |      3  \nBob     |     12  \n"},
+		{nil, []string{"c"}, GFM, true, "Name|Qty  \n----|--:  \nAnn |  3  \nBob | 12  \n"},
+		{[]string{"b"}, []string{"c", "auto"}, HTML, false, "<table>\n<thead>\n<tr>\n<th align=\"center\"><strong>Name</strong></th>\n<th align=\"right\"><strong>Qty</strong></th>\n</tr>\n</thead>\n<tbody>\n" +
+			"<tr>\n<td>Ann</td>\n<td align=\"right\">3</td>\n</tr>\n<tr>\n<td>Bob</td>\n<td align=\"right\">12</td>\n</tr>\n</tbody>\n</table>\n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.OutputFormat = test.format
+		calvin.Pretty = test.pretty
+		calvin.SetFieldAlignment([]string{"", "r"})
+		err := calvin.SetHeaderStyle(test.styles)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %s", i, err)
+		}
+		err = calvin.SetHeaderAlignment(test.alignment)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %s", i, err)
+		}
+		err = calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+	err := calvin.SetHeaderStyle([]string{"loud"})
+	if err == nil || err.Error() != `header style: unknown style "loud"` {
+		t.Errorf("got error %v want an unknown style error", err)
+	}
+	err = calvin.SetHeaderAlignment([]string{"up"})
+	if err == nil || err.Error() != `header alignment: unknown alignment "up"` {
+		t.Errorf("got error %v want an unknown alignment error", err)
+	}
+}
@@ -0,0 +1,56 @@
+package csv2md
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSourceHeading is the SourceHeading template used when multiple
+// sources are concatenated into a single output.
+const DefaultSourceHeading = "## {basename}"
+
+// Source describes the origin of CSV-encoded data.
+type Source struct {
+	// Path is the path to the source.
+	Path string
+	// ModTime is the source's modification time.
+	ModTime time.Time
+}
+
+// ExpandSourceHeading returns the heading template with its substitutions
+// replaced by information about the source.  The rows is the number of
+// data records in the source, excluding the header record.
+//
+// Supported substitutions:
+//    * {path}      the source's path
+//    * {file}      the source's file name, including the extension
+//    * {basename}  the source's file name, without the extension
+//    * {rows}      the number of data records
+//    * {modtime}   the source's modification time, RFC3339 formatted
+func ExpandSourceHeading(tmpl string, src Source, rows int) string {
+	file := filepath.Base(src.Path)
+	var modTime string
+	if !src.ModTime.IsZero() {
+		modTime = src.ModTime.Format(time.RFC3339)
+	}
+	r := strings.NewReplacer(
+		"{path}", src.Path,
+		"{file}", file,
+		"{basename}", strings.TrimSuffix(file, filepath.Ext(file)),
+		"{rows}", strconv.Itoa(rows),
+		"{modtime}", modTime,
+	)
+	return r.Replace(tmpl)
+}
+
+// writeSourceHeading writes the expanded SourceHeading followed by a blank
+// line.  The header must have already been read.  If the data has been
+// buffered, the row count is the number of buffered data records;
+// otherwise it is 0.
+func (t *Transmogrifier) writeSourceHeading() error {
+	rows := len(t.records)
+	nl := t.lineEnd
+	return t.write(ExpandSourceHeading(t.SourceHeading, t.Source, rows)+nl+nl, "source heading")
+}
@@ -0,0 +1,61 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestExpandSourceHeading(t *testing.T) {
+	mod := time.Date(2015, 11, 2, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		tmpl     string
+		src      Source
+		rows     int
+		expected string
+	}{
+		{"", Source{Path: "data/cars.csv"}, 2, ""},
+		{DefaultSourceHeading, Source{Path: "data/cars.csv"}, 2, "## cars"},
+		{"### {file} ({rows} rows)", Source{Path: "data/cars.csv"}, 2, "### cars.csv (2 rows)"},
+		{"{path}: {modtime}", Source{Path: "data/cars.csv", ModTime: mod}, 0, "data/cars.csv: 2015-11-02T10:30:00Z"},
+		{"{basename}", Source{Path: "cars"}, 0, "cars"},
+	}
+	for i, test := range tests {
+		h := ExpandSourceHeading(test.tmpl, test.src, test.rows)
+		if h != test.expected {
+			t.Errorf("%d: got %q want %q", i, h, test.expected)
+		}
+	}
+}
+
+func TestMDTableSourceHeading(t *testing.T) {
+	csvData := []byte("Manufacturer,Model\nFord,Focus\nChevy,Malibu\n")
+	tests := []struct {
+		heading   string
+		hasHeader bool
+		expected  string
+	}{
+		{"", true, "Manufacturer|Model  \n---|---  \nFord|Focus  \nChevy|Malibu  \n"},
+		{DefaultSourceHeading, true, "## cars\n\nManufacturer|Model  \n---|---  \nFord|Focus  \nChevy|Malibu  \n\n"},
+		{"## {basename}: {rows} rows", true, "## cars: 2 rows\n\nManufacturer|Model  \n---|---  \nFord|Focus  \nChevy|Malibu  \n\n"},
+		{"{rows}", false, "3\n\nColumn 1|Column 2  \n---|---  \nManufacturer|Model  \nFord|Focus  \nChevy|Malibu  \n\n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.HasHeaderRecord = test.hasHeader
+		calvin.SourceHeading = test.heading
+		calvin.Source = Source{Path: "data/cars.csv"}
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+		if calvin.BytesWritten() != int64(w.Len()) {
+			t.Errorf("%d: bytes written: got %d want %d", i, calvin.BytesWritten(), w.Len())
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package csv2md
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// htmlAlign are the align attribute values for the field alignments.  The
+// align attribute is used, instead of a style, because GitHub removes
+// style attributes when rendering HTML.
+var htmlAlign = map[Alignment]string{
+	AlignLeft:   "left",
+	AlignCenter: "center",
+	AlignRight:  "right",
+}
+
+// htmlTags are the elements used for the text styles.
+var htmlTags = map[Style]string{
+	StyleBold:          "strong",
+	StyleItalic:        "em",
+	StyleStrikethrough: "del",
+}
+
+// htmlTable renders HTML tables.  Unlike GFM tables, HTML tables can have
+// cells with multiple lines and cells that span columns; the group
+// subheaders span the table.
+type htmlTable struct {
+	t    *Transmogrifier
+	body bool
+	foot bool
+	cols int
+	rows int // the number of records, for the striping
+}
+
+func (h *htmlTable) nl() string {
+	return h.t.lineEnd
+}
+
+func (h *htmlTable) WriteHeader(names []string) error {
+	return h.WriteHeaderCells(nameCells(names))
+}
+
+func (h *htmlTable) WriteHeaderCells(cells []Cell) error {
+	nl := h.nl()
+	var b strings.Builder
+	b.WriteString(h.table() + h.caption() + "<thead" + classAttr(h.t.htmlClasses.Head) + ">" + nl + "<tr>" + nl)
+	i := -1
+	for _, c := range cells {
+		i = h.t.nextField(i)
+		h.cols++
+		v := styleTags(h.text(c.Value), c.Styles)
+		b.WriteString("<th" + alignAttr(c.Align) + classAttr(h.t.columnClasses[i]) + ">" + v + "</th>" + nl)
+	}
+	b.WriteString("</tr>" + nl + "</thead>" + nl + h.tbody())
+	h.body = true
+	return h.t.write(b.String(), "html header")
+}
+
+// WriteSeparator does nothing; the header's cells are aligned by their
+// align attributes.
+func (h *htmlTable) WriteSeparator(alignment []Alignment) error {
+	return nil
+}
+
+func (h *htmlTable) WriteGroup(column, value string) error {
+	err := h.open()
+	if err != nil {
+		return err
+	}
+	var span string
+	if h.cols > 1 {
+		span = fmt.Sprintf(" colspan=\"%d\"", h.cols)
+	}
+	nl := h.nl()
+	return h.t.write("<tr>"+nl+"<td"+span+"><strong>"+h.text(column+": "+value)+"</strong></td>"+nl+"</tr>"+nl, "html group row")
+}
+
+// WriteRow writes a record's row; the cells of the record's negative
+// values have their negative style's class, see SetNegativeStyle, and
+// those of its truncated values have their full values as their titles.
+func (h *htmlTable) WriteRow(cells []Cell) error {
+	if h.cols == 0 {
+		h.cols = len(cells)
+	}
+	err := h.open()
+	if err != nil {
+		return err
+	}
+	nl := h.nl()
+	var b strings.Builder
+	h.rows++
+	classes := h.t.stripeClasses(h.rows)
+	if h.t.rowStyle != nil && len(cells) > 0 {
+		classes = append(classes, cells[0].RowClasses...)
+	}
+	b.WriteString("<tr" + classAttr(classes...) + ">" + nl)
+	i := -1
+	for _, c := range cells {
+		i = h.t.nextField(i)
+		v := styleTags(h.text(c.Value), c.Styles)
+		var title string
+		if full, ok := h.t.truncated[i]; ok {
+			title = " title=\"" + html.EscapeString(full) + "\""
+		}
+		var negative string
+		if len(h.t.negatives) > 0 {
+			negative = h.t.negativeClass(i, h.t.raw)
+		}
+		class := classAttr(h.t.columnClasses[i], negative)
+		tag, scope := h.rowHeaderCell(i)
+		b.WriteString("<" + tag + scope + alignAttr(c.Align) + class + title + ">" + v + "</" + tag + ">" + nl)
+	}
+	b.WriteString("</tr>" + nl)
+	return h.t.write(b.String(), "html record")
+}
+
+func (h *htmlTable) WriteFooter(cells []Cell) error {
+	err := h.open()
+	if err != nil {
+		return err
+	}
+	h.foot = true
+	nl := h.nl()
+	var b strings.Builder
+	b.WriteString("</tbody>" + nl + "<tfoot>" + nl + "<tr>" + nl)
+	i := -1
+	for _, c := range cells {
+		i = h.t.nextField(i)
+		v := styleTags(h.text(c.Value), c.Styles)
+		tag, scope := h.rowHeaderCell(i)
+		b.WriteString("<" + tag + scope + alignAttr(c.Align) + ">" + v + "</" + tag + ">" + nl)
+	}
+	b.WriteString("</tr>" + nl + "</tfoot>" + nl)
+	return h.t.write(b.String(), "html footer")
+}
+
+// open starts the table, if it hasn't been started; a table without a
+// header only has a body.
+func (h *htmlTable) open() error {
+	if h.body {
+		return nil
+	}
+	h.body = true
+	return h.t.write(h.table()+h.caption()+h.tbody(), "html table")
+}
+
+// table returns the table's start tag.
+func (h *htmlTable) table() string {
+	h.t.htmlTables++
+	return "<table" + h.t.tableAttrs(h.t.htmlTables) + ">" + h.nl()
+}
+
+// tbody returns the table body's start tag.
+func (h *htmlTable) tbody() string {
+	return "<tbody" + classAttr(h.t.htmlClasses.Body) + ">" + h.nl()
+}
+
+func (h *htmlTable) Close() error {
+	if !h.body {
+		return nil
+	}
+	nl := h.nl()
+	if h.foot {
+		// the footer ended the body
+		return h.t.write("</table>"+nl, "html table")
+	}
+	return h.t.write("</tbody>"+nl+"</table>"+nl, "html table")
+}
+
+// alignAttr returns the align attribute for the alignment, if it is
+// aligned.
+func alignAttr(alignment Alignment) string {
+	a, ok := htmlAlign[alignment]
+	if !ok {
+		return ""
+	}
+	return " align=\"" + a + "\""
+}
+
+// styleTags wraps the text in the styles' elements; the first style is the
+// innermost.
+func styleTags(v string, styles []Style) string {
+	for _, s := range styles {
+		tag := htmlTags[s]
+		v = "<" + tag + ">" + v + "</" + tag + ">"
+	}
+	return v
+}
+
+// htmlText escapes the value for use as HTML text; line breaks within the
+// value are kept as <br> elements.
+func htmlText(v string) string {
+	v = strings.Replace(v, "\r\n", "\n", -1)
+	return strings.Replace(html.EscapeString(v), "\n", "<br>", -1)
+}
@@ -0,0 +1,94 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	csvData := []byte("Make,Model,Notes\nFord,Focus,\"a <b> & \"\"c\"\"\"\nKia,Rio,\"two\nlines\"\n")
+	tests := []struct {
+		alignment []string
+		style     []string
+		header    bool
+		expected  string
+	}{
+		{nil, nil, true, "<table>\n<thead>\n<tr>\n<th>Make</th>\n<th>Model</th>\n<th>Notes</th>\n</tr>\n</thead>\n<tbody>\n" +
+			"<tr>\n<td>Ford</td>\n<td>Focus</td>\n<td>a &lt;b&gt; &amp; &#34;c&#34;</td>\n</tr>\n" +
+			"<tr>\n<td>Kia</td>\n<td>Rio</td>\n<td>two<br>lines</td>\n</tr>\n</tbody>\n</table>\n"},
+		{[]string{"l", "c", "r"}, []string{"b", "i", "s"}, true, "<table>\n<thead>\n<tr>\n<th align=\"left\">Make</th>\n<th align=\"center\">Model</th>\n<th align=\"right\">Notes</th>\n</tr>\n</thead>\n<tbody>\n" +
+			"<tr>\n<td align=\"left\"><strong>Ford</strong></td>\n<td align=\"center\"><em>Focus</em></td>\n<td align=\"right\"><del>a &lt;b&gt; &amp; &#34;c&#34;</del></td>\n</tr>\n" +
+			"<tr>\n<td align=\"left\"><strong>Kia</strong></td>\n<td align=\"center\"><em>Rio</em></td>\n<td align=\"right\"><del>two<br>lines</del></td>\n</tr>\n</tbody>\n</table>\n"},
+		{nil, nil, false, "<table>\n<tbody>\n" +
+			"<tr>\n<td>Make</td>\n<td>Model</td>\n<td>Notes</td>\n</tr>\n" +
+			"<tr>\n<td>Ford</td>\n<td>Focus</td>\n<td>a &lt;b&gt; &amp; &#34;c&#34;</td>\n</tr>\n" +
+			"<tr>\n<td>Kia</td>\n<td>Rio</td>\n<td>two<br>lines</td>\n</tr>\n</tbody>\n</table>\n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.OutputFormat = HTML
+		calvin.HasHeaderRecord = test.header
+		calvin.SetFieldAlignment(test.alignment)
+		calvin.SetFieldStyle(test.style)
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestHTMLGroupBy(t *testing.T) {
+	csvData := []byte("Team,Name,Amount\nWeb,Ann,-1\nWeb,Bob,2\n")
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+	calvin.OutputFormat = HTML
+	calvin.GroupBy("Team", HideGroupColumn())
+	err := calvin.ParseStyleIf("Amount<0=b")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "<table>\n<thead>\n<tr>\n<th>Name</th>\n<th>Amount</th>\n</tr>\n</thead>\n<tbody>\n" +
+		"<tr>\n<td colspan=\"2\"><strong>Team: Web</strong></td>\n</tr>\n" +
+		"<tr>\n<td>Ann</td>\n<td><strong>-1</strong></td>\n</tr>\n" +
+		"<tr>\n<td>Bob</td>\n<td>2</td>\n</tr>\n</tbody>\n</table>\n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Format
+		err      string
+	}{
+		{"", GFM, ""},
+		{"GFM", GFM, ""},
+		{"html", HTML, ""},
+		{"box", Box, ""},
+		{"ascii", ASCIIBox, ""},
+		{"pdf", GFM, `unknown output format "pdf"`},
+	}
+	for i, test := range tests {
+		f, err := ParseFormat(test.value)
+		var s string
+		if err != nil {
+			s = err.Error()
+		}
+		if s != test.err {
+			t.Errorf("%d: got error %q want %q", i, s, test.err)
+		}
+		if f != test.expected {
+			t.Errorf("%d: got %d want %d", i, f, test.expected)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package csv2md
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// HTMLClasses are the class attributes, and the id, of the elements of HTML
+// tables, so that the tables can be styled by the site that they are part
+// of; see SetHTMLClasses.  An empty class is not written.
+type HTMLClasses struct {
+	// ID is the table's id; when the table is split or chunked, each
+	// table's id is suffixed with its number, e.g. sales-2, so that the
+	// ids are unique.
+	ID string
+	// Table is the table element's class.
+	Table string
+	// Head is the thead element's class.
+	Head string
+	// Body is the tbody element's class.
+	Body string
+	// Row is the class of each record's tr element.
+	Row string
+	// Odd and Even are the classes of the odd and even records' tr
+	// elements, for striping; the table's first record is odd.  They are
+	// in addition to the Row class.
+	Odd, Even string
+	// Columns are the classes of the named columns' th and td elements.
+	Columns map[string]string
+}
+
+// ParseHTMLClasses parses a comma separated list of element=class pairs;
+// the elements are id, table, thead, tbody, tr, odd, even, and
+// td:column, for a column's class, e.g.
+// "id=sales,table=data,odd=odd,even=even,td:Price=number".
+func ParseHTMLClasses(s string) (HTMLClasses, error) {
+	var c HTMLClasses
+	for _, v := range strings.Split(s, ",") {
+		i := strings.Index(v, "=")
+		if i < 0 {
+			return HTMLClasses{}, fmt.Errorf("html classes %q: expected element=class", v)
+		}
+		key, class := strings.TrimSpace(v[:i]), strings.TrimSpace(v[i+1:])
+		switch strings.ToLower(key) {
+		case "id":
+			c.ID = class
+		case "table":
+			c.Table = class
+		case "thead":
+			c.Head = class
+		case "tbody":
+			c.Body = class
+		case "tr":
+			c.Row = class
+		case "odd":
+			c.Odd = class
+		case "even":
+			c.Even = class
+		default:
+			if !strings.HasPrefix(strings.ToLower(key), "td:") || len(key) == len("td:") {
+				return HTMLClasses{}, fmt.Errorf("html classes %q: unknown element %q", v, key)
+			}
+			if c.Columns == nil {
+				c.Columns = make(map[string]string)
+			}
+			c.Columns[key[len("td:"):]] = class
+		}
+	}
+	return c, nil
+}
+
+// SetHTMLClasses sets the class attributes, and the id, of the HTML
+// tables' elements; see OutputFormat.  The other output formats ignore
+// them.  Every column must be the name of a field; otherwise MDTable
+// returns an UnknownColumnError.  Setting the classes replaces the
+// previous ones.
+func (t *Transmogrifier) SetHTMLClasses(c HTMLClasses) {
+	cols := make(map[string]string, len(c.Columns))
+	for k, v := range c.Columns {
+		cols[k] = v
+	}
+	c.Columns = cols
+	t.htmlClasses = c
+}
+
+// prepareHTMLClasses resolves the HTML classes' columns against the
+// header, and restarts the numbering of the tables.
+func (t *Transmogrifier) prepareHTMLClasses(header []string) error {
+	t.htmlTables = 0
+	t.columnClasses = nil
+	for column, class := range t.htmlClasses.Columns {
+		i := columnIndex(header, column)
+		if i < 0 {
+			return UnknownColumnError{Name: column, operation: "html class"}
+		}
+		if t.columnClasses == nil {
+			t.columnClasses = make(map[int]string)
+		}
+		t.columnClasses[i] = class
+	}
+	return nil
+}
+
+// tableAttrs returns the attributes of the n'th table element.
+func (t *Transmogrifier) tableAttrs(n int) string {
+	var id string
+	if t.htmlClasses.ID != "" {
+		id = t.htmlClasses.ID
+		if n > 1 {
+			id = fmt.Sprintf("%s-%d", id, n)
+		}
+		id = " id=\"" + html.EscapeString(id) + "\""
+	}
+	return id + classAttr(t.htmlClasses.Table)
+}
+
+// stripeClasses returns the classes of the n'th record's row, starting
+// from 1.
+func (t *Transmogrifier) stripeClasses(n int) []string {
+	stripe := t.htmlClasses.Odd
+	if n%2 == 0 {
+		stripe = t.htmlClasses.Even
+	}
+	return []string{t.htmlClasses.Row, stripe}
+}
+
+// classAttr returns the class attribute of the classes; the empty classes
+// are omitted.  If all of the classes are empty, there is no attribute.
+func classAttr(classes ...string) string {
+	var names []string
+	for _, c := range classes {
+		if c != "" {
+			names = append(names, c)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return " class=\"" + html.EscapeString(strings.Join(names, " ")) + "\""
+}
@@ -0,0 +1,90 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseHTMLClasses(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected HTMLClasses
+		err      string
+	}{
+		{"table=data", HTMLClasses{Table: "data"}, ""},
+		{"id=sales, TABLE=data wide,thead=head,tbody=body,tr=row,odd=odd,even=even,td:Price=number",
+			HTMLClasses{ID: "sales", Table: "data wide", Head: "head", Body: "body", Row: "row", Odd: "odd", Even: "even", Columns: map[string]string{"Price": "number"}}, ""},
+		{"table", HTMLClasses{}, `html classes "table": expected element=class`},
+		{"td=number", HTMLClasses{}, `html classes "td=number": unknown element "td"`},
+		{"td:=number", HTMLClasses{}, `html classes "td:=number": unknown element "td:"`},
+	}
+	for i, test := range tests {
+		c, err := ParseHTMLClasses(test.value)
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: got no error want %q", i, test.err)
+			continue
+		}
+		if c.ID != test.expected.ID || c.Table != test.expected.Table || c.Head != test.expected.Head ||
+			c.Body != test.expected.Body || c.Row != test.expected.Row || c.Odd != test.expected.Odd ||
+			c.Even != test.expected.Even || len(c.Columns) != len(test.expected.Columns) {
+			t.Errorf("%d: got %+v want %+v", i, c, test.expected)
+			continue
+		}
+		for k, v := range test.expected.Columns {
+			if c.Columns[k] != v {
+				t.Errorf("%d: %s: got %q want %q", i, k, c.Columns[k], v)
+			}
+		}
+	}
+}
+
+func TestSetHTMLClasses(t *testing.T) {
+	csvData := "Item,Price\nPen,-1\nInk,2\nNib,3\n"
+	tests := []struct {
+		classes   HTMLClasses
+		chunkSize int
+		expected  string
+	}{
+		{HTMLClasses{Table: "data", Head: "head", Body: "body", Odd: "odd", Even: "even"}, 0,
+			"<table class=\"data\">\n<thead class=\"head\">\n<tr>\n<th>Item</th>\n<th>Price</th>\n</tr>\n</thead>\n<tbody class=\"body\">\n" +
+				"<tr class=\"odd\">\n<td>Pen</td>\n<td class=\"neg\">-1</td>\n</tr>\n" +
+				"<tr class=\"even\">\n<td>Ink</td>\n<td>2</td>\n</tr>\n" +
+				"<tr class=\"odd\">\n<td>Nib</td>\n<td>3</td>\n</tr>\n</tbody>\n</table>\n"},
+		{HTMLClasses{ID: "sales", Row: "row", Even: "even", Columns: map[string]string{"Price": "number"}}, 2,
+			"<table id=\"sales\">\n<thead>\n<tr>\n<th>Item</th>\n<th class=\"number\">Price</th>\n</tr>\n</thead>\n<tbody>\n" +
+				"<tr class=\"row\">\n<td>Pen</td>\n<td class=\"number neg\">-1</td>\n</tr>\n" +
+				"<tr class=\"row even\">\n<td>Ink</td>\n<td class=\"number\">2</td>\n</tr>\n</tbody>\n</table>\n\n" +
+				"<table id=\"sales-2\">\n<thead>\n<tr>\n<th>Item</th>\n<th class=\"number\">Price</th>\n</tr>\n</thead>\n<tbody>\n" +
+				"<tr class=\"row\">\n<td>Nib</td>\n<td class=\"number\">3</td>\n</tr>\n</tbody>\n</table>\n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.OutputFormat = HTML
+		calvin.ChunkSize = test.chunkSize
+		calvin.SetHTMLClasses(test.classes)
+		calvin.SetColumnNegative("Price", NegativeStyle{Class: "neg"})
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+	calvin.SetHTMLClasses(HTMLClasses{Columns: map[string]string{"Cost": "number"}})
+	err := calvin.MDTable()
+	if err == nil || err.Error() != `html class: unknown column "Cost"` {
+		t.Errorf("got error %v want an unknown column error", err)
+	}
+}
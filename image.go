@@ -0,0 +1,58 @@
+package csv2md
+
+import (
+	"io"
+	"strings"
+)
+
+// ImageOption configures the images of SetColumnImage.
+type ImageOption func(*image)
+
+// ImageAlt sets the column whose value is used as the image's alt text.
+// If the record's value is empty, the header's name for the image column
+// is used.
+func ImageAlt(column string) ImageOption {
+	return func(im *image) {
+		im.alt = column
+	}
+}
+
+type image struct {
+	t   *Transmogrifier
+	alt string
+}
+
+// SetColumnImage makes the named column an image column: each value is
+// the URL, or path, of an image and is written as a Markdown image,
+// ![alt](url).  The alt text is the column's name in the header, unless
+// an ImageAlt column is set.  Empty values are written as empty cells.
+//
+// An image column replaces any cell template the column has; see
+// SetColumnTemplate.  The images are created after the value maps and
+// before the field's styling.
+func (t *Transmogrifier) SetColumnImage(column string, opts ...ImageOption) {
+	im := &image{t: t}
+	for _, opt := range opts {
+		opt(im)
+	}
+	ct := &cellTemplate{column: column, exec: im.write, kind: "image"}
+	if im.alt != "" {
+		ct.refs = []string{im.alt}
+	}
+	t.setColumnTemplate(ct)
+}
+
+func (im *image) write(w io.Writer, data CellData) error {
+	src := strings.TrimSpace(string(data.Value))
+	if src == "" {
+		return nil
+	}
+	alt := im.t.fieldName(data.Column)
+	if im.alt != "" {
+		if s := string(data.Fields[im.alt]); s != "" {
+			alt = s
+		}
+	}
+	_, err := io.WriteString(w, "!["+linkText(im.t.escape(alt))+"]("+linkDestination(src)+")")
+	return err
+}
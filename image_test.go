@@ -0,0 +1,44 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetColumnImage(t *testing.T) {
+	csvData := []byte("Name,Avatar\nAnn,https://example.com/ann.png\nBob_B,img/bob smith.png\nCat,\n")
+	tests := []struct {
+		opts     []ImageOption
+		names    map[string]string
+		expected string
+		err      string
+	}{
+		{nil, nil, "Name|Avatar  \n---|---  \nAnn|![Avatar](https://example.com/ann.png)  \nBob\\_B|![Avatar](img/bob%20smith.png)  \nCat|   \n", ""},
+		// the alt text is the header's name
+		{nil, map[string]string{"Avatar": "Photo [1]"}, "Name|Photo [1]  \n---|---  \nAnn|![Photo \\[1\\]](https://example.com/ann.png)  \nBob\\_B|![Photo \\[1\\]](img/bob%20smith.png)  \nCat|   \n", ""},
+		{[]ImageOption{ImageAlt("Name")}, nil, "Name|Avatar  \n---|---  \nAnn|![Ann](https://example.com/ann.png)  \nBob\\_B|![Bob\\_B](img/bob%20smith.png)  \nCat|   \n", ""},
+		{[]ImageOption{ImageAlt("Title")}, nil, "", `image: unknown column "Title"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.SetColumnImage("Avatar", test.opts...)
+		if test.names != nil {
+			calvin.SetFieldNameMap(test.names)
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package csv2md
+
+import "strings"
+
+// columnType is the type of a column's values, inferred from the data.
+type columnType int
+
+const (
+	// emptyColumn is a column without any values.
+	emptyColumn columnType = iota
+	numberColumn
+	boolColumn
+	textColumn
+)
+
+// inferColumnType returns the type of the i'th field of the records, whose
+// numbers are those of the locale.  Empty values are ignored.  A column
+// that has both number and boolean values is a text column.
+func inferColumnType(records [][]string, i int, l locale) columnType {
+	typ := emptyColumn
+	for _, record := range records {
+		if i >= len(record) {
+			continue
+		}
+		v := strings.TrimSpace(record[i])
+		if v == "" {
+			continue
+		}
+		vt := textColumn
+		switch {
+		case isNumber(v, l):
+			vt = numberColumn
+		case isBool(v):
+			vt = boolColumn
+		}
+		if vt == textColumn || (typ != emptyColumn && vt != typ) {
+			return textColumn
+		}
+		typ = vt
+	}
+	return typ
+}
+
+// InferFieldFormats returns a starter format for each of the CSV-encoded
+// data's fields, inferred from its header record and a sample of its
+// records, see AutoAlignSample: the field's type and the alignment that
+// auto alignment would give it.  If the data has a header record, each
+// format is for its field's Column; otherwise, the formats are by
+// position.  The formats can be written as a format spec, using
+// WriteFieldFormats, and edited by hand.  The data's records are not
+// written.
+func (t *Transmogrifier) InferFieldFormats() ([]FieldFormat, error) {
+	t.prepareRagged()
+	header, err := t.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	records, err := t.sample(t.AutoAlignSample)
+	if err != nil {
+		return nil, err
+	}
+	n := len(header)
+	if n == 0 && len(records) > 0 {
+		n = len(records[0])
+	}
+	formats := make([]FieldFormat, n)
+	for i := range formats {
+		if t.HasHeaderRecord {
+			formats[i].Column = header[i]
+		}
+		typ := inferColumnType(records, i, t.locale)
+		switch typ {
+		case numberColumn:
+			formats[i].Align = "right"
+		case boolColumn:
+			formats[i].Align = "center"
+		case textColumn:
+			formats[i].Align = "left"
+		default:
+			continue
+		}
+		formats[i].Type = typ.String()
+	}
+	return formats, nil
+}
+
+// isNumber returns whether the value is one of the locale's numbers.
+func isNumber(v string, l locale) bool {
+	_, _, ok := l.number(v)
+	return ok
+}
+
+// isBool returns whether the value is a boolean: true, false, yes, or no,
+// in any case.
+func isBool(v string) bool {
+	switch strings.ToLower(v) {
+	case "true", "false", "yes", "no":
+		return true
+	}
+	return false
+}
+
+// resolveAutoAlignment replaces any auto field alignments with the
+// alignment inferred from a sample of the records, or from the field's
+// type if it has been set; see FieldFormat.  Numeric columns are
+// right justified, boolean columns are centered, other columns are left
+// justified, and columns without values are not justified.  If AutoAlign
+// is true and no field alignment has been set, every field is auto.
+func (t *Transmogrifier) resolveAutoAlignment() error {
+	var records [][]string
+	var sampled bool
+	if t.AutoAlign && len(t.fieldAlignment) == 0 {
+		n := len(t.header)
+		if n == 0 {
+			var err error
+			records, err = t.sample(t.AutoAlignSample)
+			if err != nil {
+				return err
+			}
+			sampled = true
+			if len(records) > 0 {
+				n = len(records[0])
+			}
+		}
+		for i := 0; i < n; i++ {
+			t.fieldAlignment = append(t.fieldAlignment, auto)
+		}
+	}
+	for i, v := range t.fieldAlignment {
+		if v != auto {
+			continue
+		}
+		typ, ok := t.fieldTypes[i]
+		if !ok {
+			if !sampled {
+				var err error
+				records, err = t.sample(t.AutoAlignSample)
+				if err != nil {
+					return err
+				}
+				sampled = true
+			}
+			typ = inferColumnType(records, i, t.locale)
+		}
+		switch typ {
+		case numberColumn:
+			t.fieldAlignment[i] = right
+		case boolColumn:
+			t.fieldAlignment[i] = centered
+		case textColumn:
+			t.fieldAlignment[i] = left
+		default:
+			t.fieldAlignment[i] = none
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package csv2md
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInferColumnType(t *testing.T) {
+	records := [][]string{
+		{"1", "a", "", "1.5", "", "true", "yes", "1"},
+		{"2", "3", "", "-2e3", "x", "False", "NO", "true"},
+		{"", "4", "", "", "", "", "", ""},
+	}
+	expected := []columnType{numberColumn, textColumn, emptyColumn, numberColumn, textColumn, boolColumn, boolColumn, textColumn, emptyColumn}
+	for i, want := range expected {
+		got := inferColumnType(records, i, locale{})
+		if got != want {
+			t.Errorf("%d: got %d want %d", i, got, want)
+		}
+	}
+}
+
+func TestAutoAlignment(t *testing.T) {
+	csvData := []byte("ID,Name,Qty,Note,Price\n1,Ann,3,,9.99\n2,Bob,4,,10\n3,Cat,x,,1\n")
+	tests := []struct {
+		alignment []string
+		sample    int
+		expected  string
+	}{
+		{[]string{"l", "auto", "auto", "auto", "r"}, 0, ":--|:--|:--|---|--:"},
+		{[]string{"auto", "auto", "auto", "auto", "auto"}, 0, "--:|:--|:--|---|--:"},
+		// the third record isn't sampled
+		{[]string{"auto", "auto", "auto", "auto", "auto"}, 2, "--:|:--|--:|---|--:"},
+		// explicit alignments are never overridden
+		{[]string{"c", "r", "l", "", "auto"}, 0, ":--:|--:|:--|---|--:"},
+		{[]string{"AUTO", "", "Auto"}, 0, "--:|---|:--|---|---"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.AutoAlignSample = test.sample
+		calvin.SetFieldAlignment(test.alignment)
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		expected := "ID|Name|Qty|Note|Price  \n" + test.expected + "  \n1|Ann|3| |9.99  \n2|Bob|4| |10  \n3|Cat|x| |1  \n"
+		if w.String() != expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), expected)
+		}
+	}
+}
+
+func TestAutoAlignmentFmt(t *testing.T) {
+	csvData := []byte("a,b,c,d\n1,x,2,3\n")
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+	err := calvin.SetFmt(bytes.NewReader([]byte("A,B,C,D\nl,auto,auto,r\n")))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "A|B|C|D  \n:--|:--|--:|--:  \n1|x|2|3  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
+
+func TestAutoAlign(t *testing.T) {
+	csvData := []byte("ID,Name,Active,Note\n1,Ann,true,\n2,Bob,false,\n")
+	tests := []struct {
+		autoAlign bool
+		header    bool
+		alignment []string
+		expected  string
+	}{
+		{true, true, nil, "ID|Name|Active|Note  \n--:|:--|:--:|---  \n1|Ann|true|   \n2|Bob|false|   \n"},
+		{true, false, nil, "--:|:--|:--:|---  \n"},
+		// an explicit alignment is used as is
+		{true, true, []string{"l"}, "ID|Name|Active|Note  \n:--|---|---|---  \n1|Ann|true|   \n2|Bob|false|   \n"},
+		{false, true, nil, "ID|Name|Active|Note  \n---|---|---|---  \n1|Ann|true|   \n2|Bob|false|   \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.AutoAlign = test.autoAlign
+		calvin.HasHeaderRecord = test.header
+		calvin.SetFieldAlignment(test.alignment)
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if !test.header {
+			// no header is written, but the alignment is still inferred
+			if len(calvin.fieldAlignment) != 4 || calvin.fieldAlignment[0] != left || calvin.fieldAlignment[2] != left {
+				t.Errorf("%d: got alignment %q", i, calvin.fieldAlignment)
+			}
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestInferFieldFormats(t *testing.T) {
+	tests := []struct {
+		data     string
+		header   bool
+		expected []FieldFormat
+	}{
+		{"Make,Price,Used,Notes\nFord,18000,yes,\nKia,16500.5,no,\n", true, []FieldFormat{
+			{Column: "Make", Align: "left", Type: "text"},
+			{Column: "Price", Align: "right", Type: "number"},
+			{Column: "Used", Align: "center", Type: "boolean"},
+			{Column: "Notes"},
+		}},
+		{"Ford,18000\nKia,16500\n", false, []FieldFormat{
+			{Align: "left", Type: "text"},
+			{Align: "right", Type: "number"},
+		}},
+		{"Make,Price\n", true, []FieldFormat{{Column: "Make"}, {Column: "Price"}}},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(strings.NewReader(test.data), &bytes.Buffer{})
+		calvin.HasHeaderRecord = test.header
+		formats, err := calvin.InferFieldFormats()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(formats, test.expected) {
+			t.Errorf("%d: got %+v want %+v", i, formats, test.expected)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package csv2md
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MissingMarkerError occurs when a document does not contain a table's
+// begin marker, or a begin marker is not followed by an end marker.
+type MissingMarkerError struct {
+	Name string
+	End  bool
+}
+
+func (e MissingMarkerError) Error() string {
+	if e.End {
+		return fmt.Sprintf("%q: %s marker not found", e.Name, endMarker)
+	}
+	return fmt.Sprintf("%q: %s marker not found", e.Name, beginMarker)
+}
+
+const (
+	beginMarker = "csv2md:begin"
+	endMarker   = "csv2md:end"
+)
+
+// Inject copies the Markdown document, doc, to w, replacing the content
+// between the table's markers with the table.  The markers are HTML
+// comments, each on its own line:
+//
+//	<!-- csv2md:begin name -->
+//	<!-- csv2md:end -->
+//
+// The markers are kept so that the document can be updated again.  Every
+// block with the name is replaced; blocks with other names are left as is.
+// If the document doesn't have a block with the name, a MissingMarkerError
+// is returned.
+func Inject(doc io.Reader, w io.Writer, name string, table []byte) error {
+	br := bufio.NewReader(doc)
+	bw := bufio.NewWriter(w)
+	var found, inBlock bool
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		marker, markerName := parseMarker(line)
+		switch {
+		case inBlock:
+			if marker == beginMarker {
+				// a block can't contain another block
+				return MissingMarkerError{Name: name, End: true}
+			}
+			if marker != endMarker {
+				continue
+			}
+			inBlock = false
+			bw.WriteString(line)
+		case marker == beginMarker && markerName == name:
+			found, inBlock = true, true
+			nl := "\n"
+			if strings.HasSuffix(line, "\r\n") {
+				nl = "\r\n"
+			}
+			if !strings.HasSuffix(line, "\n") {
+				line += nl
+			}
+			bw.WriteString(line)
+			bw.Write(table)
+			if len(table) > 0 && table[len(table)-1] != '\n' {
+				bw.WriteString(nl)
+			}
+		default:
+			bw.WriteString(line)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if inBlock {
+		return MissingMarkerError{Name: name, End: true}
+	}
+	if !found {
+		return MissingMarkerError{Name: name}
+	}
+	return bw.Flush()
+}
+
+// parseMarker returns the marker and its name, if the line is a marker.
+func parseMarker(line string) (marker, name string) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "<!--") || !strings.HasSuffix(line, "-->") {
+		return "", ""
+	}
+	fields := strings.Fields(line[4 : len(line)-3])
+	switch {
+	case len(fields) == 2 && fields[0] == beginMarker:
+		return beginMarker, fields[1]
+	case len(fields) == 1 && fields[0] == endMarker:
+		return endMarker, ""
+	}
+	return "", ""
+}
@@ -0,0 +1,44 @@
+package csv2md
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInject(t *testing.T) {
+	table := []byte("a|b  \n---|---  \n1|2  \n")
+	tests := []struct {
+		doc      string
+		name     string
+		expected string
+		err      error
+	}{
+		{"# Title\n<!-- csv2md:begin cars -->\nold\n<!-- csv2md:end -->\nmore\n", "cars", "# Title\n<!-- csv2md:begin cars -->\na|b  \n---|---  \n1|2  \n<!-- csv2md:end -->\nmore\n", nil},
+		{"<!--csv2md:begin cars-->\n<!--csv2md:end-->", "cars", "<!--csv2md:begin cars-->\na|b  \n---|---  \n1|2  \n<!--csv2md:end-->", nil},
+		{"<!-- csv2md:begin cars -->\r\nold\r\n<!-- csv2md:end -->\r\n", "cars", "<!-- csv2md:begin cars -->\r\na|b  \n---|---  \n1|2  \n<!-- csv2md:end -->\r\n", nil},
+		{"<!-- csv2md:begin trucks -->\nold\n<!-- csv2md:end -->\n<!-- csv2md:begin cars -->\n<!-- csv2md:end -->\n", "cars", "<!-- csv2md:begin trucks -->\nold\n<!-- csv2md:end -->\n<!-- csv2md:begin cars -->\na|b  \n---|---  \n1|2  \n<!-- csv2md:end -->\n", nil},
+		{"<!-- csv2md:begin cars -->\n<!-- csv2md:end -->\n<!-- csv2md:begin cars -->\nold\n<!-- csv2md:end -->\n", "cars", "<!-- csv2md:begin cars -->\na|b  \n---|---  \n1|2  \n<!-- csv2md:end -->\n<!-- csv2md:begin cars -->\na|b  \n---|---  \n1|2  \n<!-- csv2md:end -->\n", nil},
+		{"# Title\n<!-- csv2md:begin trucks -->\n<!-- csv2md:end -->\n", "cars", "", MissingMarkerError{Name: "cars"}},
+		{"<!-- csv2md:begin cars -->\nold\n", "cars", "", MissingMarkerError{Name: "cars", End: true}},
+		{"<!-- csv2md:begin cars -->\n<!-- csv2md:begin trucks -->\n<!-- csv2md:end -->\n", "cars", "", MissingMarkerError{Name: "cars", End: true}},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		err := Inject(strings.NewReader(test.doc), &w, test.name, table)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("%d: got error %v want %v", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
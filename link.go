@@ -0,0 +1,112 @@
+package csv2md
+
+import (
+	"io"
+	"net/url"
+	"strings"
+)
+
+// LinkOption configures the links of SetColumnLink.
+type LinkOption func(*link)
+
+// LinkText sets the column whose value is used as the link text.  If the
+// record's value is empty, the default link text is used.
+func LinkText(column string) LinkOption {
+	return func(l *link) {
+		l.text = column
+	}
+}
+
+// Autolink writes the links as autolinks, <url>, instead of with link
+// text.  It has no effect if a LinkText column is set.
+func Autolink() LinkOption {
+	return func(l *link) {
+		l.autolink = true
+	}
+}
+
+type link struct {
+	t        *Transmogrifier
+	text     string
+	autolink bool
+}
+
+// SetColumnLink makes the named column a link column: values that are
+// URLs are written as links, [host/path](url), whose text is the URL
+// without its scheme, query, or fragment.  Values that aren't URLs are
+// written as is.  A URL is a value with an http, https, or ftp scheme
+// and a host, or one that starts with "www.", which is linked using
+// https.  See LinkText and Autolink for the other link forms.
+//
+// A link column replaces any cell template the column has; see
+// SetColumnTemplate.  The links are created after the value maps and
+// before the field's styling.
+func (t *Transmogrifier) SetColumnLink(column string, opts ...LinkOption) {
+	l := &link{t: t}
+	for _, opt := range opts {
+		opt(l)
+	}
+	ct := &cellTemplate{column: column, exec: l.write, kind: "link"}
+	if l.text != "" {
+		ct.refs = []string{l.text}
+	}
+	t.setColumnTemplate(ct)
+}
+
+func (l *link) write(w io.Writer, data CellData) error {
+	v := strings.TrimSpace(string(data.Value))
+	href, text, ok := parseLink(v)
+	if !ok {
+		_, err := io.WriteString(w, l.t.escape(string(data.Value)))
+		return err
+	}
+	if l.text != "" {
+		if s := string(data.Fields[l.text]); s != "" {
+			text = s
+		}
+	} else if l.autolink {
+		_, err := io.WriteString(w, "<"+linkDestination(href)+">")
+		return err
+	}
+	_, err := io.WriteString(w, "["+linkText(l.t.escape(text))+"]("+linkDestination(href)+")")
+	return err
+}
+
+// parseLink returns the value's link destination and its default link
+// text, the URL without its scheme, query, or fragment, if the value is a
+// URL.
+func parseLink(v string) (href, text string, ok bool) {
+	if v == "" || strings.ContainsAny(v, " \t\r\n") {
+		return "", "", false
+	}
+	href = v
+	if strings.HasPrefix(strings.ToLower(v), "www.") {
+		href = "https://" + v
+	}
+	u, err := url.Parse(href)
+	if err != nil || u.Host == "" {
+		return "", "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ftp":
+	default:
+		return "", "", false
+	}
+	text = strings.TrimSuffix(u.Host+u.EscapedPath(), "/")
+	if p, err := url.PathUnescape(text); err == nil {
+		text = p
+	}
+	return href, text, true
+}
+
+// linkText escapes the brackets in the link text; the rest of the text is
+// expected to have been escaped.
+func linkText(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(s)
+}
+
+// linkDestination percent-encodes the characters that would end a link
+// destination or a table cell.
+func linkDestination(s string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E", "|", "%7C").Replace(s)
+}
@@ -0,0 +1,54 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetColumnLink(t *testing.T) {
+	csvData := []byte("Name,URL\nGo,https://golang.org/doc/\nDocs,www.example.com/a_b?q=1\nNone,not a url\nFS,file:///etc/hosts\nSpec,http://example.com/a b(1)|2\n,\n")
+	tests := []struct {
+		opts     []LinkOption
+		expected string
+		err      string
+	}{
+		{nil, "Name|URL  \n---|---  \nGo|[golang.org/doc](https://golang.org/doc/)  \nDocs|[www.example.com/a\\_b](https://www.example.com/a_b?q=1)  \nNone|not a url  \nFS|file:///etc/hosts  \nSpec|http://example.com/a b(1)\\|2  \n |   \n", ""},
+		{[]LinkOption{Autolink()}, "Name|URL  \n---|---  \nGo|<https://golang.org/doc/>  \nDocs|<https://www.example.com/a_b?q=1>  \nNone|not a url  \nFS|file:///etc/hosts  \nSpec|http://example.com/a b(1)\\|2  \n |   \n", ""},
+		{[]LinkOption{LinkText("Name")}, "Name|URL  \n---|---  \nGo|[Go](https://golang.org/doc/)  \nDocs|[Docs](https://www.example.com/a_b?q=1)  \nNone|not a url  \nFS|file:///etc/hosts  \nSpec|http://example.com/a b(1)\\|2  \n |   \n", ""},
+		{[]LinkOption{LinkText("Title")}, "", `link: unknown column "Title"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.SetColumnLink("URL", test.opts...)
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestLinkDestination(t *testing.T) {
+	csvData := []byte("Name,URL\n[a],http://example.com/(a)|b\n")
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+	calvin.SetColumnLink("URL", LinkText("Name"))
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Name|URL  \n---|---  \n[a]|[\\[a\\]](http://example.com/%28a%29%7Cb)  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
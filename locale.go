@@ -0,0 +1,104 @@
+package csv2md
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// locale is a locale's number separators and currency symbol placement.
+// The zero locale's numbers are those of Go, e.g. 1234.56.
+type locale struct {
+	thousands string
+	decimal   string
+	suffix    bool // the currency symbol follows the amount
+}
+
+// locales are the supported locales, by name: en, 1,234.56; de, 1.234,56;
+// fr, 1 234,56; and ch, 1'234.56.
+var locales = map[string]locale{
+	"en": {thousands: ",", decimal: "."},
+	"de": {thousands: ".", decimal: ",", suffix: true},
+	"fr": {thousands: " ", decimal: ",", suffix: true},
+	"ch": {thousands: "'", decimal: "."},
+}
+
+// SetLocale sets the locale of the numbers that are read: en, 1,234.56;
+// de, 1.234,56; fr, 1 234,56; or ch, 1'234.56.  The locale's numbers, with
+// or without their thousands separators, are numbers when the alignment
+// and the metadata's types are inferred, and when they are formatted, see
+// SetColumnNumberFormat and SetColumnCurrency, or aggregated, see
+// SetColumnAggregate, AggregateBy, and SetPivot.  Formatted and aggregated
+// numbers are written with the locale's decimal mark.  An empty name
+// means that the numbers are Go's, e.g. 1234.56, which is the default.
+func (t *Transmogrifier) SetLocale(name string) error {
+	if name == "" {
+		t.locale = locale{}
+		return nil
+	}
+	l, err := parseLocale(name)
+	if err != nil {
+		return err
+	}
+	t.locale = l
+	return nil
+}
+
+// parseLocale returns the named locale; an empty name is en.
+func parseLocale(name string) (locale, error) {
+	if name == "" {
+		name = "en"
+	}
+	l, ok := locales[strings.ToLower(name)]
+	if !ok {
+		return locale{}, fmt.Errorf("unknown locale %q: expected en, de, fr, or ch", name)
+	}
+	return l, nil
+}
+
+// separate replaces the separators of a number formatted with a ","
+// thousands separator and a "." decimal point with the locale's.
+func (l locale) separate(s string) string {
+	return strings.NewReplacer(",", l.thousands, ".", l.decimal).Replace(s)
+}
+
+// number returns the value, if it is a number written using the locale's
+// separators, in Go's syntax, e.g. 1234.56 for the de locale's 1.234,56,
+// and the number.  The thousands separators must separate groups of three
+// digits.
+func (l locale) number(v string) (string, float64, bool) {
+	s := strings.TrimSpace(v)
+	if l.decimal != "" {
+		integer, fraction := s, ""
+		if i := strings.Index(s, l.decimal); i >= 0 {
+			integer, fraction = s[:i], "."+s[i+len(l.decimal):]
+		}
+		if strings.Contains(integer, l.thousands) {
+			groups := strings.Split(integer, l.thousands)
+			for i, g := range groups {
+				if i == 0 {
+					g = strings.TrimLeft(g, "+-")
+				}
+				if len(g) == 0 || len(g) > 3 || (i > 0 && len(g) != 3) || strings.Trim(g, "0123456789") != "" {
+					return "", 0, false
+				}
+			}
+			integer = strings.Join(groups, "")
+		}
+		s = integer + fraction
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return s, n, true
+}
+
+// decimalMark replaces the decimal point of a number in Go's syntax with
+// the locale's.
+func (l locale) decimalMark(s string) string {
+	if l.decimal == "" || l.decimal == "." {
+		return s
+	}
+	return strings.Replace(s, ".", l.decimal, 1)
+}
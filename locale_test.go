@@ -0,0 +1,78 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLocaleNumber(t *testing.T) {
+	tests := []struct {
+		locale   string
+		value    string
+		expected string
+		ok       bool
+	}{
+		{"", "1234.56", "1234.56", true},
+		{"", "1,234.56", "", false},
+		{"en", "1,234.56", "1234.56", true},
+		{"en", "-1,234,567", "-1234567", true},
+		{"en", "1,23", "", false},
+		{"en", "1234.56", "1234.56", true},
+		{"de", " 1.234,56 ", "1234.56", true},
+		{"de", "-1.234.567,5", "-1234567.5", true},
+		{"de", "1234,56", "1234.56", true},
+		{"de", "0,5", "0.5", true},
+		{"de", "1234.56", "", false},
+		{"de", "1.2345", "", false},
+		{"de", ".234", "", false},
+		{"fr", "1 234,56", "1234.56", true},
+		{"ch", "1'234.56", "1234.56", true},
+		{"de", "n/a", "", false},
+	}
+	for i, test := range tests {
+		var l locale
+		if test.locale != "" {
+			l = locales[test.locale]
+		}
+		s, _, ok := l.number(test.value)
+		if ok != test.ok || s != test.expected {
+			t.Errorf("%d: %s %q: got %q, %t want %q, %t", i, test.locale, test.value, s, ok, test.expected, test.ok)
+		}
+	}
+}
+
+func TestSetLocale(t *testing.T) {
+	csvData := "Item;Qty;Price\nCar;1.000;25.999,5\nHouse;2;1.234.567,89\nIdea;3;n/a\n"
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+	calvin.CSV.Comma = ';'
+	calvin.AutoAlign = true
+	err := calvin.SetLocale("de")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	calvin.SetColumnNumberFormat("Price", NumberFormat{Precision: 2, Thousands: " "})
+	err = calvin.SetColumnAggregate("Qty", "sum")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.SetColumnAggregate("Price", "max")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Item|Qty|Price  \n:--|--:|:--  \nCar|1.000|25 999,50  \nHouse|2|1 234 567,89  \nIdea|3|n/a  \n |__1005__|__1234567,89__  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+
+	calvin = NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+	err = calvin.SetLocale("nl")
+	if err == nil || err.Error() != `unknown locale "nl": expected en, de, fr, or ch` {
+		t.Errorf("got error %v want an unknown locale error", err)
+	}
+}
@@ -0,0 +1,35 @@
+package csv2md
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SetLogger sets the logger of the conversion's diagnostics: the warnings,
+// see Warnings, are logged at the warn level, the number of rows and bytes
+// of each table, and how long it took to write it, at the info level, and
+// the rows that are skipped, e.g. blank, filtered, or duplicate rows, at
+// the debug level.  A nil logger, the default, discards them.
+func (t *Transmogrifier) SetLogger(l *slog.Logger) {
+	t.logger = l
+}
+
+// WithLogger sets the logger of the conversion's diagnostics; see
+// SetLogger.
+func WithLogger(l *slog.Logger) Option {
+	return func(t *Transmogrifier) {
+		t.SetLogger(l)
+	}
+}
+
+// log logs the message and its attributes, if there is a logger.
+func (t *Transmogrifier) log(level slog.Level, msg string, args ...interface{}) {
+	if t.logger == nil {
+		return
+	}
+	ctx := t.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	t.logger.Log(ctx, level, msg, args...)
+}
@@ -0,0 +1,76 @@
+package csv2md
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	csvData := "Make,Price\nFord,1\nKia,x\n,\nFord,1\nVW,3\n"
+	tests := []struct {
+		level    slog.Level
+		align    []string
+		filter   string
+		dedup    bool
+		expected string
+	}{
+		{slog.LevelWarn, nil, "", false, ""},
+		{slog.LevelWarn, []string{"l", "r", "c"}, "", false, "level=WARN msg=\"field alignment has 3 entries, header has 2 fields\"\n"},
+		{slog.LevelInfo, nil, "", false, "level=INFO msg=\"table written\" rows=4 bytes_read=38 bytes_written=56\n"},
+		{slog.LevelDebug, nil, "Make != \"Kia\"", true, "level=DEBUG msg=\"row filtered\" row=3\n" +
+			"level=DEBUG msg=\"blank row skipped\" row=4\n" +
+			"level=DEBUG msg=\"duplicate row removed\" row=5\n" +
+			"level=INFO msg=\"table written\" rows=2 bytes_read=38 bytes_written=39\n"},
+	}
+	for i, test := range tests {
+		var log bytes.Buffer
+		l := slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{
+			Level: test.level,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey || a.Key == "elapsed" {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.SkipBlankRecords = true
+		calvin.SetLogger(l)
+		if test.align != nil {
+			calvin.SetFieldAlignment(test.align)
+		}
+		if test.filter != "" {
+			err := calvin.SetFilter(test.filter)
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+				continue
+			}
+		}
+		if test.dedup {
+			calvin.Dedup()
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if log.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, log.String(), test.expected)
+		}
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var log bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_, err := TableString(strings.NewReader("Make\nFord\n\n"), WithLogger(l))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(log.String(), "msg=\"table written\" rows=1") {
+		t.Errorf("got %q, want the table written message", log.String())
+	}
+}
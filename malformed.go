@@ -0,0 +1,83 @@
+package csv2md
+
+import (
+	"encoding/csv"
+	"errors"
+	"log/slog"
+	"strings"
+)
+
+// MalformedRow is a malformed record that was skipped; see
+// SkipMalformedRows.
+type MalformedRow struct {
+	// Row is the record's number, counting the header record.
+	Row int
+	// Raw is the record's CSV-encoded data, without its line ending; it
+	// is empty if the data isn't read using the Transmogrifier's reader,
+	// e.g. it was created using NewTransmogrifierCSV or
+	// NewTransmogrifierMerge.
+	Raw string
+	// Err is why the record is malformed.
+	Err error
+}
+
+// SkipMalformedRows skips the malformed records, instead of returning
+// their errors, so that the rest of the table is written: the records that
+// the CSV reader can't parse, e.g. a bare quote in an unquoted field, and
+// those with the wrong number of fields, unless they are handled by the
+// Ragged mode.  Each skipped record is passed to report, if it isn't nil.
+// A malformed header record is still an error, as is a field that is
+// larger than MaxFieldBytes, since the rest of the data can't be read.
+// Reading the data is slower, as the records' data is kept until they have
+// been parsed.
+func (t *Transmogrifier) SkipMalformedRows(report func(MalformedRow)) {
+	if report == nil {
+		report = func(MalformedRow) {}
+	}
+	t.malformed = report
+}
+
+// skippable returns whether the record whose error it is can be skipped.
+func (t *Transmogrifier) skippable(err error) bool {
+	if t.HasHeaderRecord && t.nRead <= 1 {
+		return false
+	}
+	var perr *csv.ParseError
+	var rerr RaggedRecordError
+	return errors.As(err, &perr) || errors.As(err, &rerr)
+}
+
+// skipMalformed counts, logs, and reports the skipped malformed record.
+func (t *Transmogrifier) skipMalformed(raw string, err error) {
+	t.nMalformed++
+	var rerr RowError
+	if errors.As(err, &rerr) {
+		err = rerr.Err
+	}
+	t.log(slog.LevelDebug, "malformed row skipped", "row", t.nRead, "error", err)
+	t.malformed(MalformedRow{Row: t.nRead, Raw: strings.TrimRight(raw, "\r\n"), Err: err})
+}
+
+// rawRecord returns the data that the CSV reader has read since the start
+// offset, which is that of the last record it read, and discards the data
+// before it.
+func (t *Transmogrifier) rawRecord(start int64) string {
+	in := t.in
+	if in == nil || t.merge != nil {
+		return ""
+	}
+	end := t.CSV.InputOffset()
+	var raw string
+	if i, j := start-in.rawOff, end-in.rawOff; i >= 0 && i <= j && j <= int64(len(in.raw)) {
+		raw = string(in.raw[i:j])
+	}
+	n := end - in.rawOff
+	if n > int64(len(in.raw)) {
+		n = int64(len(in.raw))
+	}
+	if n > 0 {
+		in.raw = append(in.raw[:0], in.raw[n:]...)
+		in.rawOff += n
+	}
+	return raw
+}
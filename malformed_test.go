@@ -0,0 +1,109 @@
+package csv2md
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type malformed struct {
+	row int
+	raw string
+	err string
+}
+
+func TestSkipMalformedRows(t *testing.T) {
+	tests := []struct {
+		csv       string
+		ragged    RaggedMode
+		expected  string
+		malformed []malformed
+	}{
+		{
+			"a,b\n1,2\n3,4\n", RaggedError,
+			"a|b  \n---|---  \n1|2  \n3|4  \n",
+			nil,
+		},
+		{
+			"a,b\n1,2\n3,4,5\n6,7\n", RaggedError,
+			"a|b  \n---|---  \n1|2  \n6|7  \n",
+			[]malformed{{3, "3,4,5", "row 3: record has 3 fields, the header has 2"}},
+		},
+		{
+			"a,b\n1,x\"y\n3,4\r\n5\n", RaggedError,
+			"a|b  \n---|---  \n3|4  \n",
+			[]malformed{
+				{2, "1,x\"y", "parse error on line 2, column 4: bare \" in non-quoted-field"},
+				{4, "5", "row 4: record has 1 fields, the header has 2"},
+			},
+		},
+		{
+			"a,b\n1,2\n3\n", RaggedPad,
+			"a|b  \n---|---  \n1|2  \n3|   \n",
+			nil,
+		},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(test.csv), &w)
+		calvin.Ragged = test.ragged
+		var got []malformed
+		calvin.SkipMalformedRows(func(m MalformedRow) {
+			got = append(got, malformed{m.Row, m.Raw, m.Err.Error()})
+		})
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+		if !reflect.DeepEqual(got, test.malformed) {
+			t.Errorf("%d: got %v want %v", i, got, test.malformed)
+		}
+		if calvin.Stats().RowsMalformed != int64(len(test.malformed)) {
+			t.Errorf("%d: rows malformed: got %d want %d", i, calvin.Stats().RowsMalformed, len(test.malformed))
+		}
+	}
+}
+
+func TestSkipMalformedHeader(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader("a,\"b\n1,2\n"), &w)
+	calvin.SkipMalformedRows(nil)
+	err := calvin.MDTable()
+	if err == nil {
+		t.Error("expected an error, got none")
+	}
+}
+
+func TestSkipMalformedRowsRaw(t *testing.T) {
+	// the CSV reader reads the data ahead of the records, in blocks
+	var data strings.Builder
+	data.WriteString("a,b\n")
+	for i := 0; i < 2000; i++ {
+		data.WriteString("\"quoted\nvalue\",2\n")
+	}
+	data.WriteString("1,2,3\n4,5\n")
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(data.String()), &w)
+	var got []MalformedRow
+	calvin.SkipMalformedRows(func(m MalformedRow) {
+		got = append(got, m)
+	})
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d malformed rows, want 1", len(got))
+	}
+	if got[0].Row != 2002 || got[0].Raw != "1,2,3" {
+		t.Errorf("got row %d %q, want row 2002 \"1,2,3\"", got[0].Row, got[0].Raw)
+	}
+	if !strings.HasSuffix(w.String(), "4|5  \n") {
+		t.Errorf("got %q, want the table to end with the last record", w.String()[w.Len()-20:])
+	}
+}
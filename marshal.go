@@ -0,0 +1,209 @@
+package csv2md
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// UnsupportedTypeError occurs when a value that is not a slice or array of
+// structs is passed to Marshal.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported type: %v: not a slice or array of structs", e.Type)
+}
+
+// Marshal writes the slice, or array, of structs, v, to w as a GitHub
+// Flavored Markdown table.  Each exported field is a column, in the order
+// that the fields are defined, and each element of v is a record.  The
+// fields of embedded structs are treated as if they were fields of the
+// outer struct.  The elements may also be pointers to structs; a nil
+// pointer is a record with empty fields.
+//
+// A field's column can be configured using the "md" key in the field's tag:
+//
+//	// The column's name is "Model", the field's name.
+//	Model string
+//	// The column's name is "Year", it is right justified and bold.
+//	Year int `md:"Year,r,b"`
+//	// The column is left justified and italicized.
+//	Make string `md:",l,i"`
+//	// The field is skipped.
+//	VIN string `md:"-"`
+//
+// The alignment and style use the same values as SetFieldAlignment and
+// SetFieldStyle.
+//
+// Field values that implement encoding.TextMarshaler are marshaled using
+// MarshalText.  Strings, booleans, and numbers are written as is, nil
+// pointers are empty, and other values are formatted using fmt.Sprint.
+func Marshal(v interface{}, w io.Writer) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return UnsupportedTypeError{Type: reflect.TypeOf(v)}
+	}
+	elem := rv.Type().Elem()
+	ptr := elem.Kind() == reflect.Ptr
+	if ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return UnsupportedTypeError{Type: rv.Type()}
+	}
+	fields := structFields(elem, nil)
+	header := make([]string, len(fields))
+	align := make([]string, len(fields))
+	style := make([]string, len(fields))
+	var aligned, styled bool
+	for i, f := range fields {
+		header[i], align[i], style[i] = f.name, f.align, f.style
+		aligned = aligned || f.align != ""
+		styled = styled || f.style != ""
+	}
+	var opts []Option
+	if aligned {
+		opts = append(opts, WithFieldAlignment(align))
+	}
+	if styled {
+		opts = append(opts, WithFieldStyle(style))
+	}
+	enc := NewEncoder(w, opts...)
+	err := enc.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+	record := make([]string, len(fields))
+	for i := 0; i < rv.Len(); i++ {
+		sv := rv.Index(i)
+		if ptr {
+			sv = sv.Elem()
+		}
+		for j, f := range fields {
+			record[j] = ""
+			if !sv.IsValid() {
+				continue
+			}
+			fv, ok := fieldByIndex(sv, f.index)
+			if !ok {
+				continue
+			}
+			record[j], err = formatValue(fv)
+			if err != nil {
+				return fmt.Errorf("row %d: field %q: %s", i+1, f.name, err)
+			}
+		}
+		err = enc.WriteRecord(record)
+		if err != nil {
+			return err
+		}
+	}
+	return enc.Flush()
+}
+
+// structField is a struct field that is a column.
+type structField struct {
+	name  string
+	align string
+	style string
+	index []int
+}
+
+// structFields returns the fields of the struct type that are columns.
+func structFields(typ reflect.Type, index []int) []structField {
+	var fields []structField
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag := f.Tag.Get("md")
+		if tag == "-" {
+			continue
+		}
+		idx := append(append([]int(nil), index...), i)
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		// embedded structs without a name are flattened
+		if f.Anonymous && ft.Kind() == reflect.Struct && tag == "" {
+			fields = append(fields, structFields(ft, idx)...)
+			continue
+		}
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		sf := structField{name: f.Name, index: idx}
+		opts := strings.Split(tag, ",")
+		if opts[0] != "" {
+			sf.name = opts[0]
+		}
+		if len(opts) > 1 {
+			sf.align = opts[1]
+		}
+		if len(opts) > 2 {
+			sf.style = opts[2]
+		}
+		fields = append(fields, sf)
+	}
+	return fields
+}
+
+// fieldByIndex returns the nested field of the struct, v.  It returns false
+// if one of the embedded structs is a nil pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// formatValue returns the field's value as a string.
+func formatValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+	}
+	if v.CanInterface() {
+		m, ok := v.Interface().(encoding.TextMarshaler)
+		if !ok && v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+			m, ok = v.Addr().Interface().(encoding.TextMarshaler)
+		}
+		if ok {
+			b, err := m.MarshalText()
+			return string(b), err
+		}
+	}
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		return formatValue(v.Elem())
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	}
+	if v.CanInterface() {
+		return fmt.Sprint(v.Interface()), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,78 @@
+package csv2md
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+)
+
+type base struct {
+	ID int `md:"#,r"`
+}
+
+type car struct {
+	base
+	Make  string  `md:",l,b"`
+	Model string  `md:"Model Name"`
+	Year  *int    `md:",c"`
+	Price float64 `md:",r"`
+	IP    net.IP
+	Sold  bool
+	vin   string
+	Notes string `md:"-"`
+}
+
+func TestMarshal(t *testing.T) {
+	year := 2017
+	tests := []struct {
+		v        interface{}
+		expected string
+		err      string
+	}{
+		{
+			[]car{
+				{base{1}, "Chevrolet", "Volt", &year, 31000.5, net.IPv4(10, 0, 0, 1), true, "x", "y"},
+				{base{2}, "Ford", "Focus|RS", nil, 25000, nil, false, "", ""},
+			},
+			"#|Make|Model Name|Year|Price|IP|Sold  \n--:|:--|---|:--:|--:|---|---  \n1|__Chevrolet__|Volt|2017|31000.5|10.0.0.1|true  \n2|__Ford__|Focus\\|RS| |25000| |false  \n", "",
+		},
+		{[]*car{nil}, "#|Make|Model Name|Year|Price|IP|Sold  \n--:|:--|---|:--:|--:|---|---  \n |__ __| | | | |   \n", ""},
+		{[1]struct{ A, B string }{{"a", "b"}}, "A|B  \n---|---  \na|b  \n", ""},
+		{[]struct{ A string }{}, "A  \n---  \n", ""},
+		{car{}, "", "unsupported type: csv2md.car: not a slice or array of structs"},
+		{[]string{"a"}, "", "unsupported type: []string: not a slice or array of structs"},
+		{nil, "", "unsupported type: <nil>: not a slice or array of structs"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		err := Marshal(test.v, &w)
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+type badText struct{}
+
+func (badText) MarshalText() ([]byte, error) {
+	return nil, errors.New("can't marshal")
+}
+
+func TestMarshalTextError(t *testing.T) {
+	var w bytes.Buffer
+	err := Marshal([]struct{ V badText }{{}}, &w)
+	if err == nil || err.Error() != `row 1: field "V": can't marshal` {
+		t.Errorf("got %v, want an error", err)
+	}
+}
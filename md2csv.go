@@ -0,0 +1,254 @@
+package csv2md
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrNoTable occurs when no GFM table is found in the Markdown.
+var ErrNoTable = errors.New("no table found")
+
+// Table is a GFM table that has been read from Markdown.  A column's
+// Alignment and Style use the same values as a format file; an empty value
+// means that the column has no alignment or styling.
+type Table struct {
+	Header    []string
+	Alignment []string
+	Style     []string
+	Records   [][]string
+}
+
+// ReadMDTable reads the first GFM table in the Markdown.  Anything before
+// the table, e.g. a heading or a metadata block, is skipped; the table ends
+// at the first blank line after it.  Rows with fewer cells than the header
+// are padded with empty cells.  Escaped characters in the cells are
+// unescaped.  If all of a column's cells are styled the same way, the style
+// is removed from the cells and recorded as the column's style; otherwise
+// the cells' markup is kept as is.
+func ReadMDTable(r io.Reader) (*Table, error) {
+	br := bufio.NewReader(r)
+	var prev string
+	var havePrev bool
+	var tbl *Table
+	var skipUntil string
+	first := true
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		eof := err == io.EOF
+		if eof && line == "" {
+			break
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case tbl != nil:
+			// the table ends at a blank line
+			if line == "" {
+				return tbl.finish(), nil
+			}
+			record := splitMDRow(line)
+			// missing cells are empty
+			for len(record) < len(tbl.Header) {
+				record = append(record, "")
+			}
+			tbl.Records = append(tbl.Records, record)
+		case skipUntil != "":
+			if strings.HasSuffix(line, skipUntil) {
+				skipUntil = ""
+			}
+		case first && line == "---":
+			// YAML front matter
+			skipUntil = "---"
+		case strings.HasPrefix(line, "<!--") && !strings.HasSuffix(line, "-->"):
+			skipUntil = "-->"
+		case havePrev && isMDDelimiterRow(line):
+			header := splitMDRow(prev)
+			align := splitMDRow(line)
+			if len(header) == len(align) {
+				tbl = &Table{Header: header}
+				for _, a := range align {
+					tbl.Alignment = append(tbl.Alignment, alignmentValue(a))
+				}
+				break
+			}
+			fallthrough
+		default:
+			prev, havePrev = line, line != ""
+		}
+		first = false
+		if eof {
+			break
+		}
+	}
+	if tbl == nil {
+		return nil, ErrNoTable
+	}
+	return tbl.finish(), nil
+}
+
+// finish extracts the column styles and unescapes the cells.
+func (tbl *Table) finish() *Table {
+	tbl.Style = make([]string, len(tbl.Header))
+	for i := range tbl.Header {
+		style, ok := "", false
+		for _, record := range tbl.Records {
+			if i >= len(record) {
+				continue
+			}
+			s := cellStyle(record[i])
+			if !ok {
+				style, ok = s, true
+			}
+			if s != style {
+				style = ""
+				break
+			}
+		}
+		if style == "" {
+			continue
+		}
+		tbl.Style[i] = styleValue(style)
+		for _, record := range tbl.Records {
+			if i < len(record) {
+				record[i] = strings.TrimSpace(record[i][len(style) : len(record[i])-len(style)])
+			}
+		}
+	}
+	for i, v := range tbl.Header {
+		tbl.Header[i] = unescape(v)
+	}
+	for _, record := range tbl.Records {
+		for i, v := range record {
+			record[i] = unescape(v)
+		}
+	}
+	return tbl
+}
+
+// WriteCSV writes the table's header and records as CSV-encoded data.
+func (tbl *Table) WriteCSV(w *csv.Writer) error {
+	err := w.Write(tbl.Header)
+	if err != nil {
+		return err
+	}
+	err = w.WriteAll(tbl.Records)
+	if err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+// WriteFmt writes the table's field names, alignment, and styling as a
+// format file; see SetFmt.
+func (tbl *Table) WriteFmt(w *csv.Writer) error {
+	return w.WriteAll([][]string{tbl.Header, tbl.Alignment, tbl.Style})
+}
+
+// splitMDRow splits a table row into its cells.  Leading and trailing
+// pipes are optional: if the row starts with a pipe, a trailing pipe ends
+// the row; otherwise, it is followed by an empty cell.  Escaped pipes do
+// not split a cell and are left escaped, along with any other escaped
+// characters.
+func splitMDRow(line string) []string {
+	line = strings.TrimSpace(line)
+	border := strings.HasPrefix(line, "|")
+	line = strings.TrimPrefix(line, "|")
+	var cells []string
+	var cell strings.Builder
+	split := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		split = false
+		switch {
+		case c == '\\' && i+1 < len(line):
+			cell.WriteByte(c)
+			i++
+			c = line[i]
+		case c == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+			split = true
+			continue
+		}
+		cell.WriteByte(c)
+	}
+	if !split || !border {
+		cells = append(cells, strings.TrimSpace(cell.String()))
+	}
+	return cells
+}
+
+// isMDDelimiterRow returns whether the line is a table's delimiter row,
+// e.g. ---|:--|--:|:-:.
+func isMDDelimiterRow(line string) bool {
+	cells := splitMDRow(line)
+	if len(cells) == 0 {
+		return false
+	}
+	for _, c := range cells {
+		c = strings.TrimSuffix(strings.TrimPrefix(c, ":"), ":")
+		if c == "" || strings.Trim(c, "-") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// alignmentValue returns the format file alignment value for a delimiter
+// row cell.
+func alignmentValue(cell string) string {
+	l := strings.HasPrefix(cell, ":")
+	r := strings.HasSuffix(cell, ":")
+	switch {
+	case l && r:
+		return "c"
+	case l:
+		return "l"
+	case r:
+		return "r"
+	}
+	return ""
+}
+
+// cellStyle returns the markup that a cell is wrapped in, if any.
+func cellStyle(cell string) string {
+	for _, s := range []string{strikethrough, bold, "**", italic, "*"} {
+		if len(cell) > 2*len(s) && strings.HasPrefix(cell, s) && strings.HasSuffix(cell, s) && !strings.HasSuffix(cell, `\`+s) {
+			return s
+		}
+	}
+	return ""
+}
+
+// styleValue returns the format file style value for the markup.
+func styleValue(markup string) string {
+	switch markup {
+	case bold, "**":
+		return "b"
+	case italic, "*":
+		return "i"
+	case strikethrough:
+		return "s"
+	}
+	return ""
+}
+
+// unescape removes the backslashes that escape ASCII punctuation.
+func unescape(v string) string {
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '\\' && i+1 < len(v) && isASCIIPunct(v[i+1]) {
+			i++
+		}
+		b.WriteByte(v[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package csv2md
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadMDTable(t *testing.T) {
+	tests := []struct {
+		md        string
+		header    []string
+		alignment []string
+		style     []string
+		records   [][]string
+		err       error
+	}{
+		{"a|b  \n---|---  \n1|2  \n3|  \n", []string{"a", "b"}, []string{"", ""}, []string{"", ""}, [][]string{{"1", "2"}, {"3", ""}}, nil},
+		{"# Cars\n\n| Make | Model |\n|:--|--:|\n| Ford | _Focus_ |\n| Kia | _Rio_ |\n\nmore text\n", []string{"Make", "Model"}, []string{"l", "r"}, []string{"", "i"}, [][]string{{"Ford", "Focus"}, {"Kia", "Rio"}}, nil},
+		{"x|y\n:-:|---\n__a\\|b__|~~c~~\n__\\_d\\___|e\n", []string{"x", "y"}, []string{"c", ""}, []string{"b", ""}, [][]string{{"a|b", "~~c~~"}, {"_d_", "e"}}, nil},
+		{"---\ntitle: x\n---\na|b\n---|---\n1|2\n", []string{"a", "b"}, []string{"", ""}, []string{"", ""}, [][]string{{"1", "2"}}, nil},
+		{"<!--\na|b\n---|---\n-->\nc|d\n---|---\n1|2\n", []string{"c", "d"}, []string{"", ""}, []string{"", ""}, [][]string{{"1", "2"}}, nil},
+		{"a|b|c\n---|---\n", nil, nil, nil, nil, ErrNoTable},
+		{"just text\n", nil, nil, nil, nil, ErrNoTable},
+	}
+	for i, test := range tests {
+		tbl, err := ReadMDTable(strings.NewReader(test.md))
+		if err != test.err {
+			t.Errorf("%d: got error %v want %v", i, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(tbl.Header, test.header) {
+			t.Errorf("%d: header: got %q want %q", i, tbl.Header, test.header)
+		}
+		if !reflect.DeepEqual(tbl.Alignment, test.alignment) {
+			t.Errorf("%d: alignment: got %q want %q", i, tbl.Alignment, test.alignment)
+		}
+		if !reflect.DeepEqual(tbl.Style, test.style) {
+			t.Errorf("%d: style: got %q want %q", i, tbl.Style, test.style)
+		}
+		if !reflect.DeepEqual(tbl.Records, test.records) {
+			t.Errorf("%d: records: got %q want %q", i, tbl.Records, test.records)
+		}
+	}
+}
+
+// a table generated by MDTable is converted back to the CSV-encoded data
+// and format that it was generated from.
+func TestMDTableRoundTrip(t *testing.T) {
+	csvData := "Make,Model,Notes\nFord,Focus,\"a|b, c\"\nKia,Rio,snake_case\nVW,Golf,\n"
+	format := "Make,Model,Notes\nl,r,c\nb,,s\n"
+	var md bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &md)
+	calvin.HasHeaderRecord = true
+	err := calvin.SetFmt(strings.NewReader(format))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tbl, err := ReadMDTable(&md)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var data, fmtData bytes.Buffer
+	err = tbl.WriteCSV(csv.NewWriter(&data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if data.String() != csvData {
+		t.Errorf("got %q want %q", data.String(), csvData)
+	}
+	w := csv.NewWriter(&fmtData)
+	err = tbl.WriteFmt(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if fmtData.String() != format {
+		t.Errorf("got %q want %q", fmtData.String(), format)
+	}
+}
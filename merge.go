@@ -0,0 +1,185 @@
+package csv2md
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MergeInput is one of the inputs that NewTransmogrifierMerge merges.
+type MergeInput struct {
+	// Name identifies the input in errors; e.g. its file name.
+	Name   string
+	Reader io.Reader
+}
+
+// InputError occurs when one of the merged inputs cannot be read; the Name
+// is the input's Name.
+type InputError struct {
+	Name string
+	Err  error
+}
+
+func (e InputError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Name, e.Err)
+}
+
+// Unwrap returns the input's error.
+func (e InputError) Unwrap() error {
+	return e.Err
+}
+
+// NewTransmogrifierMerge returns an initialized Transmogrifier whose
+// CSV-encoded data is the inputs' data merged into one table.  Each input
+// must have a header record.  The table's header is the union of the
+// inputs' header records: the first input's fields followed by the fields
+// of the other inputs that it doesn't have, in the order that they first
+// occur.  The columns are matched by name; if an input has a name more
+// than once, its occurrences are matched in order.  The inputs' records
+// follow each other, in the order of the inputs, with each field in its
+// name's column; the fields that an input doesn't have are the
+// MergePlaceholder.
+//
+// The inputs are read using the CSV reader's settings and the Encoding.
+// Each input's records are checked against its own header record, see
+// Ragged, and its errors are InputErrors.
+func NewTransmogrifierMerge(w io.Writer, inputs ...MergeInput) *Transmogrifier {
+	var r io.Reader = strings.NewReader("")
+	if len(inputs) > 0 {
+		r = inputs[0].Reader
+	}
+	t := NewTransmogrifier(r, w)
+	t.merge = &merger{t: t, inputs: inputs}
+	return t
+}
+
+// merger merges the records of the inputs; see NewTransmogrifierMerge.
+type merger struct {
+	t       *Transmogrifier
+	inputs  []MergeInput
+	readers []*csv.Reader
+	index   [][]int // the column of each of the inputs' fields
+	header  []string
+	started bool
+	cur     int // the input being read
+	row     int // the record's position in the input
+	record  []string
+}
+
+// read returns the next merged record; the first record is the header.
+func (m *merger) read() ([]string, error) {
+	if !m.started {
+		m.started = true
+		return m.start()
+	}
+	for m.cur < len(m.readers) {
+		record, err := m.readers[m.cur].Read()
+		if err == io.EOF {
+			m.cur++
+			m.row = 1
+			continue
+		}
+		m.row++
+		if err != nil {
+			return nil, InputError{Name: m.inputs[m.cur].Name, Err: err}
+		}
+		if m.t.SkipBlankRecords && blank(record) {
+			m.t.nSkipped++
+			continue
+		}
+		if m.t.checkRagged {
+			record, err = m.t.fitFields(record, len(m.index[m.cur]), m.row)
+			if err != nil {
+				return nil, InputError{Name: m.inputs[m.cur].Name, Err: err}
+			}
+		}
+		return m.merge(record), nil
+	}
+	return nil, io.EOF
+}
+
+// start reads the inputs' header records and returns the merged header.
+// The other inputs are read using the settings of the first input's
+// reader, the CSV reader.
+func (m *merger) start() ([]string, error) {
+	if !m.t.HasHeaderRecord {
+		return nil, errors.New("merge: the inputs must have header records")
+	}
+	c := m.t.CSV
+	for i, in := range m.inputs {
+		r := c
+		if i > 0 {
+			r = csv.NewReader(newInput(in.Reader, m.t))
+			r.Comma = c.Comma
+			r.Comment = c.Comment
+			r.FieldsPerRecord = c.FieldsPerRecord
+			r.LazyQuotes = c.LazyQuotes
+			r.TrimLeadingSpace = c.TrimLeadingSpace
+			r.ReuseRecord = c.ReuseRecord
+		}
+		m.readers = append(m.readers, r)
+		header, err := r.Read()
+		if err != nil && err != io.EOF {
+			return nil, InputError{Name: in.Name, Err: err}
+		}
+		m.index = append(m.index, m.union(header))
+	}
+	m.row = 1
+	if len(m.header) == 0 {
+		return nil, io.EOF
+	}
+	return append([]string(nil), m.header...), nil
+}
+
+// union adds the header's fields that the merged header doesn't have to
+// it and returns the merged header's column of each field.
+func (m *merger) union(header []string) []int {
+	index := make([]int, len(header))
+	seen := make(map[string]int)
+	for i, name := range header {
+		j := nthIndex(m.header, name, seen[name])
+		seen[name]++
+		if j < 0 {
+			m.header = append(m.header, name)
+			j = len(m.header) - 1
+		}
+		index[i] = j
+	}
+	return index
+}
+
+// nthIndex returns the index of the n'th, 0 based, occurrence of the name
+// in the names; -1 is returned if there isn't one.
+func nthIndex(names []string, name string, n int) int {
+	for i, v := range names {
+		if v != name {
+			continue
+		}
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return -1
+}
+
+// merge returns the record with its fields in their columns.
+func (m *merger) merge(record []string) []string {
+	merged := m.record[:0]
+	if !m.t.CSV.ReuseRecord {
+		merged = make([]string, 0, len(m.header))
+	}
+	for range m.header {
+		merged = append(merged, m.t.MergePlaceholder)
+	}
+	index := m.index[m.cur]
+	for i, v := range record {
+		if i < len(index) {
+			merged[index[i]] = v
+		}
+	}
+	m.record = merged
+	return merged
+}
@@ -0,0 +1,80 @@
+package csv2md
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewTransmogrifierMerge(t *testing.T) {
+	tests := []struct {
+		inputs      []string
+		placeholder string
+		expected    string
+		err         string
+	}{
+		{[]string{"Make,Model\nFord,Focus\n", "Make,Model\nKia,Rio\n"}, "", "Make|Model  \n---|---  \nFord|Focus  \nKia|Rio  \n", ""},
+		{[]string{"Make,Model\nFord,Focus\n", "Model,Year,Make\nRio,2016,Kia\n"}, "-", "Make|Model|Year  \n---|---|---  \nFord|Focus|-  \nKia|Rio|2016  \n", ""},
+		{[]string{"Make,Model\nFord,Focus\n", "", "Year\n2015\n"}, "", "Make|Model|Year  \n---|---|---  \nFord|Focus|   \n | |2015  \n", ""},
+		{[]string{"A,A\n1,2\n", "A,B,A\n3,4,5\n"}, "", "A|A|B  \n---|---|---  \n1|2|   \n3|5|4  \n", ""},
+		{[]string{"Make,Model\nFord,Focus\n", "Make,Year\nKia\n"}, "", "", "b.csv: row 2: record has 1 fields, the header has 2"},
+		{[]string{"", ""}, "", "", ""},
+	}
+	names := []string{"a.csv", "b.csv", "c.csv"}
+	for i, test := range tests {
+		var inputs []MergeInput
+		for j, s := range test.inputs {
+			inputs = append(inputs, MergeInput{Name: names[j], Reader: strings.NewReader(s)})
+		}
+		var w bytes.Buffer
+		calvin := NewTransmogrifierMerge(&w, inputs...)
+		calvin.MergePlaceholder = test.placeholder
+		err := calvin.MDTable()
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			var ierr InputError
+			if !errors.As(err, &ierr) {
+				t.Errorf("%d: got %T want an InputError", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestMergeSkipBlankRecords(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifierMerge(&w,
+		MergeInput{Name: "a.csv", Reader: strings.NewReader("Make,Model\nFord,Focus\n,\n")},
+		MergeInput{Name: "b.csv", Reader: strings.NewReader("Year\n\"\"\n2016\n")},
+	)
+	calvin.MergePlaceholder = "-"
+	calvin.SkipBlankRecords = true
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Make|Model|Year  \n---|---|---  \nFord|Focus|-  \n-|-|2016  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
+
+func TestMergeNoHeaderRecord(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifierMerge(&w, MergeInput{Name: "a.csv", Reader: strings.NewReader("Ford,Focus\n")})
+	calvin.HasHeaderRecord = false
+	err := calvin.MDTable()
+	if err == nil {
+		t.Error("expected an error, got none")
+	}
+}
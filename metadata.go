@@ -0,0 +1,158 @@
+package csv2md
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetadataFormat is the format of the metadata block that describes the
+// table.
+type MetadataFormat int
+
+const (
+	// NoMetadata means no metadata block is written.
+	NoMetadata MetadataFormat = iota
+	// YAMLMetadata is a YAML front matter block, delimited by "---" lines.
+	YAMLMetadata
+	// JSONMetadata is an HTML comment containing a JSON object.
+	JSONMetadata
+)
+
+// ParseMetadataFormat returns the MetadataFormat for the value: yaml, json,
+// or none.  An empty value is none.
+func ParseMetadataFormat(s string) (MetadataFormat, error) {
+	switch strings.TrimSpace(strings.ToLower(s)) {
+	case "", "none":
+		return NoMetadata, nil
+	case "yaml":
+		return YAMLMetadata, nil
+	case "json":
+		return JSONMetadata, nil
+	}
+	return NoMetadata, fmt.Errorf("unknown metadata format %q", s)
+}
+
+// now returns the current time; it is a variable so that it can be
+// replaced in tests.
+var now = time.Now
+
+// Metadata describes a generated table.
+type Metadata struct {
+	Source    string          `json:"source,omitempty"`
+	Generated string          `json:"generated"`
+	Rows      int             `json:"rows"`
+	Columns   int             `json:"columns"`
+	Fields    []MetadataField `json:"fields"`
+	Options   MetadataOptions `json:"options"`
+}
+
+// MetadataField describes a column of the table.  The Type is inferred from
+// the column's values, unless it has been set using SetFieldFormats:
+// number, boolean, text, or empty.
+type MetadataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// MetadataOptions are the options that were used to generate the table.
+type MetadataOptions struct {
+	HasHeaderRecord bool     `json:"has_header_record"`
+	Strict          bool     `json:"strict"`
+	FieldAlignment  []string `json:"field_alignment,omitempty"`
+	FieldStyle      []string `json:"field_style,omitempty"`
+}
+
+func (c columnType) String() string {
+	switch c {
+	case numberColumn:
+		return "number"
+	case boolColumn:
+		return "boolean"
+	case textColumn:
+		return "text"
+	}
+	return "empty"
+}
+
+// metadata returns the metadata for the table; the records must have been
+// buffered and the header read.
+func (t *Transmogrifier) metadata(header []string) Metadata {
+	m := Metadata{
+		Source:    t.Source.Path,
+		Generated: now().UTC().Format(time.RFC3339),
+		Rows:      len(t.records),
+		Columns:   len(header),
+		Options: MetadataOptions{
+			HasHeaderRecord: t.HasHeaderRecord,
+			Strict:          t.Strict,
+			FieldAlignment:  t.fieldAlignment,
+			FieldStyle:      t.fieldStyle,
+		},
+	}
+	if m.Columns == 0 && len(t.records) > 0 {
+		m.Columns = len(t.records[0])
+	}
+	m.Fields = make([]MetadataField, 0, len(header))
+	for i, name := range header {
+		typ, ok := t.fieldTypes[i]
+		if !ok {
+			typ = inferColumnType(t.records, i, t.locale)
+		}
+		m.Fields = append(m.Fields, MetadataField{Name: name, Type: typ.String()})
+	}
+	return m
+}
+
+// writeMetadata writes the metadata block, followed by a blank line.
+func (t *Transmogrifier) writeMetadata(header []string) error {
+	m := t.metadata(header)
+	nl := t.lineEnd
+	var b bytes.Buffer
+	switch t.Metadata {
+	case YAMLMetadata:
+		b.WriteString("---" + nl)
+		if m.Source != "" {
+			fmt.Fprintf(&b, "source: %s%s", strconv.Quote(m.Source), nl)
+		}
+		fmt.Fprintf(&b, "generated: %s%s", strconv.Quote(m.Generated), nl)
+		fmt.Fprintf(&b, "rows: %d%s", m.Rows, nl)
+		fmt.Fprintf(&b, "columns: %d%s", m.Columns, nl)
+		b.WriteString("fields:" + nl)
+		for _, f := range m.Fields {
+			fmt.Fprintf(&b, "  - name: %s%s", strconv.Quote(f.Name), nl)
+			fmt.Fprintf(&b, "    type: %s%s", f.Type, nl)
+		}
+		b.WriteString("options:" + nl)
+		fmt.Fprintf(&b, "  has_header_record: %t%s", m.Options.HasHeaderRecord, nl)
+		fmt.Fprintf(&b, "  strict: %t%s", m.Options.Strict, nl)
+		writeYAMLList(&b, "field_alignment", m.Options.FieldAlignment, nl)
+		writeYAMLList(&b, "field_style", m.Options.FieldStyle, nl)
+		b.WriteString("---" + nl + nl)
+	case JSONMetadata:
+		j, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return err
+		}
+		// "--" can only occur within a string and it can't be in an HTML
+		// comment
+		j = bytes.Replace(j, []byte("--"), []byte(`-\u002d`), -1)
+		b.WriteString("<!--" + nl)
+		b.Write(j)
+		b.WriteString(nl + "-->" + nl + nl)
+	}
+	return t.write(b.String(), "metadata")
+}
+
+func writeYAMLList(b *bytes.Buffer, key string, vals []string, nl string) {
+	if len(vals) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "  %s:%s", key, nl)
+	for _, v := range vals {
+		fmt.Fprintf(b, "    - %s%s", strconv.Quote(v), nl)
+	}
+}
@@ -0,0 +1,102 @@
+package csv2md
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetadata(t *testing.T) {
+	now = func() time.Time { return time.Date(2015, 11, 2, 10, 30, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	csvData := "Make,Year,Note\nFord,2015,\nChevy,2014,\n"
+	table := "Make|Year|Note  \n:--|--:|---  \nFord|2015|   \nChevy|2014|   \n"
+	tests := []struct {
+		format   MetadataFormat
+		expected string
+	}{
+		{NoMetadata, table},
+		{YAMLMetadata, "---\n" +
+			"source: \"data/cars--2015.csv\"\n" +
+			"generated: \"2015-11-02T10:30:00Z\"\n" +
+			"rows: 2\n" +
+			"columns: 3\n" +
+			"fields:\n" +
+			"  - name: \"Make\"\n" +
+			"    type: text\n" +
+			"  - name: \"Year\"\n" +
+			"    type: number\n" +
+			"  - name: \"Note\"\n" +
+			"    type: empty\n" +
+			"options:\n" +
+			"  has_header_record: true\n" +
+			"  strict: false\n" +
+			"  field_alignment:\n" +
+			"    - \":--\"\n" +
+			"    - \"--:\"\n" +
+			"---\n\n" + table},
+	}
+	for i, test := range tests {
+		s, err := TableString(strings.NewReader(csvData), WithFieldAlignment([]string{"l", "r"}), func(t *Transmogrifier) {
+			t.Metadata = test.format
+			t.Source.Path = "data/cars--2015.csv"
+		})
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if s != test.expected {
+			t.Errorf("%d: got %q want %q", i, s, test.expected)
+		}
+	}
+}
+
+func TestMetadataJSON(t *testing.T) {
+	csvData := "Make,Year\nFord,2015\n"
+	s, err := TableString(strings.NewReader(csvData), func(t *Transmogrifier) {
+		t.Metadata = JSONMetadata
+		t.Source.Path = "data/cars--2015.csv"
+		t.SourceHeading = "## {basename}"
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.HasPrefix(s, "<!--\n{") {
+		t.Fatalf("expected the metadata to be an HTML comment, got %q", s)
+	}
+	i := strings.Index(s, "\n-->\n\n")
+	if i < 0 {
+		t.Fatalf("expected the end of the HTML comment, got %q", s)
+	}
+	j := s[len("<!--\n"):i]
+	if strings.Contains(j, "--") {
+		t.Errorf("the comment contains --: %q", j)
+	}
+	var m Metadata
+	err = json.Unmarshal([]byte(j), &m)
+	if err != nil {
+		t.Fatalf("unexpected error unmarshaling the metadata: %s", err)
+	}
+	if m.Source != "data/cars--2015.csv" || m.Rows != 1 || m.Columns != 2 || len(m.Fields) != 2 || m.Fields[1].Type != "number" {
+		t.Errorf("unexpected metadata: %+v", m)
+	}
+	if rest := s[i+len("\n-->\n\n"):]; !strings.HasPrefix(rest, "## cars--2015\n\nMake|Year  \n") {
+		t.Errorf("expected the heading and table to follow the metadata, got %q", rest)
+	}
+}
+
+func TestParseMetadataFormat(t *testing.T) {
+	for i, test := range []struct {
+		v        string
+		expected MetadataFormat
+		err      bool
+	}{
+		{"", NoMetadata, false}, {"none", NoMetadata, false}, {"YAML", YAMLMetadata, false}, {"json", JSONMetadata, false}, {"toml", NoMetadata, true},
+	} {
+		f, err := ParseMetadataFormat(test.v)
+		if (err != nil) != test.err || f != test.expected {
+			t.Errorf("%d: got %d, %v want %d", i, f, err, test.expected)
+		}
+	}
+}
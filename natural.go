@@ -0,0 +1,147 @@
+package csv2md
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SortNatural sorts the records, like SortGroups, comparing the group
+// column's values naturally instead of byte by byte: the runs of digits in
+// the values are compared as numbers, so "9" sorts before "10" and
+// "v1.2" before "v1.10".  If all of the column's values are numbers,
+// including decimal and negative numbers, they are compared as numbers.
+func SortNatural() GroupOption {
+	return func(g *group) {
+		g.sort = true
+		g.natural = true
+	}
+}
+
+// SortIgnoreCase sorts the records, like SortGroups, comparing the group
+// column's values without regard to their case, e.g. "apple" sorts before
+// "Banana"; values that only differ in case are sorted byte by byte.  It
+// can be used with SortNatural.  This is a simple case folding collation,
+// not a locale's collation.
+func SortIgnoreCase() GroupOption {
+	return func(g *group) {
+		g.sort = true
+		g.fold = true
+	}
+}
+
+// valueLess returns a function that reports whether the value a sorts
+// before the value b, using the group's sort options; the values are all
+// of the values that are sorted.
+func (g *group) valueLess(values []string) func(a, b string) bool {
+	if !g.natural && !g.fold {
+		return func(a, b string) bool { return a < b }
+	}
+	if g.natural && numbers(values) {
+		// empty values sort first
+		return func(a, b string) bool {
+			a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+			if a == "" || b == "" {
+				return a == "" && b != ""
+			}
+			x, _ := strconv.ParseFloat(a, 64)
+			y, _ := strconv.ParseFloat(b, 64)
+			return x < y
+		}
+	}
+	return func(a, b string) bool {
+		if c := compareValues(a, b, g.natural, g.fold); c != 0 {
+			return c < 0
+		}
+		return a < b
+	}
+}
+
+// numbers returns whether all of the non-empty values are numbers.
+func numbers(values []string) bool {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// compareValues compares the values, returning -1 if a sorts before b, 1
+// if it sorts after it, and 0 if they are equal.  If natural is true, the
+// runs of digits are compared as numbers; if fold is true, the rest of
+// the values are compared without regard to case.
+func compareValues(a, b string, natural, fold bool) int {
+	for a != "" && b != "" {
+		if natural && isDigit(a[0]) && isDigit(b[0]) {
+			var x, y string
+			x, a = digits(a)
+			y, b = digits(b)
+			if c := compareDigits(x, y); c != 0 {
+				return c
+			}
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(a)
+		s, m := utf8.DecodeRuneInString(b)
+		a, b = a[n:], b[m:]
+		if fold {
+			r, s = unicode.ToLower(r), unicode.ToLower(s)
+		}
+		if r != s {
+			if r < s {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	}
+	return 1
+}
+
+// digits splits the value into its leading run of digits and the rest.
+func digits(v string) (string, string) {
+	i := 0
+	for i < len(v) && isDigit(v[i]) {
+		i++
+	}
+	return v[:i], v[i:]
+}
+
+// compareDigits compares two runs of digits as numbers; equal numbers
+// with fewer leading zeros sort first.
+func compareDigits(x, y string) int {
+	tx, ty := strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+	switch {
+	case len(tx) != len(ty):
+		if len(tx) < len(ty) {
+			return -1
+		}
+		return 1
+	case tx != ty:
+		if tx < ty {
+			return -1
+		}
+		return 1
+	case len(x) != len(y):
+		if len(x) < len(y) {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,59 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		natural  bool
+		fold     bool
+		expected int
+	}{
+		{"9", "10", false, false, 1},
+		{"9", "10", true, false, -1},
+		{"v1.2", "v1.10", true, false, -1},
+		{"v1.10", "v1.10", true, false, 0},
+		{"v1.10.1", "v1.10", true, false, 1},
+		{"file007", "file7", true, false, 1},
+		{"file7", "file07", true, false, -1},
+		{"apple", "Banana", false, false, 1},
+		{"apple", "Banana", false, true, -1},
+		{"Élan", "élan", false, true, 0},
+		{"Item 2", "item 10", true, true, -1},
+	}
+	for i, test := range tests {
+		c := compareValues(test.a, test.b, test.natural, test.fold)
+		if c != test.expected {
+			t.Errorf("%d: %q %q: got %d want %d", i, test.a, test.b, c, test.expected)
+		}
+	}
+}
+
+func TestSortNatural(t *testing.T) {
+	tests := []struct {
+		csv      string
+		opts     []GroupOption
+		expected string
+	}{
+		{"V,N\nv1.10,a\nv1.2,b\nv1.9,c\n", []GroupOption{SortNatural()}, "V|N  \n---|---  \n**V: v1.2**|   \nv1.2|b  \n**V: v1.9**|   \nv1.9|c  \n**V: v1.10**|   \nv1.10|a  \n"},
+		{"V,N\n10,a\n-2.5,b\n9,c\n,d\n", []GroupOption{SortNatural(), HideGroupColumn()}, "N  \n---  \n**V: **  \nd  \n**V: -2.5**  \nb  \n**V: 9**  \nc  \n**V: 10**  \na  \n"},
+		{"V,N\nb,a\nB,b\na,c\n", []GroupOption{SortIgnoreCase(), HideGroupColumn()}, "N  \n---  \n**V: a**  \nc  \n**V: B**  \nb  \n**V: b**  \na  \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(test.csv), &w)
+		calvin.GroupBy("V", test.opts...)
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
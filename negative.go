@@ -0,0 +1,133 @@
+package csv2md
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NegativeStyle is how the negative numbers of a column are written; see
+// SetColumnNegative.
+type NegativeStyle struct {
+	// Parentheses writes the negative numbers in parentheses, without
+	// their minus sign, e.g. (123.45).
+	Parentheses bool
+	// Style is the style of the negative numbers' cells, e.g. bold or
+	// strikethrough; see SetFieldStyle for the accepted style values.
+	Style string
+	// Class is the class of the negative numbers' cells in HTML tables,
+	// e.g. negative; it is ignored by the other output formats.
+	Class string
+}
+
+// ParseNegativeStyle parses a comma separated list of the treatments of
+// negative numbers: parens, for Parentheses, a style, e.g. bold, or
+// class:name, for the HTML Class; e.g. "parens,bold".
+func ParseNegativeStyle(s string) (NegativeStyle, error) {
+	var ns NegativeStyle
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		switch {
+		case strings.EqualFold(v, "parens") || strings.EqualFold(v, "parentheses"):
+			ns.Parentheses = true
+		case strings.HasPrefix(strings.ToLower(v), "class:") && len(v) > len("class:"):
+			ns.Class = v[len("class:"):]
+		case parseStyle(v) != "":
+			ns.Style = v
+		default:
+			return NegativeStyle{}, fmt.Errorf("negative style %q: expected parens, a style, or class:name", s)
+		}
+	}
+	return ns, nil
+}
+
+type negative struct {
+	column string
+	index  int
+	style  NegativeStyle
+}
+
+// SetColumnNegative sets how the named column's negative numbers are
+// written; if the column is AllColumns, it is how every column's negative
+// numbers are written, unless the column has its own.  A value is negative
+// if its raw value, as it was read, is a number of the locale, see
+// SetLocale, that is less than 0.  The parentheses replace the minus sign
+// of the value as it is written, after it has been formatted, e.g. by
+// SetColumnNumberFormat or SetColumnCurrency; a value that no longer
+// starts with a minus sign, e.g. because of a value map, is written as
+// is.  The Style is layered on top of the column's base style after the
+// styles of the cell style rules, see SetCellStyleRule.
+func (t *Transmogrifier) SetColumnNegative(column string, ns NegativeStyle) {
+	ns.Style = parseStyle(ns.Style)
+	for _, n := range t.negatives {
+		if n.column == column {
+			n.style = ns
+			return
+		}
+	}
+	t.negatives = append(t.negatives, &negative{column: column, style: ns})
+}
+
+// prepareNegatives resolves the negative styles' columns against the
+// header.
+func (t *Transmogrifier) prepareNegatives(header []string) error {
+	for _, n := range t.negatives {
+		if n.column == AllColumns {
+			n.index = -1
+			continue
+		}
+		n.index = columnIndex(header, n.column)
+		if n.index < 0 {
+			return UnknownColumnError{Name: n.column, operation: "negative style"}
+		}
+	}
+	return nil
+}
+
+// negativeStyle returns the negative style of the i'th field, if it has
+// one; the column's own style is used instead of the AllColumns style.
+func (t *Transmogrifier) negativeStyle(i int) *negative {
+	var all *negative
+	for _, n := range t.negatives {
+		switch n.index {
+		case i:
+			return n
+		case -1:
+			all = n
+		}
+	}
+	return all
+}
+
+// isNegative returns whether the value is a negative number.
+func (t *Transmogrifier) isNegative(v string) bool {
+	_, n, ok := t.locale.number(v)
+	return ok && n < 0
+}
+
+// parenthesize writes the negative values of the fields with the
+// Parentheses in parentheses.
+func (t *Transmogrifier) parenthesize(record, raw []string) {
+	for i, v := range record {
+		if i >= len(raw) || !strings.HasPrefix(v, "-") {
+			continue
+		}
+		n := t.negativeStyle(i)
+		if n == nil || !n.style.Parentheses || !t.isNegative(raw[i]) {
+			continue
+		}
+		record[i] = "(" + v[1:] + ")"
+	}
+}
+
+// negativeClass returns the class of the i'th field's HTML cell, if the
+// raw value is negative and its negative style has a class.
+func (t *Transmogrifier) negativeClass(i int, raw []string) string {
+	if i >= len(raw) {
+		return ""
+	}
+	n := t.negativeStyle(i)
+	if n == nil || n.style.Class == "" || !t.isNegative(raw[i]) {
+		return ""
+	}
+	return n.style.Class
+}
@@ -0,0 +1,97 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseNegativeStyle(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected NegativeStyle
+		err      string
+	}{
+		{"parens", NegativeStyle{Parentheses: true}, ""},
+		{"Parentheses, b", NegativeStyle{Parentheses: true, Style: "b"}, ""},
+		{"strikethrough,class:negative", NegativeStyle{Style: "strikethrough", Class: "negative"}, ""},
+		{"red", NegativeStyle{}, `negative style "red": expected parens, a style, or class:name`},
+		{"class:", NegativeStyle{}, `negative style "class:": expected parens, a style, or class:name`},
+		{"", NegativeStyle{}, `negative style "": expected parens, a style, or class:name`},
+	}
+	for i, test := range tests {
+		ns, err := ParseNegativeStyle(test.value)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if ns != test.expected {
+			t.Errorf("%d: got %+v want %+v", i, ns, test.expected)
+		}
+	}
+}
+
+func TestSetColumnNegative(t *testing.T) {
+	csvData := "Item,Amount,Balance\nCar,-25999.5,-1\nHouse,1234,-0\nIdea,-,3\n"
+	tests := []struct {
+		negatives map[string]NegativeStyle
+		currency  bool
+		expected  string
+	}{
+		{map[string]NegativeStyle{"Amount": {Parentheses: true}}, false,
+			"Item|Amount|Balance  \n---|---|---  \nCar|(25999.5)|-1  \nHouse|1234|-0  \nIdea|-|3  \n"},
+		{map[string]NegativeStyle{"Amount": {Parentheses: true, Style: "b"}}, true,
+			"Item|Amount|Balance  \n---|---|---  \nCar|__($25,999.50)__|-1  \nHouse|$1,234.00|-0  \nIdea|-|3  \n"},
+		{map[string]NegativeStyle{AllColumns: {Style: "s"}, "Balance": {Parentheses: true}}, false,
+			"Item|Amount|Balance  \n---|---|---  \nCar|~~-25999.5~~|(1)  \nHouse|1234|-0  \nIdea|-|3  \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		for column, ns := range test.negatives {
+			calvin.SetColumnNegative(column, ns)
+		}
+		if test.currency {
+			calvin.SetColumnCurrency("Amount", CurrencyFormat{Symbol: "$", Precision: -1})
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+	calvin.SetColumnNegative("Total", NegativeStyle{Parentheses: true})
+	err := calvin.MDTable()
+	if err == nil || err.Error() != `negative style: unknown column "Total"` {
+		t.Errorf("got error %v want an unknown column error", err)
+	}
+}
+
+func TestHTMLNegative(t *testing.T) {
+	csvData := "Item,Amount\nCar,-2.5\nHouse,3\n"
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+	calvin.OutputFormat = HTML
+	calvin.SetColumnNegative("Amount", NegativeStyle{Style: "i", Class: "neg"})
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "<table>\n<thead>\n<tr>\n<th>Item</th>\n<th>Amount</th>\n</tr>\n</thead>\n<tbody>\n" +
+		"<tr>\n<td>Car</td>\n<td class=\"neg\"><em>-2.5</em></td>\n</tr>\n" +
+		"<tr>\n<td>House</td>\n<td>3</td>\n</tr>\n</tbody>\n</table>\n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
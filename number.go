@@ -0,0 +1,116 @@
+package csv2md
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat is the format of a column's numbers; see
+// SetColumnNumberFormat.
+type NumberFormat struct {
+	// Precision is the number of digits after the decimal point, or, in
+	// scientific notation, of the mantissa.  A negative precision keeps
+	// the number's digits.
+	Precision int
+	// Thousands separates the groups of thousands of the integer part of
+	// fixed point numbers, e.g. ","; if it is empty, they aren't
+	// separated.
+	Thousands string
+	// Scientific formats the numbers in scientific notation, e.g.
+	// 1.23e+06, instead of fixed point notation.
+	Scientific bool
+}
+
+// ParseNumberFormat parses a number format of the form
+// [thousands][.precision][f|e], e.g. ",.2f" for 1,234,567.89 or ".3e" for
+// 1.235e+06.  The thousands separator is one of a comma, an underscore, an
+// apostrophe, or a space; the f and e are fixed point and scientific
+// notation.  The default is fixed point notation with the number's own
+// digits.
+func ParseNumberFormat(s string) (NumberFormat, error) {
+	f := NumberFormat{Precision: -1}
+	v := s
+	if v != "" && strings.ContainsAny(v[:1], ",_' ") {
+		f.Thousands, v = v[:1], v[1:]
+	}
+	if strings.HasPrefix(v, ".") {
+		i := 1
+		for i < len(v) && isDigit(v[i]) {
+			i++
+		}
+		n, err := strconv.Atoi(v[1:i])
+		if err != nil {
+			return NumberFormat{}, fmt.Errorf("number format %q: expected [thousands][.precision][f|e]", s)
+		}
+		f.Precision, v = n, v[i:]
+	}
+	switch v {
+	case "f", "":
+	case "e":
+		f.Scientific = true
+	default:
+		return NumberFormat{}, fmt.Errorf("number format %q: expected [thousands][.precision][f|e]", s)
+	}
+	if s == "" {
+		return NumberFormat{}, fmt.Errorf("number format %q: expected [thousands][.precision][f|e]", s)
+	}
+	return f, nil
+}
+
+// SetColumnNumberFormat sets the format of the named column's numbers;
+// e.g. with a precision of 2 and a Thousands separator of ",",
+// 1234567.8912 is written as 1,234,567.89.  Values that aren't numbers
+// are written as is.  The numbers are formatted after the value maps and
+// before the cell templates and the field's styling.  Setting a format
+// for a column that already has a value format replaces it.
+func (t *Transmogrifier) SetColumnNumberFormat(column string, f NumberFormat) {
+	t.setValueFormat(&valueFormat{column: column, kind: "number format", format: t.numberFormatter(f)})
+}
+
+// numberFormatter returns the function that formats the values, the
+// numbers of the Transmogrifier's locale, using f.
+func (t *Transmogrifier) numberFormatter(f NumberFormat) func(string) string {
+	return func(v string) string {
+		return f.format(v, t.locale)
+	}
+}
+
+// format returns the formatted value if it is one of the locale's
+// numbers; otherwise the value is returned as is.  The number is written
+// with the locale's decimal mark.
+func (f NumberFormat) format(v string, l locale) string {
+	s, n, ok := l.number(v)
+	if !ok || math.IsInf(n, 0) || math.IsNaN(n) {
+		return v
+	}
+	if f.Scientific {
+		return l.decimalMark(strconv.FormatFloat(n, 'e', f.Precision, 64))
+	}
+	// a number's own digits are kept, unless it is in scientific notation
+	if f.Precision >= 0 || strings.ContainsAny(s, "eE") {
+		s = strconv.FormatFloat(n, 'f', f.Precision, 64)
+	}
+	if f.Thousands == "" {
+		return l.decimalMark(s)
+	}
+	var sign string
+	if s[0] == '-' || s[0] == '+' {
+		sign, s = s[:1], s[1:]
+	}
+	point := strings.IndexByte(s, '.')
+	if point < 0 {
+		point = len(s)
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	for i := 0; i < point; i++ {
+		if i > 0 && (point-i)%3 == 0 {
+			b.WriteString(f.Thousands)
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteString(l.decimalMark(s[point:]))
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseNumberFormat(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected NumberFormat
+		err      string
+	}{
+		{",.2f", NumberFormat{Precision: 2, Thousands: ","}, ""},
+		{",", NumberFormat{Precision: -1, Thousands: ","}, ""},
+		{".0", NumberFormat{Precision: 0}, ""},
+		{"_", NumberFormat{Precision: -1, Thousands: "_"}, ""},
+		{".3e", NumberFormat{Precision: 3, Scientific: true}, ""},
+		{"e", NumberFormat{Precision: -1, Scientific: true}, ""},
+		{"f", NumberFormat{Precision: -1}, ""},
+		{"", NumberFormat{}, `number format "": expected [thousands][.precision][f|e]`},
+		{".f", NumberFormat{}, `number format ".f": expected [thousands][.precision][f|e]`},
+		{",.2x", NumberFormat{}, `number format ",.2x": expected [thousands][.precision][f|e]`},
+	}
+	for i, test := range tests {
+		f, err := ParseNumberFormat(test.value)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if f != test.expected {
+			t.Errorf("%d: got %+v want %+v", i, f, test.expected)
+		}
+	}
+}
+
+func TestNumberFormat(t *testing.T) {
+	tests := []struct {
+		format   string
+		value    string
+		expected string
+	}{
+		{",.2f", "1234567.8912", "1,234,567.89"},
+		{",.2f", "-1234.5", "-1,234.50"},
+		{",.2f", "999.999", "1,000.00"},
+		{",", "1234567.8912", "1,234,567.8912"},
+		{",", "2.50", "2.50"},
+		{",", "+1234", "+1,234"},
+		{",", "1.5e6", "1,500,000"},
+		{"'.1", "123456", "123'456.0"},
+		{".0", "2.5", "2"},
+		{".3e", "1234567.8912", "1.235e+06"},
+		{",.2f", "n/a", "n/a"},
+		{",.2f", "", ""},
+		{",.2f", "NaN", "NaN"},
+	}
+	for i, test := range tests {
+		f, err := ParseNumberFormat(test.format)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		v := f.format(test.value, locale{})
+		if v != test.expected {
+			t.Errorf("%d: %s %q: got %q want %q", i, test.format, test.value, v, test.expected)
+		}
+	}
+}
+
+func TestSetColumnNumberFormat(t *testing.T) {
+	csvData := "Item,Price\nCar,25999.5\nHouse,1234567.8912\nIdea,n/a\n"
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+	calvin.SetColumnNumberFormat("Price", NumberFormat{Precision: 2, Thousands: ","})
+	calvin.SetFieldStyle([]string{"", "b"})
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Item|Price  \n---|---  \nCar|__25,999.50__  \nHouse|__1,234,567.89__  \nIdea|__n/a__  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+
+	calvin = NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+	calvin.SetColumnNumberFormat("Cost", NumberFormat{Precision: 2})
+	err = calvin.MDTable()
+	if err == nil || err.Error() != `number format: unknown column "Cost"` {
+		t.Errorf("got error %v want an unknown column error", err)
+	}
+}
+
+func TestFieldFormatNumber(t *testing.T) {
+	csvData := "Item,Price\nCar,25999.5\n"
+	tests := []struct {
+		formats  []FieldFormat
+		expected string
+		err      string
+	}{
+		{[]FieldFormat{{}, {Number: ",.1f"}}, "Item|Price  \n---|---  \nCar|25,999.5  \n", ""},
+		{[]FieldFormat{{Column: "Price", Number: ".2e"}}, "Item|Price  \n---|---  \nCar|2.60e+04  \n", ""},
+		{[]FieldFormat{{Column: "Price", Number: "x"}}, "", `field 1: number format "x": expected [thousands][.precision][f|e]`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		err := calvin.SetFieldFormats(test.formats)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package csv2md
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// pivot is the cross-tabulation of SetPivot.
+type pivot struct {
+	row    string
+	column string
+	value  string
+	fn     string
+}
+
+// SetPivot writes the table as a pivot table, a cross-tabulation of the
+// records: the table has a row for each value of the row column and a
+// column for each value of the column column, in the order that the
+// values first occur.  Each cell is the aggregate of the value column's
+// values of the records with the cell's row and column values; a cell
+// without any records is empty.  The aggregates are those of
+// SetColumnAggregate: sum, avg, count, min, and max.  The pivot table's
+// header is the row column's name followed by the column values.
+//
+// The table must have a header record.  Computed columns and filtering
+// are applied before the records are pivoted; all other settings, e.g.
+// the field formats and the footer row, apply to the pivot table.
+// Pivoting requires all of the CSV-encoded data to be read into memory.
+func (t *Transmogrifier) SetPivot(row, column, value, agg string) error {
+	agg, err := parseAggregate(agg)
+	if err != nil {
+		return err
+	}
+	t.pivot = &pivot{row: row, column: column, value: value, fn: agg}
+	return nil
+}
+
+// ParsePivot parses a pivot of the form row,column,value=aggregate; e.g.
+// "Region,Quarter,Sales=sum", and sets it.  If the aggregate is omitted,
+// the values are summed.
+func (t *Transmogrifier) ParsePivot(s string) error {
+	agg := aggSum
+	spec := s
+	if i := strings.LastIndex(s, "="); i >= 0 {
+		spec, agg = s[:i], s[i+1:]
+	}
+	columns := strings.Split(spec, ",")
+	if len(columns) != 3 {
+		return fmt.Errorf("pivot %q: expected row,column,value=aggregate", s)
+	}
+	for i := range columns {
+		columns[i] = strings.TrimSpace(columns[i])
+	}
+	return t.SetPivot(columns[0], columns[1], columns[2], agg)
+}
+
+// pivotTable reads all of the remaining records and buffers the pivot
+// table.  The pivot table's header is returned.  Like transpose, the
+// records have already been computed and filtered, so the buffered
+// records are not computed or filtered again.
+func (t *Transmogrifier) pivotTable(header []string) ([]string, error) {
+	if header == nil {
+		return nil, errors.New("pivot: the table must have a header record")
+	}
+	var index [3]int
+	for i, name := range []string{t.pivot.row, t.pivot.column, t.pivot.value} {
+		index[i] = columnIndex(header, name)
+		if index[i] < 0 {
+			return nil, UnknownColumnError{Name: name, operation: "pivot"}
+		}
+	}
+	var rows, columns []string
+	rowIndex := make(map[string]int)
+	colIndex := make(map[string]int)
+	cells := make(map[[2]int]*aggregate)
+	for {
+		record, err := t.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		key := [3]string{}
+		for i, j := range index {
+			if j < len(record) {
+				key[i] = record[j]
+			}
+		}
+		r, ok := rowIndex[key[0]]
+		if !ok {
+			r = len(rows)
+			rowIndex[key[0]] = r
+			rows = append(rows, key[0])
+		}
+		c, ok := colIndex[key[1]]
+		if !ok {
+			c = len(columns)
+			colIndex[key[1]] = c
+			columns = append(columns, key[1])
+		}
+		a := cells[[2]int{r, c}]
+		if a == nil {
+			a = &aggregate{fn: t.pivot.fn, locale: t.locale}
+			cells[[2]int{r, c}] = a
+		}
+		a.add(key[2])
+	}
+	t.records = make([][]string, len(rows))
+	for r, v := range rows {
+		record := make([]string, len(columns)+1)
+		record[0] = v
+		for c := range columns {
+			if a := cells[[2]int{r, c}]; a != nil {
+				record[c+1] = a.value()
+			}
+		}
+		t.records[r] = record
+	}
+	t.buffered = true
+	t.nComputed = len(t.records)
+	t.nFiltered = len(t.records)
+	t.header = append([]string{t.pivot.row}, columns...)
+	return t.header, nil
+}
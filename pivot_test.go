@@ -0,0 +1,92 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const pivotCSV = "Region,Quarter,Sales\nEast,Q1,10\nWest,Q1,5\nEast,Q2,7.5\nEast,Q1,2\nWest,Q3,\n"
+
+func TestSetPivot(t *testing.T) {
+	tests := []struct {
+		row, column, value, agg string
+		expected                string
+		err                     string
+	}{
+		{"Region", "Quarter", "Sales", "sum", "Region|Q1|Q2|Q3  \n---|---|---|---  \nEast|12|7.5|   \nWest|5| |   \n", ""},
+		{"Region", "Quarter", "Sales", "count", "Region|Q1|Q2|Q3  \n---|---|---|---  \nEast|2|1|   \nWest|1| |0  \n", ""},
+		{"Region", "Quarter", "Sales", "MAX", "Region|Q1|Q2|Q3  \n---|---|---|---  \nEast|10|7.5|   \nWest|5| |   \n", ""},
+		{"Quarter", "Region", "Sales", "avg", "Quarter|East|West  \n---|---|---  \nQ1|6.00|5.00  \nQ2|7.500|   \nQ3| |   \n", ""},
+		{"Region", "Month", "Sales", "sum", "", `pivot: unknown column "Month"`},
+		{"Region", "Quarter", "Sales", "median", "", `aggregate "median": unknown aggregate`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(pivotCSV), &w)
+		err := calvin.SetPivot(test.row, test.column, test.value, test.agg)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestParsePivot(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected pivot
+		err      string
+	}{
+		{"Region,Quarter,Sales=avg", pivot{row: "Region", column: "Quarter", value: "Sales", fn: aggAvg}, ""},
+		{" Region , Quarter , Sales ", pivot{row: "Region", column: "Quarter", value: "Sales", fn: aggSum}, ""},
+		{"Region,Quarter=sum", pivot{}, `pivot "Region,Quarter=sum": expected row,column,value=aggregate`},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(strings.NewReader(""), &bytes.Buffer{})
+		err := calvin.ParsePivot(test.value)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if *calvin.pivot != test.expected {
+			t.Errorf("%d: got %+v want %+v", i, *calvin.pivot, test.expected)
+		}
+	}
+}
+
+func TestPivotTranspose(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(pivotCSV), &w)
+	calvin.Transpose = true
+	err := calvin.SetPivot("Region", "Quarter", "Sales", "sum")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Region|East|West  \n---|---|---  \nQ1|12|5  \nQ2|7.5|   \nQ3| |   \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
@@ -0,0 +1,118 @@
+package csv2md
+
+// pretty renders GitHub Flavored Markdown tables whose cells are padded so
+// that the pipes line up in the Markdown.  Since the column widths depend
+// on all of the values, the rows are buffered, as Markdown, until Close.
+type pretty struct {
+	g      gfm
+	header []Cell
+	align  []Alignment
+	rows   []prettyRow
+}
+
+// prettyRow is either a record's cells or, if the column is not empty, a
+// group subheader.
+type prettyRow struct {
+	cells         []Cell
+	column, value string
+}
+
+func (p *pretty) WriteHeader(names []string) error {
+	return p.WriteHeaderCells(nameCells(names))
+}
+
+func (p *pretty) WriteHeaderCells(cells []Cell) error {
+	p.header = p.markdown(cells, p.g.headerCell)
+	return nil
+}
+
+func (p *pretty) WriteSeparator(alignment []Alignment) error {
+	p.align = alignment
+	return nil
+}
+
+func (p *pretty) WriteGroup(column, value string) error {
+	p.rows = append(p.rows, prettyRow{column: column, value: value})
+	return nil
+}
+
+func (p *pretty) WriteRow(cells []Cell) error {
+	p.rows = append(p.rows, prettyRow{cells: p.markdown(cells, p.g.cell)})
+	return nil
+}
+
+func (p *pretty) WriteFooter(cells []Cell) error {
+	p.rows = append(p.rows, prettyRow{cells: p.markdown(cells, p.g.footerCell)})
+	return nil
+}
+
+func (p *pretty) Close() error {
+	p.widen()
+	value := func(i int, c Cell) string {
+		return c.Value
+	}
+	if p.header != nil {
+		err := p.g.writeCells(p.header, value, "header record")
+		if err != nil {
+			return err
+		}
+		err = p.g.WriteSeparator(p.align)
+		if err != nil {
+			return err
+		}
+	}
+	for _, row := range p.rows {
+		var err error
+		if row.column != "" {
+			err = p.g.WriteGroup(row.column, row.value)
+		} else {
+			err = p.g.writeCells(row.cells, value, "record")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdown returns a copy of the cells whose values are their Markdown;
+// value returns the Markdown of the i'th field's cell.
+func (p *pretty) markdown(cells []Cell, value func(i int, c Cell) string) []Cell {
+	md := make([]Cell, len(cells))
+	i := -1
+	for j, c := range cells {
+		i = p.g.t.nextField(i)
+		c.Value = value(i, c)
+		md[j] = c
+	}
+	return md
+}
+
+// widen sets each field's width to the widest of its cells, including the
+// header, unless the field's minimum width is wider.  A field is at least
+// as wide as its header record separator, 3 characters.  Group subheaders
+// are not measured.
+func (p *pretty) widen() {
+	t := p.g.t
+	measure := func(cells []Cell) {
+		i := -1
+		for _, c := range cells {
+			i = t.nextField(i)
+			for len(t.widths) <= i {
+				t.widths = append(t.widths, len(none))
+			}
+			if n := displayWidth(c.Value); n > t.widths[i] {
+				t.widths[i] = n
+			}
+		}
+	}
+	for i := range t.widths {
+		if t.widths[i] < len(none) {
+			t.widths[i] = len(none)
+		}
+	}
+	measure(p.header)
+	for _, row := range p.rows {
+		measure(row.cells)
+	}
+}
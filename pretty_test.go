@@ -0,0 +1,68 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPretty(t *testing.T) {
+	csvData := []byte("Make,Model,Year\nFord,Focus,2012\nChevrolet,Volt,2017\nKia,Rio,\n")
+	tests := []struct {
+		alignment []string
+		style     []string
+		widths    []int
+		expected  string
+	}{
+		{nil, nil, nil, "Make     |Model|Year  \n---------|-----|----  \nFord     |Focus|2012  \nChevrolet|Volt |2017  \nKia      |Rio  |      \n"},
+		{[]string{"l", "c", "r"}, []string{"b", "", "i"}, nil, "Make         |Model|  Year  \n:------------|:---:|-----:  \n__Ford__     |Focus|_2012_  \n__Chevrolet__|Volt |_2017_  \n__Kia__      | Rio |   _ _  \n"},
+		{nil, nil, []int{0, 8}, "Make     |Model   |Year  \n---------|--------|----  \nFord     |Focus   |2012  \nChevrolet|Volt    |2017  \nKia      |Rio     |      \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.Pretty = true
+		calvin.SetFieldAlignment(test.alignment)
+		calvin.SetFieldStyle(test.style)
+		calvin.SetFieldWidths(test.widths)
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+// TestPrettyWide checks that the cells are padded to their display width, so
+// that the pipes line up when a field has CJK ideographs or emoji.
+func TestPrettyWide(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader([]byte("City,Ok\n東京,✅\nParis,❌\n")), &w)
+	calvin.Pretty = true
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "City |Ok   \n-----|---  \n東京 |✅   \nParis|❌   \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
+
+func TestPrettyGroupBy(t *testing.T) {
+	csvData := []byte("Team,Name\nPlatform,Ann\nWeb,Bartholomew\n")
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+	calvin.Pretty = true
+	calvin.GroupBy("Team")
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Team    |Name         \n--------|-----------  \n**Team: Platform**|             \nPlatform|Ann          \n**Team: Web**|             \nWeb     |Bartholomew  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
@@ -0,0 +1,273 @@
+package csv2md
+
+import "strings"
+
+// minPreviewWidth is the narrowest that a column is shrunk to.
+const minPreviewWidth = 3
+
+// boxChars are the characters used to draw a box table.
+type boxChars struct {
+	horizontal, vertical            string
+	topLeft, top, topRight          string
+	left, cross, right              string
+	bottomLeft, bottom, bottomRight string
+	truncated                       string
+}
+
+var (
+	boxDrawing = boxChars{
+		horizontal: "─", vertical: "│",
+		topLeft: "┌", top: "┬", topRight: "┐",
+		left: "├", cross: "┼", right: "┤",
+		bottomLeft: "└", bottom: "┴", bottomRight: "┘",
+		truncated: "…",
+	}
+	boxASCII = boxChars{
+		horizontal: "-", vertical: "|",
+		topLeft: "+", top: "+", topRight: "+",
+		left: "+", cross: "+", right: "+",
+		bottomLeft: "+", bottom: "+", bottomRight: "+",
+		truncated: "~",
+	}
+)
+
+// box renders a plain text table, drawn with either box-drawing or ASCII
+// characters, for previewing a table in a terminal.  Since the column
+// widths depend on all of the values, the rows are buffered until close.
+type box struct {
+	t      *Transmogrifier
+	ascii  bool
+	fields []string
+	align  []string
+	rows   []boxRow
+}
+
+// boxRow is either a record's values or, if the label is not empty, a
+// group subheader that spans the table.  The footer row is separated from
+// the records by a rule.
+type boxRow struct {
+	cells  []string
+	label  string
+	footer bool
+}
+
+func (b *box) WriteHeader(names []string) error {
+	b.fields = names
+	return nil
+}
+
+// WriteSeparator sets the columns' alignments; the header is aligned the
+// same as its column.
+func (b *box) WriteSeparator(alignment []Alignment) error {
+	for _, a := range alignment {
+		b.align = append(b.align, markdownAlignments[a])
+	}
+	return nil
+}
+
+func (b *box) WriteGroup(column, value string) error {
+	b.rows = append(b.rows, boxRow{label: column + ": " + value})
+	return nil
+}
+
+func (b *box) WriteRow(cells []Cell) error {
+	b.rows = append(b.rows, boxRow{cells: cellValues(cells)})
+	return nil
+}
+
+func (b *box) WriteFooter(cells []Cell) error {
+	b.rows = append(b.rows, boxRow{cells: cellValues(cells), footer: true})
+	return nil
+}
+
+func (b *box) Close() error {
+	c := boxDrawing
+	if b.ascii {
+		c = boxASCII
+	}
+	widths := b.widths()
+	if len(widths) == 0 {
+		return nil
+	}
+	rows := b.rows
+	if b.fields != nil {
+		rows = append([]boxRow{{cells: b.fields}}, rows...)
+	}
+	// a rule is drawn after the header and around group subheaders; the
+	// joins depend on whether the rows above and below have columns.
+	lines := []string{b.rule(c, widths, false, rows[0].label == "", c.topLeft, c.topRight)}
+	for i, row := range rows {
+		if i > 0 {
+			prev := rows[i-1]
+			if (i == 1 && b.fields != nil) || prev.label != "" || row.label != "" || row.footer {
+				lines = append(lines, b.rule(c, widths, prev.label == "", row.label == "", c.left, c.right))
+			}
+		}
+		if row.label != "" {
+			lines = append(lines, b.span(c, widths, row.label))
+			continue
+		}
+		lines = append(lines, b.line(c, widths, row.cells))
+	}
+	lines = append(lines, b.rule(c, widths, rows[len(rows)-1].label == "", false, c.bottomLeft, c.bottomRight))
+	for _, l := range lines {
+		err := b.t.write(l+"\n", "preview")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// widths returns the width of each column; if the table is wider than the
+// PreviewWidth, the widest columns are shrunk.
+func (b *box) widths() []int {
+	var widths []int
+	measure := func(cells []string) {
+		for i, v := range cells {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := displayWidth(v); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	measure(b.fields)
+	for _, row := range b.rows {
+		measure(row.cells)
+	}
+	if len(widths) == 0 {
+		return nil
+	}
+	// the minimum field widths apply to the visible columns
+	var col int
+	for i := 0; col < len(widths); i++ {
+		if b.t.hidden[i] {
+			continue
+		}
+		if w := b.t.width(i); w > widths[col] {
+			widths[col] = w
+		}
+		col++
+	}
+	// a label has to fit in the table too
+	total := 3*len(widths) + 1
+	for _, w := range widths {
+		total += w
+	}
+	for _, row := range b.rows {
+		if n := displayWidth(row.label) + 4; n > total {
+			widths[len(widths)-1] += n - total
+			total = n
+		}
+	}
+	if b.t.PreviewWidth <= 0 {
+		return widths
+	}
+	for total > b.t.PreviewWidth {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minPreviewWidth {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// rule returns a horizontal line.  The above and below are whether the
+// lines above and below the rule have column separators.
+func (b *box) rule(c boxChars, widths []int, above, below bool, left, right string) string {
+	join := c.horizontal
+	switch {
+	case above && below:
+		join = c.cross
+	case above:
+		join = c.bottom
+	case below:
+		join = c.top
+	}
+	var s strings.Builder
+	s.WriteString(left)
+	for i, w := range widths {
+		if i > 0 {
+			s.WriteString(join)
+		}
+		s.WriteString(strings.Repeat(c.horizontal, w+2))
+	}
+	s.WriteString(right)
+	return s.String()
+}
+
+// line returns a row of cells.
+func (b *box) line(c boxChars, widths []int, cells []string) string {
+	var s strings.Builder
+	s.WriteString(c.vertical)
+	for i, w := range widths {
+		var v, a string
+		if i < len(cells) {
+			v = cells[i]
+		}
+		if i < len(b.align) {
+			a = b.align[i]
+		}
+		s.WriteString(" ")
+		s.WriteString(pad(truncate(v, w, c.truncated), w, a))
+		s.WriteString(" ")
+		s.WriteString(c.vertical)
+	}
+	return s.String()
+}
+
+// span returns a row with a single cell that spans the table.
+func (b *box) span(c boxChars, widths []int, label string) string {
+	w := 3 * (len(widths) - 1)
+	for _, n := range widths {
+		w += n
+	}
+	return c.vertical + " " + pad(truncate(label, w, c.truncated), w, left) + " " + c.vertical
+}
+
+// truncate shortens the value to the width, in columns, see displayWidth;
+// a truncated value ends with the marker.  A wide rune that doesn't fit
+// is dropped, so a truncated value can be a column narrower than the width.
+func truncate(v string, width int, marker string) string {
+	if displayWidth(v) <= width {
+		return v
+	}
+	if width <= 0 {
+		return ""
+	}
+	width -= displayWidth(marker)
+	var n int
+	for i, r := range v {
+		w := runeWidth(r)
+		if n+w > width {
+			return v[:i] + marker
+		}
+		n += w
+	}
+	return v + marker
+}
+
+// pad pads the value to the width, in columns, according to the alignment.
+func pad(v string, width int, alignment string) string {
+	n := width - displayWidth(v)
+	if n <= 0 {
+		return v
+	}
+	switch alignment {
+	case right:
+		return strings.Repeat(" ", n) + v
+	case centered:
+		l := n / 2
+		return strings.Repeat(" ", l) + v + strings.Repeat(" ", n-l)
+	}
+	return v + strings.Repeat(" ", n)
+}
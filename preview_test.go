@@ -0,0 +1,114 @@
+package csv2md
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBoxPreview(t *testing.T) {
+	csvData := "Team,Name,Qty\nWeb,Ann,3\nWeb,Bob,12\nPlatform,Cat,7\n"
+	tests := []struct {
+		format   Format
+		width    int
+		opts     []Option
+		expected string
+	}{
+		{Box, 0, nil, "" +
+			"┌──────────┬──────┬─────┐\n" +
+			"│ Team     │ Name │ Qty │\n" +
+			"├──────────┼──────┼─────┤\n" +
+			"│ Web      │ Ann  │   3 │\n" +
+			"│ Web      │ Bob  │  12 │\n" +
+			"│ Platform │ Cat  │   7 │\n" +
+			"└──────────┴──────┴─────┘\n"},
+		{ASCIIBox, 0, nil, "" +
+			"+----------+------+-----+\n" +
+			"| Team     | Name | Qty |\n" +
+			"+----------+------+-----+\n" +
+			"| Web      | Ann  |   3 |\n" +
+			"| Web      | Bob  |  12 |\n" +
+			"| Platform | Cat  |   7 |\n" +
+			"+----------+------+-----+\n"},
+		// the widest column is shrunk to fit
+		{ASCIIBox, 22, nil, "" +
+			"+-------+------+-----+\n" +
+			"| Team  | Name | Qty |\n" +
+			"+-------+------+-----+\n" +
+			"| Web   | Ann  |   3 |\n" +
+			"| Web   | Bob  |  12 |\n" +
+			"| Plat~ | Cat  |   7 |\n" +
+			"+-------+------+-----+\n"},
+		// the last column is widened to fit the group subheaders
+		{Box, 0, []Option{func(t *Transmogrifier) { t.GroupBy("Team", HideGroupColumn()) }}, "" +
+			"┌──────┬─────────┐\n" +
+			"│ Name │     Qty │\n" +
+			"├──────┴─────────┤\n" +
+			"│ Team: Web      │\n" +
+			"├──────┬─────────┤\n" +
+			"│ Ann  │       3 │\n" +
+			"│ Bob  │      12 │\n" +
+			"├──────┴─────────┤\n" +
+			"│ Team: Platform │\n" +
+			"├──────┬─────────┤\n" +
+			"│ Cat  │       7 │\n" +
+			"└──────┴─────────┘\n"},
+	}
+	for i, test := range tests {
+		opts := append([]Option{func(t *Transmogrifier) {
+			t.OutputFormat = test.format
+			t.PreviewWidth = test.width
+			t.SetFieldAlignment([]string{"", "l", "auto"})
+		}}, test.opts...)
+		s, err := TableString(strings.NewReader(csvData), opts...)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if s != test.expected {
+			t.Errorf("%d: got\n%s\nwant\n%s", i, s, test.expected)
+		}
+	}
+}
+
+// TestBoxPreviewWide checks that the columns are as wide as their values
+// are displayed: CJK ideographs and emoji take two columns.
+func TestBoxPreviewWide(t *testing.T) {
+	csvData := "City,Ok\n東京,✅\nParis,❌\n"
+	expected := "" +
+		"┌───────┬────┐\n" +
+		"│ City  │ Ok │\n" +
+		"├───────┼────┤\n" +
+		"│ 東京  │ ✅ │\n" +
+		"│ Paris │ ❌ │\n" +
+		"└───────┴────┘\n"
+	s, err := TableString(strings.NewReader(csvData), func(t *Transmogrifier) { t.OutputFormat = Box })
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s != expected {
+		t.Errorf("got\n%s\nwant\n%s", s, expected)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		v        string
+		width    int
+		expected string
+	}{
+		{"abc", 3, "abc"},
+		{"abcd", 3, "ab…"},
+		{"héllo", 4, "hél…"},
+		{"abc", 0, ""},
+		{"東京都", 6, "東京都"},
+		{"東京都", 5, "東京…"},
+		{"東京都", 4, "東…"},
+		{"e\u0301tude", 4, "e\u0301tu…"},
+	}
+	for i, test := range tests {
+		v := truncate(test.v, test.width, "…")
+		if v != test.expected {
+			t.Errorf("%d: got %q want %q", i, v, test.expected)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package csv2md
+
+// SetProgress sets a function that reports the conversion's progress.  It
+// is called after every n records that are written, and once the table
+// has been written, with the number of records written and the number of
+// bytes read and written so far; an n less than 1 means after each
+// record.  The bytes read run ahead of the records, see BytesRead, and,
+// for output that is written when the table ends, e.g. Pretty, the bytes
+// written lag behind them.  The function is called synchronously, so it
+// should return quickly.  A nil function stops the reporting.
+func (t *Transmogrifier) SetProgress(n int, f func(rows, readBytes, writtenBytes int64)) {
+	if n < 1 {
+		n = 1
+	}
+	t.progress = f
+	t.progressN = int64(n)
+}
+
+// progressed counts a written record and reports the progress after every
+// progressN records.
+func (t *Transmogrifier) progressed() {
+	t.nRows++
+	if t.progress != nil && t.nRows%t.progressN == 0 {
+		t.progress(t.nRows, t.BytesRead(), t.wBytes)
+	}
+}
+
+// reportProgress reports the progress once the table has been written.
+func (t *Transmogrifier) reportProgress() {
+	if t.progress != nil {
+		t.progress(t.nRows, t.BytesRead(), t.wBytes)
+	}
+}
+
+// WithProgress sets the function that reports the conversion's progress;
+// see SetProgress.
+func WithProgress(n int, f func(rows, readBytes, writtenBytes int64)) Option {
+	return func(t *Transmogrifier) {
+		t.SetProgress(n, f)
+	}
+}
@@ -0,0 +1,88 @@
+package csv2md
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+type progress struct {
+	rows, read, written int64
+}
+
+func TestSetProgress(t *testing.T) {
+	csvData := []byte("Make,Model\nFord,Focus\nKia,Rio\nVW,Golf\n")
+	tests := []struct {
+		n        int
+		pretty   bool
+		expected []progress
+	}{
+		{0, false, []progress{{1, 38, 36}, {2, 38, 46}, {3, 38, 56}, {3, 38, 56}}},
+		{2, false, []progress{{2, 38, 46}, {3, 38, 56}}},
+		{5, false, []progress{{3, 38, 56}}},
+		{2, true, []progress{{2, 38, 0}, {3, 38, 65}}},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.Pretty = test.pretty
+		var got []progress
+		calvin.SetProgress(test.n, func(rows, read, written int64) {
+			got = append(got, progress{rows, read, written})
+		})
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%d: got %v want %v", i, got, test.expected)
+		}
+		if calvin.BytesRead() != int64(len(csvData)) {
+			t.Errorf("%d: bytes read: got %d want %d", i, calvin.BytesRead(), len(csvData))
+		}
+	}
+}
+
+func TestBytesReadCSV(t *testing.T) {
+	csvData := []byte("Make,Model\nFord,Focus\nKia,Rio\n")
+	var w bytes.Buffer
+	calvin := NewTransmogrifierCSV(csv.NewReader(bytes.NewReader(csvData)), &w)
+	if calvin.BytesRead() != 0 {
+		t.Errorf("bytes read: got %d want 0", calvin.BytesRead())
+	}
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calvin.BytesRead() != int64(len(csvData)) {
+		t.Errorf("bytes read: got %d want %d", calvin.BytesRead(), len(csvData))
+	}
+}
+
+func TestEncoderProgress(t *testing.T) {
+	var w bytes.Buffer
+	var got []progress
+	e := NewEncoder(&w, WithProgress(2, func(rows, read, written int64) {
+		got = append(got, progress{rows, read, written})
+	}))
+	err := e.WriteHeader([]string{"Make"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, v := range []string{"Ford", "Kia", "VW"} {
+		err = e.WriteRecord([]string{v})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	err = e.Flush()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []progress{{2, 0, 26}, {3, 0, int64(w.Len())}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v want %v", got, expected)
+	}
+}
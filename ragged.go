@@ -0,0 +1,91 @@
+package csv2md
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RaggedMode specifies how records that have fewer or more fields than the
+// header are handled.  The header is the CSV-encoded data's first record,
+// whether or not it is a header record.  The records are only checked if
+// the CSV reader's FieldsPerRecord is 0, its default; otherwise, the CSV
+// reader's check is used.
+type RaggedMode int
+
+const (
+	// RaggedError results in a RaggedRecordError.
+	RaggedError RaggedMode = iota
+	// RaggedPad pads records that have fewer fields than the header with
+	// empty fields.  A record with more fields is an error.
+	RaggedPad
+	// RaggedTruncate drops the fields of records that have more fields
+	// than the header and pads records that have fewer fields with empty
+	// fields.
+	RaggedTruncate
+)
+
+// ParseRaggedMode returns the RaggedMode for the value: error, pad, or
+// truncate.  An empty value is error.
+func ParseRaggedMode(s string) (RaggedMode, error) {
+	switch strings.TrimSpace(strings.ToLower(s)) {
+	case "", "error":
+		return RaggedError, nil
+	case "pad":
+		return RaggedPad, nil
+	case "truncate":
+		return RaggedTruncate, nil
+	}
+	return RaggedError, fmt.Errorf("unknown ragged mode %q", s)
+}
+
+// RaggedRecordError occurs when a record does not have the same number of
+// fields as the header and the Ragged mode does not handle it.  The Row is
+// the record's position in the CSV-encoded data, including the header
+// record.
+type RaggedRecordError struct {
+	Row          int
+	Fields       int
+	HeaderFields int
+}
+
+func (e RaggedRecordError) Error() string {
+	return fmt.Sprintf("row %d: record has %d fields, the header has %d", e.Row, e.Fields, e.HeaderFields)
+}
+
+// prepareRagged takes over the CSV reader's check of the number of fields
+// in each record, unless its FieldsPerRecord has been set.
+func (t *Transmogrifier) prepareRagged() {
+	t.nFields = 0
+	if t.CSV.FieldsPerRecord == 0 {
+		t.CSV.FieldsPerRecord = -1
+		t.checkRagged = true
+	}
+}
+
+// fit fits the record to the header's number of fields according to the
+// Ragged mode.  The first record is the header.  If blank records are
+// skipped, they are left as is.
+func (t *Transmogrifier) fit(record []string) ([]string, error) {
+	if t.nFields == 0 {
+		t.nFields = len(record)
+		return record, nil
+	}
+	return t.fitFields(record, t.nFields, t.nRead)
+}
+
+// fitFields fits the record to n fields according to the Ragged mode; the
+// row is the record's position, for errors.
+func (t *Transmogrifier) fitFields(record []string, n, row int) ([]string, error) {
+	switch {
+	case len(record) == n, t.SkipBlankRecords && blank(record):
+		return record, nil
+	case len(record) < n && t.Ragged != RaggedError:
+		for len(record) < n {
+			record = append(record, "")
+		}
+		return record, nil
+	case len(record) > n && t.Ragged == RaggedTruncate:
+		return record[:n], nil
+	}
+	return record, RaggedRecordError{Row: row, Fields: len(record), HeaderFields: n}
+}
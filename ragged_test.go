@@ -0,0 +1,94 @@
+package csv2md
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRagged(t *testing.T) {
+	tests := []struct {
+		csv      string
+		mode     RaggedMode
+		pretty   bool
+		expected string
+		err      string
+	}{
+		{"Make,Model,Year\nFord,Focus,2015\nKia,Rio\n", RaggedError, false, "", "row 3: record has 2 fields, the header has 3"},
+		{"Make,Model,Year\nFord,Focus,2015,blue\nKia,Rio,2016\n", RaggedError, false, "", "row 2: record has 4 fields, the header has 3"},
+		{"Make,Model,Year\nFord,Focus,2015\nKia,Rio\n", RaggedPad, false, "Make|Model|Year  \n---|---|---  \nFord|Focus|2015  \nKia|Rio|   \n", ""},
+		{"Make,Model,Year\nFord,Focus,2015,blue\nKia,Rio\n", RaggedPad, false, "", "row 2: record has 4 fields, the header has 3"},
+		{"Make,Model,Year\nFord,Focus,2015,blue\nKia,Rio\n", RaggedTruncate, false, "Make|Model|Year  \n---|---|---  \nFord|Focus|2015  \nKia|Rio|   \n", ""},
+		{"Make,Model,Year\nFord,Focus,2015,blue\nKia\n", RaggedTruncate, true, "Make|Model|Year  \n----|-----|----  \nFord|Focus|2015  \nKia |     |      \n", ""},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(test.csv), &w)
+		calvin.Ragged = test.mode
+		calvin.Pretty = test.pretty
+		err := calvin.MDTable()
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			var rerr RaggedRecordError
+			if !errors.As(err, &rerr) {
+				t.Errorf("%d: got %T want a RaggedRecordError", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestRaggedFieldsPerRecord(t *testing.T) {
+	// the CSV reader's FieldsPerRecord takes precedence
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader("Make,Model\nFord\n"), &w)
+	calvin.CSV.FieldsPerRecord = -1
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Make|Model  \n---|---  \nFord  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
+
+func TestParseRaggedMode(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected RaggedMode
+		err      string
+	}{
+		{"", RaggedError, ""},
+		{"Error", RaggedError, ""},
+		{"pad", RaggedPad, ""},
+		{" truncate ", RaggedTruncate, ""},
+		{"drop", RaggedError, `unknown ragged mode "drop"`},
+	}
+	for i, test := range tests {
+		mode, err := ParseRaggedMode(test.value)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if mode != test.expected {
+			t.Errorf("%d: got %d want %d", i, mode, test.expected)
+		}
+	}
+}
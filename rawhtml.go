@@ -0,0 +1,83 @@
+package csv2md
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// HTMLHandling specifies how the HTML in the values, e.g. <b> or <script>,
+// and the characters <, >, and &, are handled; see CellHTML.
+type HTMLHandling int
+
+const (
+	// DefaultHTML passes the HTML through in Markdown, where GitHub
+	// renders, or removes, it, and escapes it in HTML tables.
+	DefaultHTML HTMLHandling = iota
+	// EscapeHTML escapes <, >, and & as HTML entities, so that the HTML is
+	// shown as is.
+	EscapeHTML
+	// StripHTML removes the HTML tags, and comments, and the contents of
+	// the script and style elements; the text between the tags, and any
+	// other <, >, and &, is kept.  In HTML tables, the rest is escaped.
+	StripHTML
+	// PassHTML passes the HTML through, as raw HTML, in both Markdown and
+	// HTML tables.
+	PassHTML
+)
+
+// ParseHTMLHandling returns the HTMLHandling for the value: default or an
+// empty string, escape, strip, or pass, in any case.
+func ParseHTMLHandling(s string) (HTMLHandling, error) {
+	switch strings.TrimSpace(strings.ToLower(s)) {
+	case "", "default":
+		return DefaultHTML, nil
+	case "escape":
+		return EscapeHTML, nil
+	case "strip":
+		return StripHTML, nil
+	case "pass":
+		return PassHTML, nil
+	}
+	return DefaultHTML, fmt.Errorf("unknown html handling %q: expected default, escape, strip, or pass", s)
+}
+
+// htmlTagPattern matches the HTML that StripHTML removes.
+var htmlTagPattern = regexp.MustCompile(`(?is)<(script|style)\b[^>]*>.*?</(script|style)\s*>|<!--.*?-->|</?[a-z][^<>]*>`)
+
+// htmlEscaper escapes the characters that make up HTML in Markdown.
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// markdownHTML handles the HTML in a value that is written as Markdown
+// according to CellHTML.
+func (t *Transmogrifier) markdownHTML(v string) string {
+	switch t.CellHTML {
+	case EscapeHTML:
+		return htmlEscaper.Replace(v)
+	case StripHTML:
+		return stripHTML(v)
+	}
+	return v
+}
+
+// text returns the value as the HTML text of a cell, handling its HTML
+// according to CellHTML; line breaks within the value are kept as <br>
+// elements.
+func (h *htmlTable) text(v string) string {
+	switch h.t.CellHTML {
+	case StripHTML:
+		v = stripHTML(v)
+	case PassHTML:
+		v = strings.Replace(v, "\r\n", "\n", -1)
+		return strings.Replace(v, "\n", "<br>", -1)
+	}
+	return htmlText(v)
+}
+
+// stripHTML removes the HTML tags and comments from the value.
+func stripHTML(v string) string {
+	if !strings.Contains(v, "<") {
+		return v
+	}
+	return htmlTagPattern.ReplaceAllString(v, "")
+}
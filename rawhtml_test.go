@@ -0,0 +1,96 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseHTMLHandling(t *testing.T) {
+	tests := []struct {
+		v        string
+		expected HTMLHandling
+		err      string
+	}{
+		{"", DefaultHTML, ""},
+		{"Default", DefaultHTML, ""},
+		{"escape", EscapeHTML, ""},
+		{" strip ", StripHTML, ""},
+		{"pass", PassHTML, ""},
+		{"sanitize", DefaultHTML, `unknown html handling "sanitize": expected default, escape, strip, or pass`},
+	}
+	for i, test := range tests {
+		v, err := ParseHTMLHandling(test.v)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if v != test.expected {
+			t.Errorf("%d: got %d want %d", i, v, test.expected)
+		}
+	}
+}
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"a < b && c > d", "a < b && c > d"},
+		{"<b>bold</b> text", "bold text"},
+		{"x<script>alert('<b>')</script>y", "xy"},
+		{"<SCRIPT type=\"text/javascript\">\nalert(1)\n</Script >ok", "ok"},
+		{"<style>td {color: red}</style>plain", "plain"},
+		{"a<!-- note -->b", "ab"},
+		{`<a href="https://example.com" onclick="x()">link</a><br/>`, "link"},
+		{"1 <2", "1 <2"},
+	}
+	for i, test := range tests {
+		v := stripHTML(test.value)
+		if v != test.expected {
+			t.Errorf("%d: got %q want %q", i, v, test.expected)
+		}
+	}
+}
+
+func TestCellHTML(t *testing.T) {
+	csvData := "<i>Name</i>,Note\nAnn,<script>alert(1)</script>AT&T <b>x</b>\n"
+	tests := []struct {
+		handling HTMLHandling
+		format   Format
+		expected string
+	}{
+		{DefaultHTML, GFM, "<i>Name</i>|Note  \n---|---  \nAnn|<script>alert(1)</script>AT&T <b>x</b>  \n"},
+		{EscapeHTML, GFM, "&lt;i&gt;Name&lt;/i&gt;|Note  \n---|---  \nAnn|&lt;script&gt;alert(1)&lt;/script&gt;AT&amp;T &lt;b&gt;x&lt;/b&gt;  \n"},
+		{StripHTML, GFM, "Name|Note  \n---|---  \nAnn|AT&T x  \n"},
+		{PassHTML, GFM, "<i>Name</i>|Note  \n---|---  \nAnn|<script>alert(1)</script>AT&T <b>x</b>  \n"},
+		{DefaultHTML, HTML, "<table>\n<thead>\n<tr>\n<th>&lt;i&gt;Name&lt;/i&gt;</th>\n<th>Note</th>\n</tr>\n</thead>\n<tbody>\n" +
+			"<tr>\n<td>Ann</td>\n<td>&lt;script&gt;alert(1)&lt;/script&gt;AT&amp;T &lt;b&gt;x&lt;/b&gt;</td>\n</tr>\n</tbody>\n</table>\n"},
+		{EscapeHTML, HTML, "<table>\n<thead>\n<tr>\n<th>&lt;i&gt;Name&lt;/i&gt;</th>\n<th>Note</th>\n</tr>\n</thead>\n<tbody>\n" +
+			"<tr>\n<td>Ann</td>\n<td>&lt;script&gt;alert(1)&lt;/script&gt;AT&amp;T &lt;b&gt;x&lt;/b&gt;</td>\n</tr>\n</tbody>\n</table>\n"},
+		{StripHTML, HTML, "<table>\n<thead>\n<tr>\n<th>Name</th>\n<th>Note</th>\n</tr>\n</thead>\n<tbody>\n" +
+			"<tr>\n<td>Ann</td>\n<td>AT&amp;T x</td>\n</tr>\n</tbody>\n</table>\n"},
+		{PassHTML, HTML, "<table>\n<thead>\n<tr>\n<th><i>Name</i></th>\n<th>Note</th>\n</tr>\n</thead>\n<tbody>\n" +
+			"<tr>\n<td>Ann</td>\n<td><script>alert(1)</script>AT&T <b>x</b></td>\n</tr>\n</tbody>\n</table>\n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.CellHTML = test.handling
+		calvin.OutputFormat = test.format
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
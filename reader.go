@@ -0,0 +1,320 @@
+package csv2md
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrFieldTooLarge occurs when a field in the CSV-encoded data is larger
+// than MaxFieldBytes.  The error returned is a FieldTooLargeError, which
+// wraps ErrFieldTooLarge.
+var ErrFieldTooLarge = errors.New("field too large")
+
+// FieldTooLargeError provides the location and size of a field that is
+// larger than MaxFieldBytes.  The Row is the record's position in the
+// CSV-encoded data, including the header record, and the Column is the
+// field's position in the record; both are 1 based.  The Size is the number
+// of bytes of the field that were read before reading was stopped.
+type FieldTooLargeError struct {
+	Row    int
+	Column int
+	Size   int
+	Limit  int
+}
+
+func (e FieldTooLargeError) Error() string {
+	return fmt.Sprintf("row %d: column %d: %s: read %d bytes, the limit is %d bytes", e.Row, e.Column, ErrFieldTooLarge, e.Size, e.Limit)
+}
+
+// Unwrap returns ErrFieldTooLarge.
+func (e FieldTooLargeError) Unwrap() error {
+	return ErrFieldTooLarge
+}
+
+// input wraps the CSV-encoded data's reader.  It decodes the data to UTF-8
+// and tracks the structure of the data, records, fields, and quoting, as it
+// is read so that limits can be enforced and line endings normalized before
+// the data reaches the CSV reader.
+type input struct {
+	src io.Reader
+	r   *bufio.Reader // the decoded data; see Encoding
+	t   *Transmogrifier
+	// the separator, the CSV reader's Comma, and the bytes that end a run
+	// of an unquoted field's bytes: the first byte of a separator that is
+	// longer than a byte ends it too
+	comma  string
+	delims [256]bool
+	// state of the data that has been read
+	row        int
+	column     int
+	fieldBytes int
+	lead       int // the bytes of the field's leading space, see TrimLeadingSpace
+	skip       int // the bytes of the rune that has been scanned that are yet to be read
+	inQuotes   bool
+	quoted     bool
+	comment    bool
+	prev       byte
+	err        error
+	// the data that has been read by the CSV reader, starting at the
+	// rawOff offset, when the malformed records are skipped; see
+	// SkipMalformedRows
+	raw    []byte
+	rawOff int64
+}
+
+func newInput(r io.Reader, t *Transmogrifier) *input {
+	return &input{src: counter{r: r, t: t}, t: t, row: 1, column: 1}
+}
+
+// counter counts the bytes read from the source; see BytesRead.
+type counter struct {
+	r io.Reader
+	t *Transmogrifier
+}
+
+func (c counter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.t.rBytes += int64(n)
+	return n, err
+}
+
+func (in *input) Read(p []byte) (int, error) {
+	n, err := in.read(p)
+	if in.t.malformed != nil {
+		in.raw = append(in.raw, p[:n]...)
+	}
+	return n, err
+}
+
+// read reads the decoded data, scanning it if it has to be.  The data is
+// scanned in place, a run of a field's bytes at a time, as it is copied
+// from the decoder's buffer.
+func (in *input) read(p []byte) (int, error) {
+	if in.err != nil {
+		return 0, in.err
+	}
+	// the Encoding, and the CSV reader's settings, can be set after the
+	// Transmogrifier has been created
+	if in.r == nil {
+		in.r = decoder(in.src, in.t.Encoding)
+		in.comma = ","
+		if r := in.t.CSV.Comma; r > 0 && utf8.ValidRune(r) {
+			in.comma = string(r)
+		}
+		in.delims['\n'] = true
+		in.delims['"'] = true
+		in.delims[in.comma[0]] = true
+		in.delims['\r'] = in.t.NormalizeLineEndings
+	}
+	if in.t.MaxFieldBytes <= 0 && !in.t.NormalizeLineEndings {
+		return in.r.Read(p)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// return what has been read instead of waiting for more data
+	if in.r.Buffered() == 0 {
+		_, err := in.r.Peek(1)
+		if err != nil {
+			in.err = err
+			return 0, err
+		}
+	}
+	b, _ := in.r.Peek(in.r.Buffered())
+	n := copy(p, b)
+	n, err := in.scan(p[:n])
+	in.r.Discard(n)
+	if err != nil {
+		in.err = err
+	}
+	return n, err
+}
+
+// scan updates the state with the data that has been read, normalizing its
+// line endings, and returns how much of it was scanned.  Less than all of
+// the data is scanned if what follows its end is needed, e.g. to tell a
+// lone carriage return from a CRLF; the rest is scanned by the next read.
+// If a field exceeds MaxFieldBytes, a FieldTooLargeError is returned.
+func (in *input) scan(p []byte) (int, error) {
+	for i := 0; i < len(p); {
+		// the rest of a rune that has been scanned
+		if in.skip > 0 {
+			n := len(p) - i
+			if n > in.skip {
+				n = in.skip
+			}
+			in.skip -= n
+			i += n
+			in.prev = p[i-1]
+			continue
+		}
+		if in.comment {
+			// a comment is skipped up to its line ending
+			j := bytes.IndexByte(p[i:], '\n')
+			if in.t.NormalizeLineEndings {
+				if k := bytes.IndexByte(p[i:], '\r'); k >= 0 && (j < 0 || k < j) {
+					j = k
+				}
+			}
+			if j < 0 {
+				in.prev = p[len(p)-1]
+				return len(p), nil
+			}
+			if j > 0 {
+				in.prev = p[i+j-1]
+				i += j
+			}
+		} else if in.inQuotes || (in.fieldBytes > in.lead && !(in.quoted && in.prev == '"')) {
+			// the bytes within a field, other than its leading space and
+			// the quotes that start or escape a quote, are only counted
+			var j int
+			if in.inQuotes {
+				j = bytes.IndexByte(p[i:], '"')
+			} else {
+				j = in.indexDelim(p[i:])
+			}
+			if j < 0 {
+				j = len(p) - i
+			}
+			if j > 0 {
+				size := in.fieldBytes
+				err := in.count(j)
+				if err != nil {
+					// the bytes that are within the limit are read
+					return i + in.t.MaxFieldBytes - size, err
+				}
+				in.prev = p[i+j-1]
+				i += j
+				continue
+			}
+		}
+		// a separator, a comment character, or a space that is longer
+		// than a byte is decoded from what follows too
+		size := 1
+		r := rune(p[i])
+		if r >= utf8.RuneSelf && !in.inQuotes {
+			if !utf8.FullRune(p[i:]) {
+				b, ok := in.peek(p, i, utf8.UTFMax)
+				if !ok {
+					return i, nil
+				}
+				r, size = utf8.DecodeRune(b)
+			} else {
+				r, size = utf8.DecodeRune(p[i:])
+			}
+		}
+		// a lone carriage return, outside of a quoted field, ends the
+		// record
+		if r == '\r' && in.t.NormalizeLineEndings && !in.inQuotes {
+			b, ok := in.peek(p, i, 2)
+			if !ok {
+				return i, nil
+			}
+			if len(b) < 2 || b[1] != '\n' {
+				p[i] = '\n'
+				r = '\n'
+			}
+		}
+		err := in.scanRune(r, size, string(r) == in.comma)
+		if err != nil {
+			return i, err
+		}
+		// a rune that was peeked can be longer than the rest of p
+		if i+size > len(p) {
+			in.skip = i + size - len(p)
+			in.prev = p[len(p)-1]
+			return len(p), nil
+		}
+		in.prev = p[i+size-1]
+		i += size
+	}
+	return len(p), nil
+}
+
+// indexDelim returns the index of the first byte in p that ends a run of an
+// unquoted field's bytes, or -1 if there is none.
+func (in *input) indexDelim(p []byte) int {
+	for i, c := range p {
+		if in.delims[c] {
+			return i
+		}
+	}
+	return -1
+}
+
+// peek returns the n bytes of the data starting at p[i], or fewer at the
+// end of the data.  If they aren't all in p, they are peeked from the
+// decoder, but only if p[i] is the first byte that is being read, i.e. i is
+// 0; otherwise false is returned.
+func (in *input) peek(p []byte, i, n int) ([]byte, bool) {
+	if i+n <= len(p) {
+		return p[i : i+n], true
+	}
+	if i > 0 {
+		return nil, false
+	}
+	b, _ := in.r.Peek(n)
+	return b, true
+}
+
+// scanRune updates the state with the rune, which is size bytes of the
+// data; the comma is whether it is the separator.
+func (in *input) scanRune(r rune, size int, comma bool) error {
+	prev := in.prev
+	if in.comment {
+		if r == '\n' {
+			in.comment = false
+		}
+		return nil
+	}
+	if in.inQuotes {
+		if r == '"' {
+			in.inQuotes = false
+			return nil
+		}
+		return in.count(size)
+	}
+	switch {
+	case r == '\n':
+		in.row++
+		in.column = 1
+		in.fieldBytes = 0
+		in.lead = 0
+		in.quoted = false
+		return nil
+	case comma:
+		in.column++
+		in.fieldBytes = 0
+		in.lead = 0
+		in.quoted = false
+		return nil
+	case r == '"' && (in.fieldBytes == in.lead || (in.quoted && prev == '"')):
+		// either the start of a quoted field, which can follow the
+		// leading space that is trimmed, or an escaped quote
+		in.inQuotes = true
+		in.quoted = true
+	case in.column == 1 && in.fieldBytes == 0 && in.t.CSV.Comment > 0 && r == in.t.CSV.Comment:
+		in.comment = true
+		return nil
+	case in.t.CSV.TrimLeadingSpace && !in.quoted && in.fieldBytes == in.lead && unicode.IsSpace(r):
+		in.lead += size
+	}
+	return in.count(size)
+}
+
+// count adds the n bytes to the field's size.  If the field exceeds
+// MaxFieldBytes, a FieldTooLargeError is returned; its Size is the size of
+// the field up to and including the byte that exceeded the limit.
+func (in *input) count(n int) error {
+	in.fieldBytes += n
+	if max := in.t.MaxFieldBytes; max > 0 && in.fieldBytes > max {
+		in.fieldBytes = max + 1
+		return FieldTooLargeError{Row: in.row, Column: in.column, Size: in.fieldBytes, Limit: max}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package csv2md
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestMaxFieldBytes(t *testing.T) {
+	tests := []struct {
+		data     string
+		comment  rune
+		expected string
+		err      string
+	}{
+		{"a,b\n1234,12345678\n", 0, "a|b  \n---|---  \n1234|12345678  \n", ""},
+		{"a,b\n1234,123456789\n", 0, "", "row 2: column 2: field too large: read 9 bytes, the limit is 8 bytes"},
+		// the quotes count toward the size
+		{"a,b\n\"12,34\",\"1\"\"2\"\n", 0, "a|b  \n---|---  \n12,34|1\"2  \n", ""},
+		{"a,b\n\"1\n2\n3\",x\n\"123456789\"\n", 0, "", "row 3: column 1: field too large: read 9 bytes, the limit is 8 bytes"},
+		{"a,b\n# a comment that is longer than the limit\n1,2\n", '#', "a|b  \n---|---  \n1|2  \n", ""},
+		{"a,b\r# a comment that is longer than the limit\r1,2\r", '#', "a|b  \n---|---  \n1|2  \n", ""},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(test.data), &w)
+		calvin.MaxFieldBytes = 8
+		calvin.CSV.Comment = test.comment
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+// an unterminated quote would otherwise result in the rest of the data
+// being read into a single field.
+func TestMaxFieldBytesUnterminatedQuote(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString("a,b\n1,\"unterminated\n")
+	for i := 0; i < 1<<16; i++ {
+		b.WriteString("2,some more data\n")
+	}
+	r := &countingReader{r: &b}
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(r, &w)
+	calvin.MaxFieldBytes = 1 << 10
+	err := calvin.MDTable()
+	if !errors.Is(err, ErrFieldTooLarge) {
+		t.Fatalf("got %v, want ErrFieldTooLarge", err)
+	}
+	var ferr FieldTooLargeError
+	if !errors.As(err, &ferr) {
+		t.Fatalf("got %T, want FieldTooLargeError", err)
+	}
+	if ferr.Row != 2 || ferr.Column != 2 || ferr.Size != 1<<10+1 {
+		t.Errorf("got row %d column %d size %d, want row 2 column 2 size %d", ferr.Row, ferr.Column, ferr.Size, 1<<10+1)
+	}
+	// reading was stopped long before the end of the data
+	if r.n > 1<<14 {
+		t.Errorf("read %d bytes, expected reading to stop shortly after the limit", r.n)
+	}
+}
+
+func TestNormalizeLineEndings(t *testing.T) {
+	tests := []struct {
+		data      string
+		normalize bool
+		records   int
+		expected  string
+	}{
+		{"a,b\r1,2\r3,4\r", true, 3, "a|b  \n---|---  \n1|2  \n3|4  \n"},
+		{"a,b\r1,2\r3,4", true, 3, "a|b  \n---|---  \n1|2  \n3|4  \n"},
+		{"a,b\r\n1,2\r3,4\n5,6\r", true, 4, "a|b  \n---|---  \n1|2  \n3|4  \n5|6  \n"},
+		{"a,b\r\r1,2\n", true, 2, "a|b  \n---|---  \n1|2  \n"},
+		{"a,b\n\"1\r2\",3\r4,5\n", true, 3, "a|b  \n---|---  \n1<br>2|3  \n4|5  \n"},
+		{"a,b\r1,2\r3,4\r", false, 1, ""},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(iotest.OneByteReader(strings.NewReader(test.data)), ioutil.Discard)
+		calvin.NormalizeLineEndings = test.normalize
+		records, err := calvin.CSV.ReadAll()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if len(records) != test.records {
+			t.Errorf("%d: got %d records want %d", i, len(records), test.records)
+		}
+		if test.expected == "" {
+			continue
+		}
+		var w bytes.Buffer
+		calvin = NewTransmogrifier(strings.NewReader(test.data), &w)
+		err = calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+type countingReader struct {
+	r *bytes.Buffer
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+// TestReaderQuoteAfterLeadingSpace checks that a quoted field that follows
+// the leading space that is trimmed, see TrimLeadingSpace, is scanned as a
+// quoted field: its carriage return isn't a line ending and its separator
+// doesn't start a new field.
+func TestReaderQuoteAfterLeadingSpace(t *testing.T) {
+	tests := []struct {
+		data     string
+		max      int
+		expected [][]string
+		err      string
+	}{
+		{"a,b\r1, \"x\ry\"\r", 0, [][]string{{"a", "b"}, {"1", "x\ry"}}, ""},
+		{"a,b\n1,\t \"x\r\ny\"\n", 0, [][]string{{"a", "b"}, {"1", "x\ny"}}, ""},
+		{"a,b\n1,　\"x\ry\"\n", 0, [][]string{{"a", "b"}, {"1", "x\ry"}}, ""},
+		{"a,b\n1,  \"1,2,3\",4\n", 8, [][]string{{"a", "b"}, {"1", "1,2,3", "4"}}, ""},
+		{"a,b\n1,  \"1,2,3,4,5\"\n", 8, nil, "row 2: column 2: field too large: read 9 bytes, the limit is 8 bytes"},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(iotest.OneByteReader(strings.NewReader(test.data)), ioutil.Discard)
+		calvin.CSV.TrimLeadingSpace = true
+		calvin.CSV.FieldsPerRecord = -1
+		calvin.MaxFieldBytes = test.max
+		records, err := calvin.CSV.ReadAll()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if !reflect.DeepEqual(records, test.expected) {
+			t.Errorf("%d: got %q want %q", i, records, test.expected)
+		}
+	}
+}
+
+// TestReaderSeparatorRune checks that a separator that is longer than a
+// byte separates the fields that MaxFieldBytes limits, even when it is
+// split between reads.
+func TestReaderSeparatorRune(t *testing.T) {
+	tests := []struct {
+		comma    rune
+		data     string
+		expected [][]string
+		err      string
+	}{
+		{'；', "a；b\n1234；12345678\n", [][]string{{"a", "b"}, {"1234", "12345678"}}, ""},
+		{'；', "a；b\n1234；123456789\n", nil, "row 2: column 2: field too large: read 9 bytes, the limit is 8 bytes"},
+		{'；', "a；b\r\"1；2\"；4\r", [][]string{{"a", "b"}, {"1；2", "4"}}, ""},
+		{'¦', "a¦b\n\"x\ry\"¦12345678\r", [][]string{{"a", "b"}, {"x\ry", "12345678"}}, ""},
+		{'😀', "a😀b\n1😀2\n", [][]string{{"a", "b"}, {"1", "2"}}, ""},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(iotest.OneByteReader(strings.NewReader(test.data)), ioutil.Discard)
+		calvin.CSV.Comma = test.comma
+		calvin.MaxFieldBytes = 8
+		records, err := calvin.CSV.ReadAll()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if !reflect.DeepEqual(records, test.expected) {
+			t.Errorf("%d: got %q want %q", i, records, test.expected)
+		}
+	}
+}
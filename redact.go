@@ -0,0 +1,134 @@
+package csv2md
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// redactionMask is the character that a redacted value's characters are
+// replaced with; unlike *, it isn't Markdown markup.
+const redactionMask = "•"
+
+// DefaultHashLength is the number of hexadecimal digits of a hashed value
+// when the hash redaction doesn't specify it.
+const DefaultHashLength = 12
+
+// Redaction is how a column's values are redacted; see SetColumnRedaction.
+// The zero value masks all of the value's characters.
+type Redaction struct {
+	// Keep is the number of the value's last characters that are not
+	// masked, e.g. 4 for the last 4 digits of a card number.  A value that
+	// doesn't have more than Keep characters is masked entirely.
+	Keep int
+	// Token replaces each value, e.g. [REDACTED]; if it is empty, the
+	// value's characters are masked.
+	Token string
+	// Hash is the number of hexadecimal digits, up to 64, of the value's
+	// SHA-256 hash that replace the value; the same values have the same
+	// hash, so the rows can still be told apart, or joined, without
+	// showing the values.  It is used instead of the Token.
+	Hash int
+	// Salt is prepended to the values before they are hashed, so that the
+	// hashes of known values can't be looked up.
+	Salt string
+}
+
+// ParseRedaction parses a redaction: mask, which masks the entire value,
+// last:n, which keeps the last n characters, e.g. last:4, token:text,
+// which replaces the value with the text, e.g. token:[REDACTED], or hash
+// or hash:n, which replaces the value with the first n digits of its hash,
+// DefaultHashLength by default.  The Salt of a hash redaction is set
+// separately.
+func ParseRedaction(s string) (Redaction, error) {
+	v := strings.TrimSpace(s)
+	switch {
+	case strings.EqualFold(v, "mask"):
+		return Redaction{}, nil
+	case strings.EqualFold(v, "hash"):
+		return Redaction{Hash: DefaultHashLength}, nil
+	case strings.HasPrefix(strings.ToLower(v), "hash:"):
+		n, err := strconv.Atoi(strings.TrimSpace(v[len("hash:"):]))
+		if err == nil && n > 0 && n <= 2*sha256.Size {
+			return Redaction{Hash: n}, nil
+		}
+	case strings.HasPrefix(strings.ToLower(v), "last:"):
+		n, err := strconv.Atoi(strings.TrimSpace(v[len("last:"):]))
+		if err == nil && n > 0 {
+			return Redaction{Keep: n}, nil
+		}
+	case strings.HasPrefix(strings.ToLower(v), "token:") && len(v) > len("token:"):
+		return Redaction{Token: v[len("token:"):]}, nil
+	}
+	return Redaction{}, fmt.Errorf("redaction %q: expected mask, last:n, token:text, or hash[:n]", s)
+}
+
+// redact returns the redacted value; empty values are left as is.
+func (r Redaction) redact(v string) string {
+	if v == "" {
+		return v
+	}
+	if r.Hash > 0 {
+		sum := sha256.Sum256([]byte(r.Salt + v))
+		h := hex.EncodeToString(sum[:])
+		if r.Hash < len(h) {
+			h = h[:r.Hash]
+		}
+		return h
+	}
+	if r.Token != "" {
+		return r.Token
+	}
+	chars := []rune(v)
+	keep := r.Keep
+	if keep >= len(chars) {
+		keep = 0
+	}
+	return strings.Repeat(redactionMask, len(chars)-keep) + string(chars[len(chars)-keep:])
+}
+
+type redaction struct {
+	column string
+	index  int
+	r      Redaction
+}
+
+// SetColumnRedaction redacts the named column's values, e.g. so that a
+// table of production data can be published.  The values are redacted
+// before anything else is done with them, so that every output format and
+// everything that is written, e.g. the group subheaders, split headings,
+// and footer row, has the redacted values; the filter, see SetFilter, and
+// the row selection, e.g. Dedup, use the values as they were read.  The
+// column's header is not redacted.  Setting a redaction for a column that
+// already has one replaces it.
+func (t *Transmogrifier) SetColumnRedaction(column string, r Redaction) {
+	for _, v := range t.redactions {
+		if v.column == column {
+			v.r = r
+			return
+		}
+	}
+	t.redactions = append(t.redactions, &redaction{column: column, r: r})
+}
+
+// prepareRedactions resolves the redactions' columns against the header.
+func (t *Transmogrifier) prepareRedactions(header []string) error {
+	for _, r := range t.redactions {
+		r.index = columnIndex(header, r.column)
+		if r.index < 0 {
+			return UnknownColumnError{Name: r.column, operation: "redaction"}
+		}
+	}
+	return nil
+}
+
+// redact redacts the record's values.
+func (t *Transmogrifier) redact(record []string) {
+	for _, r := range t.redactions {
+		if r.index < len(record) {
+			record[r.index] = r.r.redact(record[r.index])
+		}
+	}
+}
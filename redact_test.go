@@ -0,0 +1,123 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseRedaction(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Redaction
+		err      string
+	}{
+		{"mask", Redaction{}, ""},
+		{" Last:4", Redaction{Keep: 4}, ""},
+		{"token:[REDACTED]", Redaction{Token: "[REDACTED]"}, ""},
+		{"hash", Redaction{Hash: DefaultHashLength}, ""},
+		{"hash:64", Redaction{Hash: 64}, ""},
+		{"hash:65", Redaction{}, `redaction "hash:65": expected mask, last:n, token:text, or hash[:n]`},
+		{"last:0", Redaction{}, `redaction "last:0": expected mask, last:n, token:text, or hash[:n]`},
+		{"last:x", Redaction{}, `redaction "last:x": expected mask, last:n, token:text, or hash[:n]`},
+		{"token:", Redaction{}, `redaction "token:": expected mask, last:n, token:text, or hash[:n]`},
+		{"", Redaction{}, `redaction "": expected mask, last:n, token:text, or hash[:n]`},
+	}
+	for i, test := range tests {
+		r, err := ParseRedaction(test.value)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if r != test.expected {
+			t.Errorf("%d: got %+v want %+v", i, r, test.expected)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		r        Redaction
+		value    string
+		expected string
+	}{
+		{Redaction{}, "secret", "••••••"},
+		{Redaction{}, "", ""},
+		{Redaction{Keep: 4}, "4111-1111-1111-1234", "•••••••••••••••1234"},
+		{Redaction{Keep: 4}, "1234", "••••"},
+		{Redaction{Keep: 2}, "Zoë", "•oë"},
+		{Redaction{Token: "[REDACTED]"}, "ann@example.com", "[REDACTED]"},
+		{Redaction{Hash: 12}, "ann@example.com", "71d4f55f72fa"},
+		{Redaction{Hash: 8, Salt: "pepper"}, "ann@example.com", "83174071"},
+		{Redaction{Hash: 8}, "", ""},
+	}
+	for i, test := range tests {
+		v := test.r.redact(test.value)
+		if v != test.expected {
+			t.Errorf("%d: got %q want %q", i, v, test.expected)
+		}
+	}
+}
+
+func TestSetColumnRedaction(t *testing.T) {
+	csvData := "Team,Email,Card\nWeb,ann@example.com,4111111111111234\nWeb,bob@example.com,\n"
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{GFM, "Team|Email|Card  \n---|---|---  \n**Team: ••b**| |   \n••b|[REDACTED]|••••••••••••1234  \n••b|[REDACTED]|   \n"},
+		{HTML, "<table>\n<thead>\n<tr>\n<th>Team</th>\n<th>Email</th>\n<th>Card</th>\n</tr>\n</thead>\n<tbody>\n" +
+			"<tr>\n<td colspan=\"3\"><strong>Team: ••b</strong></td>\n</tr>\n" +
+			"<tr>\n<td>••b</td>\n<td>[REDACTED]</td>\n<td>••••••••••••1234</td>\n</tr>\n" +
+			"<tr>\n<td>••b</td>\n<td>[REDACTED]</td>\n<td></td>\n</tr>\n</tbody>\n</table>\n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.OutputFormat = test.format
+		calvin.GroupBy("Team")
+		calvin.SetColumnRedaction("Email", Redaction{Token: "[REDACTED]"})
+		calvin.SetColumnRedaction("Card", Redaction{Keep: 4})
+		calvin.SetColumnRedaction("Team", Redaction{})
+		calvin.SetColumnRedaction("Team", Redaction{Keep: 1})
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+
+	// hashed values can still be grouped
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+	calvin.SetColumnRedaction("Team", Redaction{Hash: 6})
+	err := calvin.SetColumnAggregate("Email", "count")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	calvin.GroupBy("Team")
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Team|Email|Card  \n---|---|---  \n**Team: 297510**| |   \n297510|ann@example.com|4111111111111234  \n297510|bob@example.com|   \n |__2__|   \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+
+	calvin = NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+	calvin.SetColumnRedaction("SSN", Redaction{})
+	err = calvin.MDTable()
+	if err == nil || err.Error() != `redaction: unknown column "SSN"` {
+		t.Errorf("got error %v want an unknown column error", err)
+	}
+}
@@ -0,0 +1,45 @@
+package csv2md
+
+// SetFieldNameMap renames fields in the table header: a field whose name
+// is a key in the map is shown with the key's value as its name.  Only the
+// names that are written are renamed, i.e. the header, group subheaders,
+// and split headings; the settings that refer to columns, e.g. GroupBy or
+// SetFilter, use the names from the CSV-encoded data, or the field names,
+// and computed columns.  Every key must be the name of a field; otherwise
+// MDTable returns an UnknownColumnError.  When the table is transposed, the
+// field names in the first column are renamed.
+//
+// Unlike SetFieldNames, only the fields that are being renamed need to be
+// in the map.  Setting a map replaces the previous one.
+func (t *Transmogrifier) SetFieldNameMap(m map[string]string) {
+	t.fieldNameMap = make(map[string]string, len(m))
+	for k, v := range m {
+		t.fieldNameMap[k] = v
+	}
+}
+
+// renameFields returns a copy of the header with the fields renamed
+// according to the field name map.
+func (t *Transmogrifier) renameFields(header []string) ([]string, error) {
+	if len(t.fieldNameMap) == 0 {
+		return header, nil
+	}
+	for name := range t.fieldNameMap {
+		if columnIndex(header, name) < 0 {
+			return nil, UnknownColumnError{Name: name, operation: "rename"}
+		}
+	}
+	names := make([]string, len(header))
+	for i, name := range header {
+		names[i] = t.fieldName(name)
+	}
+	return names, nil
+}
+
+// fieldName returns the name that the field is shown with.
+func (t *Transmogrifier) fieldName(name string) string {
+	if v, ok := t.fieldNameMap[name]; ok {
+		return v
+	}
+	return name
+}
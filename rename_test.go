@@ -0,0 +1,56 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetFieldNameMap(t *testing.T) {
+	csvData := []byte("Year,Manufacturer,Model\n1997,Ford,E350\n2000,Mercury,Cougar\n")
+	tests := []struct {
+		names     map[string]string
+		groupBy   string
+		where     string
+		transpose bool
+		expected  string
+		err       string
+	}{
+		{nil, "", "", false, "Year|Manufacturer|Model  \n---|---|---  \n1997|Ford|E350  \n2000|Mercury|Cougar  \n", ""},
+		{map[string]string{"Manufacturer": "Make", "Year": "Yr"}, "", "", false, "Yr|Make|Model  \n---|---|---  \n1997|Ford|E350  \n2000|Mercury|Cougar  \n", ""},
+		// the other settings use the original names
+		{map[string]string{"Manufacturer": "Make"}, "", `Manufacturer == "Ford"`, false, "Year|Make|Model  \n---|---|---  \n1997|Ford|E350  \n", ""},
+		{map[string]string{"Manufacturer": "Make"}, "Manufacturer", "", false, "Year|Make|Model  \n---|---|---  \n**Make: Ford**| |   \n1997|Ford|E350  \n**Make: Mercury**| |   \n2000|Mercury|Cougar  \n", ""},
+		{map[string]string{"Manufacturer": "Make"}, "", "", true, "Year|1997|2000  \n---|---|---  \nMake|Ford|Mercury  \nModel|E350|Cougar  \n", ""},
+		{map[string]string{"Make": "Manufacturer"}, "", "", false, "", `rename: unknown column "Make"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.SetFieldNameMap(test.names)
+		if test.groupBy != "" {
+			calvin.GroupBy(test.groupBy)
+		}
+		if test.where != "" {
+			err := calvin.SetFilter(test.where)
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+				continue
+			}
+		}
+		calvin.Transpose = test.transpose
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
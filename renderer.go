@@ -0,0 +1,272 @@
+package csv2md
+
+// Alignment is the alignment of a field's values.
+type Alignment int
+
+const (
+	// AlignNone is a field without alignment.
+	AlignNone Alignment = iota
+	// AlignLeft is a left justified field.
+	AlignLeft
+	// AlignCenter is a centered field.
+	AlignCenter
+	// AlignRight is a right justified field.
+	AlignRight
+)
+
+// Style is a text style.
+type Style int
+
+const (
+	// StyleBold is bold text.
+	StyleBold Style = iota
+	// StyleItalic is italic text.
+	StyleItalic
+	// StyleStrikethrough is struck through text.
+	StyleStrikethrough
+)
+
+// Cell is a record's field, as it is to be rendered.  The Styles are in
+// the order that they are to be applied; the first is the innermost.
+type Cell struct {
+	Value  string
+	Align  Alignment
+	Styles []Style
+	// Header is whether the cell is its record's row header, see
+	// RowHeader; a GFM table writes it in bold and an HTML table as a th.
+	Header bool
+	// RowClasses are the classes of the cell's record, from its row style
+	// column; see SetRowStyleColumn.
+	RowClasses []string
+}
+
+// Renderer writes a table in an output format.  A Renderer can be set,
+// using SetRenderer, to write the table in a format that isn't supported
+// by the Transmogrifier; when no Renderer is set the table is written in
+// the OutputFormat.  The Transmogrifier does all of the processing of the
+// records, e.g. value maps, computed columns, and grouping, and calls the
+// Renderer with the results.  Hidden fields are not passed to the
+// Renderer.
+//
+// If the table has a header, WriteHeader, or a HeaderRenderer's
+// WriteHeaderCells, is called first, followed by WriteSeparator.  WriteRow
+// is called for each record, in order, and Close is called once all of the
+// records have been written.  If the table has a footer row, it is written
+// after the records, see FooterRenderer.
+type Renderer interface {
+	// WriteHeader writes the header record's field names.
+	WriteHeader(names []string) error
+	// WriteSeparator writes the separator between the header record and
+	// the records; alignment is each field's alignment.
+	WriteSeparator(alignment []Alignment) error
+	// WriteRow writes a record.
+	WriteRow(cells []Cell) error
+	// Close writes anything that has not been written yet.
+	Close() error
+}
+
+// HeaderRenderer is a Renderer that writes the header's styles and
+// alignments, see SetHeaderStyle and SetHeaderAlignment.  If a Renderer
+// implements HeaderRenderer, WriteHeaderCells is called instead of
+// WriteHeader.
+type HeaderRenderer interface {
+	Renderer
+	// WriteHeaderCells writes the header record's cells; their values are
+	// the field names.
+	WriteHeaderCells(cells []Cell) error
+}
+
+// GroupRenderer is a Renderer that writes group subheaders itself.  If a
+// Renderer does not implement GroupRenderer, a group's subheader is
+// written using WriteRow, as a row whose first cell is the bold
+// "column: value".
+type GroupRenderer interface {
+	Renderer
+	// WriteGroup writes the subheader of the group whose column's value
+	// is value.
+	WriteGroup(column, value string) error
+}
+
+// FooterRenderer is a Renderer that writes the footer row itself, see
+// SetColumnAggregate.  If a Renderer does not implement FooterRenderer,
+// the footer row is written using WriteRow, with bold cells.
+type FooterRenderer interface {
+	Renderer
+	// WriteFooter writes the footer row's cells.
+	WriteFooter(cells []Cell) error
+}
+
+// SetRenderer sets the Renderer that writes the table; the OutputFormat
+// is ignored.  If r is nil, the table is written in the OutputFormat.
+func (t *Transmogrifier) SetRenderer(r Renderer) {
+	t.customRenderer = r
+}
+
+var alignments = map[string]Alignment{
+	left:     AlignLeft,
+	centered: AlignCenter,
+	right:    AlignRight,
+}
+
+var styles = map[string]Style{
+	bold:          StyleBold,
+	italic:        StyleItalic,
+	strikethrough: StyleStrikethrough,
+}
+
+var markdownAlignments = map[Alignment]string{
+	AlignNone:   none,
+	AlignLeft:   left,
+	AlignCenter: centered,
+	AlignRight:  right,
+}
+
+var markups = map[Style]string{
+	StyleBold:          bold,
+	StyleItalic:        italic,
+	StyleStrikethrough: strikethrough,
+}
+
+// markup wraps the field in the styles' Markdown; the first style is the
+// innermost.
+func markup(field string, styles []Style) string {
+	for _, s := range styles {
+		field = markups[s] + field + markups[s]
+	}
+	return field
+}
+
+// nextField returns the index of the first field after the i'th that
+// isn't hidden.  A Renderer's cells are the fields that aren't hidden, so
+// this is how the built-in renderers find the field of each of the cells,
+// starting from -1.
+func (t *Transmogrifier) nextField(i int) int {
+	i++
+	for t.hidden[i] {
+		i++
+	}
+	return i
+}
+
+// nameCells returns the cells of the header record's field names, without
+// any styles or alignments.
+func nameCells(names []string) []Cell {
+	cells := make([]Cell, len(names))
+	for i, name := range names {
+		cells[i] = Cell{Value: name}
+	}
+	return cells
+}
+
+// cellValues returns the cells' values.
+func cellValues(cells []Cell) []string {
+	v := make([]string, len(cells))
+	for i, c := range cells {
+		v[i] = c.Value
+	}
+	return v
+}
+
+// writeHeader writes the header record, followed by the separator, using
+// the Renderer.
+func (t *Transmogrifier) writeHeader(r Renderer, fields []string) error {
+	err := t.checkHeader(fields)
+	if err != nil {
+		return err
+	}
+	// fields without an alignment or width entry are not justified or
+	// padded and any extra entries are ignored
+	if len(t.fieldAlignment) > 0 && len(t.fieldAlignment) != len(fields) {
+		t.warnf("field alignment has %d entries, header has %d fields", len(t.fieldAlignment), len(fields))
+	}
+	if len(t.fieldWidths) > 0 && len(t.fieldWidths) != len(fields) {
+		t.warnf("field width has %d entries, header has %d fields", len(t.fieldWidths), len(fields))
+	}
+	cells := make([]Cell, 0, len(fields))
+	align := make([]Alignment, 0, len(fields))
+	for i, f := range fields {
+		if t.hidden[i] {
+			continue
+		}
+		cell := Cell{Value: f, Align: alignments[t.headerAlignment(i)]}
+		if s := headerSetting(t.headerStyle, i); s != "" && f != "" {
+			cell.Styles = []Style{styles[s]}
+		}
+		cells = append(cells, cell)
+		align = append(align, alignments[t.alignment(i)])
+	}
+	err = writeHeaderCells(r, cells)
+	if err != nil {
+		return err
+	}
+	return r.WriteSeparator(align)
+}
+
+// writeHeaderCells writes the header record's cells using the Renderer; a
+// Renderer that isn't a HeaderRenderer is only given their values.
+func writeHeaderCells(r Renderer, cells []Cell) error {
+	if h, ok := r.(HeaderRenderer); ok {
+		return h.WriteHeaderCells(cells)
+	}
+	return r.WriteHeader(cellValues(cells))
+}
+
+// writeGroup writes the subheader of the group whose column's value is
+// value using the Renderer.  For a Renderer that isn't a GroupRenderer,
+// the subheader is a row with a cell for each of the prepared header's
+// visible fields.
+func (t *Transmogrifier) writeGroup(r Renderer, column, value string) error {
+	if g, ok := r.(GroupRenderer); ok {
+		return g.WriteGroup(column, value)
+	}
+	var n int
+	for i := 0; i < t.group.fields; i++ {
+		if !t.hidden[i] {
+			n++
+		}
+	}
+	if n == 0 {
+		n = 1
+	}
+	cells := make([]Cell, n)
+	cells[0] = Cell{Value: column + ": " + value, Styles: []Style{StyleBold}}
+	return r.WriteRow(cells)
+}
+
+// writeRecord writes the record's fields using the Renderer; the raw
+// fields are the record's fields as they were read, which is what the
+// style rules are evaluated against.
+func (t *Transmogrifier) writeRecord(r Renderer, fields, raw []string) error {
+	err := t.checkStyles(fields)
+	if err != nil {
+		return err
+	}
+	t.raw = raw
+	classes := t.rowClasses(raw)
+	cells := make([]Cell, 0, len(fields))
+	for i, f := range fields {
+		if t.hidden[i] {
+			continue
+		}
+		cell := Cell{Value: f, Align: alignments[t.alignment(i)], Header: t.isRowHeader(i), RowClasses: classes}
+		if i < len(t.fieldStyle) && t.fieldStyle[i] != "" {
+			cell.Styles = append(cell.Styles, styles[t.fieldStyle[i]])
+		}
+		if t.styled() {
+			for _, s := range t.ruleStyles(i, raw) {
+				cell.Styles = append(cell.Styles, styles[s])
+			}
+		}
+		cells = append(cells, cell)
+	}
+	return r.WriteRow(cells)
+}
+
+// writeFooterCells writes the footer row's cells using the Renderer; a
+// Renderer that isn't a FooterRenderer writes them as a row.
+func writeFooterCells(r Renderer, cells []Cell) error {
+	if f, ok := r.(FooterRenderer); ok {
+		return f.WriteFooter(cells)
+	}
+	return r.WriteRow(cells)
+}
@@ -0,0 +1,174 @@
+package csv2md
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recorder is a Renderer that records the calls made to it.
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) WriteHeader(names []string) error {
+	r.calls = append(r.calls, fmt.Sprintf("header %q", names))
+	return nil
+}
+
+func (r *recorder) WriteSeparator(alignment []Alignment) error {
+	r.calls = append(r.calls, fmt.Sprintf("separator %v", alignment))
+	return nil
+}
+
+func (r *recorder) WriteRow(cells []Cell) error {
+	r.calls = append(r.calls, fmt.Sprintf("row %v", cells))
+	return nil
+}
+
+func (r *recorder) Close() error {
+	r.calls = append(r.calls, "close")
+	return nil
+}
+
+type groupRecorder struct {
+	recorder
+}
+
+func (r *groupRecorder) WriteGroup(column, value string) error {
+	r.calls = append(r.calls, "group "+column+"="+value)
+	return nil
+}
+
+type headerRecorder struct {
+	recorder
+}
+
+func (r *headerRecorder) WriteHeaderCells(cells []Cell) error {
+	r.calls = append(r.calls, fmt.Sprintf("header cells %v", cells))
+	return nil
+}
+
+func TestSetRenderer(t *testing.T) {
+	csvData := []byte("Team,Name,Amount\nWeb,Ann,-1\nWeb,Bob,2\n")
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+	r := &recorder{}
+	calvin.SetRenderer(r)
+	calvin.SetFieldAlignment([]string{"l", "c", "r"})
+	calvin.SetFieldStyle([]string{"", "i", ""})
+	calvin.GroupBy("Team", HideGroupColumn())
+	err := calvin.ParseStyleIf("Amount<0=s")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{
+		`header ["Name" "Amount"]`,
+		"separator [2 3]",
+		"row [{Team: Web 0 [0] false []} { 0 [] false []}]",
+		"row [{Ann 2 [1] false []} {-1 3 [2] false []}]",
+		"row [{Bob 2 [1] false []} {2 3 [] false []}]",
+		"close",
+	}
+	if !reflect.DeepEqual(r.calls, expected) {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(r.calls, "\n"), strings.Join(expected, "\n"))
+	}
+	if w.Len() != 0 {
+		t.Errorf("expected nothing to be written to the writer, got %q", w.String())
+	}
+	// a GroupRenderer writes its own group subheaders
+	g := &groupRecorder{}
+	calvin = NewTransmogrifier(bytes.NewReader(csvData), &w)
+	calvin.SetRenderer(g)
+	calvin.GroupBy("Team")
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(g.calls) != 6 || g.calls[2] != "group Team=Web" {
+		t.Errorf("got %q", g.calls)
+	}
+}
+
+func TestSetRendererCellState(t *testing.T) {
+	csvData := []byte("Name,Amount,Flags\nAnn,1,\"bold,deprecated\"\nBob,2,\n")
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+	calvin.RowHeader = true
+	r := &headerRecorder{}
+	calvin.SetRenderer(r)
+	calvin.SetFieldAlignment([]string{"l", "r"})
+	calvin.SetRowStyleColumn("Flags")
+	err := calvin.SetHeaderStyle([]string{"i"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.SetHeaderAlignment([]string{"c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{
+		"header cells [{Name 2 [1] false []} {Amount 2 [1] false []}]",
+		"separator [1 3]",
+		"row [{Ann 1 [0] true [deprecated]} {1 3 [0] false [deprecated]}]",
+		"row [{Bob 1 [] true []} {2 3 [] false []}]",
+		"close",
+	}
+	if !reflect.DeepEqual(r.calls, expected) {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(r.calls, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+type footerRecorder struct {
+	recorder
+}
+
+func (r *footerRecorder) WriteFooter(cells []Cell) error {
+	r.calls = append(r.calls, fmt.Sprintf("footer %v", cells))
+	return nil
+}
+
+func TestSetRendererFooter(t *testing.T) {
+	csvData := []byte("Name,Amount\nAnn,1\nBob,2\n")
+	tests := []struct {
+		r        Renderer
+		expected string
+	}{
+		{&recorder{}, "row [{Total 0 [0] false []} {3 0 [0] false []}]"},
+		{&footerRecorder{}, "footer [{Total 0 [0] false []} {3 0 [0] false []}]"},
+	}
+	for _, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.FooterLabel = "Total"
+		calvin.SetRenderer(test.r)
+		err := calvin.SetColumnAggregate("Amount", "sum")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		err = calvin.MDTable()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		var calls []string
+		switch r := test.r.(type) {
+		case *recorder:
+			calls = r.calls
+		case *footerRecorder:
+			calls = r.calls
+		}
+		if len(calls) != 6 || calls[4] != test.expected {
+			t.Errorf("%T: got %q want %q before close", test.r, calls, test.expected)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package csv2md
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Replacement is a regular expression substitution of a column's values;
+// see AddColumnReplacement.
+type Replacement struct {
+	// Pattern is the regular expression that is replaced.
+	Pattern *regexp.Regexp
+	// With is the replacement of each match; $1 or ${name} are replaced by
+	// the submatches, see regexp.Regexp.Expand.
+	With string
+}
+
+// ParseReplacement parses a replacement of the form /pattern/replacement/,
+// e.g. "/^https?:\/\/example\.com//", which removes a URL prefix.  Like
+// sed, the first character is the delimiter, so any other character can be
+// used instead of the /, e.g. "|^https?://example\.com||", and a delimiter
+// in the pattern or replacement is escaped with a \.  The pattern uses
+// Go's regular expression syntax.
+func ParseReplacement(s string) (Replacement, error) {
+	parts, ok := splitReplacement(s)
+	if !ok {
+		return Replacement{}, fmt.Errorf("replacement %q: expected /pattern/replacement/", s)
+	}
+	re, err := regexp.Compile(parts[0])
+	if err != nil {
+		return Replacement{}, fmt.Errorf("replacement %q: %s", s, err)
+	}
+	return Replacement{Pattern: re, With: parts[1]}, nil
+}
+
+// splitReplacement splits the replacement into its pattern and replacement
+// at the unescaped delimiters.
+func splitReplacement(s string) (parts [2]string, ok bool) {
+	if len(s) < 3 {
+		return parts, false
+	}
+	d := s[0]
+	if d == '\\' || d == ' ' || isDigit(d) || 'a' <= d && d <= 'z' || 'A' <= d && d <= 'Z' {
+		return parts, false
+	}
+	var b strings.Builder
+	var n int
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == d:
+			b.WriteByte(d)
+			i++
+		case c == d:
+			if n == len(parts) {
+				return parts, false
+			}
+			parts[n] = b.String()
+			b.Reset()
+			n++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return parts, n == len(parts) && b.Len() == 0
+}
+
+type replacement struct {
+	column string
+	index  int
+	r      Replacement
+}
+
+// AddColumnReplacement adds a regular expression substitution of the named
+// column's values, e.g. to remove a URL prefix or to shorten UUIDs; if the
+// column is AllColumns, the substitution is of every column's values.
+// Every match of the pattern is replaced.  A column can have multiple
+// replacements; they are applied in the order that they were added, after
+// the value maps, see SetColumnValueMap, and before the field's formatting,
+// e.g. SetColumnNumberFormat, and styling.  The cell style rules, see
+// SetCellStyleRule, are evaluated against the values as they were read.
+func (t *Transmogrifier) AddColumnReplacement(column string, r Replacement) {
+	t.replacements = append(t.replacements, &replacement{column: column, r: r})
+}
+
+// prepareReplacements resolves the replacements' columns against the
+// header.
+func (t *Transmogrifier) prepareReplacements(header []string) error {
+	for _, r := range t.replacements {
+		switch r.column {
+		case "":
+			// a field format's replacement is by position
+		case AllColumns:
+			r.index = -1
+		default:
+			r.index = columnIndex(header, r.column)
+			if r.index < 0 {
+				return UnknownColumnError{Name: r.column, operation: "replacement"}
+			}
+		}
+	}
+	return nil
+}
+
+// replaceValues applies the replacements to the record's values.
+func (t *Transmogrifier) replaceValues(record []string) {
+	for _, r := range t.replacements {
+		if r.index < 0 {
+			for i, v := range record {
+				record[i] = r.r.Pattern.ReplaceAllString(v, r.r.With)
+			}
+			continue
+		}
+		if r.index < len(record) {
+			record[r.index] = r.r.Pattern.ReplaceAllString(record[r.index], r.r.With)
+		}
+	}
+}
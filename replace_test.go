@@ -0,0 +1,141 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseReplacement(t *testing.T) {
+	tests := []struct {
+		value   string
+		pattern string
+		with    string
+		err     string
+	}{
+		{"/a/b/", "a", "b", ""},
+		{`/^https?:\/\/example\.com//`, `^https?://example\.com`, "", ""},
+		{`|^https?://example\.com||`, `^https?://example\.com`, "", ""},
+		{`#(\d+)#n\##`, `(\d+)`, "n#", ""},
+		{"///", "", "", ""},
+		{"/a/b", "", "", `replacement "/a/b": expected /pattern/replacement/`},
+		{"/a/b/c/", "", "", `replacement "/a/b/c/": expected /pattern/replacement/`},
+		{"sabs", "", "", `replacement "sabs": expected /pattern/replacement/`},
+		{"", "", "", `replacement "": expected /pattern/replacement/`},
+		{"/(/x/", "", "", "replacement \"/(/x/\": error parsing regexp: missing closing ): `(`"},
+	}
+	for i, test := range tests {
+		r, err := ParseReplacement(test.value)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if r.Pattern.String() != test.pattern || r.With != test.with {
+			t.Errorf("%d: got %q, %q want %q, %q", i, r.Pattern, r.With, test.pattern, test.with)
+		}
+	}
+}
+
+func TestAddColumnReplacement(t *testing.T) {
+	csvData := "ID,URL,Qty\n1b4e28ba-2fa1-11d2-883f-0016d3cca427,https://example.com/a,1 000\n6fa459ea-ee8a-3ca4-894e-db77e160355e,http://example.org/b,2\n"
+	tests := []struct {
+		replacements map[string]string
+		expected     string
+	}{
+		{map[string]string{"URL": `|^https?://example\.com||`},
+			"ID|URL|Qty  \n---|---|---  \n1b4e28ba-2fa1-11d2-883f-0016d3cca427|/a|1 000  \n6fa459ea-ee8a-3ca4-894e-db77e160355e|http://example.org/b|2  \n"},
+		{map[string]string{"ID": `/^([0-9a-f]{8})-.*/$1…/`, AllColumns: "/ //"},
+			"ID|URL|Qty  \n---|---|---  \n1b4e28ba…|https://example.com/a|1000  \n6fa459ea…|http://example.org/b|2  \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		for column, v := range test.replacements {
+			r, err := ParseReplacement(v)
+			if err != nil {
+				t.Fatalf("%d: unexpected error: %s", i, err)
+			}
+			calvin.AddColumnReplacement(column, r)
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+	r, _ := ParseReplacement("/a/b/")
+	calvin.AddColumnReplacement("Link", r)
+	err := calvin.MDTable()
+	if err == nil || err.Error() != `replacement: unknown column "Link"` {
+		t.Errorf("got error %v want an unknown column error", err)
+	}
+}
+
+func TestReplacementOrder(t *testing.T) {
+	// the replacement is of the mapped value and is formatted and styled
+	csvData := "Status,Price\n1,USD 1234.5\n2,USD -3\n"
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+	calvin.SetColumnValueMap("Status", map[string]string{"1": "open-1", "2": "closed-2"}, true)
+	r, _ := ParseReplacement("/-[0-9]+$//")
+	calvin.AddColumnReplacement("Status", r)
+	r, _ = ParseReplacement("/^new$/x/")
+	calvin.AddColumnReplacement("Status", r)
+	r, _ = ParseReplacement("/^USD //")
+	calvin.AddColumnReplacement("Price", r)
+	calvin.SetColumnNumberFormat("Price", NumberFormat{Precision: 2, Thousands: ","})
+	calvin.SetCellStyleRule("Status", func(v string, record []string) bool { return v == "2" }, "b")
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Status|Price  \n---|---  \nopen|1,234.50  \n__closed__|-3.00  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
+
+func TestFieldFormatReplace(t *testing.T) {
+	csvData := "Name,URL\nAnn,https://example.com/ann\n"
+	tests := []struct {
+		formats  []FieldFormat
+		expected string
+		err      string
+	}{
+		{[]FieldFormat{{}, {Replace: "|https://||"}}, "Name|URL  \n---|---  \nAnn|example.com/ann  \n", ""},
+		{[]FieldFormat{{Column: "URL", Replace: "|.*/||"}}, "Name|URL  \n---|---  \nAnn|ann  \n", ""},
+		{[]FieldFormat{{Column: "URL", Replace: "|x|"}}, "", `field 1: replacement "|x|": expected /pattern/replacement/`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		err := calvin.SetFieldFormats(test.formats)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package csv2md
+
+// rowBuffer assembles a GFM table row, including its new line, so that the
+// row is written using a single Write.  The buffer is reused for each row.
+type rowBuffer struct {
+	b     []byte
+	cells int
+	outer bool
+}
+
+// reset starts a new row; if outer is true, the row has leading and
+// trailing pipes, see OuterPipes.
+func (r *rowBuffer) reset(outer bool) {
+	r.b = r.b[:0]
+	r.cells = 0
+	r.outer = outer
+}
+
+// cell appends the cell's value, padded to the width, in columns, see
+// displayWidth, using the alignment.
+func (r *rowBuffer) cell(v string, width int, alignment string) {
+	switch {
+	case r.cells > 0 && r.outer:
+		r.b = append(r.b, " | "...)
+	case r.cells > 0:
+		r.b = append(r.b, '|')
+	case r.outer:
+		r.b = append(r.b, "| "...)
+	}
+	r.cells++
+	n := width - displayWidth(v)
+	if n <= 0 {
+		r.b = append(r.b, v...)
+		return
+	}
+	var left int
+	switch alignment {
+	case right:
+		left = n
+	case centered:
+		left = n / 2
+	}
+	r.b = appendSpaces(r.b, left)
+	r.b = append(r.b, v...)
+	r.b = appendSpaces(r.b, n-left)
+}
+
+// end ends the row with the new line and returns the row.
+func (r *rowBuffer) end(newLine string) []byte {
+	if r.outer && r.cells > 0 {
+		r.b = append(r.b, " |"...)
+	}
+	r.b = append(r.b, newLine...)
+	return r.b
+}
+
+func appendSpaces(b []byte, n int) []byte {
+	for ; n > 0; n-- {
+		b = append(b, ' ')
+	}
+	return b
+}
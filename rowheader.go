@@ -0,0 +1,37 @@
+package csv2md
+
+// WithRowHeader makes the first column the row header; see RowHeader.
+func WithRowHeader() Option {
+	return func(t *Transmogrifier) {
+		t.RowHeader = true
+	}
+}
+
+// prepareRowHeader finds the row header's column: the first column that
+// isn't hidden.
+func (t *Transmogrifier) prepareRowHeader() {
+	t.rowHeaderCol = -1
+	if !t.RowHeader {
+		return
+	}
+	i := 0
+	for t.hidden[i] {
+		i++
+	}
+	t.rowHeaderCol = i
+}
+
+// isRowHeader returns whether the i'th field is the record's row header.
+func (t *Transmogrifier) isRowHeader(i int) bool {
+	return t.RowHeader && i == t.rowHeaderCol
+}
+
+// rowHeaderCell returns the tag and the scope attribute of the i'th field's
+// HTML cell: a th, with a row scope, for the row header and a td for the
+// other fields.
+func (h *htmlTable) rowHeaderCell(i int) (tag, scope string) {
+	if h.t.isRowHeader(i) {
+		return "th", " scope=\"row\""
+	}
+	return "td", ""
+}
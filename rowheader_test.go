@@ -0,0 +1,52 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRowHeader(t *testing.T) {
+	csvData := "Feature,Basic,Pro\nSSO,no,yes\n,yes,yes\n"
+	tests := []struct {
+		format   Format
+		style    []string
+		exclude  string
+		expected string
+	}{
+		{GFM, nil, "", "Feature|Basic|Pro  \n---|---|---  \n__SSO__|no|yes  \n |yes|yes  \n"},
+		// a bold row header isn't bolded twice
+		{GFM, []string{"bold", "italic"}, "", "Feature|Basic|Pro  \n---|---|---  \n__SSO__|_no_|yes  \n__ __|_yes_|yes  \n"},
+		// the hidden columns are skipped
+		{GFM, nil, "Feature", "Basic|Pro  \n---|---  \n__no__|yes  \n__yes__|yes  \n"},
+		{HTML, nil, "", "<table>\n<thead>\n<tr>\n<th>Feature</th>\n<th>Basic</th>\n<th>Pro</th>\n</tr>\n</thead>\n<tbody>\n" +
+			"<tr>\n<th scope=\"row\">SSO</th>\n<td>no</td>\n<td>yes</td>\n</tr>\n" +
+			"<tr>\n<th scope=\"row\"></th>\n<td>yes</td>\n<td>yes</td>\n</tr>\n</tbody>\n</table>\n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.RowHeader = true
+		calvin.OutputFormat = test.format
+		calvin.SetFieldStyle(test.style)
+		if test.exclude != "" {
+			calvin.ExcludeColumns(test.exclude)
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+
+	s, err := TableString(strings.NewReader("Feature,Pro\nSSO,yes\n"), WithRowHeader(), WithRowNumbers())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s != "#|Feature|Pro  \n---|---|---  \n__1__|SSO|yes  \n" {
+		t.Errorf("got %q want the row numbers as the row header", s)
+	}
+}
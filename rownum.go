@@ -0,0 +1,52 @@
+package csv2md
+
+import (
+	"io"
+	"strconv"
+)
+
+// RowNumberColumn is the name of the column of row numbers; see
+// RowNumbers.
+const RowNumberColumn = "#"
+
+// WithRowNumbers numbers the table's rows; see RowNumbers.
+func WithRowNumbers() Option {
+	return func(t *Transmogrifier) {
+		t.RowNumbers = true
+	}
+}
+
+// numberRows reads the records and prepends the RowNumberColumn to them
+// and to the header.  The records are numbered in the order they were
+// read; they are renumbered as they are written, since the groups may be
+// sorted.
+func (t *Transmogrifier) numberRows(header []string) ([]string, error) {
+	var records [][]string
+	for {
+		record, err := t.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		record = append([]string{strconv.Itoa(len(records) + 1)}, record...)
+		records = append(records, record)
+	}
+	t.records = records
+	t.buffered = true
+	t.nComputed = len(t.records)
+	t.nFiltered = len(t.records)
+	t.rowNumber = 0
+	if header != nil {
+		header = append([]string{RowNumberColumn}, header...)
+	}
+	t.header = header
+	return header, nil
+}
+
+// numberRow sets the record's row number to the next row's number.
+func (t *Transmogrifier) numberRow(record []string) {
+	t.rowNumber++
+	record[0] = strconv.Itoa(t.rowNumber)
+}
@@ -0,0 +1,58 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRowNumbers(t *testing.T) {
+	csvData := "Team,Name,Amount\nWeb,Ann,3\nOps,Bob,-1\nWeb,Cat,5\nOps,Dan,2\n"
+	tests := []struct {
+		setup    func(*Transmogrifier) error
+		expected string
+	}{
+		{func(calvin *Transmogrifier) error { return nil },
+			"#|Team|Name|Amount  \n---|---|---|---  \n1|Web|Ann|3  \n2|Ops|Bob|-1  \n3|Web|Cat|5  \n4|Ops|Dan|2  \n"},
+		{func(calvin *Transmogrifier) error { return calvin.SetFilter("Amount > 0") },
+			"#|Team|Name|Amount  \n---|---|---|---  \n1|Web|Ann|3  \n2|Web|Cat|5  \n3|Ops|Dan|2  \n"},
+		{func(calvin *Transmogrifier) error {
+			calvin.GroupBy("Team", SortGroups())
+			return nil
+		}, "#|Team|Name|Amount  \n---|---|---|---  \n**Team: Ops**| | |   \n1|Ops|Bob|-1  \n2|Ops|Dan|2  \n**Team: Web**| | |   \n3|Web|Ann|3  \n4|Web|Cat|5  \n"},
+		{func(calvin *Transmogrifier) error {
+			calvin.SetFieldAlignment([]string{"r"})
+			calvin.SetRowRange(1, 2)
+			return nil
+		}, "#|Team|Name|Amount  \n--:|---|---|---  \n1|Ops|Bob|-1  \n2|Web|Cat|5  \n"},
+		{func(calvin *Transmogrifier) error { return calvin.SetColumnAggregate("#", "count") },
+			"#|Team|Name|Amount  \n---|---|---|---  \n1|Web|Ann|3  \n2|Ops|Bob|-1  \n3|Web|Cat|5  \n4|Ops|Dan|2  \n__4__| | |   \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.RowNumbers = true
+		err := test.setup(calvin)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestWithRowNumbers(t *testing.T) {
+	s, err := TableString(strings.NewReader("Name\nAnn\nBob\n"), WithRowNumbers())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "#|Name  \n---|---  \n1|Ann  \n2|Bob  \n"
+	if s != expected {
+		t.Errorf("got %q want %q", s, expected)
+	}
+}
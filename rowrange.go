@@ -0,0 +1,152 @@
+package csv2md
+
+import (
+	"errors"
+	"io"
+)
+
+// rowRange is the slice of the records that is written; see SetRowRange
+// and SetTail.
+type rowRange struct {
+	offset   int
+	limit    int
+	tail     int
+	skipped  int  // the records skipped by the offset
+	taken    int  // the records in the range
+	more     bool // whether there are records after the limit
+	omitted  int  // the records before the tail
+	fields   int  // the number of fields in the range's last record
+	led      bool // whether the leading ellipsis row has been written
+	prepared bool
+}
+
+// SetRowRange only writes a range of the records: the first offset
+// records are skipped and, if the limit is greater than 0, at most limit
+// records are written.  The range is of the records as they are read,
+// after the filter and the removal of duplicates; see SetFilter and
+// Dedup.  Once the limit has been reached, the rest of the CSV-encoded
+// data is not read.  The header record is always written.  See
+// EllipsisRows for marking the omitted records.
+func (t *Transmogrifier) SetRowRange(offset, limit int) {
+	if t.rows == nil {
+		t.rows = &rowRange{}
+	}
+	t.rows.offset = offset
+	t.rows.limit = limit
+}
+
+// SetTail only writes the last n records.  Like SetRowRange, they are the
+// last of the records that are read, after the filter and the removal of
+// duplicates.  This requires all of the CSV-encoded data to be read, but
+// only the last n records are held in memory.  A tail cannot be used with
+// a row range.
+func (t *Transmogrifier) SetTail(n int) {
+	if t.rows == nil {
+		t.rows = &rowRange{}
+	}
+	t.rows.tail = n
+}
+
+// prepareRowRange starts selecting the records in the row range; it is
+// prepared with the filter.
+func (t *Transmogrifier) prepareRowRange() error {
+	r := t.rows
+	if r == nil {
+		return nil
+	}
+	if r.tail > 0 && (r.offset > 0 || r.limit > 0) {
+		return errors.New("a tail and a row range are mutually exclusive")
+	}
+	*r = rowRange{offset: r.offset, limit: r.limit, tail: r.tail, prepared: true}
+	return nil
+}
+
+// selected returns whether the record is in the row range.
+func (r *rowRange) selected(record []string) bool {
+	if r.skipped < r.offset {
+		r.skipped++
+		return false
+	}
+	if r.limit > 0 && r.taken >= r.limit {
+		r.more = true
+		return false
+	}
+	r.taken++
+	r.fields = len(record)
+	return true
+}
+
+// readTail reads all of the remaining records and buffers the last of
+// them.  Like transpose, the records have already been computed and
+// filtered, so the buffered records are not computed or filtered again.
+func (t *Transmogrifier) readTail() error {
+	n := t.rows.tail
+	last := make([][]string, 0, n)
+	var read int
+	for {
+		record, err := t.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		record = t.keep(record)
+		if len(last) < n {
+			last = append(last, record)
+		} else {
+			last[read%n] = record
+		}
+		read++
+	}
+	if read > n {
+		// the oldest of the kept records is the next one to be replaced
+		i := read % n
+		last = append(last[i:], last[:i]...)
+		t.rows.omitted = read - n
+		t.nExcluded += int64(t.rows.omitted)
+	}
+	if len(last) > 0 {
+		t.rows.fields = len(last[len(last)-1])
+	}
+	t.records = last
+	t.buffered = true
+	t.nComputed = len(t.records)
+	t.nFiltered = len(t.records)
+	return nil
+}
+
+// writeEllipsis writes an ellipsis row, if EllipsisRows is set, for the
+// records that are omitted before the row range, if it hasn't been written
+// yet, and, at the end of the table, for the records after it.
+func (t *Transmogrifier) writeEllipsis(r Renderer, end bool) error {
+	s := t.rows
+	if s == nil || !t.EllipsisRows || t.Transpose || t.pivot != nil || t.groupAggs != nil || t.tables != nil {
+		return nil
+	}
+	n := 0
+	if !s.led && (s.skipped > 0 || s.omitted > 0) {
+		s.led = true
+		n++
+	}
+	if end && s.more {
+		n++
+	}
+	fields := s.fields
+	if t.header != nil {
+		fields = len(t.header)
+	} else if t.RowNumbers {
+		fields++
+	}
+	for ; n > 0; n-- {
+		cells := make([]string, fields)
+		for i := range cells {
+			cells[i] = ellipsis
+		}
+		err := t.writeRecord(r, cells, cells)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
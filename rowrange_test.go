@@ -0,0 +1,74 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRowRange(t *testing.T) {
+	csvData := "N,Sq\n1,1\n2,4\n3,9\n4,16\n5,25\n"
+	tests := []struct {
+		offset, limit, tail int
+		ellipsis            bool
+		expected            string
+		err                 string
+	}{
+		{0, 2, 0, false, "N|Sq  \n---|---  \n1|1  \n2|4  \n", ""},
+		{0, 2, 0, true, "N|Sq  \n---|---  \n1|1  \n2|4  \n…|…  \n", ""},
+		{0, 5, 0, true, "N|Sq  \n---|---  \n1|1  \n2|4  \n3|9  \n4|16  \n5|25  \n", ""},
+		{1, 2, 0, true, "N|Sq  \n---|---  \n…|…  \n2|4  \n3|9  \n…|…  \n", ""},
+		{3, 0, 0, false, "N|Sq  \n---|---  \n4|16  \n5|25  \n", ""},
+		{9, 0, 0, true, "N|Sq  \n---|---  \n…|…  \n", ""},
+		{0, 0, 2, true, "N|Sq  \n---|---  \n…|…  \n4|16  \n5|25  \n", ""},
+		{0, 0, 3, false, "N|Sq  \n---|---  \n3|9  \n4|16  \n5|25  \n", ""},
+		{0, 0, 9, true, "N|Sq  \n---|---  \n1|1  \n2|4  \n3|9  \n4|16  \n5|25  \n", ""},
+		{1, 0, 2, false, "", "a tail and a row range are mutually exclusive"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		if test.offset > 0 || test.limit > 0 {
+			calvin.SetRowRange(test.offset, test.limit)
+		}
+		if test.tail > 0 {
+			calvin.SetTail(test.tail)
+		}
+		calvin.EllipsisRows = test.ellipsis
+		err := calvin.MDTable()
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestRowRangeFilter(t *testing.T) {
+	// the range is of the filtered records and the remaining records are
+	// not read once the limit has been reached
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader("N\n1\n2\n3\n4\n5\n6\n\"7\n"), &w)
+	err := calvin.SetFilter("N > 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	calvin.SetRowRange(1, 2)
+	calvin.Pretty = true
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "N    \n---  \n3    \n4    \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
@@ -0,0 +1,81 @@
+package csv2md
+
+import "strings"
+
+type rowStyle struct {
+	column string
+	index  int
+}
+
+// SetRowStyleColumn styles each record using its value of the named column,
+// which is omitted from the table; this allows the system that produced the
+// CSV-encoded data to flag records, e.g. deprecated or failed ones.  The
+// value is a comma separated list of styles, see SetFieldStyle, e.g. bold,
+// and of classes, which are the class attribute of the record's row in
+// HTML tables, e.g. deprecated; anything that isn't a style is a class.
+// The styles are applied to every cell in the record, after the cell style
+// rules' styles, see SetCellStyleRule.  An empty value doesn't style the
+// record.  The column is named before the fields are renamed; if it is
+// empty, no column styles the records.
+func (t *Transmogrifier) SetRowStyleColumn(column string) {
+	if column == "" {
+		t.rowStyle = nil
+		return
+	}
+	t.rowStyle = &rowStyle{column: column}
+}
+
+// prepareRowStyle resolves the row style column against the header and
+// hides it.
+func (t *Transmogrifier) prepareRowStyle(header []string) error {
+	if t.rowStyle == nil {
+		return nil
+	}
+	t.rowStyle.index = columnIndex(header, t.rowStyle.column)
+	if t.rowStyle.index < 0 {
+		return UnknownColumnError{Name: t.rowStyle.column, operation: "row style"}
+	}
+	if t.hidden == nil {
+		t.hidden = make(map[int]bool)
+	}
+	t.hidden[t.rowStyle.index] = true
+	return nil
+}
+
+// rowValues returns the values of the raw record's row style column.
+func (t *Transmogrifier) rowValues(raw []string) []string {
+	if t.rowStyle == nil || t.rowStyle.index >= len(raw) {
+		return nil
+	}
+	var vals []string
+	for _, v := range strings.Split(raw[t.rowStyle.index], ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
+
+// rowStyles returns the styles of the raw record's row style.
+func (t *Transmogrifier) rowStyles(raw []string) []string {
+	var styles []string
+	for _, v := range t.rowValues(raw) {
+		if s := parseStyle(v); s != "" {
+			styles = append(styles, s)
+		}
+	}
+	return styles
+}
+
+// rowClasses returns the classes of the raw record's HTML row, if its row
+// style has classes.
+func (t *Transmogrifier) rowClasses(raw []string) []string {
+	var classes []string
+	for _, v := range t.rowValues(raw) {
+		if parseStyle(v) == "" {
+			classes = append(classes, v)
+		}
+	}
+	return classes
+}
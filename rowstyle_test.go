@@ -0,0 +1,60 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSetRowStyleColumn(t *testing.T) {
+	csvData := "Name,Flag,Qty\nAnn,bold,3\nBob,,4\nCat,\"strike, failed\",5\nDan,new,6\n"
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{GFM, "Name|Qty  \n---|---  \n__Ann__|__3__  \nBob|4  \n~~Cat~~|~~_5_~~  \nDan|6  \n"},
+		{HTML, "<table>\n<thead>\n<tr>\n<th>Name</th>\n<th>Qty</th>\n</tr>\n</thead>\n<tbody>\n" +
+			"<tr>\n<td><strong>Ann</strong></td>\n<td><strong>3</strong></td>\n</tr>\n" +
+			"<tr>\n<td>Bob</td>\n<td>4</td>\n</tr>\n" +
+			"<tr class=\"failed\">\n<td><del>Cat</del></td>\n<td><del><em>5</em></del></td>\n</tr>\n" +
+			"<tr class=\"new\">\n<td>Dan</td>\n<td>6</td>\n</tr>\n</tbody>\n</table>\n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.OutputFormat = test.format
+		calvin.SetRowStyleColumn("Flag")
+		err := calvin.SetCellStyleExpr("Qty", "Qty == 5", "i")
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %s", i, err)
+		}
+		err = calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+
+	calvin := NewTransmogrifier(strings.NewReader(csvData), &bytes.Buffer{})
+	calvin.SetRowStyleColumn("Style")
+	err := calvin.MDTable()
+	if err == nil || err.Error() != `row style: unknown column "Style"` {
+		t.Errorf("got error %v want an unknown column error", err)
+	}
+
+	// an empty column unsets the row style column
+	var w bytes.Buffer
+	calvin = NewTransmogrifier(strings.NewReader(csvData), &w)
+	calvin.SetRowStyleColumn("Style")
+	calvin.SetRowStyleColumn("")
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.HasPrefix(w.String(), "Name|Flag|Qty  \n") {
+		t.Errorf("got %q want the Flag column", w.String())
+	}
+}
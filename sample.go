@@ -0,0 +1,109 @@
+package csv2md
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+)
+
+// sample is the random sample of the records; see SetSample and
+// SetSamplePercent.
+type sample struct {
+	n        int
+	pct      float64
+	seed     int64
+	rng      *rand.Rand
+	prepared bool
+}
+
+// SetSample only writes a random sample of n of the records, in their
+// original order.  The sample is of the records as they are read, after
+// the filter and the removal of duplicates; see SetFilter and Dedup.  The
+// same seed selects the same sample of the same records.  This requires
+// all of the CSV-encoded data to be read, but only the sampled records are
+// held in memory.  A sample cannot be used with a row range or a tail.
+func (t *Transmogrifier) SetSample(n int, seed int64) error {
+	if n < 1 {
+		return fmt.Errorf("sample: %d: the sample must have at least 1 record", n)
+	}
+	t.sampling = &sample{n: n, seed: seed}
+	return nil
+}
+
+// SetSamplePercent only writes a random sample of about pct percent of the
+// records: each record is sampled with a probability of pct percent, so
+// the records are streamed.  Like SetSample, the same seed selects the
+// same sample of the same records.
+func (t *Transmogrifier) SetSamplePercent(pct float64, seed int64) error {
+	if pct <= 0 || pct > 100 {
+		return fmt.Errorf("sample: %g%%: the percent must be greater than 0 and at most 100", pct)
+	}
+	t.sampling = &sample{pct: pct, seed: seed}
+	return nil
+}
+
+// prepareSample starts sampling the records; it is prepared with the
+// filter.
+func (t *Transmogrifier) prepareSample() error {
+	s := t.sampling
+	if s == nil {
+		return nil
+	}
+	if t.rows != nil {
+		return errors.New("a sample is mutually exclusive with a row range and a tail")
+	}
+	s.rng = rand.New(rand.NewSource(s.seed))
+	s.prepared = true
+	return nil
+}
+
+// sampled returns whether the record is in a percent sample; every record
+// is in a sample of n records until the sample has been read.
+func (s *sample) sampled() bool {
+	return s.pct == 0 || s.rng.Float64()*100 < s.pct
+}
+
+// readSample reads all of the remaining records and buffers a sample of
+// n of them using reservoir sampling.  Like transpose, the records have
+// already been computed and filtered, so the buffered records are not
+// computed or filtered again.
+func (t *Transmogrifier) readSample() error {
+	type sampled struct {
+		i      int
+		record []string
+	}
+	s := t.sampling
+	reservoir := make([]sampled, 0, s.n)
+	var read int
+	for i := 0; ; i++ {
+		record, err := t.read()
+		if err == io.EOF {
+			read = i
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(reservoir) < s.n {
+			reservoir = append(reservoir, sampled{i, t.keep(record)})
+			continue
+		}
+		if j := s.rng.Intn(i + 1); j < s.n {
+			reservoir[j] = sampled{i, t.keep(record)}
+		}
+	}
+	t.nExcluded += int64(read - len(reservoir))
+	sort.Slice(reservoir, func(i, j int) bool {
+		return reservoir[i].i < reservoir[j].i
+	})
+	t.records = make([][]string, len(reservoir))
+	for i, v := range reservoir {
+		t.records[i] = v.record
+	}
+	t.buffered = true
+	t.nComputed = len(t.records)
+	t.nFiltered = len(t.records)
+	return nil
+}
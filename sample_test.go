@@ -0,0 +1,129 @@
+package csv2md
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func sampleCSV(n int) string {
+	var b strings.Builder
+	b.WriteString("N\n")
+	for i := 1; i <= n; i++ {
+		b.WriteString(strconv.Itoa(i) + "\n")
+	}
+	return b.String()
+}
+
+// sampledRows returns the rows of the table written by MDTable.
+func sampledRows(t *testing.T, calvin *Transmogrifier, w *bytes.Buffer) []int {
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	var rows []int
+	for _, line := range lines[2:] {
+		n, err := strconv.Atoi(strings.TrimSpace(line))
+		if err != nil {
+			t.Fatalf("%q: %s", line, err)
+		}
+		rows = append(rows, n)
+	}
+	return rows
+}
+
+func TestSetSample(t *testing.T) {
+	for _, seed := range []int64{0, 42} {
+		var rows [2][]int
+		for i := range rows {
+			var w bytes.Buffer
+			calvin := NewTransmogrifier(strings.NewReader(sampleCSV(100)), &w)
+			err := calvin.SetSample(10, seed)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			rows[i] = sampledRows(t, calvin, &w)
+		}
+		if len(rows[0]) != 10 {
+			t.Errorf("%d: got %d rows want 10", seed, len(rows[0]))
+		}
+		for i := range rows[0] {
+			if i > 0 && rows[0][i] <= rows[0][i-1] {
+				t.Errorf("%d: the rows are not in their original order: %v", seed, rows[0])
+				break
+			}
+		}
+		if len(rows[0]) != len(rows[1]) {
+			t.Errorf("%d: the sample is not reproducible: %v %v", seed, rows[0], rows[1])
+			continue
+		}
+		for i := range rows[0] {
+			if rows[0][i] != rows[1][i] {
+				t.Errorf("%d: the sample is not reproducible: %v %v", seed, rows[0], rows[1])
+				break
+			}
+		}
+	}
+	// a sample of more records than there are is all of the records
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(sampleCSV(3)), &w)
+	err := calvin.SetSample(10, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rows := sampledRows(t, calvin, &w); len(rows) != 3 {
+		t.Errorf("got %v want all 3 rows", rows)
+	}
+}
+
+func TestSetSamplePercent(t *testing.T) {
+	var rows [2][]int
+	for i := range rows {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(sampleCSV(1000)), &w)
+		err := calvin.SetSamplePercent(10, 42)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		rows[i] = sampledRows(t, calvin, &w)
+	}
+	if len(rows[0]) < 50 || len(rows[0]) > 150 {
+		t.Errorf("got %d rows want about 100", len(rows[0]))
+	}
+	if len(rows[0]) != len(rows[1]) {
+		t.Fatalf("the sample is not reproducible: got %d and %d rows", len(rows[0]), len(rows[1]))
+	}
+	for i := range rows[0] {
+		if rows[0][i] != rows[1][i] {
+			t.Fatalf("the sample is not reproducible: %v %v", rows[0], rows[1])
+		}
+	}
+}
+
+func TestSampleErrors(t *testing.T) {
+	calvin := NewTransmogrifier(strings.NewReader(sampleCSV(3)), &bytes.Buffer{})
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{calvin.SetSample(0, 1), "sample: 0: the sample must have at least 1 record"},
+		{calvin.SetSamplePercent(0, 1), "sample: 0%: the percent must be greater than 0 and at most 100"},
+		{calvin.SetSamplePercent(101, 1), "sample: 101%: the percent must be greater than 0 and at most 100"},
+	}
+	for i, test := range tests {
+		if test.err == nil || test.err.Error() != test.expected {
+			t.Errorf("%d: got error %v want %q", i, test.err, test.expected)
+		}
+	}
+	err := calvin.SetSample(2, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	calvin.SetTail(1)
+	err = calvin.MDTable()
+	if err == nil || err.Error() != "a sample is mutually exclusive with a row range and a tail" {
+		t.Errorf("got error %v want the sample and tail to be mutually exclusive", err)
+	}
+}
@@ -0,0 +1,122 @@
+package csv2md
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Sanitize specifies how control characters in the CSV-encoded data's
+// fields are handled.  Tabs and line breaks are never considered control
+// characters; line breaks within a field are written as the LineBreak.
+type Sanitize int
+
+const (
+	// PassThrough leaves control characters as is.
+	PassThrough Sanitize = iota
+	// StripControl removes control characters.
+	StripControl
+	// EscapeControl replaces control characters with a visible escape
+	// sequence; e.g. ESC becomes \x1b.
+	EscapeControl
+	// ErrorControl results in a ControlCharError when a control character
+	// is encountered.
+	ErrorControl
+	// StripANSI removes ANSI escape sequences, e.g. the color codes of
+	// command output, and the other control characters.  Unlike
+	// StripControl, the whole sequence is removed, not just its ESC.
+	StripANSI
+)
+
+// ParseSanitize returns the Sanitize for the value.
+//
+// Valid values:
+//    * PassThrough
+//      * empty string
+//      * none
+//      * pass
+//    * StripControl
+//      * strip
+//    * EscapeControl
+//      * escape
+//    * ErrorControl
+//      * error
+//    * StripANSI
+//      * ansi
+//      * strip-ansi
+func ParseSanitize(s string) (Sanitize, error) {
+	switch strings.TrimSpace(strings.ToLower(s)) {
+	case "", "none", "pass":
+		return PassThrough, nil
+	case "strip":
+		return StripControl, nil
+	case "escape":
+		return EscapeControl, nil
+	case "error":
+		return ErrorControl, nil
+	case "ansi", "strip-ansi":
+		return StripANSI, nil
+	}
+	return PassThrough, fmt.Errorf("unknown sanitize value %q", s)
+}
+
+// ControlCharError occurs when SanitizeControl is ErrorControl and a field
+// contains a control character.  The Row is the record's position in the
+// CSV-encoded data, including the header record; it is 0 if the field is
+// one of the field names that were set.  The Column is 1 based.
+type ControlCharError struct {
+	Row    int
+	Column int
+	Char   rune
+}
+
+func (e ControlCharError) Error() string {
+	return fmt.Sprintf("row %d: column %d: control character %q", e.Row, e.Column, e.Char)
+}
+
+func isControl(r rune) bool {
+	return r != '\t' && r != '\n' && r != '\r' && unicode.IsControl(r)
+}
+
+// sanitize handles the control characters in the fields according to
+// SanitizeControl.  The fields are modified in place.
+func (t *Transmogrifier) sanitize(fields []string, row int) error {
+	if t.SanitizeControl == PassThrough {
+		return nil
+	}
+	for i, field := range fields {
+		j := strings.IndexFunc(field, isControl)
+		if j < 0 {
+			continue
+		}
+		switch t.SanitizeControl {
+		case StripControl:
+			fields[i] = strings.Map(func(r rune) rune {
+				if isControl(r) {
+					return -1
+				}
+				return r
+			}, field)
+		case EscapeControl:
+			var b strings.Builder
+			for _, r := range field {
+				if !isControl(r) {
+					b.WriteRune(r)
+					continue
+				}
+				if r > 0xff {
+					fmt.Fprintf(&b, `\u%04x`, r)
+					continue
+				}
+				fmt.Fprintf(&b, `\x%02x`, r)
+			}
+			fields[i] = b.String()
+		case StripANSI:
+			fields[i] = stripANSI(field)
+		case ErrorControl:
+			r := []rune(field[j:])[0]
+			return ControlCharError{Row: row, Column: i + 1, Char: r}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseSanitize(t *testing.T) {
+	tests := []struct {
+		v        string
+		expected Sanitize
+		err      string
+	}{
+		{"", PassThrough, ""},
+		{"none", PassThrough, ""},
+		{"Strip", StripControl, ""},
+		{"escape", EscapeControl, ""},
+		{" error ", ErrorControl, ""},
+		{"ansi", StripANSI, ""},
+		{"Strip-ANSI", StripANSI, ""},
+		{"scrub", PassThrough, `unknown sanitize value "scrub"`},
+	}
+	for i, test := range tests {
+		v, err := ParseSanitize(test.v)
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if v != test.expected {
+			t.Errorf("%d: got %d want %d", i, v, test.expected)
+		}
+	}
+}
+
+func TestSanitizeControl(t *testing.T) {
+	csvData := []byte("Na\x00me,Msg\nAnn,\x1b[31mred\x1b[0m\nBob,a\tb\x0bc\n")
+	tests := []struct {
+		sanitize Sanitize
+		expected string
+		err      string
+	}{
+		{PassThrough, "Na\x00me|Msg  \n---|---  \nAnn|\x1b[31mred\x1b[0m  \nBob|a\tb\x0bc  \n", ""},
+		{StripControl, "Name|Msg  \n---|---  \nAnn|[31mred[0m  \nBob|a\tbc  \n", ""},
+		{EscapeControl, "Na\\x00me|Msg  \n---|---  \nAnn|\\x1b[31mred\\x1b[0m  \nBob|a\tb\\x0bc  \n", ""},
+		{ErrorControl, "", `row 1: column 1: control character '\x00'`},
+		{StripANSI, "Name|Msg  \n---|---  \nAnn|red  \nBob|a\tbc  \n", ""},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.SanitizeControl = test.sanitize
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+// TestSanitizeControlLineBreaks checks that the line breaks in a quoted field
+// are left for LineBreak in every mode.
+func TestSanitizeControlLineBreaks(t *testing.T) {
+	csvData := []byte("Name,Address\nCalvin,\"Main St\nApt 2\"\nHobbes,\"Tree\r\nHouse\"\n")
+	expected := "Name|Address  \n---|---  \nCalvin|Main St<br>Apt 2  \nHobbes|Tree<br>House  \n"
+	for _, sanitize := range []Sanitize{PassThrough, StripControl, EscapeControl, ErrorControl, StripANSI} {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.SanitizeControl = sanitize
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", sanitize, err)
+			continue
+		}
+		if w.String() != expected {
+			t.Errorf("%d: got %q want %q", sanitize, w.String(), expected)
+		}
+	}
+}
+
+func TestSanitizeControlError(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader([]byte("a,b\nok,ok\nok,b\x07d\n")), &w)
+	calvin.SanitizeControl = ErrorControl
+	err := calvin.MDTable()
+	cerr, ok := err.(ControlCharError)
+	if !ok {
+		t.Fatalf("got %v, want a ControlCharError", err)
+	}
+	if cerr.Row != 3 || cerr.Column != 2 || cerr.Char != '\a' {
+		t.Errorf("got row %d column %d char %q, want row 3 column 2 char '\\a'", cerr.Row, cerr.Column, cerr.Char)
+	}
+	// field names are also sanitized
+	w.Reset()
+	calvin = NewTransmogrifier(bytes.NewReader([]byte("ok,ok\n")), &w)
+	calvin.SanitizeControl = ErrorControl
+	calvin.HasHeaderRecord = false
+	calvin.SetFieldNames([]string{"Name", "M\x7fsg"})
+	err = calvin.MDTable()
+	if err == nil || err.Error() != `row 0: column 2: control character '\x7f'` {
+		t.Errorf("got %v, want row 0: column 2: control character '\\x7f'", err)
+	}
+}
@@ -0,0 +1,50 @@
+package csv2md
+
+import "sort"
+
+// DefaultSplitHeading is the heading template used for each table when the
+// table is split by a column and no heading template has been set.
+const DefaultSplitHeading = "## {value}"
+
+// SplitHeading sets the template for the heading that is written before
+// each of the tables of SplitBy.  The {column} and {value} substitutions are
+// replaced by the split column's name and the table's value.  The default
+// is DefaultSplitHeading.  It has no effect on GroupBy.
+func SplitHeading(tmpl string) GroupOption {
+	return func(g *group) {
+		g.heading = tmpl
+	}
+}
+
+// SplitBy splits the table into a table per value of the named column;
+// each table is preceded by a heading, see SplitHeading, and has its own
+// header and footer row.  The GroupOptions are the same as for GroupBy:
+// SortGroups sorts the records so that each value has one table and
+// HideGroupColumn omits the column from the tables.  If there are no
+// records, nothing is written.
+//
+// Unless SortGroups is used, the tables are in the order that their values
+// first occur in, and each table's records are in their original order, so
+// the CSV-encoded data doesn't need to be sorted by the column.  Either way,
+// all of the CSV-encoded data is read into memory.  SplitBy and GroupBy are
+// mutually exclusive; the last one called is used.
+func (t *Transmogrifier) SplitBy(column string, opts ...GroupOption) {
+	t.GroupBy(column, opts...)
+	t.group.split = true
+}
+
+// orderSplit orders the buffered records by the row that their value first
+// occurs in so that each value has one table.  The order is stable; within
+// a table, the records retain their original order.
+func (t *Transmogrifier) orderSplit() {
+	first := make(map[string]int)
+	for _, record := range t.records {
+		v := t.group.value(record)
+		if _, ok := first[v]; !ok {
+			first[v] = len(first)
+		}
+	}
+	sort.SliceStable(t.records, func(i, j int) bool {
+		return first[t.group.value(t.records[i])] < first[t.group.value(t.records[j])]
+	})
+}
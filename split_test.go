@@ -0,0 +1,57 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitBy(t *testing.T) {
+	csvData := []byte("Make,Model,Qty\nFord,Focus,1\nKia,Rio,2\nFord,F150,3\n")
+	tests := []struct {
+		opts     []GroupOption
+		setup    func(*Transmogrifier)
+		expected string
+	}{
+		// the tables are in the order that their values first occur in
+		{nil, nil, "## Ford\n\nMake|Model|Qty  \n---|---|---  \nFord|Focus|1  \nFord|F150|3  \n\n## Kia\n\nMake|Model|Qty  \n---|---|---  \nKia|Rio|2  \n"},
+		{[]GroupOption{SortGroups(), HideGroupColumn(), SplitHeading("### {column}: {value}")}, nil, "### Make: Ford\n\nModel|Qty  \n---|---  \nFocus|1  \nF150|3  \n\n### Make: Kia\n\nModel|Qty  \n---|---  \nRio|2  \n"},
+		// each table has its own footer and widths
+		{[]GroupOption{SortGroups(), HideGroupColumn()}, func(t *Transmogrifier) {
+			t.Pretty = true
+			t.FooterLabel = "Total"
+			t.SetColumnAggregate("Qty", "sum")
+		}, "## Ford\n\nModel    |Qty    \n---------|-----  \nFocus    |1      \nF150     |3      \n__Total__|__4__  \n\n## Kia\n\nModel    |Qty    \n---------|-----  \nRio      |2      \n__Total__|__2__  \n"},
+		{[]GroupOption{SortGroups()}, func(t *Transmogrifier) { t.SetFilter(`Make == "Nope"`) }, ""},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.SplitBy("Make", test.opts...)
+		if test.setup != nil {
+			test.setup(calvin)
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+// TestSplitByUnsorted checks that each value of unsorted data has one table.
+func TestSplitByUnsorted(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader([]byte("Team,Name\nPlatform,Ann\nWeb,Bob\nPlatform,Cat\nWeb,Dan\nPlatform,Eve\n")), &w)
+	calvin.SplitBy("Team", HideGroupColumn())
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "## Platform\n\nName  \n---  \nAnn  \nCat  \nEve  \n\n## Web\n\nName  \n---  \nBob  \nDan  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+}
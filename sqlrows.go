@@ -0,0 +1,61 @@
+package csv2md
+
+import (
+	"database/sql"
+	"io"
+)
+
+// WithNullPlaceholder sets the value that is written for NULLs by
+// FromRows.  The default is an empty string.
+func WithNullPlaceholder(s string) Option {
+	return func(t *Transmogrifier) {
+		t.null = s
+	}
+}
+
+// FromRows writes the result set, rows, to w as a GitHub Flavored Markdown
+// table.  The column names are the header record and each row is a record.
+// The values are converted to strings the same way as Rows.Scan converts
+// them; NULLs are written as the placeholder set by WithNullPlaceholder.
+// The options are applied, in order, to the Transmogrifier that is used to
+// write the table; see Encoder for which settings apply.
+//
+// FromRows reads all of the rows; it does not close rows.
+func FromRows(rows *sql.Rows, w io.Writer, opts ...Option) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	enc := NewEncoder(w, opts...)
+	err = enc.WriteHeader(columns)
+	if err != nil {
+		return err
+	}
+	vals := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+	record := make([]string, len(columns))
+	for rows.Next() {
+		err = rows.Scan(dest...)
+		if err != nil {
+			return err
+		}
+		for i, v := range vals {
+			record[i] = enc.t.null
+			if v.Valid {
+				record[i] = v.String
+			}
+		}
+		err = enc.WriteRecord(record)
+		if err != nil {
+			return err
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+	return enc.Flush()
+}
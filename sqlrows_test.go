@@ -0,0 +1,113 @@
+package csv2md
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// testDriver is a database/sql driver whose queries return the result set
+// with the query's name.
+type testDriver struct{}
+
+type testResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var testResults = map[string]testResult{
+	"cars": {
+		[]string{"Make", "Model", "Year", "Price", "Sold"},
+		[][]driver.Value{
+			{"Chevrolet", []byte("Volt"), int64(2017), 31000.5, true},
+			{"Ford", nil, int64(2012), nil, false},
+		},
+	},
+	"dates": {
+		[]string{"Date"},
+		[][]driver.Value{{time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)}},
+	},
+	"empty": {[]string{"a", "b"}, nil},
+}
+
+func (testDriver) Open(name string) (driver.Conn, error) { return testConn{}, nil }
+
+type testConn struct{}
+
+func (testConn) Prepare(query string) (driver.Stmt, error) { return testStmt(query), nil }
+func (testConn) Close() error                              { return nil }
+func (testConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type testStmt string
+
+func (s testStmt) Close() error  { return nil }
+func (s testStmt) NumInput() int { return 0 }
+func (s testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	res, ok := testResults[string(s)]
+	if !ok {
+		return nil, errors.New("unknown query")
+	}
+	return &testRows{res: res}, nil
+}
+
+type testRows struct {
+	res testResult
+	i   int
+}
+
+func (r *testRows) Columns() []string { return r.res.columns }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.res.rows) {
+		return io.EOF
+	}
+	copy(dest, r.res.rows[r.i])
+	r.i++
+	return nil
+}
+
+func init() {
+	sql.Register("csv2mdtest", testDriver{})
+}
+
+func TestFromRows(t *testing.T) {
+	db, err := sql.Open("csv2mdtest", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+	tests := []struct {
+		query    string
+		opts     []Option
+		expected string
+	}{
+		{"cars", nil, "Make|Model|Year|Price|Sold  \n---|---|---|---|---  \nChevrolet|Volt|2017|31000.5|true  \nFord| |2012| |false  \n"},
+		{"cars", []Option{WithNullPlaceholder("NULL"), WithFieldAlignment([]string{"", "", "r", "r"})}, "Make|Model|Year|Price|Sold  \n---|---|--:|--:|---  \nChevrolet|Volt|2017|31000.5|true  \nFord|NULL|2012|NULL|false  \n"},
+		{"dates", nil, "Date  \n---  \n2017-01-02T03:04:05Z  \n"},
+		{"empty", nil, "a|b  \n---|---  \n"},
+	}
+	for i, test := range tests {
+		rows, err := db.Query(test.query)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		var w bytes.Buffer
+		err = FromRows(rows, &w, test.opts...)
+		rows.Close()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
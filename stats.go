@@ -0,0 +1,62 @@
+package csv2md
+
+import "time"
+
+// Stats are the statistics of a conversion; see Transmogrifier.Stats.
+type Stats struct {
+	// RowsRead is the number of records read from the CSV-encoded data,
+	// not counting the header record.
+	RowsRead int64
+	// RowsWritten is the number of records written to the table.
+	RowsWritten int64
+	// RowsSkipped is the number of blank records that were skipped; see
+	// SkipBlankRecords.
+	RowsSkipped int64
+	// RowsFiltered is the number of records that weren't included in the
+	// table: those that didn't match the filter, the duplicates, and those
+	// that weren't sampled or were outside of the row range.
+	RowsFiltered int64
+	// RowsMalformed is the number of malformed records that were skipped;
+	// see SkipMalformedRows.
+	RowsMalformed int64
+	// BytesRead and BytesWritten are the number of bytes read and written;
+	// see BytesRead and BytesWritten.
+	BytesRead, BytesWritten int64
+	// Elapsed is how long it took to write the table.
+	Elapsed time.Duration
+}
+
+// RowsPerSecond returns the number of records read per second.
+func (s Stats) RowsPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.RowsRead) / s.Elapsed.Seconds()
+}
+
+// BytesPerSecond returns the number of bytes read per second.
+func (s Stats) BytesPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.BytesRead) / s.Elapsed.Seconds()
+}
+
+// Stats returns the statistics of the conversion; once MDTable has
+// returned, they are those of the table, even if it wasn't finished.
+func (t *Transmogrifier) Stats() Stats {
+	s := Stats{
+		RowsRead:      int64(t.nRead),
+		RowsWritten:   t.nRows,
+		RowsSkipped:   t.nSkipped,
+		RowsFiltered:  t.nExcluded,
+		RowsMalformed: t.nMalformed,
+		BytesRead:     t.BytesRead(),
+		BytesWritten:  t.wBytes,
+		Elapsed:       t.elapsed,
+	}
+	if t.HasHeaderRecord && s.RowsRead > 0 {
+		s.RowsRead--
+	}
+	return s
+}
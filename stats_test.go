@@ -0,0 +1,76 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	csvData := "Make,Price\nFord,1\nKia,2\n,\nFord,1\nVW,3\nBMW,4\n"
+	tests := []struct {
+		filter   string
+		dedup    bool
+		limit    int
+		tail     int
+		expected Stats
+	}{
+		{"", false, 0, 0, Stats{RowsRead: 6, RowsWritten: 5, RowsSkipped: 1}},
+		{"Price > 1", false, 0, 0, Stats{RowsRead: 6, RowsWritten: 3, RowsSkipped: 1, RowsFiltered: 2}},
+		{"", true, 0, 0, Stats{RowsRead: 6, RowsWritten: 4, RowsSkipped: 1, RowsFiltered: 1}},
+		{"", true, 0, 2, Stats{RowsRead: 6, RowsWritten: 2, RowsSkipped: 1, RowsFiltered: 3}},
+		{"Price > 1", false, 1, 0, Stats{RowsRead: 5, RowsWritten: 1, RowsSkipped: 1, RowsFiltered: 3}},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.SkipBlankRecords = true
+		if test.filter != "" {
+			err := calvin.SetFilter(test.filter)
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+				continue
+			}
+		}
+		if test.dedup {
+			calvin.Dedup()
+		}
+		if test.limit > 0 {
+			calvin.SetRowRange(0, test.limit)
+		}
+		if test.tail > 0 {
+			calvin.SetTail(test.tail)
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		got := calvin.Stats()
+		if got.Elapsed <= 0 {
+			t.Errorf("%d: elapsed: got %s, want more than 0", i, got.Elapsed)
+		}
+		if got.BytesWritten != int64(w.Len()) {
+			t.Errorf("%d: bytes written: got %d want %d", i, got.BytesWritten, w.Len())
+		}
+		got.Elapsed, got.BytesRead, got.BytesWritten = 0, 0, 0
+		if got != test.expected {
+			t.Errorf("%d: got %+v want %+v", i, got, test.expected)
+		}
+	}
+}
+
+func TestStatsThroughput(t *testing.T) {
+	s := Stats{RowsRead: 50, BytesRead: 2000, Elapsed: 500 * time.Millisecond}
+	if s.RowsPerSecond() != 100 {
+		t.Errorf("rows per second: got %f want 100", s.RowsPerSecond())
+	}
+	if s.BytesPerSecond() != 4000 {
+		t.Errorf("bytes per second: got %f want 4000", s.BytesPerSecond())
+	}
+	var zero Stats
+	if zero.RowsPerSecond() != 0 || zero.BytesPerSecond() != 0 {
+		t.Errorf("zero stats: got %f, %f want 0, 0", zero.RowsPerSecond(), zero.BytesPerSecond())
+	}
+}
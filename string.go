@@ -0,0 +1,79 @@
+package csv2md
+
+import (
+	"bytes"
+	"io"
+)
+
+// Option configures a Transmogrifier.
+type Option func(*Transmogrifier)
+
+// WithHeaderRecord sets whether the CSV-encoded data has a header record;
+// see HasHeaderRecord.
+func WithHeaderRecord(b bool) Option {
+	return func(t *Transmogrifier) {
+		t.HasHeaderRecord = b
+	}
+}
+
+// WithFieldNames sets the field names; see SetFieldNames.
+func WithFieldNames(vals []string) Option {
+	return func(t *Transmogrifier) {
+		t.SetFieldNames(vals)
+	}
+}
+
+// WithFieldAlignment sets the field alignment; see SetFieldAlignment.
+func WithFieldAlignment(vals []string) Option {
+	return func(t *Transmogrifier) {
+		t.SetFieldAlignment(vals)
+	}
+}
+
+// WithFieldStyle sets the field styling; see SetFieldStyle.
+func WithFieldStyle(vals []string) Option {
+	return func(t *Transmogrifier) {
+		t.SetFieldStyle(vals)
+	}
+}
+
+// WithNewLine sets the new line sequence; see SetNewLine.
+func WithNewLine(s string) Option {
+	return func(t *Transmogrifier) {
+		t.SetNewLine(s)
+	}
+}
+
+// TableString returns the CSV-encoded data read from r as a GitHub
+// Flavored Markdown table.  The options are applied, in order, to the
+// Transmogrifier before the table is created.
+func TableString(r io.Reader, opts ...Option) (string, error) {
+	t := NewTransmogrifier(r, nil)
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t.String()
+}
+
+// String returns the table as a string instead of writing it to the
+// Transmogrifier's writer.  The bytes are still counted by BytesWritten.
+func (t *Transmogrifier) String() (string, error) {
+	var b bytes.Buffer
+	_, err := t.WriteTo(&b)
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// WriteTo writes the table to w instead of the Transmogrifier's writer.
+// The number of bytes written is returned; they are also counted by
+// BytesWritten.  This implements io.WriterTo.
+func (t *Transmogrifier) WriteTo(w io.Writer) (int64, error) {
+	orig := t.w
+	t.w = w
+	defer func() { t.w = orig }()
+	n := t.wBytes
+	err := t.MDTable()
+	return t.wBytes - n, err
+}
@@ -0,0 +1,68 @@
+package csv2md
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+var carsCSV = "Manufacturer,Model,Type,Year\nFord,Focus,Sedan,2015\nChevy,Malibu,Sedan,2015\n"
+
+func TestTableString(t *testing.T) {
+	tests := []struct {
+		opts     []Option
+		expected string
+	}{
+		{nil, "Manufacturer|Model|Type|Year  \n---|---|---|---  \nFord|Focus|Sedan|2015  \nChevy|Malibu|Sedan|2015  \n"},
+		{[]Option{WithFieldNames([]string{"Make", "Model", "Type", "Yr"}), WithFieldAlignment([]string{"c", "l", "l", "r"}), WithFieldStyle([]string{"b", "i", "", "s"})},
+			"Make|Model|Type|Yr  \n:--:|:--|:--|--:  \n__Ford__|_Focus_|Sedan|~~2015~~  \n__Chevy__|_Malibu_|Sedan|~~2015~~  \n"},
+		{[]Option{WithHeaderRecord(false), WithNewLine("\r\n")},
+			"Column 1|Column 2|Column 3|Column 4  \r\n---|---|---|---  \r\nManufacturer|Model|Type|Year  \r\nFord|Focus|Sedan|2015  \r\nChevy|Malibu|Sedan|2015  \r\n"},
+		{[]Option{func(t *Transmogrifier) { t.CollapseRepeats([]string{"Type"}) }},
+			"Manufacturer|Model|Type|Year  \n---|---|---|---  \nFord|Focus|Sedan|2015  \nChevy|Malibu| |2015  \n"},
+	}
+	for i, test := range tests {
+		s, err := TableString(strings.NewReader(carsCSV), test.opts...)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if s != test.expected {
+			t.Errorf("%d: got %q want %q", i, s, test.expected)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(carsCSV), &w)
+	s, err := calvin.String()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if w.Len() != 0 {
+		t.Errorf("expected nothing to be written to the writer, got %q", w.String())
+	}
+	if int64(len(s)) != calvin.BytesWritten() {
+		t.Errorf("bytes written: got %d want %d", calvin.BytesWritten(), len(s))
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(strings.NewReader(carsCSV), nil)
+	calvin.SetFieldStyle([]string{"b", "", "", ""})
+	var _ io.WriterTo = calvin
+	n, err := calvin.WriteTo(&w)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "Manufacturer|Model|Type|Year  \n---|---|---|---  \n__Ford__|Focus|Sedan|2015  \n__Chevy__|Malibu|Sedan|2015  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+	if n != int64(w.Len()) || n != calvin.BytesWritten() {
+		t.Errorf("got %d bytes, %d bytes written, want %d", n, calvin.BytesWritten(), w.Len())
+	}
+}
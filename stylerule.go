@@ -0,0 +1,208 @@
+package csv2md
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AllColumns can be used as the column of a cell style rule to apply the
+// rule to every cell in the record.
+const AllColumns = "*"
+
+type styleRule struct {
+	column    string
+	predicate func(value string, record []string) bool
+	style     string
+	index     int
+	// the columns referenced by the rule's expression, if it has one
+	columns []string
+	expr    string
+}
+
+// SetCellStyleRule adds a rule that applies the style to the named
+// column's cell when the predicate returns true.  The predicate is called
+// with the cell's raw value, before any substitution or formatting, and
+// the raw record.  If the column is AllColumns, the rule is evaluated for,
+// and applied to, every cell in the record.  See SetFieldStyle for the
+// accepted style values.
+//
+// Rule styles are layered on top of the column's base style.  When
+// multiple rules match, their styles are applied in the order that the
+// rules were added; a style that has already been applied to the cell is
+// not applied again.  The predicate must not retain the record; see
+// ReuseRecord.
+func (t *Transmogrifier) SetCellStyleRule(column string, predicate func(value string, record []string) bool, style string) {
+	t.styleRules = append(t.styleRules, &styleRule{column: column, predicate: predicate, style: parseStyle(style)})
+}
+
+// SetCellStyleExpr adds a cell style rule whose predicate is an
+// expression; e.g. `Amount < 0`.  See SetCellStyleRule for how the rules
+// are applied.  The expression is evaluated against the raw record: column
+// names in the expression refer to the record's values for those columns;
+// a column that isn't in the header is an UnknownColumnError.  If the
+// expression cannot be evaluated for a record, e.g. a value in an
+// arithmetic operation isn't a number, the rule doesn't match and a warning
+// is added.
+func (t *Transmogrifier) SetCellStyleExpr(column, expr, style string) error {
+	n, err := parseExpr(expr)
+	if err != nil {
+		return err
+	}
+	t.SetCellStyleRule(column, func(_ string, record []string) bool {
+		v, err := n.eval(recordEnv(t.header, record))
+		if err != nil {
+			t.warnf("row %d: style rule %q: %s", t.row, expr, err)
+			return false
+		}
+		return v.truth()
+	}, style)
+	r := t.styleRules[len(t.styleRules)-1]
+	r.columns, r.expr = exprColumns(n), expr
+	return nil
+}
+
+// ParseStyleIf parses a style-if rule of the form expression=style;
+// e.g. "Amount<0=bold" and adds it as a cell style rule.  The styled column
+// is the first column referenced in the expression.
+func (t *Transmogrifier) ParseStyleIf(rule string) error {
+	i := strings.LastIndex(rule, "=")
+	if i < 0 {
+		return fmt.Errorf("style rule %q: expected expression=style", rule)
+	}
+	expr, style := rule[:i], rule[i+1:]
+	if parseStyle(style) == "" {
+		return fmt.Errorf("style rule %q: unknown style %q", rule, style)
+	}
+	n, err := parseExpr(expr)
+	if err != nil {
+		return err
+	}
+	cols := exprColumns(n)
+	if len(cols) == 0 {
+		return fmt.Errorf("style rule %q: no column in expression", rule)
+	}
+	return t.SetCellStyleExpr(cols[0], expr, style)
+}
+
+// ParseRule parses a conditional formatting rule of the form
+// expression:style or expression:style:target; e.g. "Price>100:bold" or
+// `Status=="failed":strikethrough:*`, and adds it as a cell style rule.  The
+// target is the column whose cell is styled, or AllColumns to style every
+// cell in the record; if there is no target, the styled column is the
+// first column referenced in the expression.
+func (t *Transmogrifier) ParseRule(rule string) error {
+	expr, style, target := rule, "", ""
+	i := strings.LastIndex(expr, ":")
+	if i < 0 {
+		return fmt.Errorf("rule %q: expected expression:style", rule)
+	}
+	expr, style = expr[:i], expr[i+1:]
+	if parseStyle(style) == "" {
+		// the last part is the target
+		i = strings.LastIndex(expr, ":")
+		if i < 0 {
+			return fmt.Errorf("rule %q: unknown style %q", rule, style)
+		}
+		target = strings.TrimSpace(style)
+		expr, style = expr[:i], expr[i+1:]
+		if parseStyle(style) == "" {
+			return fmt.Errorf("rule %q: unknown style %q", rule, style)
+		}
+	}
+	n, err := parseExpr(expr)
+	if err != nil {
+		return err
+	}
+	if target == "" {
+		cols := exprColumns(n)
+		if len(cols) == 0 {
+			return fmt.Errorf("rule %q: no column in expression", rule)
+		}
+		target = cols[0]
+	}
+	return t.SetCellStyleExpr(target, expr, style)
+}
+
+// prepareStyleRules resolves the style rules' columns against the header.
+func (t *Transmogrifier) prepareStyleRules(header []string) error {
+	for _, r := range t.styleRules {
+		if r.column == AllColumns {
+			r.index = -1
+			continue
+		}
+		r.index = columnIndex(header, r.column)
+		if r.index < 0 {
+			return UnknownColumnError{Name: r.column, operation: "style rule"}
+		}
+	}
+	for _, r := range t.styleRules {
+		for _, name := range r.columns {
+			if columnIndex(header, name) < 0 {
+				return UnknownColumnError{Name: name, operation: fmt.Sprintf("style rule %q", r.expr)}
+			}
+		}
+	}
+	return nil
+}
+
+// styled returns whether the cells' styles depend on their raw values:
+// whether there are style rules, negative styles, or a row style column.
+func (t *Transmogrifier) styled() bool {
+	return len(t.styleRules) > 0 || len(t.negatives) > 0 || t.rowStyle != nil
+}
+
+// ruleStyles returns the styles of the rules, of the negative style, and
+// of the row style that match the i'th field of the raw record.  A rule's
+// style is omitted if it is the field's base style or if a previous rule
+// has the same style.
+func (t *Transmogrifier) ruleStyles(i int, raw []string) []string {
+	var styles []string
+	if i < len(t.fieldStyle) && t.fieldStyle[i] != "" {
+		styles = append(styles, t.fieldStyle[i])
+	}
+	var v string
+	if i < len(raw) {
+		v = raw[i]
+	}
+	for _, r := range t.styleRules {
+		if r.style == "" || (r.index != i && r.index != -1) {
+			continue
+		}
+		if hasStyle(styles, r.style) || !r.predicate(v, raw) {
+			continue
+		}
+		styles = append(styles, r.style)
+	}
+	if n := t.negativeStyle(i); n != nil && n.style.Style != "" && !hasStyle(styles, n.style.Style) && t.isNegative(v) {
+		styles = append(styles, n.style.Style)
+	}
+	if t.rowStyle != nil {
+		for _, s := range t.rowStyles(raw) {
+			if !hasStyle(styles, s) {
+				styles = append(styles, s)
+			}
+		}
+	}
+	if i < len(t.fieldStyle) && t.fieldStyle[i] != "" {
+		return styles[1:]
+	}
+	return styles
+}
+
+func hasStyle(styles []string, style string) bool {
+	for _, s := range styles {
+		if s == style {
+			return true
+		}
+	}
+	return false
+}
+
+// applyStyles wraps the field in the styles' markup; the first style is
+// the innermost.
+func applyStyles(field string, styles []string) string {
+	for _, s := range styles {
+		field = s + field + s
+	}
+	return field
+}
@@ -0,0 +1,131 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetCellStyleRule(t *testing.T) {
+	csvData := []byte("Item,Amount,Status\nA,10,ok\nB,-5,cancelled\nC,-1,ok\n")
+	negative := func(v string, record []string) bool { return len(v) > 0 && v[0] == '-' }
+	cancelled := func(v string, record []string) bool { return record[2] == "cancelled" }
+	tests := []struct {
+		base     []string
+		rules    func(*Transmogrifier)
+		expected string
+	}{
+		{nil, func(calvin *Transmogrifier) {
+			calvin.SetCellStyleRule("Amount", negative, "bold")
+		}, "Item|Amount|Status  \n---|---|---  \nA|10|ok  \nB|__-5__|cancelled  \nC|__-1__|ok  \n"},
+		{[]string{"", "i", ""}, func(calvin *Transmogrifier) {
+			calvin.SetCellStyleRule("Amount", negative, "bold")
+		}, "Item|Amount|Status  \n---|---|---  \nA|_10_|ok  \nB|___-5___|cancelled  \nC|___-1___|ok  \n"},
+		{[]string{"", "b", ""}, func(calvin *Transmogrifier) {
+			calvin.SetCellStyleRule("Amount", negative, "bold")
+		}, "Item|Amount|Status  \n---|---|---  \nA|__10__|ok  \nB|__-5__|cancelled  \nC|__-1__|ok  \n"},
+		{nil, func(calvin *Transmogrifier) {
+			calvin.SetCellStyleRule("Amount", negative, "bold")
+			calvin.SetCellStyleRule(AllColumns, cancelled, "s")
+		}, "Item|Amount|Status  \n---|---|---  \nA|10|ok  \n~~B~~|~~__-5__~~|~~cancelled~~  \nC|__-1__|ok  \n"},
+		{nil, func(calvin *Transmogrifier) {
+			calvin.SetCellStyleRule(AllColumns, cancelled, "s")
+			calvin.SetCellStyleRule("Amount", negative, "bold")
+		}, "Item|Amount|Status  \n---|---|---  \nA|10|ok  \n~~B~~|__~~-5~~__|~~cancelled~~  \nC|__-1__|ok  \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		if test.base != nil {
+			calvin.SetFieldStyle(test.base)
+		}
+		test.rules(calvin)
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestParseStyleIf(t *testing.T) {
+	csvData := []byte("Item,Amount,Status\nA,10,open\nB,-5,closed\n")
+	tests := []struct {
+		rule     string
+		expected string
+		err      string
+	}{
+		{"Amount<0=bold", "Item|Amount|Status  \n---|---|---  \nA|10|Open  \nB|__-5__|Closed  \n", ""},
+		{`Status="closed"=s`, "Item|Amount|Status  \n---|---|---  \nA|10|Open  \nB|-5|~~Closed~~  \n", ""},
+		// the raw value is seen, not the mapped value
+		{"Status==\"open\"=i", "Item|Amount|Status  \n---|---|---  \nA|10|_Open_  \nB|-5|Closed  \n", ""},
+		{"Amount<0", "", `style rule "Amount<0": expected expression=style`},
+		{"Amount<0=shiny", "", `style rule "Amount<0=shiny": unknown style "shiny"`},
+		{"1<0=b", "", `style rule "1<0=b": no column in expression`},
+		{"Price<0=b", "", `style rule: unknown column "Price"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.SetColumnValueMap("Status", map[string]string{"open": "Open", "closed": "Closed"}, true)
+		err := calvin.ParseStyleIf(test.rule)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	csvData := []byte("Item,Price,Status\nA,150,ok\nB,20,failed\n")
+	tests := []struct {
+		rule     string
+		expected string
+		err      string
+	}{
+		{"Price>100:bold", "Item|Price|Status  \n---|---|---  \nA|__150__|ok  \nB|20|failed  \n", ""},
+		{"Price>100:b:Item", "Item|Price|Status  \n---|---|---  \n__A__|150|ok  \nB|20|failed  \n", ""},
+		{`Status=="failed":strikethrough:*`, "Item|Price|Status  \n---|---|---  \nA|150|ok  \n~~B~~|~~20~~|~~failed~~  \n", ""},
+		{`Status=="a:b":i`, "Item|Price|Status  \n---|---|---  \nA|150|ok  \nB|20|failed  \n", ""},
+		{"Price>100", "", `rule "Price>100": expected expression:style`},
+		{"Price>100:shiny", "", `rule "Price>100:shiny": unknown style "shiny"`},
+		{"Price>100:shiny:*", "", `rule "Price>100:shiny:*": unknown style "shiny"`},
+		{"1<0:b", "", `rule "1<0:b": no column in expression`},
+		{"Price>100:b:Cost", "", `style rule: unknown column "Cost"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		err := calvin.ParseRule(test.rule)
+		if err == nil {
+			err = calvin.MDTable()
+		}
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
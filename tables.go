@@ -0,0 +1,186 @@
+package csv2md
+
+import (
+	"strconv"
+	"strings"
+)
+
+// tables renders the records as multiple tables: a table per value of the
+// SplitBy column and, if a ChunkSize is set, a table per chunk of records.
+// Each table is rendered by its own Renderer, see formatRenderer.
+type tables struct {
+	t       *Transmogrifier
+	r       Renderer
+	header  []Cell
+	align   []Alignment
+	started bool
+	value   string // the split column's value for the current table
+	first   int    // the number, within the split table, of the chunk's first record
+	rows    int    // the number of records in the current chunk
+}
+
+func (s *tables) WriteHeader(names []string) error {
+	return s.WriteHeaderCells(nameCells(names))
+}
+
+// WriteHeaderCells keeps the header; it is written at the start of each
+// table.
+func (s *tables) WriteHeaderCells(cells []Cell) error {
+	s.header = cells
+	return nil
+}
+
+func (s *tables) WriteSeparator(alignment []Alignment) error {
+	s.align = alignment
+	if s.t.group != nil && s.t.group.split {
+		// the first table starts with the first record
+		return nil
+	}
+	return s.start()
+}
+
+func (s *tables) WriteGroup(column, value string) error {
+	return s.t.writeGroup(s.r, column, value)
+}
+
+func (s *tables) WriteRow(cells []Cell) error {
+	s.rows++
+	return s.r.WriteRow(cells)
+}
+
+func (s *tables) WriteFooter(cells []Cell) error {
+	if !s.started {
+		return nil
+	}
+	return writeFooterCells(s.r, cells)
+}
+
+func (s *tables) Close() error {
+	if !s.started {
+		return nil
+	}
+	return s.closeTable()
+}
+
+// startRow ends the current table, and starts a new one, if the record
+// starts a new split table or the current chunk is full.  It must be
+// called before the record is written.
+func (s *tables) startRow(record []string) error {
+	t := s.t
+	if t.group != nil && t.group.split {
+		v := t.group.value(record)
+		if !s.started || v != s.value {
+			if s.started {
+				err := s.end(true)
+				if err != nil {
+					return err
+				}
+			}
+			s.value = v
+			s.first = 1
+			err := s.writeHeading(v)
+			if err != nil {
+				return err
+			}
+			// each split table is totaled on its own
+			err = t.prepareFooter(t.header)
+			if err != nil {
+				return err
+			}
+			return s.start()
+		}
+	}
+	if !s.started {
+		return s.start()
+	}
+	if t.ChunkSize > 0 && s.rows >= t.ChunkSize {
+		err := s.end(false)
+		if err != nil {
+			return err
+		}
+		s.first += s.rows
+		return s.start()
+	}
+	return nil
+}
+
+// start starts a table: its header is written, if there is one, and the
+// repeated values that are collapsed and the group subheader are written
+// again.
+func (s *tables) start() error {
+	t := s.t
+	if s.first == 0 {
+		s.first = 1
+	}
+	s.started = true
+	s.rows = 0
+	if t.collapse != nil {
+		t.collapse.reset()
+	}
+	if t.group != nil {
+		t.group.seen = false
+	}
+	// each table is sized on its own
+	err := t.prepareWidths(t.header)
+	if err != nil {
+		return err
+	}
+	s.r = t.formatRenderer()
+	if s.header == nil {
+		return nil
+	}
+	err = writeHeaderCells(s.r, s.header)
+	if err != nil {
+		return err
+	}
+	return s.r.WriteSeparator(s.align)
+}
+
+// end ends the current table, writing its footer if footer is true, and
+// writes the blank line that separates it from the next table.
+func (s *tables) end(footer bool) error {
+	if footer {
+		err := s.t.writeFooter(s.r)
+		if err != nil {
+			return err
+		}
+	}
+	err := s.closeTable()
+	if err != nil {
+		return err
+	}
+	return s.t.write(s.nl(), "new line")
+}
+
+// closeTable closes the current table and, if the table is chunked and
+// has a ChunkCaption, writes the chunk's caption.
+func (s *tables) closeTable() error {
+	t := s.t
+	err := s.r.Close()
+	if err != nil {
+		return err
+	}
+	if t.ChunkSize <= 0 || t.ChunkCaption == "" || s.rows == 0 {
+		return nil
+	}
+	caption := strings.NewReplacer(
+		"{first}", strconv.Itoa(s.first),
+		"{last}", strconv.Itoa(s.first+s.rows-1),
+	).Replace(t.ChunkCaption)
+	return t.write(s.nl()+caption+s.nl(), "chunk caption")
+}
+
+// writeHeading writes the split table's heading followed by a blank line.
+func (s *tables) writeHeading(value string) error {
+	t := s.t
+	tmpl := t.group.heading
+	if tmpl == "" {
+		tmpl = DefaultSplitHeading
+	}
+	heading := strings.NewReplacer("{column}", t.escape(t.fieldName(t.group.column)), "{value}", t.escape(value)).Replace(tmpl)
+	return t.write(heading+s.nl()+s.nl(), "split heading")
+}
+
+func (s *tables) nl() string {
+	return s.t.lineEnd
+}
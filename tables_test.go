@@ -0,0 +1,48 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestChunkSize(t *testing.T) {
+	csvData := []byte("Team,Name,Qty\nWeb,Ann,1\nWeb,Bob,2\nWeb,Cy,3\nOps,Di,4\nOps,Ed,5\n")
+	tests := []struct {
+		size     int
+		caption  string
+		setup    func(*Transmogrifier)
+		expected string
+	}{
+		{2, "", nil, "Team|Name|Qty  \n---|---|---  \nWeb|Ann|1  \nWeb|Bob|2  \n\nTeam|Name|Qty  \n---|---|---  \nWeb|Cy|3  \nOps|Di|4  \n\nTeam|Name|Qty  \n---|---|---  \nOps|Ed|5  \n"},
+		{3, "_Rows {first}-{last}_", nil, "Team|Name|Qty  \n---|---|---  \nWeb|Ann|1  \nWeb|Bob|2  \nWeb|Cy|3  \n\n_Rows 1-3_\n\nTeam|Name|Qty  \n---|---|---  \nOps|Di|4  \nOps|Ed|5  \n\n_Rows 4-5_\n"},
+		{5, "_Rows {first}-{last}_", nil, "Team|Name|Qty  \n---|---|---  \nWeb|Ann|1  \nWeb|Bob|2  \nWeb|Cy|3  \nOps|Di|4  \nOps|Ed|5  \n\n_Rows 1-5_\n"},
+		// the group subheader and collapsed values are repeated in each
+		// chunk and the footer is only written after the last chunk
+		{2, "", func(t *Transmogrifier) {
+			t.GroupBy("Team", HideGroupColumn())
+			t.CollapseRepeats([]string{"Team"})
+			t.FooterLabel = "Total"
+			t.SetColumnAggregate("Qty", "sum")
+		}, "Name|Qty  \n---|---  \n**Team: Web**|   \nAnn|1  \nBob|2  \n\nName|Qty  \n---|---  \n**Team: Web**|   \nCy|3  \n**Team: Ops**|   \nDi|4  \n\nName|Qty  \n---|---  \n**Team: Ops**|   \nEd|5  \n__Total__|__15__  \n"},
+		// each split table is chunked
+		{2, "Rows {first}-{last}", func(t *Transmogrifier) { t.SplitBy("Team", HideGroupColumn()) }, "## Web\n\nName|Qty  \n---|---  \nAnn|1  \nBob|2  \n\nRows 1-2\n\nName|Qty  \n---|---  \nCy|3  \n\nRows 3-3\n\n## Ops\n\nName|Qty  \n---|---  \nDi|4  \nEd|5  \n\nRows 1-2\n"},
+		{2, "Rows {first}-{last}", func(t *Transmogrifier) { t.SetFilter("Qty > 5") }, "Team|Name|Qty  \n---|---|---  \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.ChunkSize = test.size
+		calvin.ChunkCaption = test.caption
+		if test.setup != nil {
+			test.setup(calvin)
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package csv2md
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// CellValue is a value in a cell template.  It is a string except when it
+// is formatted using one of the fmt package's number verbs, e.g. with
+// printf "%.2f", in which case a numeric value is formatted as a number
+// and any other value is written as is.
+type CellValue string
+
+// Format implements fmt.Formatter.
+func (v CellValue) Format(f fmt.State, verb rune) {
+	s := strings.TrimSpace(string(v))
+	switch verb {
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			fmt.Fprintf(f, fmt.FormatString(f, verb), n)
+			return
+		}
+	case 'd', 'b', 'o', 'x', 'X':
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			fmt.Fprintf(f, fmt.FormatString(f, verb), n)
+			return
+		}
+	}
+	switch verb {
+	case 'e', 'E', 'f', 'F', 'g', 'G', 'd', 'b', 'o', 'x', 'X':
+		f.Write([]byte(v))
+		return
+	}
+	fmt.Fprintf(f, fmt.FormatString(f, verb), string(v))
+}
+
+// CellData is the data a cell template is executed with.
+type CellData struct {
+	// Value is the cell's value.
+	Value CellValue
+	// Column is the name of the cell's column.
+	Column string
+	// Row is the record's position in the CSV-encoded data, including the
+	// header record.
+	Row int
+	// Fields are the record's values, by column name.
+	Fields map[string]CellValue
+}
+
+// TemplateError occurs when a cell template cannot be executed.
+type TemplateError struct {
+	Row    int
+	Column string
+	Err    error
+}
+
+func (e TemplateError) Error() string {
+	return fmt.Sprintf("row %d: column %q: template: %s", e.Row, e.Column, e.Err)
+}
+
+// cellTemplate transforms a column's values into Markdown.
+type cellTemplate struct {
+	column string // empty for a field template, which is positional
+	index  int
+	exec   func(w io.Writer, data CellData) error
+	refs   []string // the other columns the template uses
+	kind   string   // the kind of template, for errors; e.g. link
+}
+
+// the functions available to cell templates
+var templateFuncs = template.FuncMap{
+	"escape": func(v interface{}) string {
+		return (&Transmogrifier{EscapeMarkdown: true}).escape(fmt.Sprint(v))
+	},
+}
+
+// SetColumnTemplate sets a text/template that is executed for each of the
+// named column's cells; its result is the cell's value.  The template is
+// executed with the cell's CellData; numeric values can be formatted using
+// printf, see CellValue.  e.g.
+//
+//	{{printf "%.2f" .Value}}
+//	[{{.Value}}](https://tracker.example.com/{{.Value}})
+//
+// The result is Markdown: unlike the values, it is not escaped.  The
+// escape function escapes a value, e.g. {{escape .Value}}.  Templates are
+// executed after the value maps and before the field's styling, which
+// is applied to the result.  Setting a template for a column that already
+// has one replaces it.
+func (t *Transmogrifier) SetColumnTemplate(column, text string) error {
+	ct, err := newColumnTemplate(column, text)
+	if err != nil {
+		return err
+	}
+	t.setColumnTemplate(ct)
+	return nil
+}
+
+// newColumnTemplate returns the column's cell template.
+func newColumnTemplate(column, text string) (*cellTemplate, error) {
+	tmpl, err := template.New(column).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &cellTemplate{column: column, exec: executor(tmpl)}, nil
+}
+
+// setColumnTemplate sets the column's template, replacing any template
+// that the column already has.
+func (t *Transmogrifier) setColumnTemplate(ct *cellTemplate) {
+	column := ct.column
+	for i, v := range t.templates {
+		if v.column == column {
+			t.templates[i] = ct
+			return
+		}
+	}
+	// the column templates precede the field templates
+	t.templates = append([]*cellTemplate{ct}, t.templates...)
+}
+
+// SetFieldTemplates sets the cell templates for each field, by position;
+// a field whose template is empty has no template.  The templates are the
+// same as those of SetColumnTemplate.  A column template of the same field
+// takes precedence.
+func (t *Transmogrifier) SetFieldTemplates(vals []string) error {
+	var templates []*cellTemplate
+	// the column templates are kept; they precede the field templates
+	for _, v := range t.templates {
+		if v.column != "" {
+			templates = append(templates, v)
+		}
+	}
+	for i, v := range vals {
+		if v == "" {
+			continue
+		}
+		tmpl, err := template.New(fmt.Sprintf("field %d", i+1)).Funcs(templateFuncs).Parse(v)
+		if err != nil {
+			return err
+		}
+		templates = append(templates, &cellTemplate{index: i, exec: executor(tmpl)})
+	}
+	t.templates = templates
+	return nil
+}
+
+// executor returns a function that executes the template.
+func executor(tmpl *template.Template) func(io.Writer, CellData) error {
+	return func(w io.Writer, data CellData) error {
+		return tmpl.Execute(w, data)
+	}
+}
+
+// prepareTemplates resolves the column templates, and the columns they
+// use, against the header.
+func (t *Transmogrifier) prepareTemplates(header []string) error {
+	t.tmplHeader = header
+	for _, ct := range t.templates {
+		kind := ct.kind
+		if kind == "" {
+			kind = "template"
+		}
+		for _, ref := range ct.refs {
+			if columnIndex(header, ref) < 0 {
+				return UnknownColumnError{Name: ref, operation: kind}
+			}
+		}
+		if ct.column == "" {
+			continue
+		}
+		ct.index = columnIndex(header, ct.column)
+		if ct.index < 0 {
+			return UnknownColumnError{Name: ct.column, operation: kind}
+		}
+	}
+	return nil
+}
+
+// applyTemplates replaces the record's templated values with their
+// templates' results.  The templates are executed against the values of
+// the record before any of them are replaced.
+func (t *Transmogrifier) applyTemplates(record []string) error {
+	fields := make(map[string]CellValue, len(t.tmplHeader))
+	for i, name := range t.tmplHeader {
+		if i < len(record) {
+			fields[name] = CellValue(record[i])
+		}
+	}
+	values := make(map[int]string, len(t.templates))
+	var b bytes.Buffer
+	for _, ct := range t.templates {
+		if ct.index >= len(record) {
+			continue
+		}
+		if _, ok := values[ct.index]; ok {
+			// the field has a column template, which takes precedence
+			continue
+		}
+		var column string
+		if ct.index < len(t.tmplHeader) {
+			column = t.tmplHeader[ct.index]
+		}
+		b.Reset()
+		err := ct.exec(&b, CellData{Value: CellValue(record[ct.index]), Column: column, Row: t.row, Fields: fields})
+		if err != nil {
+			return TemplateError{Row: t.row, Column: column, Err: err}
+		}
+		values[ct.index] = b.String()
+	}
+	for i, v := range values {
+		record[i] = v
+	}
+	return nil
+}
+
+// templated returns whether the i'th field has a template.
+func (t *Transmogrifier) templated(i int) bool {
+	for _, ct := range t.templates {
+		if ct.index == i {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetColumnTemplate(t *testing.T) {
+	csvData := []byte("ID,Price,Status\n101,12.5,open\n102,3,closed_now\n")
+	tests := []struct {
+		column   string
+		tmpl     string
+		expected string
+		err      string
+	}{
+		{"Price", `{{printf "%.2f" .Value}}`, "ID|Price|Status  \n---|---|---  \n101|12.50|open  \n102|3.00|closed\\_now  \n", ""},
+		{"ID", `[{{.Value}}](https://tracker/issues/{{.Value}})`, "ID|Price|Status  \n---|---|---  \n[101](https://tracker/issues/101)|12.5|open  \n[102](https://tracker/issues/102)|3|closed\\_now  \n", ""},
+		{"Status", `{{.Fields.ID}}: {{escape .Value}}`, "ID|Price|Status  \n---|---|---  \n101|12.5|101: open  \n102|3|102: closed\\_now  \n", ""},
+		{"ID", `{{printf "%06d" .Value}} {{printf "%.1f" .Fields.Price}}`, "ID|Price|Status  \n---|---|---  \n000101 12.5|12.5|open  \n000102 3.0|3|closed\\_now  \n", ""},
+		{"Status", `{{printf "%.2f" .Value}}`, "ID|Price|Status  \n---|---|---  \n101|12.5|open  \n102|3|closed_now  \n", ""},
+		{"Status", `{{.Column}} {{.Row}}`, "ID|Price|Status  \n---|---|---  \n101|12.5|Status 2  \n102|3|Status 3  \n", ""},
+		{"Status", `{{if eq .Value "open"}}{{.Value}}{{end}}`, "ID|Price|Status  \n---|---|---  \n101|12.5|open  \n102|3|   \n", ""},
+		{"Price", `{{printf "%d" .Nope}}`, "", `row 2: column "Price": template: template: Price:1:14: executing "Price" at <.Nope>: can't evaluate field Nope in type csv2md.CellData`},
+		{"Cost", `{{.Value}}`, "", `template: unknown column "Cost"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		err := calvin.SetColumnTemplate(test.column, test.tmpl)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		err = calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestSetColumnTemplateParseError(t *testing.T) {
+	calvin := NewTransmogrifier(bytes.NewReader(nil), &bytes.Buffer{})
+	err := calvin.SetColumnTemplate("Price", "{{.Value")
+	if err == nil {
+		t.Error("expected an error, got none")
+	}
+}
+
+func TestFieldTemplates(t *testing.T) {
+	csvData := []byte("ID,Price\n101,12.5\n")
+	tests := []struct {
+		format   string
+		column   string
+		expected string
+	}{
+		{"ID,Price\n,r\n,b\n,\n,\"{{printf \"\"%.2f\"\" .Value}}\"\n", "", "ID|Price  \n---|--:  \n101|__12.50__  \n"},
+		// the column template takes precedence
+		{"ID,Price\n,\n,\n,\n,\"{{printf \"\"%.2f\"\" .Value}}\"\n", "Price", "ID|Price  \n---|---  \n101|$12.5  \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.CSV.FieldsPerRecord = -1
+		if test.column != "" {
+			err := calvin.SetColumnTemplate(test.column, "${{.Value}}")
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+				continue
+			}
+		}
+		err := calvin.SetFmt(bytes.NewReader([]byte(test.format)))
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		err = calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package csv2md
+
+import "io"
+
+// transpose reads all of the remaining records and buffers the transposed
+// table: the header becomes the first column and each record becomes a
+// column.  The transposed table's header is returned; it is nil if the
+// table has no header and no records.  The records have already been
+// computed and filtered, so the buffered records are not computed or
+// filtered again.
+func (t *Transmogrifier) transpose(header []string) ([]string, error) {
+	var rows [][]string
+	if header != nil {
+		rows = append(rows, header)
+	}
+	for {
+		record, err := t.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, t.keep(record))
+	}
+	var n int
+	for _, row := range rows {
+		if len(row) > n {
+			n = len(row)
+		}
+	}
+	transposed := make([][]string, n)
+	for i := range transposed {
+		transposed[i] = make([]string, len(rows))
+		for j, row := range rows {
+			if i < len(row) {
+				transposed[i][j] = row[i]
+			}
+		}
+	}
+	t.records = transposed
+	t.buffered = true
+	t.nComputed = len(t.records)
+	t.nFiltered = len(t.records)
+	if header == nil || len(transposed) == 0 {
+		t.header = nil
+		return nil, nil
+	}
+	t.header = t.records[0]
+	t.records = t.records[1:]
+	t.nComputed--
+	t.nFiltered--
+	return t.header, nil
+}
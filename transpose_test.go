@@ -0,0 +1,43 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTranspose(t *testing.T) {
+	tests := []struct {
+		csv      string
+		setup    func(*Transmogrifier)
+		expected string
+	}{
+		{"Make,Model,Year\nFord,Focus,2012\n", nil, "Make|Ford  \n---|---  \nModel|Focus  \nYear|2012  \n"},
+		{"Make,Model,Year\nFord,Focus,2012\nKia,Rio\n", func(t *Transmogrifier) { t.CSV.FieldsPerRecord = -1 }, "Make|Ford|Kia  \n---|---|---  \nModel|Focus|Rio  \nYear|2012|   \n"},
+		{"Make,Model\n", nil, "Make  \n---  \nModel  \n"},
+		{"Ford,Focus\nKia,Rio\n", func(t *Transmogrifier) { t.HasHeaderRecord = false }, "Column 1|Column 2  \n---|---  \nFord|Kia  \nFocus|Rio  \n"},
+		// computed columns and filters are applied before transposing and
+		// the other settings after
+		{"Make,Qty,Price\nFord,2,3\nKia,1,5\n", func(t *Transmogrifier) {
+			t.AddComputedExpr("Total", "Qty*Price")
+			t.SetFilter(`Make == "Ford"`)
+			t.SetFieldAlignment([]string{"", "r"})
+			t.SetFieldStyle([]string{"b"})
+		}, "Make|Ford  \n---|--:  \n__Qty__|2  \n__Price__|3  \n__Total__|6  \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader([]byte(test.csv)), &w)
+		calvin.Transpose = true
+		if test.setup != nil {
+			test.setup(calvin)
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
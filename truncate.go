@@ -0,0 +1,183 @@
+package csv2md
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ellipsis ends a truncated value.
+const ellipsis = "…"
+
+// Truncation is how a value that is wider than its column's maximum width
+// is truncated; see SetColumnTruncation.
+type Truncation int
+
+const (
+	// EllipsisTruncation ends the truncated value with an ellipsis, which
+	// counts towards the width.
+	EllipsisTruncation Truncation = iota
+	// CutTruncation cuts the value at the width, without an ellipsis.
+	CutTruncation
+	// FootnoteTruncation ends the truncated value with an ellipsis and, in
+	// Markdown, refers to a footnote with the full value, as if
+	// TruncateFootnotes were set for the column.
+	FootnoteTruncation
+)
+
+// ParseTruncation returns the Truncation for the value: ellipsis or an
+// empty string, cut, or footnote, in any case.
+func ParseTruncation(s string) (Truncation, error) {
+	switch strings.TrimSpace(strings.ToLower(s)) {
+	case "", "ellipsis":
+		return EllipsisTruncation, nil
+	case "cut":
+		return CutTruncation, nil
+	case "footnote":
+		return FootnoteTruncation, nil
+	}
+	return EllipsisTruncation, fmt.Errorf("unknown truncation %q: expected ellipsis, cut, or footnote", s)
+}
+
+// SetColumnMaxWidth sets the maximum width of the named column's values,
+// in runes; it overrides the MaxCellWidth.  A longer value is truncated
+// and ends with an ellipsis, which counts towards the width.  A width of
+// 0 means the column's values are never truncated.
+//
+// Columns with a cell template, link, or image are not truncated, since
+// their values are Markdown.  See TruncateFootnotes for keeping the full
+// values.
+func (t *Transmogrifier) SetColumnMaxWidth(column string, n int) {
+	if t.columnMaxWidth == nil {
+		t.columnMaxWidth = make(map[string]int)
+	}
+	t.columnMaxWidth[column] = n
+}
+
+// SetColumnTruncation sets how the named column's values are truncated
+// when they are wider than the column's maximum width; see
+// SetColumnMaxWidth.  By default, truncated values end with an ellipsis.
+func (t *Transmogrifier) SetColumnTruncation(column string, tr Truncation) {
+	if t.columnTruncate == nil {
+		t.columnTruncate = make(map[string]Truncation)
+	}
+	t.columnTruncate[column] = tr
+}
+
+// prepareMaxWidths resolves the columns' maximum widths, and their
+// truncations, against the header; the columns' settings take precedence
+// over the fields'.
+func (t *Transmogrifier) prepareMaxWidths(header []string) error {
+	t.maxWidths = nil
+	for i, n := range t.fieldMaxWidths {
+		if t.maxWidths == nil {
+			t.maxWidths = make(map[int]int)
+		}
+		t.maxWidths[i] = n
+	}
+	t.truncations = nil
+	for i, tr := range t.fieldTruncate {
+		if t.truncations == nil {
+			t.truncations = make(map[int]Truncation)
+		}
+		t.truncations[i] = tr
+	}
+	for column, tr := range t.columnTruncate {
+		i := columnIndex(header, column)
+		if i < 0 {
+			return UnknownColumnError{Name: column, operation: "truncation"}
+		}
+		if t.truncations == nil {
+			t.truncations = make(map[int]Truncation)
+		}
+		t.truncations[i] = tr
+	}
+	for column, n := range t.columnMaxWidth {
+		i := columnIndex(header, column)
+		if i < 0 {
+			return UnknownColumnError{Name: column, operation: "max width"}
+		}
+		if t.maxWidths == nil {
+			t.maxWidths = make(map[int]int)
+		}
+		t.maxWidths[i] = n
+	}
+	return nil
+}
+
+// maxWidth returns the maximum width of the i'th field; 0 means there is
+// no maximum.
+func (t *Transmogrifier) maxWidth(i int) int {
+	if n, ok := t.maxWidths[i]; ok {
+		return n
+	}
+	return t.MaxCellWidth
+}
+
+// truncate truncates the record's values that are wider than their
+// field's maximum width.  The full values of the truncated fields are
+// kept, by index, in truncated; if footnotes are being written, each
+// truncated value refers to a footnote with its full value.
+func (t *Transmogrifier) truncate(record []string) {
+	for k := range t.truncated {
+		delete(t.truncated, k)
+	}
+	for i, v := range record {
+		n := t.maxWidth(i)
+		if n <= 0 || t.hidden[i] || t.templated(i) || utf8.RuneCountInString(v) <= n {
+			continue
+		}
+		if t.truncated == nil {
+			t.truncated = make(map[int]string)
+		}
+		t.truncated[i] = v
+		tr := t.truncations[i]
+		if tr == CutTruncation {
+			v = cut(v, n)
+		} else {
+			// the ellipsis replaces the last rune that fits
+			v = strings.TrimRight(cut(v, n-1), " ") + ellipsis
+		}
+		if t.footnotes() || (tr == FootnoteTruncation && t.isGFM()) {
+			t.notes = append(t.notes, record[i])
+			v += "[^" + t.FootnotePrefix + strconv.Itoa(len(t.notes)) + "]"
+		}
+		record[i] = v
+	}
+}
+
+// cut returns the value's first n runes.
+func cut(v string, n int) string {
+	var runes int
+	for j := range v {
+		if runes == n {
+			return v[:j]
+		}
+		runes++
+	}
+	return v
+}
+
+// footnotes returns whether the full values of truncated cells are written
+// as footnotes: they are only written for Markdown output.
+func (t *Transmogrifier) footnotes() bool {
+	return t.TruncateFootnotes && t.isGFM()
+}
+
+// writeFootnotes writes the footnotes of the truncated values, separated
+// from the table by a blank line.
+func (t *Transmogrifier) writeFootnotes() error {
+	if len(t.notes) == 0 {
+		return nil
+	}
+	nl := t.lineEnd
+	var b strings.Builder
+	for i, note := range t.notes {
+		note = strings.Join(strings.Fields(note), " ")
+		b.WriteString(nl + "[^" + t.escape(t.FootnotePrefix+strconv.Itoa(i+1)) + "]: " + t.escape(note))
+	}
+	b.WriteString(nl)
+	t.notes = nil
+	return t.write(b.String(), "footnotes")
+}
@@ -0,0 +1,101 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMaxCellWidth(t *testing.T) {
+	csvData := []byte("ID,Message\n1,short\n2,connection reset by peer\n3,über_lange Nachricht\n")
+	tests := []struct {
+		max       int
+		columns   map[string]int
+		footnotes bool
+		prefix    string
+		format    Format
+		expected  string
+		err       string
+	}{
+		{0, nil, false, "", GFM, "ID|Message  \n---|---  \n1|short  \n2|connection reset by peer  \n3|über\\_lange Nachricht  \n", ""},
+		{10, nil, false, "", GFM, "ID|Message  \n---|---  \n1|short  \n2|connectio…  \n3|über\\_lang…  \n", ""},
+		// the ellipsis replaces the trailing space
+		{12, nil, false, "", GFM, "ID|Message  \n---|---  \n1|short  \n2|connection…  \n3|über\\_lange…  \n", ""},
+		{10, map[string]int{"Message": 0}, false, "", GFM, "ID|Message  \n---|---  \n1|short  \n2|connection reset by peer  \n3|über\\_lange Nachricht  \n", ""},
+		{0, map[string]int{"Message": 6}, false, "", GFM, "ID|Message  \n---|---  \n1|short  \n2|conne…  \n3|über\\_…  \n", ""},
+		{10, nil, true, "", GFM, "ID|Message  \n---|---  \n1|short  \n2|connectio…[^1]  \n3|über\\_lang…[^2]  \n\n[^1]: connection reset by peer\n[^2]: über\\_lange Nachricht\n", ""},
+		{10, nil, true, "log_", GFM, "ID|Message  \n---|---  \n1|short  \n2|connectio…[^log\\_1]  \n3|über\\_lang…[^log\\_2]  \n\n[^log\\_1]: connection reset by peer\n[^log\\_2]: über\\_lange Nachricht\n", ""},
+		{10, nil, true, "", HTML, "<table>\n<thead>\n<tr>\n<th>ID</th>\n<th>Message</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>1</td>\n<td>short</td>\n</tr>\n<tr>\n<td>2</td>\n<td title=\"connection reset by peer\">connectio…</td>\n</tr>\n<tr>\n<td>3</td>\n<td title=\"über_lange Nachricht\">über_lang…</td>\n</tr>\n</tbody>\n</table>\n", ""},
+		{0, map[string]int{"Msg": 6}, false, "", GFM, "", `max width: unknown column "Msg"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.MaxCellWidth = test.max
+		for column, n := range test.columns {
+			calvin.SetColumnMaxWidth(column, n)
+		}
+		calvin.TruncateFootnotes = test.footnotes
+		calvin.FootnotePrefix = test.prefix
+		calvin.OutputFormat = test.format
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestSetColumnTruncation(t *testing.T) {
+	csvData := []byte("ID,Message\n1,short\n2,connection reset by peer\n")
+	tests := []struct {
+		truncation string
+		expected   string
+		err        string
+	}{
+		{"", "ID|Message  \n---|---  \n1|short  \n2|connectio…  \n", ""},
+		{"Cut", "ID|Message  \n---|---  \n1|short  \n2|connection  \n", ""},
+		{"footnote", "ID|Message  \n---|---  \n1|short  \n2|connectio…[^1]  \n\n[^1]: connection reset by peer\n", ""},
+		{"clip", "", `unknown truncation "clip": expected ellipsis, cut, or footnote`},
+	}
+	for i, test := range tests {
+		tr, err := ParseTruncation(test.truncation)
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.MaxCellWidth = 10
+		calvin.SetColumnTruncation("Message", tr)
+		err = calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+
+	calvin := NewTransmogrifier(bytes.NewReader(csvData), &bytes.Buffer{})
+	calvin.SetColumnTruncation("Msg", CutTruncation)
+	err := calvin.MDTable()
+	if err == nil || err.Error() != `truncation: unknown column "Msg"` {
+		t.Errorf("got error %v want an unknown column error", err)
+	}
+}
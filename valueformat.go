@@ -0,0 +1,73 @@
+package csv2md
+
+// valueFormat formats a column's, or, if the column is empty, a field's,
+// values; e.g. its numbers, see SetColumnNumberFormat.  A format with a
+// ref column is formatted using the record's value of that column, e.g.
+// a currency code, see CurrencyFormat.
+type valueFormat struct {
+	column    string
+	index     int
+	kind      string // the kind of format, for errors; e.g. number format
+	format    func(v string) string
+	ref       string
+	refIndex  int
+	formatRef func(v, ref string) string
+}
+
+// setValueFormat adds the value format, replacing the one of the same
+// column or field.
+func (t *Transmogrifier) setValueFormat(vf *valueFormat) {
+	for i, v := range t.valueFormats {
+		if v.column == vf.column && (vf.column != "" || v.index == vf.index) {
+			t.valueFormats[i] = vf
+			return
+		}
+	}
+	t.valueFormats = append(t.valueFormats, vf)
+}
+
+// prepareValueFormats resolves the value formats' columns against the
+// header.
+func (t *Transmogrifier) prepareValueFormats(header []string) error {
+	for _, vf := range t.valueFormats {
+		if vf.ref != "" {
+			vf.refIndex = columnIndex(header, vf.ref)
+			if vf.refIndex < 0 {
+				return UnknownColumnError{Name: vf.ref, operation: vf.kind}
+			}
+		}
+		if vf.column == "" {
+			continue
+		}
+		vf.index = columnIndex(header, vf.column)
+		if vf.index < 0 {
+			return UnknownColumnError{Name: vf.column, operation: vf.kind}
+		}
+	}
+	return nil
+}
+
+// formatValues formats the record's values.  The ref values are those of
+// the record before it is formatted.
+func (t *Transmogrifier) formatValues(record []string) {
+	var refs []string
+	for _, vf := range t.valueFormats {
+		if vf.ref != "" && refs == nil {
+			refs = append(refs, record...)
+		}
+	}
+	for _, vf := range t.valueFormats {
+		if vf.index >= len(record) {
+			continue
+		}
+		if vf.ref == "" {
+			record[vf.index] = vf.format(record[vf.index])
+			continue
+		}
+		var ref string
+		if vf.refIndex < len(refs) {
+			ref = refs[vf.refIndex]
+		}
+		record[vf.index] = vf.formatRef(record[vf.index], ref)
+	}
+}
@@ -0,0 +1,133 @@
+package csv2md
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// UnmappedValueError occurs when a column has a value map that does not
+// keep unmapped values and a value is encountered that is not in the map.
+type UnmappedValueError struct {
+	Row    int
+	Column string
+	Value  string
+}
+
+func (e UnmappedValueError) Error() string {
+	return fmt.Sprintf("row %d: column %q: no mapping for value %q", e.Row, e.Column, e.Value)
+}
+
+type valueMap struct {
+	column       string
+	m            map[string]string
+	keepUnmapped bool
+	index        int
+}
+
+// SetColumnValueMap sets a map of values to substitute for the named
+// column's values; e.g. a status code of "0" can be shown as "open".  If
+// a value is not in the map, it is kept as is when keepUnmapped is true;
+// otherwise MDTable returns an UnmappedValueError.
+//
+// The substitution is done before the field's styling and formatting are
+// applied so that they operate on the substituted value.  Setting a map
+// for a column that already has one replaces it.
+func (t *Transmogrifier) SetColumnValueMap(column string, m map[string]string, keepUnmapped bool) {
+	vm := &valueMap{column: column, m: make(map[string]string, len(m)), keepUnmapped: keepUnmapped}
+	for k, v := range m {
+		vm.m[k] = v
+	}
+	for i, v := range t.valueMaps {
+		if v.column == column {
+			t.valueMaps[i] = vm
+			return
+		}
+	}
+	t.valueMaps = append(t.valueMaps, vm)
+}
+
+// prepareValueMaps resolves the value maps' columns against the header.
+func (t *Transmogrifier) prepareValueMaps(header []string) error {
+	for _, vm := range t.valueMaps {
+		vm.index = columnIndex(header, vm.column)
+		if vm.index < 0 {
+			return UnknownColumnError{Name: vm.column, operation: "value map"}
+		}
+	}
+	return nil
+}
+
+// mapValues substitutes the mapped values in the record.
+func (t *Transmogrifier) mapValues(record []string) error {
+	for _, vm := range t.valueMaps {
+		if vm.index >= len(record) {
+			continue
+		}
+		v, ok := vm.m[record[vm.index]]
+		if ok {
+			record[vm.index] = v
+			continue
+		}
+		if !vm.keepUnmapped {
+			return UnmappedValueError{Row: t.row, Column: vm.column, Value: record[vm.index]}
+		}
+	}
+	return nil
+}
+
+// ReadValueMap reads a value map, for SetColumnValueMap, from r.  The
+// syntax is either csv or json.  A CSV map consists of records with two
+// fields, the value to replace and the value to replace it with, and no
+// header record; it is read using the CSV reader's Comma, LazyQuotes, and
+// TrimLeadingSpace settings.  A JSON map is an object whose members are
+// the values to replace and the values to replace them with, e.g.
+// {"1": "Open", "2": "Closed"}; the replacements can also be numbers,
+// booleans, or null, which is an empty value.
+func (t *Transmogrifier) ReadValueMap(r io.Reader, syntax string) (map[string]string, error) {
+	switch strings.ToLower(syntax) {
+	case "csv":
+		c := csv.NewReader(r)
+		c.Comma = t.CSV.Comma
+		c.LazyQuotes = t.CSV.LazyQuotes
+		c.TrimLeadingSpace = t.CSV.TrimLeadingSpace
+		c.FieldsPerRecord = 2
+		records, err := c.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]string, len(records))
+		for _, record := range records {
+			m[record[0]] = record[1]
+		}
+		return m, nil
+	case "json":
+		var members map[string]interface{}
+		dec := json.NewDecoder(r)
+		dec.UseNumber()
+		err := dec.Decode(&members)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]string, len(members))
+		for k, v := range members {
+			switch v := v.(type) {
+			case string:
+				m[k] = v
+			case json.Number:
+				m[k] = v.String()
+			case bool:
+				m[k] = strconv.FormatBool(v)
+			case nil:
+				m[k] = ""
+			default:
+				return nil, fmt.Errorf("value %q: the replacement must be a string, number, boolean, or null", k)
+			}
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("value map: unsupported syntax %q: expected csv or json", syntax)
+}
@@ -0,0 +1,100 @@
+package csv2md
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetColumnValueMap(t *testing.T) {
+	csvData := []byte("ID,Status\n1,0\n2,1\n3,2\n")
+	status := map[string]string{"0": "open", "1": "closed"}
+	tests := []struct {
+		column       string
+		keepUnmapped bool
+		style        []string
+		expected     string
+		err          string
+	}{
+		{"Status", true, nil, "ID|Status  \n---|---  \n1|open  \n2|closed  \n3|2  \n", ""},
+		{"Status", true, []string{"", "b"}, "ID|Status  \n---|---  \n1|__open__  \n2|__closed__  \n3|__2__  \n", ""},
+		{"Status", false, nil, "", `row 4: column "Status": no mapping for value "2"`},
+		{"State", true, nil, "", `value map: unknown column "State"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.SetColumnValueMap(test.column, status, test.keepUnmapped)
+		if test.style != nil {
+			calvin.SetFieldStyle(test.style)
+		}
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestSetColumnValueMapReplace(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader([]byte("Status\n0\n")), &w)
+	calvin.SetColumnValueMap("Status", map[string]string{"0": "open"}, true)
+	calvin.SetColumnValueMap("Status", map[string]string{"0": "ouvert"}, true)
+	if len(calvin.valueMaps) != 1 {
+		t.Fatalf("value maps: got %d want 1", len(calvin.valueMaps))
+	}
+	err := calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if w.String() != "Status  \n---  \nouvert  \n" {
+		t.Errorf("got %q", w.String())
+	}
+}
+
+func TestReadValueMap(t *testing.T) {
+	tests := []struct {
+		data     string
+		syntax   string
+		comma    rune
+		expected map[string]string
+		err      string
+	}{
+		{"1,Open\n2,Closed\n3,\"Pending, review\"\n", "csv", ',', map[string]string{"1": "Open", "2": "Closed", "3": "Pending, review"}, ""},
+		{"1;Open\n2;Closed\n", "CSV", ';', map[string]string{"1": "Open", "2": "Closed"}, ""},
+		{"1,Open\n2\n", "csv", ',', nil, "record on line 2: wrong number of fields"},
+		{`{"1": "Open", "2": 2.50, "3": true, "4": null}`, "json", ',', map[string]string{"1": "Open", "2": "2.50", "3": "true", "4": ""}, ""},
+		{`{"1": ["Open"]}`, "json", ',', nil, `value "1": the replacement must be a string, number, boolean, or null`},
+		{`["Open"]`, "json", ',', nil, "json: cannot unmarshal array into Go value of type map[string]interface {}"},
+		{"1: Open", "yaml", ',', nil, `value map: unsupported syntax "yaml": expected csv or json`},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier(strings.NewReader(""), &bytes.Buffer{})
+		calvin.CSV.Comma = test.comma
+		m, err := calvin.ReadValueMap(strings.NewReader(test.data), test.syntax)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%d: got error %v want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(m, test.expected) {
+			t.Errorf("%d: got %v want %v", i, m, test.expected)
+		}
+	}
+}
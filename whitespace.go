@@ -0,0 +1,47 @@
+package csv2md
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeSpace trims and collapses the white space of the record's fields
+// according to TrimSpace and CollapseSpace.
+func (t *Transmogrifier) normalizeSpace(record []string) {
+	for i, v := range record {
+		if t.TrimSpace {
+			v = strings.TrimSpace(v)
+		}
+		if t.CollapseSpace {
+			v = collapseSpace(v)
+		}
+		record[i] = v
+	}
+}
+
+// collapseSpace replaces each run of white space in v with a single space.
+func collapseSpace(v string) string {
+	i := strings.IndexFunc(v, unicode.IsSpace)
+	if i < 0 {
+		return v
+	}
+	var b strings.Builder
+	b.Grow(len(v))
+	b.WriteString(v[:i])
+	var space bool
+	for _, r := range v[i:] {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
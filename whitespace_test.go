@@ -0,0 +1,54 @@
+package csv2md
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCollapseSpace(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"abc", "abc"},
+		{"a  b\t\tc", "a b c"},
+		{"  a \r\n b  ", " a b "},
+		{"Zoë  Ng", "Zoë Ng"},
+	}
+	for i, test := range tests {
+		v := collapseSpace(test.value)
+		if v != test.expected {
+			t.Errorf("%d: got %q want %q", i, v, test.expected)
+		}
+	}
+}
+
+func TestNormalizeSpace(t *testing.T) {
+	csvData := " Name ,Qty\n\"  Ann   Lee \",  3 \nBob,\"4\n\"\n"
+	tests := []struct {
+		trim, collapse bool
+		expected       string
+	}{
+		{false, false, " Name |Qty  \n---|---  \n  Ann   Lee |  3   \nBob|4<br>  \n"},
+		{true, false, "Name|Qty  \n---|---  \nAnn   Lee|3  \nBob|4  \n"},
+		{false, true, " Name |Qty  \n---|---  \n Ann Lee | 3   \nBob|4   \n"},
+		{true, true, "Name|Qty  \n:--|--:  \nAnn Lee|3  \nBob|4  \n"},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(strings.NewReader(csvData), &w)
+		calvin.TrimSpace = test.trim
+		calvin.CollapseSpace = test.collapse
+		calvin.AutoAlign = test.trim && test.collapse
+		err := calvin.MDTable()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package csv2md
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SetFieldWidths sets the minimum width, in columns, of each field in the
+// table; a wide character, e.g. a CJK ideograph or an emoji, takes two.
+// Cells that are narrower than their field's width are padded with spaces
+// according to the field's alignment: right justified fields are padded
+// with leading spaces, centered fields on both sides, and all other fields
+// with trailing spaces.  The header record separator is stretched to
+// the width.  Longer values are left alone.  A width of 0 means that the
+// field has no minimum width.
+//
+// Since no measurement of the data is needed, the widths are applied as the
+// records are streamed.
+func (t *Transmogrifier) SetFieldWidths(widths []int) {
+	t.fieldWidths = make([]int, len(widths))
+	copy(t.fieldWidths, widths)
+}
+
+// SetColumnWidth sets the minimum width, in columns, of the named column.
+// This takes precedence over the column's width in SetFieldWidths.  See
+// SetFieldWidths for how the width is applied.
+func (t *Transmogrifier) SetColumnWidth(column string, width int) {
+	if t.columnWidths == nil {
+		t.columnWidths = make(map[string]int)
+	}
+	t.columnWidths[column] = width
+}
+
+// ParseFieldWidths parses the field width values; e.g. the width row of a
+// format file.  An empty value is a width of 0.
+func ParseFieldWidths(vals []string) ([]int, error) {
+	widths := make([]int, len(vals))
+	for i, v := range vals {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("field width %q: not a valid width", v)
+		}
+		widths[i] = n
+	}
+	return widths, nil
+}
+
+// prepareWidths resolves the field and column widths against the header.
+func (t *Transmogrifier) prepareWidths(header []string) error {
+	t.widths = append([]int(nil), t.fieldWidths...)
+	for column, w := range t.columnWidths {
+		i := columnIndex(header, column)
+		if i < 0 {
+			return UnknownColumnError{Name: column, operation: "field width"}
+		}
+		for len(t.widths) <= i {
+			t.widths = append(t.widths, 0)
+		}
+		t.widths[i] = w
+	}
+	return nil
+}
+
+// width returns the minimum width of the i'th field.
+func (t *Transmogrifier) width(i int) int {
+	if i < len(t.widths) {
+		return t.widths[i]
+	}
+	return 0
+}
+
+// alignment returns the alignment of the i'th field.
+func (t *Transmogrifier) alignment(i int) string {
+	if i < len(t.fieldAlignment) {
+		return t.fieldAlignment[i]
+	}
+	return none
+}
+
+// stretchSeparator stretches the header record separator cell to the
+// width, keeping its alignment colons.
+func stretchSeparator(sep string, width int) string {
+	if len(sep) >= width {
+		return sep
+	}
+	l := strings.HasPrefix(sep, ":")
+	r := strings.HasSuffix(sep, ":")
+	n := width
+	if l {
+		n--
+	}
+	if r {
+		n--
+	}
+	s := strings.Repeat("-", n)
+	if l {
+		s = ":" + s
+	}
+	if r {
+		s += ":"
+	}
+	return s
+}
@@ -0,0 +1,92 @@
+package csv2md
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFieldWidths(t *testing.T) {
+	csvData := []byte("ID,Name,Qty\n1,Ann,3\n22,Bob,\n")
+	tests := []struct {
+		widths    []int
+		columns   map[string]int
+		alignment []string
+		style     []string
+		expected  string
+		err       string
+	}{
+		{[]int{4, 0, 5}, nil, nil, nil, "ID  |Name|Qty    \n----|---|-----  \n1   |Ann|3      \n22  |Bob|       \n", ""},
+		{[]int{4, 0, 5}, nil, []string{"r", "c", "l"}, nil, "  ID|Name|Qty    \n---:|:--:|:----  \n   1|Ann|3      \n  22|Bob|       \n", ""},
+		{[]int{0, 6}, nil, []string{"", "c"}, []string{"", "b"}, "ID| Name |Qty  \n---|:----:|---  \n1|__Ann__|3  \n22|__Bob__|   \n", ""},
+		{[]int{1, 2}, nil, nil, nil, "ID|Name|Qty  \n---|---|---  \n1|Ann|3  \n22|Bob|   \n", ""},
+		{[]int{4}, map[string]int{"ID": 2, "Qty": 4}, nil, nil, "ID|Name|Qty   \n---|---|----  \n1 |Ann|3     \n22|Bob|      \n", ""},
+		{nil, map[string]int{"Price": 4}, nil, nil, "", `field width: unknown column "Price"`},
+	}
+	for i, test := range tests {
+		var w bytes.Buffer
+		calvin := NewTransmogrifier(bytes.NewReader(csvData), &w)
+		calvin.SetFieldWidths(test.widths)
+		for k, v := range test.columns {
+			calvin.SetColumnWidth(k, v)
+		}
+		calvin.SetFieldAlignment(test.alignment)
+		calvin.SetFieldStyle(test.style)
+		err := calvin.MDTable()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		if w.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, w.String(), test.expected)
+		}
+	}
+}
+
+func TestFieldWidthsFormatFile(t *testing.T) {
+	var w bytes.Buffer
+	calvin := NewTransmogrifier(bytes.NewReader([]byte("1,Ann\n2,Bob\n")), &w)
+	calvin.HasHeaderRecord = false
+	err := calvin.SetFmt(bytes.NewReader([]byte("ID,Name\nr,\n,i\n3,\n")))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.MDTable()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := " ID|Name  \n--:|---  \n  1|_Ann_  \n  2|_Bob_  \n"
+	if w.String() != expected {
+		t.Errorf("got %q want %q", w.String(), expected)
+	}
+	err = calvin.SetFmt(bytes.NewReader([]byte("ID,Name\n,\n,\nx,\n")))
+	if err == nil || err.Error() != `field width "x": not a valid width` {
+		t.Errorf("got error %v want field width \"x\": not a valid width", err)
+	}
+}
+
+func TestStretchSeparator(t *testing.T) {
+	tests := []struct {
+		sep      string
+		width    int
+		expected string
+	}{
+		{none, 0, "---"},
+		{none, 5, "-----"},
+		{left, 4, ":---"},
+		{right, 4, "---:"},
+		{centered, 6, ":----:"},
+		{centered, 2, ":--:"},
+	}
+	for i, test := range tests {
+		s := stretchSeparator(test.sep, test.width)
+		if s != test.expected {
+			t.Errorf("%d: got %q want %q", i, s, test.expected)
+		}
+	}
+}